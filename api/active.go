@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -83,7 +84,7 @@ func (api *API) Active(c *common.Context) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = api.genNodeAndSysApp(record.Namespace, record.BatchName, record.NodeName)
+	_, err = api.genNodeAndSysApp(c.Request.Context(), record.Namespace, record.BatchName, record.NodeName)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +162,7 @@ func (api *API) checkRecord(batch *models.Batch, fingerprintValue string) (*mode
 	return record, nil
 }
 
-func (api *API) genNodeAndSysApp(ns, batchName, nodeName string) ([]specV1.Application, error) {
+func (api *API) genNodeAndSysApp(ctx context.Context, ns, batchName, nodeName string) ([]specV1.Application, error) {
 	node, err := api.nodeService.Get(ns, nodeName)
 	if err != nil {
 		if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
@@ -183,7 +184,7 @@ func (api *API) genNodeAndSysApp(ns, batchName, nodeName string) ([]specV1.Appli
 
 	}
 
-	apps, err := api.GenSysApp(node.Name, ns, SystemApps)
+	apps, err := api.GenSysApp(ctx, node.Name, ns, SystemApps)
 	if err != nil {
 		return nil, err
 	}
@@ -391,7 +391,7 @@ func TestAPI_Active_ErrSecret(t *testing.T) {
 		Version:   "123",
 	}
 	ccs.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(conf, nil).Times(2)
-	as.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
+	as.EXPECT().Create(gomock.Any(), mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
 	rs.EXPECT().GetBatch(mBatch.Name, mBatch.Namespace).Return(mBatch, nil).Times(1)
 	rs.EXPECT().GetRecordByFingerprint(mBatch.Name, mBatch.Namespace, mRecord.FingerprintValue).Return(mRecord, nil).Times(1)
 	rs.EXPECT().UpdateRecord(mRecord).Return(nil, nil).Times(1)
@@ -501,7 +501,7 @@ func TestAPI_Active(t *testing.T) {
 		Version:   "123",
 	}
 	ccs.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(conf, nil).Times(2)
-	as.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
+	as.EXPECT().Create(gomock.Any(), mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
 	rs.EXPECT().GetBatch(mBatch.Name, mBatch.Namespace).Return(mBatch, nil).Times(1)
 	rs.EXPECT().GetRecordByFingerprint(mBatch.Name, mBatch.Namespace, mRecord.FingerprintValue).Return(mRecord, nil).Times(1)
 	rs.EXPECT().UpdateRecord(mRecord).Return(nil, nil).Times(1)
@@ -524,7 +524,7 @@ func TestAPI_Active(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	ccs.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(conf, nil).Times(2)
-	as.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
+	as.EXPECT().Create(gomock.Any(), mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
 	rs.EXPECT().GetBatch(mBatch.Name, mBatch.Namespace).Return(mBatch, nil).Times(1)
 	rs.EXPECT().GetRecordByFingerprint(mBatch.Name, mBatch.Namespace, mRecord.FingerprintValue).Return(mRecord, nil).Times(1)
 	rs.EXPECT().UpdateRecord(mRecord).Return(nil, nil).Times(1)
@@ -539,7 +539,7 @@ func TestAPI_Active(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
 	ccs.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(conf, nil).Times(2)
-	as.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
+	as.EXPECT().Create(gomock.Any(), mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
 	rs.EXPECT().GetBatch(mBatch.Name, mBatch.Namespace).Return(mBatch, nil).Times(1)
 	rs.EXPECT().GetRecordByFingerprint(mBatch.Name, mBatch.Namespace, mRecord.FingerprintValue).Return(mRecord, nil).Times(1)
 	rs.EXPECT().UpdateRecord(mRecord).Return(nil, nil).Times(1)
@@ -564,7 +564,7 @@ func TestAPI_Active(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	ccs.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(conf, nil).Times(2)
-	as.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
+	as.EXPECT().Create(gomock.Any(), mNode.Namespace, gomock.Any()).Return(app, nil).Times(2)
 	rs.EXPECT().GetBatch(mBatch.Name, mBatch.Namespace).Return(mBatch, nil).Times(1)
 	rs.EXPECT().GetRecordByFingerprint(mBatch.Name, mBatch.Namespace, mRecord.FingerprintValue).Return(mRecord, nil).Times(1)
 	rs.EXPECT().UpdateRecord(mRecord).Return(nil, nil).Times(1)
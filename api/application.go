@@ -24,7 +24,7 @@ const (
 // GetApplication get a application
 func (api *API) GetApplication(c *common.Context) (interface{}, error) {
 	ns, n := c.GetNamespace(), c.GetNameFromParam()
-	app, err := api.applicationService.Get(ns, n, "")
+	app, err := api.applicationService.Get(c.Request.Context(), ns, n, "")
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +35,7 @@ func (api *API) GetApplication(c *common.Context) (interface{}, error) {
 // ListApplication list application
 func (api *API) ListApplication(c *common.Context) (interface{}, error) {
 	ns := c.GetNamespace()
-	apps, err := api.applicationService.List(ns, api.parseListOptionsAppendSystemLabel(c))
+	apps, err := api.applicationService.List(c.Request.Context(), ns, api.parseListOptionsAppendSystemLabel(c))
 	if err != nil {
 		return nil, common.Error(common.ErrRequestParamInvalid, common.Field("error", err.Error()))
 	}
@@ -56,7 +56,7 @@ func (api *API) CreateApplication(c *common.Context) (interface{}, error) {
 	}
 
 	// TODO: remove get method, return error inside service instead
-	oldApp, err := api.applicationService.Get(ns, name, "")
+	oldApp, err := api.applicationService.Get(c.Request.Context(), ns, name, "")
 	if err != nil {
 		if e, ok := err.(errors.Coder); !ok || e.Code() != common.ErrResourceNotFound {
 			return nil, err
@@ -85,7 +85,11 @@ func (api *API) CreateApplication(c *common.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	app, err = api.applicationService.CreateWithBase(ns, app, baseApp)
+	var bases []*specV1.Application
+	if baseApp != nil {
+		bases = append(bases, baseApp)
+	}
+	app, err = api.applicationService.CreateWithBases(c.Request.Context(), ns, app, bases, &models.CreateOptions{Operator: c.GetUser().ID})
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +116,7 @@ func (api *API) UpdateApplication(c *common.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	oldApp, err := api.applicationService.Get(ns, name, "")
+	oldApp, err := api.applicationService.Get(c.Request.Context(), ns, name, "")
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +132,7 @@ func (api *API) UpdateApplication(c *common.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	app, err = api.applicationService.Update(ns, app)
+	app, err = api.applicationService.UpdateWithOptions(c.Request.Context(), ns, app, &models.UpdateOptions{Operator: c.GetUser().ID})
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +157,7 @@ func (api *API) UpdateApplication(c *common.Context) (interface{}, error) {
 // DeleteApplication delete the application
 func (api *API) DeleteApplication(c *common.Context) (interface{}, error) {
 	ns, name := c.GetNamespace(), c.GetNameFromParam()
-	app, err := api.applicationService.Get(ns, name, "")
+	app, err := api.applicationService.Get(c.Request.Context(), ns, name, "")
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +168,10 @@ func (api *API) DeleteApplication(c *common.Context) (interface{}, error) {
 		return nil, common.Error(common.ErrAppReferencedByNode, common.Field("name", name))
 	}
 
-	if err := api.applicationService.Delete(ns, c.GetNameFromParam(), ""); err != nil {
+	// force deletes the app still used as the base of other apps
+	force, _ := strconv.ParseBool(c.Query("force"))
+	if err := api.applicationService.DeleteWithOptions(c.Request.Context(), ns, c.GetNameFromParam(), "",
+		&models.DeleteOptions{Operator: c.GetUser().ID, Force: force}); err != nil {
 		return nil, err
 	}
 
@@ -215,7 +222,7 @@ func (api *API) parseApplication(c *common.Context) (*models.ApplicationView, er
 func (api *API) getBaseAppIfSet(c *common.Context) (*specV1.Application, error) {
 	if base, ok := c.GetQuery("base"); ok {
 		namespace := c.GetNamespace()
-		baseApp, err := api.applicationService.Get(namespace, base, "")
+		baseApp, err := api.applicationService.Get(c.Request.Context(), namespace, base, "")
 		if err != nil {
 			return nil, err
 		}
@@ -231,6 +238,9 @@ func (api *API) parseListOptions(c *common.Context) *models.ListOptions {
 		FieldSelector: c.Query("fieldSelector"),
 		Limit:         limit,
 		Continue:      c.Query("continue"),
+		Search:        c.Query("search"),
+		OrderBy:       c.Query("orderBy"),
+		Order:         c.Query("order"),
 	}
 	return lp
 }
@@ -163,7 +163,7 @@ func TestGetContainerApplication(t *testing.T) {
 	api.secretService = mSecretService
 
 	mApp := getMockContainerApp()
-	mkApplicationService.EXPECT().Get(mApp.Namespace, "cba", "").Return(nil, errors.New("err")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mApp.Namespace, "cba", "").Return(nil, errors.New("err")).Times(1)
 	req, _ := http.NewRequest(http.MethodGet, "/v1/apps/cba", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -176,7 +176,7 @@ func TestGetContainerApplication(t *testing.T) {
 			specV1.SecretLabel: specV1.SecretRegistry,
 		},
 	}
-	mkApplicationService.EXPECT().Get(mApp.Namespace, mApp.Name, "").Return(mApp, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mApp.Namespace, mApp.Name, "").Return(mApp, nil).Times(1)
 	mSecretService.EXPECT().Get(mApp.Namespace, secret.Name, "").Return(secret, nil).Times(1)
 
 	// 200
@@ -200,7 +200,7 @@ func TestGetFunctionApplication(t *testing.T) {
 
 	mApp := getMockFunctionApp()
 
-	mkApplicationService.EXPECT().Get(mApp.Namespace, "cba", "").Return(nil, errors.New("err")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mApp.Namespace, "cba", "").Return(nil, errors.New("err")).Times(1)
 	req, _ := http.NewRequest(http.MethodGet, "/v1/apps/cba", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -222,7 +222,7 @@ func TestGetFunctionApplication(t *testing.T) {
 			"service.yml": string(data),
 		},
 	}
-	mkApplicationService.EXPECT().Get(mApp.Namespace, mApp.Name, "").Return(mApp, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mApp.Namespace, mApp.Name, "").Return(mApp, nil).Times(1)
 	mkConfigService.EXPECT().Get(mApp.Namespace, "baetyl-function-app-service-xxxxxxxxx", "").Return(config, nil).Times(1)
 
 	// 200
@@ -246,7 +246,7 @@ func TestListApplication(t *testing.T) {
 
 	mClist := &models.ApplicationList{}
 
-	mkApplicationService.EXPECT().List("baetyl-cloud", &models.ListOptions{
+	mkApplicationService.EXPECT().List(gomock.Any(), "baetyl-cloud", &models.ListOptions{
 		LabelSelector: "!" + common.LabelSystem,
 	}).Return(mClist, nil)
 
@@ -256,7 +256,7 @@ func TestListApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	mkApplicationService.EXPECT().List("baetyl-cloud", &models.ListOptions{
+	mkApplicationService.EXPECT().List(gomock.Any(), "baetyl-cloud", &models.ListOptions{
 		LabelSelector: "!" + common.LabelSystem,
 	}).Return(nil, fmt.Errorf("error"))
 
@@ -266,7 +266,7 @@ func TestListApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
-	mkApplicationService.EXPECT().List("baetyl-cloud", &models.ListOptions{
+	mkApplicationService.EXPECT().List(gomock.Any(), "baetyl-cloud", &models.ListOptions{
 		LabelSelector: "!" + common.LabelSystem,
 	}).Return(nil, fmt.Errorf("error"))
 
@@ -362,7 +362,7 @@ func TestCreateContainerApplication(t *testing.T) {
 	mkConfigService.EXPECT().Get(appView.Namespace, "agent-conf", "").Return(config, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "registry01", "").Return(secret, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(app, nil).Times(1)
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(appView)
@@ -406,8 +406,8 @@ func TestCreateContainerApplication(t *testing.T) {
 	mkConfigService.EXPECT().Get(appView.Namespace, "agent-conf", "").Return(config, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "registry01", "").Return(secret, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
 	req, _ = http.NewRequest(http.MethodPost, "/v1/apps?base=eden", bytes.NewReader(body))
@@ -435,8 +435,8 @@ func TestCreateContainerApplication(t *testing.T) {
 	mkConfigService.EXPECT().Get(appView.Namespace, "agent-conf", "").Return(config, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "registry01", "").Return(secret, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
 	req, _ = http.NewRequest(http.MethodPost, "/v1/apps?base=eden2", bytes.NewReader(body))
@@ -464,9 +464,9 @@ func TestCreateContainerApplication(t *testing.T) {
 	mkConfigService.EXPECT().Get(appView.Namespace, "agent-conf", "").Return(config, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "registry01", "").Return(secret, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
-	mkApplicationService.EXPECT().CreateWithBase(appView.Namespace, gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().CreateWithBases(gomock.Any(), appView.Namespace, gomock.Any(), gomock.Any(), &models.CreateOptions{}).Return(nil, fmt.Errorf("error")).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
 	req, _ = http.NewRequest(http.MethodPost, "/v1/apps?base=eden2", bytes.NewReader(body))
@@ -477,9 +477,9 @@ func TestCreateContainerApplication(t *testing.T) {
 	mkConfigService.EXPECT().Get(appView.Namespace, "agent-conf", "").Return(config, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "registry01", "").Return(secret, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Return(eden2, nil).Times(1)
-	mkApplicationService.EXPECT().CreateWithBase(appView.Namespace, gomock.Any(), eden2).Return(mApp, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().CreateWithBases(gomock.Any(), appView.Namespace, gomock.Any(), []*specV1.Application{eden2}, &models.CreateOptions{}).Return(mApp, nil).Times(1)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(appView.Namespace, gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
@@ -490,9 +490,9 @@ func TestCreateContainerApplication(t *testing.T) {
 	mkConfigService.EXPECT().Get(appView.Namespace, "agent-conf", "").Return(config, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "registry01", "").Return(secret, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Return(eden2, nil).Times(1)
-	mkApplicationService.EXPECT().CreateWithBase(appView.Namespace, gomock.Any(), eden2).Return(eden2, nil)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().CreateWithBases(gomock.Any(), appView.Namespace, gomock.Any(), []*specV1.Application{eden2}, &models.CreateOptions{}).Return(eden2, nil)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(appView.Namespace, gomock.Any()).Return([]string{}, nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(appView.Namespace, gomock.Any(), gomock.Any()).Return(nil).Times(1)
 	mSecretService.EXPECT().Get(appView.Namespace, "secret01", "").Return(secret, nil).Times(1)
@@ -528,7 +528,7 @@ func TestUpdateContainerApplication(t *testing.T) {
 	mSecretService.EXPECT().Get(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil).AnyTimes()
 	mSecretService.EXPECT().Get(gomock.Any(), registry.Name, gomock.Any()).Return(secret1, nil).AnyTimes()
 
-	mkApplicationService.EXPECT().Get(mApp.Namespace, "abc", gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mApp.Namespace, "abc", gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(mApp)
 	req, _ := http.NewRequest(http.MethodPut, "/v1/apps/abc", bytes.NewReader(body))
@@ -538,8 +538,8 @@ func TestUpdateContainerApplication(t *testing.T) {
 	mApp2 := getMockContainerApp()
 	mApp2.Selector = "name = test"
 
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(mApp, nil).AnyTimes()
-	mkApplicationService.EXPECT().Update(mApp.Namespace, gomock.Any()).Return(mApp2, nil)
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(mApp, nil).AnyTimes()
+	mkApplicationService.EXPECT().UpdateWithOptions(gomock.Any(), mApp.Namespace, gomock.Any(), &models.UpdateOptions{}).Return(mApp2, nil)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mApp.Namespace, mApp.Name, gomock.Any()).Return(nil).Times(2)
 	mkNodeService.EXPECT().DeleteNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, nil)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(gomock.Any(), gomock.Any()).Return([]string{}, nil)
@@ -549,14 +549,14 @@ func TestUpdateContainerApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	mkApplicationService.EXPECT().Update(mApp.Namespace, gomock.Any()).Return(nil, fmt.Errorf("error"))
+	mkApplicationService.EXPECT().UpdateWithOptions(gomock.Any(), mApp.Namespace, gomock.Any(), &models.UpdateOptions{}).Return(nil, fmt.Errorf("error"))
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(mApp)
 	req, _ = http.NewRequest(http.MethodPut, "/v1/apps/abc", bytes.NewReader(body))
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	mkApplicationService.EXPECT().Update(mApp.Namespace, gomock.Any()).Return(mApp2, nil)
+	mkApplicationService.EXPECT().UpdateWithOptions(gomock.Any(), mApp.Namespace, gomock.Any(), &models.UpdateOptions{}).Return(mApp2, nil)
 	mkNodeService.EXPECT().DeleteNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(mApp)
@@ -565,8 +565,8 @@ func TestUpdateContainerApplication(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	// 500
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(nil, nil).AnyTimes()
-	mkApplicationService.EXPECT().Update(mApp.Namespace, gomock.Any()).Return(mApp, nil)
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(nil, nil).AnyTimes()
+	mkApplicationService.EXPECT().UpdateWithOptions(gomock.Any(), mApp.Namespace, gomock.Any(), &models.UpdateOptions{}).Return(mApp, nil)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(mApp)
@@ -643,7 +643,7 @@ func TestCreateFunctionApplication(t *testing.T) {
 	config := &specV1.Configuration{}
 	app := &specV1.Application{}
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(app, nil).Times(1)
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(appView)
@@ -667,8 +667,8 @@ func TestCreateFunctionApplication(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
 	req, _ = http.NewRequest(http.MethodPost, "/v1/apps?base=eden", bytes.NewReader(body))
@@ -694,8 +694,8 @@ func TestCreateFunctionApplication(t *testing.T) {
 	}
 
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
@@ -722,8 +722,8 @@ func TestCreateFunctionApplication(t *testing.T) {
 	}
 
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(nil, errors.New("err")).Times(1)
 
 	w = httptest.NewRecorder()
@@ -753,8 +753,8 @@ func TestCreateFunctionApplication(t *testing.T) {
 		},
 	}
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 	sysconfig := &models.SysConfig{
 		Type:  common.BaetylFunctionRuntime,
 		Key:   "python36",
@@ -770,11 +770,11 @@ func TestCreateFunctionApplication(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysconfig, nil).Times(1)
 	mkConfigService.EXPECT().Upsert(appView.Namespace, gomock.Any()).Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().CreateWithBase(appView.Namespace, gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
+	mkApplicationService.EXPECT().CreateWithBases(gomock.Any(), appView.Namespace, gomock.Any(), gomock.Any(), &models.CreateOptions{}).Return(nil, fmt.Errorf("error")).Times(1)
 
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(appView)
@@ -783,8 +783,8 @@ func TestCreateFunctionApplication(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysconfig, nil).Times(1)
 	mkConfigService.EXPECT().Upsert(appView.Namespace, gomock.Any()).Return(config, nil).Times(1)
 
@@ -845,7 +845,7 @@ func TestCreateFunctionApplication(t *testing.T) {
 			},
 		},
 	}
-	mkApplicationService.EXPECT().CreateWithBase(appView.Namespace, gomock.Any(), gomock.Any()).Return(mApp, nil).Times(1)
+	mkApplicationService.EXPECT().CreateWithBases(gomock.Any(), appView.Namespace, gomock.Any(), gomock.Any(), &models.CreateOptions{}).Return(mApp, nil).Times(1)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(appView.Namespace, gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
 
 	w = httptest.NewRecorder()
@@ -855,11 +855,11 @@ func TestCreateFunctionApplication(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	mkConfigService.EXPECT().Get(appView.Namespace, "func1", "").Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), appView.Namespace, "eden2", "").Return(eden2, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysconfig, nil).Times(1)
 	mkConfigService.EXPECT().Upsert(appView.Namespace, gomock.Any()).Return(config, nil).Times(1)
-	mkApplicationService.EXPECT().CreateWithBase(appView.Namespace, gomock.Any(), gomock.Any()).Return(mApp, nil).Times(1)
+	mkApplicationService.EXPECT().CreateWithBases(gomock.Any(), appView.Namespace, gomock.Any(), gomock.Any(), &models.CreateOptions{}).Return(mApp, nil).Times(1)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(appView.Namespace, gomock.Any()).Return([]string{}, nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(appView.Namespace, gomock.Any(), gomock.Any()).Return(nil).Times(1)
 	mkConfigService.EXPECT().Get(appView.Namespace, gomock.Any(), "").Return(config, nil).AnyTimes()
@@ -1262,14 +1262,14 @@ func TestUpdateFunctionApplication(t *testing.T) {
 	}
 
 	configCode := &specV1.Configuration{}
-	mkApplicationService.EXPECT().Get(namespace, "abc", "").Return(oldApp, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), namespace, "abc", "").Return(oldApp, nil).Times(1)
 	mkConfigService.EXPECT().Get(namespace, "func2", "").Return(configCode, nil).Times(1)
 	mkConfigService.EXPECT().Get(namespace, "func3", "").Return(configCode, nil).Times(1)
 	mkConfigService.EXPECT().Get(namespace, "baetyl-function-config-app-service-2", "").Return(config2, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysconfig, nil).Times(2)
 	mkConfigService.EXPECT().Upsert(namespace, gomock.Any()).Return(config2extra, nil).Times(1)
 	mkConfigService.EXPECT().Upsert(namespace, gomock.Any()).Return(config3, nil).Times(1)
-	mkApplicationService.EXPECT().Update(namespace, gomock.Any()).Return(newApp, nil).Times(1)
+	mkApplicationService.EXPECT().UpdateWithOptions(gomock.Any(), namespace, gomock.Any(), &models.UpdateOptions{}).Return(newApp, nil).Times(1)
 	mkNodeService.EXPECT().UpdateNodeAppVersion(namespace, gomock.Any()).Return([]string{}, nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(namespace, gomock.Any(), gomock.Any()).Return(nil).Times(1)
 	mkConfigService.EXPECT().Delete(namespace, gomock.Any()).Return(nil).Times(1)
@@ -1302,23 +1302,23 @@ func TestDeleteApplication(t *testing.T) {
 	}
 
 	// 404
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(nil, common.Error(common.ErrResourceNotFound))
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(nil, common.Error(common.ErrResourceNotFound))
 	req, _ := http.NewRequest(http.MethodDelete, "/v1/apps/abc", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	// 500
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(app.Namespace, app.Name, "").Return(fmt.Errorf("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().DeleteWithOptions(gomock.Any(), app.Namespace, app.Name, "", &models.DeleteOptions{}).Return(fmt.Errorf("error")).Times(1)
 	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/abc", nil)
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	// 500
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(app.Namespace, app.Name, "").Return(nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().DeleteWithOptions(gomock.Any(), app.Namespace, app.Name, "", &models.DeleteOptions{}).Return(nil).Times(1)
 	mkNodeService.EXPECT().DeleteNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
 	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/abc", nil)
 	w = httptest.NewRecorder()
@@ -1326,8 +1326,8 @@ func TestDeleteApplication(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
 	// 200
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(app.Namespace, app.Name, "").Return(nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().DeleteWithOptions(gomock.Any(), app.Namespace, app.Name, "", &models.DeleteOptions{}).Return(nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(app.Namespace, app.Name, gomock.Any()).Return(nil).Times(1)
 	mkNodeService.EXPECT().DeleteNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
 	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/abc", nil)
@@ -1335,8 +1335,27 @@ func TestDeleteApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
+	// 409 the base of other apps
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().DeleteWithOptions(gomock.Any(), app.Namespace, app.Name, "", &models.DeleteOptions{}).
+		Return(common.Error(common.ErrResourceInUse, common.Field("name", app.Name), common.Field("users", "b"))).Times(1)
+	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/abc", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// 200 forced
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().DeleteWithOptions(gomock.Any(), app.Namespace, app.Name, "", &models.DeleteOptions{Force: true}).Return(nil).Times(1)
+	mkIndexService.EXPECT().RefreshNodesIndexByApp(app.Namespace, app.Name, gomock.Any()).Return(nil).Times(1)
+	mkNodeService.EXPECT().DeleteNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/abc?force=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
 	app.Name = "baetyl-core-test"
-	mkApplicationService.EXPECT().Get(gomock.Any(), app.Name, gomock.Any()).Return(app, nil).AnyTimes()
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), app.Name, gomock.Any()).Return(app, nil).AnyTimes()
 	mkIndexService.EXPECT().ListNodesByApp(gomock.Any(), app.Name).Return(nil, fmt.Errorf("error"))
 	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/baetyl-core-test", nil)
 	w = httptest.NewRecorder()
@@ -1408,10 +1427,10 @@ func TestDeleteApplication(t *testing.T) {
 	}
 
 	// 200
-	mkApplicationService.EXPECT().Get(gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), gomock.Any(), "abc", gomock.Any()).Return(app, nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(app.Namespace, app.Name, gomock.Any()).Return(nil)
 	mkNodeService.EXPECT().DeleteNodeAppVersion(gomock.Any(), gomock.Any()).Return(nil, nil)
-	mkApplicationService.EXPECT().Delete(app.Namespace, app.Name, "").Return(nil)
+	mkApplicationService.EXPECT().DeleteWithOptions(gomock.Any(), app.Namespace, app.Name, "", &models.DeleteOptions{}).Return(nil)
 	mkConfigService.EXPECT().Delete(app.Namespace, "baetyl-function-config-app-service-xxxxxxxxx").Return(nil).Times(1)
 
 	req, _ = http.NewRequest(http.MethodDelete, "/v1/apps/abc", nil)
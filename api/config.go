@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 	"time"
@@ -106,7 +107,7 @@ func (api *API) UpdateConfig(c *common.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	if err := api.updateNodeAndApp(ns, res, appNames); err != nil {
+	if err := api.updateNodeAndApp(c.Request.Context(), ns, res, appNames); err != nil {
 		log.L().Error("update node and app failed", log.Error(err))
 		return nil, err
 	}
@@ -146,7 +147,7 @@ func (api *API) GetAppByConfig(c *common.Context) (interface{}, error) {
 		log.L().Error("get config failed", log.Error(err))
 		return nil, err
 	}
-	return api.listAppByConfig(ns, res.Name)
+	return api.listAppByConfig(c.Request.Context(), ns, res.Name)
 }
 
 // parseAndCheckConfigModel parse and check the config model
@@ -198,9 +199,9 @@ func (api *API) parseAndCheckConfigView(c *common.Context) (*specV1.Configuratio
 	return config, nil
 }
 
-func (api *API) updateNodeAndApp(namespace string, config *specV1.Configuration, appNames []string) error {
+func (api *API) updateNodeAndApp(ctx context.Context, namespace string, config *specV1.Configuration, appNames []string) error {
 	for _, appName := range appNames {
-		app, err := api.applicationService.Get(namespace, appName, "")
+		app, err := api.applicationService.Get(ctx, namespace, appName, "")
 		if err != nil {
 			if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
 				continue
@@ -212,7 +213,7 @@ func (api *API) updateNodeAndApp(namespace string, config *specV1.Configuration,
 			continue
 		}
 		// Todo remove by list watch
-		app, err = api.applicationService.Update(namespace, app)
+		app, err = api.applicationService.Update(ctx, namespace, app)
 		if err != nil {
 			return err
 		}
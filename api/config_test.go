@@ -458,7 +458,7 @@ func TestUpdateConfig(t *testing.T) {
 	mkConfigService.EXPECT().Get(namespace, name, gomock.Any()).Return(res3, nil).Times(1)
 	mkConfigService.EXPECT().Update(namespace, gomock.Any()).Return(res, nil).Times(1)
 	mkIndexService.EXPECT().ListAppIndexByConfig(namespace, name).Return(appNames, nil).Times(1)
-	mkAppService.EXPECT().Get(namespace, "app01", "").Return(nil, errors.New("err")).Times(1)
+	mkAppService.EXPECT().Get(gomock.Any(), namespace, "app01", "").Return(nil, errors.New("err")).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(mConf2)
 	req, _ = http.NewRequest(http.MethodPut, "/v1/configs/"+name, bytes.NewReader(body))
@@ -509,9 +509,9 @@ func TestUpdateConfig(t *testing.T) {
 	mkConfigService.EXPECT().Get(namespace, name, gomock.Any()).Return(res3, nil).Times(1)
 	mkConfigService.EXPECT().Update(namespace, gomock.Any()).Return(res, nil).Times(1)
 	mkIndexService.EXPECT().ListAppIndexByConfig(namespace, name).Return(appNames, nil).Times(1)
-	mkAppService.EXPECT().Get(namespace, appNames[0], "").Return(apps[0], nil).Times(1)
-	mkAppService.EXPECT().Get(namespace, appNames[1], "").Return(apps[1], nil).Times(1)
-	mkAppService.EXPECT().Get(namespace, appNames[2], "").Return(apps[2], nil).Times(1)
+	mkAppService.EXPECT().Get(gomock.Any(), namespace, appNames[0], "").Return(apps[0], nil).Times(1)
+	mkAppService.EXPECT().Get(gomock.Any(), namespace, appNames[1], "").Return(apps[1], nil).Times(1)
+	mkAppService.EXPECT().Get(gomock.Any(), namespace, appNames[2], "").Return(apps[2], nil).Times(1)
 	w = httptest.NewRecorder()
 	body, _ = json.Marshal(mConf2)
 	req, _ = http.NewRequest(http.MethodPut, "/v1/configs/"+name, bytes.NewReader(body))
@@ -604,9 +604,9 @@ func TestGetAppByConfig(t *testing.T) {
 	}
 
 	mkIndexService.EXPECT().ListAppIndexByConfig(mConf.Namespace, mConf.Name).Return(appNames, nil).Times(1)
-	mkAppService.EXPECT().Get(mConf.Namespace, appNames[0], "").Return(apps[0], nil)
-	mkAppService.EXPECT().Get(mConf.Namespace, appNames[1], "").Return(apps[1], nil)
-	mkAppService.EXPECT().Get(mConf.Namespace, appNames[2], "").Return(apps[2], nil)
+	mkAppService.EXPECT().Get(gomock.Any(), mConf.Namespace, appNames[0], "").Return(apps[0], nil)
+	mkAppService.EXPECT().Get(gomock.Any(), mConf.Namespace, appNames[1], "").Return(apps[1], nil)
+	mkAppService.EXPECT().Get(gomock.Any(), mConf.Namespace, appNames[2], "").Return(apps[2], nil)
 
 	// 200
 	req, _ := http.NewRequest(http.MethodGet, "/v1/configs/abc/apps", nil)
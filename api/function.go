@@ -18,7 +18,7 @@ func (api *API) ListFunctionSources(c *common.Context) (interface{}, error) {
 
 // ListFunctions list functions
 func (api *API) ListFunctions(c *common.Context) (interface{}, error) {
-	res, err := api.functionService.List(c.GetUser().ID, c.Param("source"))
+	res, err := api.functionService.List(c.Request.Context(), c.GetUser().ID, c.Param("source"))
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +41,7 @@ func (api *API) ListFunctions(c *common.Context) (interface{}, error) {
 // ListFunctionVersions list versions of a function
 func (api *API) ListFunctionVersions(c *common.Context) (interface{}, error) {
 	id, n, source := c.GetUser().ID, c.Param("name"), c.Param("source")
-	res, err := api.functionService.ListFunctionVersions(id, n, source)
+	res, err := api.functionService.ListFunctionVersions(c.Request.Context(), id, n, source)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +52,7 @@ func (api *API) ListFunctionVersions(c *common.Context) (interface{}, error) {
 func (api *API) ImportFunction(c *common.Context) (interface{}, error) {
 	id, name, version, source := c.GetUser().ID, c.Param("name"), c.Param("version"), c.Param("source")
 
-	functionObj, err := api.functionService.GetFunction(id, name, version, source)
+	functionObj, err := api.functionService.GetFunction(c.Request.Context(), id, name, version, source)
 	if err != nil {
 		return nil, err
 	}
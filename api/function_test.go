@@ -101,7 +101,7 @@ func TestListFunctions(t *testing.T) {
 	}
 
 	// 200
-	mkFunctionService.EXPECT().List("default", "baiducfc").Return(functions, nil).Times(1)
+	mkFunctionService.EXPECT().List(gomock.Any(), "default", "baiducfc").Return(functions, nil).Times(1)
 	mkSysConfigService.EXPECT().ListSysConfigAll(common.BaetylFunctionRuntime).Return(runtimes, nil).Times(1)
 	req1, _ := http.NewRequest(http.MethodGet, "/v1/functions/baiducfc/functions", nil)
 	w1 := httptest.NewRecorder()
@@ -115,7 +115,7 @@ func TestListFunctions(t *testing.T) {
 	assert.Equal(t, res.Functions[1].Name, functions[1].Name)
 
 	// 500
-	mkFunctionService.EXPECT().List("default", "unknown").Return(nil, errors.New("err")).Times(1)
+	mkFunctionService.EXPECT().List(gomock.Any(), "default", "unknown").Return(nil, errors.New("err")).Times(1)
 	req2, _ := http.NewRequest(http.MethodGet, "/v1/functions/unknown/functions", nil)
 	w2 := httptest.NewRecorder()
 	router.ServeHTTP(w2, req2)
@@ -140,14 +140,14 @@ func TestListFunctionVersions(t *testing.T) {
 	}
 
 	// 200
-	mkPluginService.EXPECT().ListFunctionVersions("default", "abc", "baiducfc").Return(functions, nil).Times(1)
+	mkPluginService.EXPECT().ListFunctionVersions(gomock.Any(), "default", "abc", "baiducfc").Return(functions, nil).Times(1)
 	req, _ := http.NewRequest(http.MethodGet, "/v1/functions/baiducfc/functions/abc/versions", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// 500
-	mkPluginService.EXPECT().ListFunctionVersions("default", "cba", "baiducfc").Return(nil, errors.New("error")).Times(1)
+	mkPluginService.EXPECT().ListFunctionVersions(gomock.Any(), "default", "cba", "baiducfc").Return(nil, errors.New("error")).Times(1)
 	req, _ = http.NewRequest(http.MethodGet, "/v1/functions/baiducfc/functions/cba/versions", nil)
 	w2 := httptest.NewRecorder()
 	router.ServeHTTP(w2, req)
@@ -176,7 +176,7 @@ func TestImportFunction(t *testing.T) {
 		},
 	}
 	namespace := "default"
-	mkFunctionService.EXPECT().GetFunction(namespace, function.Name,
+	mkFunctionService.EXPECT().GetFunction(gomock.Any(), namespace, function.Name,
 		function.Version, "baiducfc").Return(function, nil).Times(1)
 
 	sysConfig := &models.SysConfig{
@@ -208,7 +208,7 @@ func TestImportFunction(t *testing.T) {
 	assert.Equal(t, "baetyl-cloud-default", res.Bucket)
 	assert.Equal(t, "9f02518384acce28a79f34df94df17062960533786214652fe6c63c27424cccf/name1.zip", res.Object)
 
-	mkFunctionService.EXPECT().GetFunction(namespace, function.Name,
+	mkFunctionService.EXPECT().GetFunction(gomock.Any(), namespace, function.Name,
 		function.Version, "baiducfc").Return(nil, errors.New("err")).Times(1)
 
 	// 500
@@ -218,7 +218,7 @@ func TestImportFunction(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	mkFunctionService.EXPECT().GetFunction(namespace, function.Name,
+	mkFunctionService.EXPECT().GetFunction(gomock.Any(), namespace, function.Name,
 		function.Version, "baiducfc").Return(function, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(sysConfig.Type, sysConfig.Key).Return(nil, errors.New("err")).Times(1)
 
@@ -229,7 +229,7 @@ func TestImportFunction(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	mkFunctionService.EXPECT().GetFunction(namespace, function.Name,
+	mkFunctionService.EXPECT().GetFunction(gomock.Any(), namespace, function.Name,
 		function.Version, "baiducfc").Return(function, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(sysConfig.Type, sysConfig.Key).Return(sysConfig, nil).Times(1)
 	mkObjectService.EXPECT().CreateBucketIfNotExist(namespace, bucket.Name, common.AWSS3PrivatePermission, sysConfig.Value).Return(nil, errors.New("err")).Times(1)
@@ -241,7 +241,7 @@ func TestImportFunction(t *testing.T) {
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	mkFunctionService.EXPECT().GetFunction(namespace, function.Name,
+	mkFunctionService.EXPECT().GetFunction(gomock.Any(), namespace, function.Name,
 		function.Version, "baiducfc").Return(function, nil).Times(1)
 	mkSysConfigService.EXPECT().GetSysConfig(sysConfig.Type, sysConfig.Key).Return(sysConfig, nil).Times(1)
 	mkObjectService.EXPECT().CreateBucketIfNotExist(namespace, bucket.Name, common.AWSS3PrivatePermission, sysConfig.Value).Return(bucket, nil).Times(1)
@@ -114,7 +114,7 @@ func (api *API) CreateNode(c *common.Context) (interface{}, error) {
 		common.BaetylCore,
 		common.BaetylFunction,
 	}
-	_, err = api.GenSysApp(name, ns, list)
+	_, err = api.GenSysApp(c.Request.Context(), name, ns, list)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +176,7 @@ func (api *API) DeleteNode(c *common.Context) (interface{}, error) {
 	sysAppInfos := node.Desire.AppInfos(true)
 	for _, ai := range sysAppInfos {
 		// Clean APP
-		app, err := api.applicationService.Get(ns, ai.Name, "")
+		app, err := api.applicationService.Get(c.Request.Context(), ns, ai.Name, "")
 		if err != nil {
 			if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
 				continue
@@ -229,7 +229,7 @@ func (api *API) DeleteNode(c *common.Context) (interface{}, error) {
 				}
 			}
 		}
-		if err := api.applicationService.Delete(ns, ai.Name, ai.Version); err != nil {
+		if err := api.applicationService.Delete(c.Request.Context(), ns, ai.Name, ai.Version); err != nil {
 			common.LogDirtyData(err,
 				log.Any("type", common.Application),
 				log.Any(common.KeyContextNamespace, ns),
@@ -268,7 +268,7 @@ func (api *API) GetAppByNode(c *common.Context) (interface{}, error) {
 		}
 	}
 
-	return api.listAppByNames(ns, appNames)
+	return api.listAppByNames(c.Request.Context(), ns, appNames)
 }
 
 // GenInitCmdFromNode generate install command
@@ -362,7 +362,7 @@ func TestCreateNode(t *testing.T) {
 		Version:   "123",
 	}
 	cs.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(conf, nil).AnyTimes()
-	as.EXPECT().Create(mNode.Namespace, gomock.Any()).Return(app, nil).AnyTimes()
+	as.EXPECT().Create(gomock.Any(), mNode.Namespace, gomock.Any()).Return(app, nil).AnyTimes()
 	ss.EXPECT().Get(mNode.Namespace, gomock.Any(), "").Return(secret, nil).AnyTimes()
 	scs.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysConf, nil).AnyTimes()
 	pki.EXPECT().SignClientCertificate(gomock.Any(), gomock.Any()).Return(certPEM, nil).AnyTimes()
@@ -632,16 +632,16 @@ func TestDeleteNode(t *testing.T) {
 
 	mkNodeService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(mNode, nil).Times(1)
 	mkNodeService.EXPECT().Delete(mNode.Namespace, mNode.Name).Return(nil).Times(1)
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appCore.Name, "").Return(appCore, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(mNode.Namespace, appCore.Name, appCore.Version).Return(nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appCore.Name, "").Return(appCore, nil).Times(1)
+	mkApplicationService.EXPECT().Delete(gomock.Any(), mNode.Namespace, appCore.Name, appCore.Version).Return(nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mNode.Namespace, appCore.Name, gomock.Any()).Return(nil).Times(1)
 	mkConfigService.EXPECT().Delete(mNode.Namespace, appCore.Volumes[0].Config.Name).Times(1)
 	mkSecretService.EXPECT().Get(mNode.Namespace, appCore.Volumes[1].Secret.Name, "").Return(secret1, nil).Times(1)
 	mkPkiService.EXPECT().DeleteClientCertificate("certId1").Return(nil).Times(1)
 	mkSecretService.EXPECT().Delete(mNode.Namespace, appCore.Volumes[1].Secret.Name).Times(1)
 
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appFunction.Name, "").Return(appFunction, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(mNode.Namespace, appFunction.Name, appFunction.Version).Return(nil).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appFunction.Name, "").Return(appFunction, nil).Times(1)
+	mkApplicationService.EXPECT().Delete(gomock.Any(), mNode.Namespace, appFunction.Name, appFunction.Version).Return(nil).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mNode.Namespace, appFunction.Name, gomock.Any()).Return(nil).Times(1)
 	mkConfigService.EXPECT().Delete(mNode.Namespace, appFunction.Volumes[0].Config.Name).Times(1)
 	mkSecretService.EXPECT().Get(mNode.Namespace, appFunction.Volumes[1].Secret.Name, "").Return(secret1f, nil).Times(1)
@@ -761,8 +761,8 @@ func TestDeleteNodeError(t *testing.T) {
 
 	mkNodeService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(mNode, nil).Times(1)
 	mkNodeService.EXPECT().Delete(mNode.Namespace, mNode.Name).Return(nil).Times(1)
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appCore.Name, "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appFunction.Name, "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appCore.Name, "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appFunction.Name, "").Return(nil, common.Error(common.ErrResourceNotFound)).Times(1)
 
 	// 200
 	req, _ := http.NewRequest(http.MethodDelete, "/v1/nodes/abc", nil)
@@ -772,16 +772,16 @@ func TestDeleteNodeError(t *testing.T) {
 
 	mkNodeService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(mNode, nil).Times(1)
 	mkNodeService.EXPECT().Delete(mNode.Namespace, mNode.Name).Return(nil).Times(1)
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appCore.Name, "").Return(appCore, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(mNode.Namespace, appCore.Name, appCore.Version).Return(errors.New("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appCore.Name, "").Return(appCore, nil).Times(1)
+	mkApplicationService.EXPECT().Delete(gomock.Any(), mNode.Namespace, appCore.Name, appCore.Version).Return(errors.New("error")).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mNode.Namespace, appCore.Name, gomock.Any()).Return(errors.New("error")).Times(1)
 	mkConfigService.EXPECT().Delete(mNode.Namespace, appCore.Volumes[0].Config.Name).Return(errors.New("error")).Times(1)
 	mkSecretService.EXPECT().Get(mNode.Namespace, appCore.Volumes[1].Secret.Name, "").Return(secret1, nil).Times(1)
 	mkPkiService.EXPECT().DeleteClientCertificate("certId1").Return(errors.New("error")).Times(1)
 	mkSecretService.EXPECT().Delete(mNode.Namespace, appCore.Volumes[1].Secret.Name).Times(1)
 
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appFunction.Name, "").Return(appFunction, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(mNode.Namespace, appFunction.Name, appFunction.Version).Return(errors.New("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appFunction.Name, "").Return(appFunction, nil).Times(1)
+	mkApplicationService.EXPECT().Delete(gomock.Any(), mNode.Namespace, appFunction.Name, appFunction.Version).Return(errors.New("error")).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mNode.Namespace, appFunction.Name, gomock.Any()).Return(errors.New("error")).Times(1)
 	mkConfigService.EXPECT().Delete(mNode.Namespace, appFunction.Volumes[0].Config.Name).Return(errors.New("error")).Times(1)
 	mkSecretService.EXPECT().Get(mNode.Namespace, appFunction.Volumes[1].Secret.Name, "").Return(nil, errors.New("error")).Times(1)
@@ -794,12 +794,12 @@ func TestDeleteNodeError(t *testing.T) {
 
 	mkNodeService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(mNode, nil).Times(1)
 	mkNodeService.EXPECT().Delete(mNode.Namespace, mNode.Name).Return(nil).Times(1)
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appCore.Name, "").Return(nil, errors.New("error")).Times(1)
-	mkApplicationService.EXPECT().Delete(mNode.Namespace, appCore.Name, appCore.Version).Return(errors.New("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appCore.Name, "").Return(nil, errors.New("error")).Times(1)
+	mkApplicationService.EXPECT().Delete(gomock.Any(), mNode.Namespace, appCore.Name, appCore.Version).Return(errors.New("error")).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mNode.Namespace, appCore.Name, gomock.Any()).Return(errors.New("error")).Times(1)
 
-	mkApplicationService.EXPECT().Get(mNode.Namespace, appFunction.Name, "").Return(appFunction, nil).Times(1)
-	mkApplicationService.EXPECT().Delete(mNode.Namespace, appFunction.Name, appFunction.Version).Return(errors.New("error")).Times(1)
+	mkApplicationService.EXPECT().Get(gomock.Any(), mNode.Namespace, appFunction.Name, "").Return(appFunction, nil).Times(1)
+	mkApplicationService.EXPECT().Delete(gomock.Any(), mNode.Namespace, appFunction.Name, appFunction.Version).Return(errors.New("error")).Times(1)
 	mkIndexService.EXPECT().RefreshNodesIndexByApp(mNode.Namespace, appFunction.Name, gomock.Any()).Return(errors.New("error")).Times(1)
 	mkConfigService.EXPECT().Delete(mNode.Namespace, appFunction.Volumes[0].Config.Name).Return(errors.New("error")).Times(1)
 	mkSecretService.EXPECT().Get(mNode.Namespace, appFunction.Volumes[1].Secret.Name, "").Return(nil, errors.New("error")).Times(1)
@@ -973,12 +973,12 @@ func TestGetAppByNode(t *testing.T) {
 	node.Desire.SetAppInfos(true, sysappinfos)
 	node.Desire.SetAppInfos(false, appinfos)
 
-	mkAppService.EXPECT().Get(node.Namespace, appNames[0], "").Return(apps[0], nil).AnyTimes()
-	mkAppService.EXPECT().Get(node.Namespace, appNames[1], "").Return(apps[1], nil).AnyTimes()
-	mkAppService.EXPECT().Get(node.Namespace, appNames[2], "").Return(nil, common.Error(common.ErrResourceNotFound)).AnyTimes()
-	mkAppService.EXPECT().Get(node.Namespace, sysAppNames[0], "").Return(sysapps[0], nil).AnyTimes()
-	mkAppService.EXPECT().Get(node.Namespace, sysAppNames[1], "").Return(sysapps[1], nil).AnyTimes()
-	mkAppService.EXPECT().Get(node.Namespace, sysAppNames[2], "").Return(nil, common.Error(common.ErrResourceNotFound)).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), node.Namespace, appNames[0], "").Return(apps[0], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), node.Namespace, appNames[1], "").Return(apps[1], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), node.Namespace, appNames[2], "").Return(nil, common.Error(common.ErrResourceNotFound)).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), node.Namespace, sysAppNames[0], "").Return(sysapps[0], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), node.Namespace, sysAppNames[1], "").Return(sysapps[1], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), node.Namespace, sysAppNames[2], "").Return(nil, common.Error(common.ErrResourceNotFound)).AnyTimes()
 
 	w4 = httptest.NewRecorder()
 	req4, _ = http.NewRequest(http.MethodGet, "/v1/nodes/abc/apps", nil)
@@ -105,7 +105,7 @@ func (api *API) RefreshRegistryPassword(c *common.Context) (interface{}, error)
 	if err = api.validateRegistryModel(res); err != nil {
 		return nil, err
 	}
-	err = api.updateAppSecret(ns, res.ToSecret())
+	err = api.updateAppSecret(c.Request.Context(), ns, res.ToSecret())
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +129,7 @@ func (api *API) GetAppByRegistry(c *common.Context) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return api.listAppBySecret(ns, res.Name)
+	return api.listAppBySecret(c.Request.Context(), ns, res.Name)
 }
 
 // parseAndCheckRegistryModel parse and check the config model
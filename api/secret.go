@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -71,7 +72,7 @@ func (api *API) UpdateSecret(c *common.Context) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = api.updateAppSecret(ns, res.ToSecret())
+	err = api.updateAppSecret(c.Request.Context(), ns, res.ToSecret())
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +96,7 @@ func (api *API) GetAppBySecret(c *common.Context) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return api.listAppBySecret(ns, res.Name)
+	return api.listAppBySecret(c.Request.Context(), ns, res.Name)
 }
 
 // parseAndCheckSecretModel parse and check the config model
@@ -146,13 +147,13 @@ func (api *API) deleteSecret(namespace, secret, secretType string) (interface{},
 	return nil, api.secretService.Delete(namespace, secret)
 }
 
-func (api *API) updateAppSecret(namespace string, secret *specV1.Secret) error {
+func (api *API) updateAppSecret(ctx context.Context, namespace string, secret *specV1.Secret) error {
 	appNames, err := api.indexService.ListAppIndexBySecret(namespace, secret.Name)
 	if err != nil {
 		return err
 	}
 	for _, appName := range appNames {
-		app, err := api.applicationService.Get(namespace, appName, "")
+		app, err := api.applicationService.Get(ctx, namespace, appName, "")
 		if err != nil {
 			if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
 				continue
@@ -162,7 +163,7 @@ func (api *API) updateAppSecret(namespace string, secret *specV1.Secret) error {
 		if !needUpdateAppSecret(secret, app) {
 			continue
 		}
-		app, err = api.applicationService.Update(namespace, app)
+		app, err = api.applicationService.Update(ctx, namespace, app)
 		if err != nil {
 			return err
 		}
@@ -188,29 +189,29 @@ func needUpdateAppSecret(secret *specV1.Secret, app *specV1.Application) bool {
 	return appNeedUpdate
 }
 
-func (api *API) listAppBySecret(namespace, secret string) (*models.ApplicationList, error) {
+func (api *API) listAppBySecret(ctx context.Context, namespace, secret string) (*models.ApplicationList, error) {
 	appNames, err := api.indexService.ListAppIndexBySecret(namespace, secret)
 	if err != nil {
 		return nil, err
 	}
-	return api.listAppByNames(namespace, appNames)
+	return api.listAppByNames(ctx, namespace, appNames)
 }
 
-func (api *API) listAppByConfig(namespace, config string) (*models.ApplicationList, error) {
+func (api *API) listAppByConfig(ctx context.Context, namespace, config string) (*models.ApplicationList, error) {
 	appNames, err := api.indexService.ListAppIndexByConfig(namespace, config)
 	if err != nil {
 		return nil, err
 	}
-	return api.listAppByNames(namespace, appNames)
+	return api.listAppByNames(ctx, namespace, appNames)
 }
 
-func (api *API) listAppByNames(namespace string, appNames []string) (*models.ApplicationList, error) {
+func (api *API) listAppByNames(ctx context.Context, namespace string, appNames []string) (*models.ApplicationList, error) {
 	result := &models.ApplicationList{
 		Total: 0,
 		Items: []models.AppItem{},
 	}
 	for _, appName := range appNames {
-		app, err := api.applicationService.Get(namespace, appName, "")
+		app, err := api.applicationService.Get(ctx, namespace, appName, "")
 		if err != nil {
 			if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
 				continue
@@ -249,10 +249,10 @@ func TestUpdateSecret(t *testing.T) {
 	mkSecretService.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(mConfSecret3, nil)
 	mkSecretService.EXPECT().Update(mConf2.Namespace, gomock.Any()).Return(mConfSecret3, nil)
 	mkIndexService.EXPECT().ListAppIndexBySecret(mConf2.Namespace, mConf2.Name).Return(appNames, nil).Times(1)
-	mkAppService.EXPECT().Get(mConf2.Namespace, appNames[0], "").Return(apps[0], nil).AnyTimes()
-	mkAppService.EXPECT().Get(mConf2.Namespace, appNames[1], "").Return(apps[1], nil).AnyTimes()
-	mkAppService.EXPECT().Get(mConf2.Namespace, appNames[2], "").Return(apps[2], nil).AnyTimes()
-	mkAppService.EXPECT().Update(mConf2.Namespace, gomock.Any()).Return(apps[0], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), mConf2.Namespace, appNames[0], "").Return(apps[0], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), mConf2.Namespace, appNames[1], "").Return(apps[1], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), mConf2.Namespace, appNames[2], "").Return(apps[2], nil).AnyTimes()
+	mkAppService.EXPECT().Update(gomock.Any(), mConf2.Namespace, gomock.Any()).Return(apps[0], nil).AnyTimes()
 	mkNodeService.EXPECT().UpdateNodeAppVersion(mConf2.Namespace, gomock.Any()).Return(nil, nil).AnyTimes()
 
 	w4 := httptest.NewRecorder()
@@ -333,9 +333,9 @@ func TestGetAppBySecret(t *testing.T) {
 	mkSecretService.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(mConfSecret3, nil)
 
 	mkIndexService.EXPECT().ListAppIndexBySecret(mConfSecret3.Namespace, mConfSecret3.Name).Return(appNames, nil).Times(1)
-	mkAppService.EXPECT().Get(mConfSecret3.Namespace, appNames[0], "").Return(apps[0], nil).AnyTimes()
-	mkAppService.EXPECT().Get(mConfSecret3.Namespace, appNames[1], "").Return(apps[1], nil).AnyTimes()
-	mkAppService.EXPECT().Get(mConfSecret3.Namespace, appNames[2], "").Return(apps[2], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), mConfSecret3.Namespace, appNames[0], "").Return(apps[0], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), mConfSecret3.Namespace, appNames[1], "").Return(apps[1], nil).AnyTimes()
+	mkAppService.EXPECT().Get(gomock.Any(), mConfSecret3.Namespace, appNames[2], "").Return(apps[2], nil).AnyTimes()
 
 	w4 := httptest.NewRecorder()
 	req4, _ := http.NewRequest(http.MethodGet, "/v1/secrets/abc/apps", nil)
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/service"
 	"github.com/baetyl/baetyl-go/log"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 )
@@ -17,20 +19,20 @@ var (
 	}
 )
 
-func (api *API) GenSysApp(nodeName, ns string, appList []common.SystemApplication) ([]specV1.Application, error) {
+func (api *API) GenSysApp(ctx context.Context, nodeName, ns string, appList []common.SystemApplication) ([]specV1.Application, error) {
 	var apps []specV1.Application
 	isSysApp := true
 	for _, appName := range appList {
 		app := specV1.Application{}
 		switch appName {
 		case common.BaetylCore:
-			res, err := api.GenCoreApp(nodeName, ns, isSysApp)
+			res, err := api.GenCoreApp(ctx, nodeName, ns, isSysApp)
 			if err != nil {
 				return nil, err
 			}
 			app = *res
 		case common.BaetylFunction:
-			res, err := api.GenFunctionApp(nodeName, ns, isSysApp)
+			res, err := api.GenFunctionApp(ctx, nodeName, ns, isSysApp)
 			if err != nil {
 				return nil, err
 			}
@@ -41,7 +43,7 @@ func (api *API) GenSysApp(nodeName, ns string, appList []common.SystemApplicatio
 	return apps, nil
 }
 
-func (api *API) GenCoreApp(nodeName, ns string, isSys bool) (*specV1.Application, error) {
+func (api *API) GenCoreApp(ctx context.Context, nodeName, ns string, isSys bool) (*specV1.Application, error) {
 	// get sys config
 	imageConf, err := api.sysConfigService.GetSysConfig(common.BaetylModule, string(common.BaetylCore))
 	if err != nil {
@@ -85,10 +87,10 @@ func (api *API) GenCoreApp(nodeName, ns string, isSys bool) (*specV1.Application
 		"ConfigVersion":   conf.Version,
 		"AppType":         common.ContainerApp,
 	}
-	return api.genApp(ns, common.TemplateJsonAppCore, appMap, isSys)
+	return api.genApp(ctx, ns, common.TemplateJsonAppCore, appMap, isSys)
 }
 
-func (api *API) GenFunctionApp(nodeName, ns string, isSys bool) (*specV1.Application, error) {
+func (api *API) GenFunctionApp(ctx context.Context, nodeName, ns string, isSys bool) (*specV1.Application, error) {
 	// get sys config
 	imageConf, err := api.sysConfigService.GetSysConfig(common.BaetylModule, string(common.BaetylFunction))
 	if err != nil {
@@ -118,7 +120,7 @@ func (api *API) GenFunctionApp(nodeName, ns string, isSys bool) (*specV1.Applica
 		"ConfigVersion": conf.Version,
 		"AppType":       common.ContainerApp,
 	}
-	return api.genApp(ns, common.TemplateJsonAppFunction, appMap, isSys)
+	return api.genApp(ctx, ns, common.TemplateJsonAppFunction, appMap, isSys)
 }
 
 func (api *API) genCertSync(appName, nodeName, ns string, module common.SystemApplication, isSys bool) (*specV1.Secret, error) {
@@ -178,21 +180,25 @@ func (api *API) genConfig(ns, template string, params map[string]string, isSys b
 	return conf, nil
 }
 
-func (api *API) genApp(ns, template string, params map[string]string, isSys bool) (*specV1.Application, error) {
+func (api *API) genApp(ctx context.Context, ns, template string, params map[string]string, isSys bool) (*specV1.Application, error) {
 	appJson, err := api.ParseTemplate(template, params)
 	if err != nil {
 		return nil, err
 	}
+	// the violations are reported with their paths, which json.Unmarshal doesn't tell
+	if err = service.ValidateApplicationSchema(appJson, false); err != nil {
+		return nil, err
+	}
 	application := &specV1.Application{}
 	err = json.Unmarshal(appJson, application)
 	if err != nil {
 		return nil, err
 	}
 	application.System = isSys
-	app, err := api.applicationService.Create(ns, application)
+	app, err := api.applicationService.Create(ctx, ns, application)
 	if err != nil {
 		log.L().Error("API", log.Any("func", "genApp"), log.Any("err", err.Error()))
-		res, err := api.applicationService.Get(ns, application.Name, "")
+		res, err := api.applicationService.Get(ctx, ns, application.Name, "")
 		if err != nil {
 			return nil, err
 		}
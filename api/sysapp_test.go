@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -73,7 +74,7 @@ func TestAPI_GenSysApp(t *testing.T) {
 		Version:   "123",
 	}
 	cs.EXPECT().Create(node.Namespace, gomock.Any()).Return(conf, nil).Times(2)
-	as.EXPECT().Create(node.Namespace, gomock.Any()).Return(app, nil).Times(2)
+	as.EXPECT().Create(gomock.Any(), node.Namespace, gomock.Any()).Return(app, nil).Times(2)
 	ss.EXPECT().Get(node.Namespace, gomock.Any(), "").Return(secret, nil).AnyTimes()
 	scs.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysConf, nil).AnyTimes()
 	pki.EXPECT().SignClientCertificate(gomock.Any(), gomock.Any()).Return(certPEM, nil).AnyTimes()
@@ -83,7 +84,7 @@ func TestAPI_GenSysApp(t *testing.T) {
 	is.EXPECT().RefreshNodesIndexByApp(node.Namespace, gomock.Any(), nodeList).Times(2)
 	init.EXPECT().GetResource(gomock.Any()).Return("{}", nil).AnyTimes()
 
-	apps, err := api.GenSysApp(node.Name, node.Namespace, list)
+	apps, err := api.GenSysApp(context.Background(), node.Name, node.Namespace, list)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(apps))
 }
@@ -147,7 +148,7 @@ func TestAPI_GenSysApp_ErrUpdateNode(t *testing.T) {
 		Version:   "123",
 	}
 	cs.EXPECT().Create(node.Namespace, gomock.Any()).Return(conf, nil).Times(1)
-	as.EXPECT().Create(node.Namespace, gomock.Any()).Return(app, nil).Times(1)
+	as.EXPECT().Create(gomock.Any(), node.Namespace, gomock.Any()).Return(app, nil).Times(1)
 	ss.EXPECT().Get(node.Namespace, gomock.Any(), "").Return(secret, nil).AnyTimes()
 	scs.EXPECT().GetSysConfig(gomock.Any(), gomock.Any()).Return(sysConf, nil).AnyTimes()
 	pki.EXPECT().SignClientCertificate(gomock.Any(), gomock.Any()).Return(certPEM, nil).AnyTimes()
@@ -157,12 +158,13 @@ func TestAPI_GenSysApp_ErrUpdateNode(t *testing.T) {
 	is.EXPECT().RefreshNodesIndexByApp(node.Namespace, gomock.Any(), nodeList).Return(fmt.Errorf("update err")).Times(1)
 	init.EXPECT().GetResource(gomock.Any()).Return("{}", nil).AnyTimes()
 
-	_, err := api.GenSysApp(node.Name, node.Namespace, list)
+	_, err := api.GenSysApp(context.Background(), node.Name, node.Namespace, list)
 	assert.NotNil(t, err)
 	assert.Equal(t, "update err", err.Error())
 }
 
 func TestAPI_GenSysApp_ErrSysConfig(t *testing.T) {
+	ctx := context.Background()
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
 
@@ -181,7 +183,7 @@ func TestAPI_GenSysApp_ErrSysConfig(t *testing.T) {
 		common.Field("name", "core"))
 	scs.EXPECT().GetSysConfig(common.BaetylModule, string(common.BaetylCore)).Return(nil, err1).Times(1)
 
-	_, err2 := api.GenSysApp(node.Name, node.Namespace, list)
+	_, err2 := api.GenSysApp(ctx, node.Name, node.Namespace, list)
 	assert.NotNil(t, err1)
 	assert.Equal(t, err1, err2)
 
@@ -196,7 +198,7 @@ func TestAPI_GenSysApp_ErrSysConfig(t *testing.T) {
 		common.Field("name", common.AddressNode))
 	scs.EXPECT().GetSysConfig(common.BaetylModule, string(common.BaetylCore)).Return(sysConf, nil).Times(1)
 	scs.EXPECT().GetSysConfig("address", common.AddressNode).Return(nil, err3).Times(1)
-	_, err4 := api.GenSysApp(node.Name, node.Namespace, list)
+	_, err4 := api.GenSysApp(ctx, node.Name, node.Namespace, list)
 	assert.NotNil(t, err4)
 	assert.Equal(t, err3, err4)
 
@@ -209,7 +211,7 @@ func TestAPI_GenSysApp_ErrSysConfig(t *testing.T) {
 		common.Field("name", "function"))
 	scs.EXPECT().GetSysConfig(common.BaetylModule, string(common.BaetylFunction)).Return(nil, err5).Times(1)
 
-	_, err6 := api.GenSysApp(node.Name, node.Namespace, list)
+	_, err6 := api.GenSysApp(ctx, node.Name, node.Namespace, list)
 	assert.NotNil(t, err6)
 	assert.Equal(t, err5, err6)
 }
@@ -258,6 +260,7 @@ func Test_genConfig_Err(t *testing.T) {
 }
 
 func Test_genApp_Err(t *testing.T) {
+	ctx := context.Background()
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
 
@@ -282,19 +285,19 @@ func Test_genApp_Err(t *testing.T) {
 
 	// bad case 0
 	init.EXPECT().GetResource(templateKey).Return("", fmt.Errorf("get template err")).Times(1)
-	_, err := api.genApp(ns, templateKey, params, true)
+	_, err := api.genApp(ctx, ns, templateKey, params, true)
 	assert.Error(t, err)
 
 	// bad case 1
 	init.EXPECT().GetResource(templateKey).Return("error json", nil).Times(1)
-	_, err = api.genApp(ns, templateKey, params, true)
+	_, err = api.genApp(ctx, ns, templateKey, params, true)
 	assert.Error(t, err)
 
 	// bad case 2
 	init.EXPECT().GetResource(templateKey).Return(template, nil).Times(1)
-	as.EXPECT().Create(ns, gomock.Any()).Return(nil, os.ErrInvalid).Times(1)
-	as.EXPECT().Get(ns, appName, "").Return(nil, os.ErrInvalid).Times(1)
-	_, err = api.genApp(ns, templateKey, params, true)
+	as.EXPECT().Create(gomock.Any(), ns, gomock.Any()).Return(nil, os.ErrInvalid).Times(1)
+	as.EXPECT().Get(gomock.Any(), ns, appName, "").Return(nil, os.ErrInvalid).Times(1)
+	_, err = api.genApp(ctx, ns, templateKey, params, true)
 	assert.Error(t, err, common.Error(
 		common.ErrResourceNotFound,
 		common.Field("type", "config"),
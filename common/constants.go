@@ -13,6 +13,8 @@ const (
 	TimeFormat = "2006-01-02T15:04:05Z"
 	// KeyContextNamespace the key of namespace in context
 	KeyContextNamespace = "namespace"
+	// UnknownOperator the operator recorded in application history if the actor of a change is unknown
+	UnknownOperator = "unknown"
 
 	// ResourceName resource name
 	ResourceName = "resourceName"
@@ -43,6 +45,8 @@ const (
 	Batch Resource = "batch"
 	// Index index resource
 	Index Resource = "index"
+	// Base the base of application, named by LabelBaseApp
+	Base Resource = "base"
 	// !deprecated
 	// DefaultConfigDir default host dir of config
 	DefaultConfigDir = "var/db/baetyl"
@@ -104,6 +108,17 @@ const (
 	LabelAppName     = "baetyl-app-name"
 	LabelSystem      = "baetyl-cloud-system"
 	LabelBatch       = "baetyl-batch"
+	// LabelConfigPinned marks the app whose config versions are pinned at create time
+	LabelConfigPinned = "baetyl-config-pinned"
+	// LabelBaseApp names the bases of an app as [namespace/]name separated by commas, the namespace defaults to the app's
+	LabelBaseApp = "baetyl-base-app"
+	// LabelConfigDigest the digest of the data of a config copied from a base, identical copies are shared
+	LabelConfigDigest = "baetyl-config-digest"
+	// LabelCanaryStable, LabelCanaryVersion and LabelCanaryWeight store the split of an app between
+	// the stable and the canary versions, the weight is the percentage of canary
+	LabelCanaryStable  = "baetyl-canary-stable"
+	LabelCanaryVersion = "baetyl-canary-version"
+	LabelCanaryWeight  = "baetyl-canary-weight"
 )
 
 const (
@@ -118,6 +133,8 @@ const (
 	AnnotationUpdateTimestamp = BaetylCloudGroup + "/" + UpdateTimestamp
 	AnnotationMetadata        = BaetylCloudGroup + "/" + Metadata
 	AnnotationPkiCertID       = BaetylCloudGroup + "/" + PkiCertID
+	// AnnotationVersion the version of app taken from the version strategy, the resource version is used if absent
+	AnnotationVersion = BaetylCloudGroup + "/version"
 )
 
 const (
@@ -40,3 +40,57 @@ type F struct {
 	k string
 	v interface{}
 }
+
+// MultiError aggregates errors, the code of the first error is used as its code
+type MultiError struct {
+	errs []error
+}
+
+// Append appends errors, nil is ignored and nested multi-errors are flattened
+func (m *MultiError) Append(errs ...error) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if e, ok := err.(*MultiError); ok {
+			m.errs = append(m.errs, e.errs...)
+			continue
+		}
+		m.errs = append(m.errs, err)
+	}
+}
+
+// Errors returns all errors appended
+func (m *MultiError) Errors() []error {
+	return m.errs
+}
+
+// Code returns the code of the first error
+func (m *MultiError) Code() string {
+	for _, err := range m.errs {
+		if e, ok := err.(errors.Coder); ok {
+			return e.Code()
+		}
+	}
+	return ErrUnknown
+}
+
+func (m *MultiError) Error() string {
+	msgs := make([]string, 0, len(m.errs))
+	for _, err := range m.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ErrorOrNil returns nil if there is no error, returns the error itself if there is only one
+func (m *MultiError) ErrorOrNil() error {
+	switch len(m.errs) {
+	case 0:
+		return nil
+	case 1:
+		return m.errs[0]
+	default:
+		return m
+	}
+}
@@ -1,8 +1,10 @@
 package common
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/baetyl/baetyl-go/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -99,3 +101,27 @@ func TestError(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiError(t *testing.T) {
+	errs := &MultiError{}
+	errs.Append(nil)
+	assert.NoError(t, errs.ErrorOrNil())
+
+	e1 := Error(ErrAppNameConflict, Field("where", "Volumes[]"), Field("name", "a"))
+	errs.Append(e1)
+	assert.Equal(t, e1, errs.ErrorOrNil())
+
+	e2 := Error(ErrVolumeNotFoundWhenMount, Field("name", "b"))
+	nested := &MultiError{}
+	nested.Append(e2, fmt.Errorf("unknown"))
+	errs.Append(nested)
+	assert.Len(t, errs.Errors(), 3)
+
+	err := errs.ErrorOrNil()
+	assert.Equal(t, ErrAppNameConflict, err.(errors.Coder).Code())
+	assert.EqualError(t, err, e1.Error()+"; "+e2.Error()+"; unknown")
+
+	errs = &MultiError{}
+	errs.Append(fmt.Errorf("e1"), fmt.Errorf("e2"))
+	assert.Equal(t, ErrUnknown, errs.Code())
+}
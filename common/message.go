@@ -22,21 +22,40 @@ const (
 	ErrRequestAccessDenied   = "ErrRequestAccessDenied"
 	ErrRequestMethodNotFound = "ErrRequestMethodNotFound"
 	ErrRequestParamInvalid   = "ErrRequestParamInvalid"
+	ErrRequestTimeout        = "ErrRequestTimeout"
+	ErrTooManyRequests       = "ErrTooManyRequests"
+	ErrNotSupported          = "ErrNotSupported"
 	// * resource
 	ErrResourceNotFound        = "ErrResourceNotFound"
 	ErrResourceAccessForbidden = "ErrResourceAccessForbidden"
 	ErrResourceConflict        = "ErrResourceConflict"
 	ErrResourceHasBeenUsed     = "ErrResourceHasBeenUsed"
+	ErrResourceInUse           = "ErrResourceInUse"
 	ErrNodeNotReady            = "ErrNodeNotReady"
 
 	// * volumes
-	ErrVolumeType = "ErrVolumeType"
+	ErrVolumeType          = "ErrVolumeType"
+	ErrUnusedVolume        = "ErrUnusedVolume"
+	ErrSecretMountWritable = "ErrSecretMountWritable"
 	// * unknown
 	ErrUnknown = "UnknownError"
 	// * application
 	ErrAppNameConflict         = "ErrAppNameConflict"
 	ErrVolumeNotFoundWhenMount = "ErrVolumeNotFoundWhenMount"
 	ErrAppReferencedByNode     = "ErrAppReferencedByNode"
+	ErrVolumeMountPathConflict = "ErrVolumeMountPathConflict"
+	ErrServicePortConflict     = "ErrServicePortConflict"
+	ErrInvalidEnvName          = "ErrInvalidEnvName"
+	ErrEnvNameConflict         = "ErrEnvNameConflict"
+	ErrInvalidResourceSpec     = "ErrInvalidResourceSpec"
+	ErrCircularReference       = "ErrCircularReference"
+	ErrSelfReference           = "ErrSelfReference"
+	ErrInvalidImageRef         = "ErrInvalidImageRef"
+	ErrSpecTooLarge            = "ErrSpecTooLarge"
+	ErrInvalidNodeSelector     = "ErrInvalidNodeSelector"
+	ErrSchemaViolation         = "ErrSchemaViolation"
+	ErrCrossNamespaceReference = "ErrCrossNamespaceReference"
+	ErrMissingTemplateParam    = "ErrMissingTemplateParam"
 	// * node
 	ErrNodeNumMaxLimit       = "ErrNodeNumMaxLimit"
 	ErrNodeNumQueryException = "ErrNodeNumQueryException"
@@ -77,6 +96,8 @@ const (
 	ErrInvalidName = "nonBaetyl"
 	// * license
 	ErrLicenseQuota = "ErrLicenseQuota"
+	// * quota
+	ErrQuotaExceeded = "ErrQuotaExceeded"
 	// * third server error
 	ErrThirdServer = "ErrThirdServer"
 )
@@ -89,20 +110,39 @@ var templates = map[Code]string{
 	ErrRequestAccessDenied:   "The request access is denied.",
 	ErrRequestMethodNotFound: "The request method is not found.",
 	ErrRequestParamInvalid:   "The request parameter is invalid.{{if .error}} ({{.error}}){{end}}",
+	ErrRequestTimeout:        "The request is timeout{{if .timeout}} after {{.timeout}}{{end}}.{{if .error}} ({{.error}}){{end}}",
+	ErrTooManyRequests:       "Too many requests{{if .namespace}} in namespace ({{.namespace}}){{end}}, please retry later.",
+	ErrNotSupported:          "The operation{{if .name}} ({{.name}}){{end}} is not supported.{{if .error}} ({{.error}}){{end}}",
 	// * resource
 	ErrResourceNotFound:        `The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} is not found{{if .namespace}} in namespace({{.namespace}}){{end}}.`,
 	ErrResourceAccessForbidden: `The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} connot be accessed{{if .namespace}} in namespace({{.namespace}}){{end}}.`,
-	ErrResourceConflict:        `The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} already exist.`,
+	ErrResourceConflict:        `The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} {{if .error}}is conflicted. ({{.error}}){{else}}already exist.{{end}}`,
 	ErrResourceHasBeenUsed:     `The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} has been used.`,
+	ErrResourceInUse:           `The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} is still used{{if .users}} by ({{.users}}){{end}}.`,
 	// * volumes
-	ErrVolumeType: "The volume{{if .name}} ({{.name}}){{end}} type should be{{if .type}} ({{.type}}){{end}}.",
+	ErrVolumeType:          "The volume{{if .name}} ({{.name}}){{end}} type should be{{if .type}} ({{.type}}){{end}}.{{if .error}} ({{.error}}){{end}}",
+	ErrUnusedVolume:        "The volume{{if .name}} ({{.name}}){{end}} is not mounted by any service.",
+	ErrSecretMountWritable: "The secret volume is mounted writable{{if .where}} at ({{.where}}){{end}}, it should be read-only.",
 	// * unknown
 	ErrUnknown: "There is a unknown error{{if .error}} ({{.error}}){{end}}. If the attempt to retry does not work, please contact us.",
 	// * application
 	ErrAppNameConflict:         "A naming conflict occurs when you try to create/update app.{{if .where}} where={{.where}}.{{end}}{{if .name}} name={{.name}}.{{end}}",
-	ErrVolumeNotFoundWhenMount: "The mount volume name{{if .name}}({{.name}}){{end}} can't find in the Volumes[].",
+	ErrVolumeNotFoundWhenMount: "The mount volume name{{if .name}}({{.name}}){{end}} can't find in the Volumes[].{{if .where}} where={{.where}}.{{end}}",
 	ErrNodeNotReady:            "The node {{if .name}}({{.name}} ){{end}}is not ready, please retry later.",
 	ErrAppReferencedByNode:     "The {{if .name}}({{.name}}){{end}} app is still referenced by a node.",
+	ErrVolumeMountPathConflict: "The mount path{{if .path}} ({{.path}}){{end}} is used by more than one volume mount{{if .name}} in service ({{.name}}){{end}}.",
+	ErrServicePortConflict:     "The host port{{if .port}} ({{.port}}){{end}} is conflicted between service{{if .name}} ({{.name}}){{end}} and service{{if .other}} ({{.other}}){{end}}.",
+	ErrInvalidEnvName:          "The environment variable name{{if .env}} ({{.env}}){{end}} of service{{if .name}} ({{.name}}){{end}} is invalid, it should match [A-Za-z_][A-Za-z0-9_]*.",
+	ErrEnvNameConflict:         "The environment variable name{{if .env}} ({{.env}}){{end}} is duplicated in service{{if .name}} ({{.name}}){{end}}.",
+	ErrInvalidResourceSpec:     "The resource spec{{if .where}} ({{.where}}){{end}} of service{{if .name}} ({{.name}}){{end}} is invalid.{{if .error}} ({{.error}}){{end}}",
+	ErrCircularReference:       "The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}} is circularly referenced.{{if .cycle}} cycle={{.cycle}}.{{end}}",
+	ErrSelfReference:           "The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}}{{if .volume}} of volume ({{.volume}}){{end}} is created by the app itself.{{if .error}} ({{.error}}){{end}}",
+	ErrInvalidImageRef:         "The image{{if .image}} ({{.image}}){{end}} of service{{if .name}} ({{.name}}){{end}} is invalid.{{if .error}} ({{.error}}){{end}}",
+	ErrSpecTooLarge:            "The spec of app{{if .name}} ({{.name}}){{end}} is too large, {{if .type}}the number of {{.type}} is {{.size}}{{else}}the size is {{.size}} bytes{{end}} which exceeds the limit {{.limit}}.",
+	ErrCrossNamespaceReference: "The {{if .type}}({{.type}}) {{end}}resource{{if .name}} ({{.name}}){{end}}{{if .volume}} of volume ({{.volume}}){{end}} is not in the namespace{{if .namespace}} ({{.namespace}}){{end}} of app.{{if .other}} It is in ({{.other}}).{{end}}",
+	ErrSchemaViolation:         "The spec violates the schema{{if .path}} at ({{.path}}){{end}}.{{if .error}} ({{.error}}){{end}}",
+	ErrInvalidNodeSelector:     "The node selector{{if .selector}} ({{.selector}}){{end}} of app{{if .name}} ({{.name}}){{end}} is invalid.{{if .error}} ({{.error}}){{end}}",
+	ErrMissingTemplateParam:    "The parameters{{if .params}} ({{.params}}){{end}} of template{{if .name}} ({{.name}}){{end}} are missing.",
 	// * node
 	ErrNodeNumMaxLimit:       "The number of nodes reaches the maximum limit",
 	ErrNodeNumQueryException: "The number of nodes is null",
@@ -135,11 +175,14 @@ var templates = map[Code]string{
 	ErrInvalidDuration:         "The ({{if .duration}}{{.duration}}{{end}}) must be a positive integer, optionally followed by a corresponding time unit (s|m|h)",
 	ErrInvalidSetcpus: "The ({{if .setcpus}}{{.setcpus}}{{end}}) must be a comma-separated list or hyphen-separated range of CPUs a container can use, " +
 		"a valid value might be 0-3 (to use the first, second, third, and fourth CPU) or 1,3 (to use the second and fourth CPU)",
-	ErrInvalidName: "The field ({{if .nonBaetyl}}{{.nonBaetyl}}{{end}}) cannot contain baetyl (case insensitive)",
+	ErrInvalidName: "{{if .nonBaetyl}}The field ({{.nonBaetyl}}) cannot contain baetyl (case insensitive){{else}}The name{{if .name}} ({{.name}}){{end}}{{if .where}} of {{.where}}{{end}} is invalid.{{if .error}} ({{.error}}){{end}}{{end}}",
 
 	// * License
 	ErrLicenseQuota: "Check {{if .name}}({{.name}}){{end}} quota failed, the limited number is {{if .limit}}({{.limit}}){{end}}",
 
+	// * quota
+	ErrQuotaExceeded: "The quota of {{if .type}}({{.type}}) {{end}}resource{{if .namespace}} in namespace({{.namespace}}){{end}} is exceeded{{if .limit}}, the limit is {{.limit}}{{end}}.",
+
 	// * third server error
 	ErrThirdServer: "Third server {{if .name}}({{.name}}){{end}} error.{{if .error}} ({{.error}}){{end}}",
 }
@@ -150,8 +193,18 @@ func getHTTPStatus(c Code) int {
 		return http.StatusNotFound
 	case ErrRequestAccessDenied:
 		return http.StatusUnauthorized
+	case ErrRequestTimeout:
+		return http.StatusGatewayTimeout
+	case ErrTooManyRequests:
+		return http.StatusTooManyRequests
+	case ErrNotSupported:
+		return http.StatusNotImplemented
 	case ErrResourceHasBeenUsed:
 		return http.StatusForbidden
+	case ErrResourceInUse:
+		return http.StatusConflict
+	case ErrResourceConflict:
+		return http.StatusConflict
 	case ErrUnknown:
 		return http.StatusInternalServerError
 	default:
@@ -2,6 +2,7 @@ package common
 
 import (
 	"strings"
+	"time"
 
 	uuid2 "github.com/google/uuid"
 )
@@ -39,6 +40,50 @@ func CompareNumericalString(a, b string) int {
 
 }
 
+// CompareVersion compare versions segment by segment split by dot, numerical segments are compared as numbers
+// and the others lexically, a version with more segments is greater if the common ones are equal
+func CompareVersion(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		var res int
+		if isNumerical(as[i]) && isNumerical(bs[i]) {
+			res = CompareNumericalString(trimZero(as[i]), trimZero(bs[i]))
+		} else {
+			res = strings.Compare(as[i], bs[i])
+		}
+		if res != 0 {
+			return res
+		}
+	}
+	switch {
+	case len(as) > len(bs):
+		return 1
+	case len(as) < len(bs):
+		return -1
+	default:
+		return 0
+	}
+}
+
+func isNumerical(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func trimZero(s string) string {
+	if res := strings.TrimLeft(s, "0"); res != "" {
+		return res
+	}
+	return "0"
+}
+
 func AddSystemLabel(labels map[string]string, infos map[string]string) map[string]string {
 	if labels == nil {
 		labels = make(map[string]string)
@@ -50,3 +95,16 @@ func AddSystemLabel(labels map[string]string, infos map[string]string) map[strin
 
 	return labels
 }
+
+// Retry call fn at most attempts times until it succeeds, the delay between attempts starts from delay and doubles.
+// fn is called once if attempts is not positive, the last error is returned.
+func Retry(attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for i := 0; ; i++ {
+		if err = fn(); err == nil || i >= attempts-1 {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
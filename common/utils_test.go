@@ -1,9 +1,11 @@
 package common
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestUUIDPrune(t *testing.T) {
@@ -34,6 +36,33 @@ func TestCompareNumericalString(t *testing.T) {
 	assert.Equal(t, -1, CompareNumericalString(a, b))
 }
 
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1", 0},
+		{"10", "9", 1},
+		{"1.10", "1.9", 1},
+		{"2.0", "1.10", 1},
+		{"1.0", "1", 1},
+		{"01", "1", 0},
+		{"1.a", "1.b", -1},
+		{"1.2", "1.a", -1},
+		{"", "1", -1},
+		// malformed
+		{"", "", 0},
+		{"1..2", "1.0.2", -1},
+		{"1.", "1", 1},
+		{"v1", "1", 1},
+		{"99999999999999999999", "1", 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CompareVersion(tt.a, tt.b), tt.a+" "+tt.b)
+		assert.Equal(t, -tt.want, CompareVersion(tt.b, tt.a), tt.b+" "+tt.a)
+	}
+}
+
 func TestAddSystemLabel(t *testing.T) {
 	var labels map[string]string
 	infos := map[string]string{
@@ -48,3 +77,24 @@ func TestAddSystemLabel(t *testing.T) {
 		assert.Equal(t, v, l)
 	}
 }
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	flaky := func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("error %d", calls)
+		}
+		return nil
+	}
+	assert.NoError(t, Retry(3, time.Millisecond, flaky))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	assert.EqualError(t, Retry(2, time.Millisecond, flaky), "error 2")
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	assert.EqualError(t, Retry(0, time.Millisecond, flaky), "error 1")
+	assert.Equal(t, 1, calls)
+}
@@ -12,14 +12,25 @@ const (
 	AdminServerPort  = "ADMIN_PORT"
 	NodeServerPort   = "NODE_PORT"
 	ActiveServerPort = "ACTIVE_PORT"
+
+	// the strategies to suffix the name of config or secret copied from base app
+	CopyNameRandom  = "random"
+	CopyNameCounter = "counter"
+
+	// the strategies to compute the next version of application on update
+	VersionStorage   = "storage"
+	VersionCounter   = "counter"
+	VersionTimestamp = "timestamp"
 )
 
 // CloudConfig baetyl-cloud config
 type CloudConfig struct {
-	ActiveServer Server     `yaml:"activeServer" json:"activeServer" default:"{\"port\":\":9003\",\"readTimeout\":30000000000,\"writeTimeout\":30000000000,\"shutdownTime\":3000000000}"`
-	AdminServer  Server     `yaml:"adminServer" json:"adminServer" default:"{\"port\":\":9004\",\"readTimeout\":30000000000,\"writeTimeout\":30000000000,\"shutdownTime\":3000000000}"`
-	NodeServer   NodeServer `yaml:"nodeServer" json:"nodeServer" default:"{\"port\":\":9005\",\"readTimeout\":30000000000,\"writeTimeout\":30000000000,\"shutdownTime\":3000000000,\"commonName\":\"common-name\"}"`
-	LogInfo      log.Config `yaml:"logger" json:"logger"`
+	ActiveServer Server         `yaml:"activeServer" json:"activeServer" default:"{\"port\":\":9003\",\"readTimeout\":30000000000,\"writeTimeout\":30000000000,\"shutdownTime\":3000000000}"`
+	AdminServer  Server         `yaml:"adminServer" json:"adminServer" default:"{\"port\":\":9004\",\"readTimeout\":30000000000,\"writeTimeout\":30000000000,\"shutdownTime\":3000000000}"`
+	NodeServer   NodeServer     `yaml:"nodeServer" json:"nodeServer" default:"{\"port\":\":9005\",\"readTimeout\":30000000000,\"writeTimeout\":30000000000,\"shutdownTime\":3000000000,\"commonName\":\"common-name\"}"`
+	LogInfo      log.Config     `yaml:"logger" json:"logger"`
+	Application  AppConfig      `yaml:"application" json:"application"`
+	Function     FunctionConfig `yaml:"function" json:"function"`
 	Plugin       struct {
 		PKI       string   `yaml:"pki" json:"pki" default:"defaultpki"`
 		Auth      string   `yaml:"auth" json:"auth" default:"defaultauth"`
@@ -27,6 +38,13 @@ type CloudConfig struct {
 		Shadow    string   `yaml:"shadow" json:"shadow" default:"database"`
 		Objects   []string `yaml:"objects" json:"objects" default:"[]"`
 		Functions []string `yaml:"functions" json:"functions" default:"[]"`
+		// EventSink the sink of application events, no event is published if it is empty
+		EventSink string `yaml:"eventSink" json:"eventSink"`
+
+		// ModelStorageReplica the model storage which serves the reads of application service, writes go to ModelStorage.
+		// The objects written within ModelStorageReplicaLag are read from ModelStorage if replica doesn't have them.
+		ModelStorageReplica    string        `yaml:"modelStorageReplica" json:"modelStorageReplica"`
+		ModelStorageReplicaLag time.Duration `yaml:"modelStorageReplicaLag" json:"modelStorageReplicaLag" default:"5s"`
 
 		// TODO: deprecated
 		ModelStorage    string `yaml:"modelStorage" json:"modelStorage" default:"kubernetes"`
@@ -34,6 +52,99 @@ type CloudConfig struct {
 	} `yaml:"plugin" json:"plugin"`
 }
 
+// AppConfig application service config
+type AppConfig struct {
+	// the duration a soft deleted application can be restored
+	SoftDeleteRetention time.Duration `yaml:"softDeleteRetention" json:"softDeleteRetention" default:"72h"`
+	// the attempts and the base delay of exponential backoff to write application history
+	HistoryRetryAttempts int                `yaml:"historyRetryAttempts" json:"historyRetryAttempts" default:"3"`
+	HistoryRetryDelay    time.Duration      `yaml:"historyRetryDelay" json:"historyRetryDelay" default:"100ms"`
+	Cache                AppCacheConfig     `yaml:"cache" json:"cache"`
+	History              AppHistoryConfig   `yaml:"history" json:"history"`
+	Quota                AppQuotaConfig     `yaml:"quota" json:"quota"`
+	CopyName             AppCopyNameConfig  `yaml:"copyName" json:"copyName"`
+	DefaultBase          AppDefaultBase     `yaml:"defaultBase" json:"defaultBase"`
+	Limit                AppLimitConfig     `yaml:"limit" json:"limit"`
+	RateLimit            AppRateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+	// the version of updated app is assigned by the storage, or counted up from the old one, or the update time in nanoseconds
+	VersionStrategy string `yaml:"versionStrategy" json:"versionStrategy" default:"storage"`
+	// skip checking the functions of the function configs referenced by volumes, for air-gapped setups without function backend
+	SkipFunctionCheck bool `yaml:"skipFunctionCheck" json:"skipFunctionCheck"`
+	// the fields of app unknown to the schema are rejected
+	StrictSchema bool `yaml:"strictSchema" json:"strictSchema"`
+	// allow the admin tools to create applications without validation, see models.CreateOptions.SkipValidation
+	AllowSkipValidation bool `yaml:"allowSkipValidation" json:"allowSkipValidation"`
+	// the post create hooks whose failures roll back the creation, the failures of others are only logged
+	FatalHooks []string `yaml:"fatalHooks" json:"fatalHooks"`
+}
+
+// AppCacheConfig the cache of getting application, it is disabled if size is not positive.
+// Versions are immutable so version-pinned gets can live long, but the latest should be short-lived.
+type AppCacheConfig struct {
+	Size       int           `yaml:"size" json:"size"`
+	LatestTTL  time.Duration `yaml:"latestTTL" json:"latestTTL" default:"5s"`
+	VersionTTL time.Duration `yaml:"versionTTL" json:"versionTTL" default:"10m"`
+}
+
+// AppHistoryConfig the retention of application history, the newest keep versions besides the active one are kept.
+// The background pruner is disabled if interval is not positive.
+// No history is kept if disabled, the methods need history are not supported then.
+type AppHistoryConfig struct {
+	Keep     int           `yaml:"keep" json:"keep" default:"20"`
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	Disabled bool          `yaml:"disabled" json:"disabled"`
+}
+
+// AppQuotaConfig the max number of applications in a namespace, namespaces overrides limit for some namespaces.
+// It is unlimited if the limit is not positive.
+type AppQuotaConfig struct {
+	Limit      int            `yaml:"limit" json:"limit"`
+	Namespaces map[string]int `yaml:"namespaces" json:"namespaces"`
+}
+
+// AppLimitConfig the max size of app spec in bytes of json, and the max number of services and volumes per app.
+// The limit is disabled if it is not positive.
+type AppLimitConfig struct {
+	SpecBytes int `yaml:"specBytes" json:"specBytes"`
+	Services  int `yaml:"services" json:"services"`
+	Volumes   int `yaml:"volumes" json:"volumes"`
+}
+
+// AppRateLimitConfig the token bucket of the writes of apps per namespace, which is refilled
+// at rate tokens per second and holds at most burst tokens. It is disabled if the rate is not positive.
+type AppRateLimitConfig struct {
+	Rate  float64 `yaml:"rate" json:"rate"`
+	Burst int     `yaml:"burst" json:"burst" default:"10"`
+}
+
+// AppCopyNameConfig the suffix of the config or secret copied from base app when the name is used.
+// The random strategy retries once with a random suffix of length, the counter one retries with -1, -2 ... at most maxAttempts times.
+type AppCopyNameConfig struct {
+	Strategy    string `yaml:"strategy" json:"strategy" default:"random"`
+	Length      int    `yaml:"length" json:"length" default:"9"`
+	MaxAttempts int    `yaml:"maxAttempts" json:"maxAttempts" default:"10"`
+}
+
+// AppDefaultBase the base app which new apps are created on top of, namespaces overrides it for some namespaces.
+// It is disabled if the name is empty.
+type AppDefaultBase struct {
+	Namespace  string                `yaml:"namespace" json:"namespace"`
+	Name       string                `yaml:"name" json:"name"`
+	Namespaces map[string]AppBaseRef `yaml:"namespaces" json:"namespaces"`
+}
+
+// AppBaseRef the reference of base app
+type AppBaseRef struct {
+	Namespace string `yaml:"namespace" json:"namespace"`
+	Name      string `yaml:"name" json:"name"`
+}
+
+// FunctionConfig function service config
+type FunctionConfig struct {
+	// the timeout of a test invocation of function
+	InvokeTimeout time.Duration `yaml:"invokeTimeout" json:"invokeTimeout" default:"30s"`
+}
+
 type NodeServer struct {
 	Server     `yaml:",inline" json:",inline"`
 	CommonName string `yaml:"commonName" json:"commonName" default:"common-name"`
@@ -59,11 +59,25 @@ func TestDefaultValue(t *testing.T) {
 	expect.LogInfo.MaxBackups = 15
 	expect.LogInfo.Encoding = "json"
 
+	expect.Application.SoftDeleteRetention = time.Hour * 72
+	expect.Application.HistoryRetryAttempts = 3
+	expect.Application.HistoryRetryDelay = time.Millisecond * 100
+	expect.Application.Cache.LatestTTL = time.Second * 5
+	expect.Application.Cache.VersionTTL = time.Minute * 10
+	expect.Application.History.Keep = 20
+	expect.Application.CopyName.Strategy = "random"
+	expect.Application.CopyName.Length = 9
+	expect.Application.CopyName.MaxAttempts = 10
+	expect.Application.VersionStrategy = "storage"
+	expect.Application.RateLimit.Burst = 10
+	expect.Function.InvokeTimeout = time.Second * 30
+
 	expect.Plugin.PKI = "defaultpki"
 	expect.Plugin.Auth = "defaultauth"
 	expect.Plugin.License = "defaultlicense"
 	expect.Plugin.DatabaseStorage = "database"
 	expect.Plugin.ModelStorage = "kubernetes"
+	expect.Plugin.ModelStorageReplicaLag = 5 * time.Second
 	expect.Plugin.Shadow = "database"
 	expect.Plugin.Functions = []string{}
 	expect.Plugin.Objects = []string{}
@@ -13,6 +13,7 @@ import (
 	_ "github.com/baetyl/baetyl-cloud/plugin/default/pki"
 	_ "github.com/baetyl/baetyl-cloud/plugin/kube"
 	"github.com/baetyl/baetyl-cloud/server"
+	"github.com/baetyl/baetyl-cloud/service"
 	"github.com/baetyl/baetyl-go/context"
 	"github.com/baetyl/baetyl-go/log"
 	_ "github.com/go-sql-driver/mysql"
@@ -61,6 +62,13 @@ func main() {
 		defer as.Close()
 		ctx.Log().Info("active server starting")
 
+		hp, err := service.NewHistoryPruner(&cfg)
+		if err != nil {
+			return err
+		}
+		go hp.Run()
+		defer hp.Close()
+
 		ctx.Wait()
 		return nil
 	})
@@ -5,6 +5,7 @@
 package plugin
 
 import (
+	context "context"
 	models "github.com/baetyl/baetyl-cloud/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
@@ -47,6 +48,35 @@ func (mr *MockFunctionMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockFunction)(nil).Close))
 }
 
+// Create mocks base method
+func (m *MockFunction) Create(arg0 string, arg1 *models.Function) (*models.Function, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*models.Function)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockFunctionMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFunction)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockFunction) Delete(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockFunctionMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFunction)(nil).Delete), arg0, arg1, arg2)
+}
+
 // Get mocks base method
 func (m *MockFunction) Get(arg0, arg1, arg2 string) (*models.Function, error) {
 	m.ctrl.T.Helper()
@@ -62,19 +92,35 @@ func (mr *MockFunctionMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFunction)(nil).Get), arg0, arg1, arg2)
 }
 
-// List mocks base method
-func (m *MockFunction) List(arg0 string) ([]models.Function, error) {
+// Invoke mocks base method
+func (m *MockFunction) Invoke(arg0, arg1, arg2 string, arg3 []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0)
-	ret0, _ := ret[0].([]models.Function)
+	ret := m.ctrl.Call(m, "Invoke", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// Invoke indicates an expected call of Invoke
+func (mr *MockFunctionMockRecorder) Invoke(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invoke", reflect.TypeOf((*MockFunction)(nil).Invoke), arg0, arg1, arg2, arg3)
+}
+
+// List mocks base method
+func (m *MockFunction) List(arg0 string, arg1 *models.ListOptions) ([]models.Function, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]models.Function)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // List indicates an expected call of List
-func (mr *MockFunctionMockRecorder) List(arg0 interface{}) *gomock.Call {
+func (mr *MockFunctionMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFunction)(nil).List), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFunction)(nil).List), arg0, arg1)
 }
 
 // ListFunctionVersions mocks base method
@@ -91,3 +137,47 @@ func (mr *MockFunctionMockRecorder) ListFunctionVersions(arg0, arg1 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFunctionVersions", reflect.TypeOf((*MockFunction)(nil).ListFunctionVersions), arg0, arg1)
 }
+
+// ListRuntimes mocks base method
+func (m *MockFunction) ListRuntimes(arg0 string) ([]models.FunctionRuntime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuntimes", arg0)
+	ret0, _ := ret[0].([]models.FunctionRuntime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuntimes indicates an expected call of ListRuntimes
+func (mr *MockFunctionMockRecorder) ListRuntimes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuntimes", reflect.TypeOf((*MockFunction)(nil).ListRuntimes), arg0)
+}
+
+// Ping mocks base method
+func (m *MockFunction) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockFunctionMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockFunction)(nil).Ping), arg0)
+}
+
+// Update mocks base method
+func (m *MockFunction) Update(arg0 string, arg1 *models.Function) (*models.Function, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(*models.Function)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockFunctionMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFunction)(nil).Update), arg0, arg1)
+}
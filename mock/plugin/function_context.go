@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/baetyl/baetyl-cloud/plugin (interfaces: FunctionContext)
+
+// Package plugin is a generated GoMock package.
+package plugin
+
+import (
+	context "context"
+	models "github.com/baetyl/baetyl-cloud/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockFunctionContext is a mock of FunctionContext interface
+type MockFunctionContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockFunctionContextMockRecorder
+}
+
+// MockFunctionContextMockRecorder is the mock recorder for MockFunctionContext
+type MockFunctionContextMockRecorder struct {
+	mock *MockFunctionContext
+}
+
+// NewMockFunctionContext creates a new mock instance
+func NewMockFunctionContext(ctrl *gomock.Controller) *MockFunctionContext {
+	mock := &MockFunctionContext{ctrl: ctrl}
+	mock.recorder = &MockFunctionContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFunctionContext) EXPECT() *MockFunctionContextMockRecorder {
+	return m.recorder
+}
+
+// GetContext mocks base method
+func (m *MockFunctionContext) GetContext(arg0 context.Context, arg1, arg2, arg3 string) (*models.Function, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Function)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContext indicates an expected call of GetContext
+func (mr *MockFunctionContextMockRecorder) GetContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContext", reflect.TypeOf((*MockFunctionContext)(nil).GetContext), arg0, arg1, arg2, arg3)
+}
+
+// InvokeContext mocks base method
+func (m *MockFunctionContext) InvokeContext(arg0 context.Context, arg1, arg2, arg3 string, arg4 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvokeContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InvokeContext indicates an expected call of InvokeContext
+func (mr *MockFunctionContextMockRecorder) InvokeContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvokeContext", reflect.TypeOf((*MockFunctionContext)(nil).InvokeContext), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListContext mocks base method
+func (m *MockFunctionContext) ListContext(arg0 context.Context, arg1 string, arg2 *models.ListOptions) ([]models.Function, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Function)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListContext indicates an expected call of ListContext
+func (mr *MockFunctionContextMockRecorder) ListContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContext", reflect.TypeOf((*MockFunctionContext)(nil).ListContext), arg0, arg1, arg2)
+}
+
+// ListFunctionVersionsContext mocks base method
+func (m *MockFunctionContext) ListFunctionVersionsContext(arg0 context.Context, arg1, arg2 string) ([]models.Function, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFunctionVersionsContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Function)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFunctionVersionsContext indicates an expected call of ListFunctionVersionsContext
+func (mr *MockFunctionContextMockRecorder) ListFunctionVersionsContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFunctionVersionsContext", reflect.TypeOf((*MockFunctionContext)(nil).ListFunctionVersionsContext), arg0, arg1, arg2)
+}
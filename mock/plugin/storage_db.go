@@ -5,6 +5,7 @@
 package plugin
 
 import (
+	context "context"
 	sql "database/sql"
 	common "github.com/baetyl/baetyl-cloud/common"
 	models "github.com/baetyl/baetyl-cloud/models"
@@ -12,6 +13,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	sqlx "github.com/jmoiron/sqlx"
 	reflect "reflect"
+	time "time"
 )
 
 // MockDBStorage is a mock of DBStorage interface
@@ -201,6 +203,51 @@ func (mr *MockDBStorageMockRecorder) CreateApplication(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplication", reflect.TypeOf((*MockDBStorage)(nil).CreateApplication), arg0)
 }
 
+// CreateApplicationHistory mocks base method
+func (m *MockDBStorage) CreateApplicationHistory(arg0 *v1.Application, arg1 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationHistory", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationHistory indicates an expected call of CreateApplicationHistory
+func (mr *MockDBStorageMockRecorder) CreateApplicationHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationHistory", reflect.TypeOf((*MockDBStorage)(nil).CreateApplicationHistory), arg0, arg1)
+}
+
+// CreateApplicationHistoryWithTx mocks base method
+func (m *MockDBStorage) CreateApplicationHistoryWithTx(arg0 *sqlx.Tx, arg1 *v1.Application, arg2 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationHistoryWithTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationHistoryWithTx indicates an expected call of CreateApplicationHistoryWithTx
+func (mr *MockDBStorageMockRecorder) CreateApplicationHistoryWithTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationHistoryWithTx", reflect.TypeOf((*MockDBStorage)(nil).CreateApplicationHistoryWithTx), arg0, arg1, arg2)
+}
+
+// CreateApplicationRequest mocks base method
+func (m *MockDBStorage) CreateApplicationRequest(arg0 *models.ApplicationRequest) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationRequest", arg0)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationRequest indicates an expected call of CreateApplicationRequest
+func (mr *MockDBStorageMockRecorder) CreateApplicationRequest(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationRequest", reflect.TypeOf((*MockDBStorage)(nil).CreateApplicationRequest), arg0)
+}
+
 // CreateApplicationWithTx mocks base method
 func (m *MockDBStorage) CreateApplicationWithTx(arg0 *sqlx.Tx, arg1 *v1.Application) (sql.Result, error) {
 	m.ctrl.T.Helper()
@@ -410,6 +457,36 @@ func (mr *MockDBStorageMockRecorder) DeleteApplication(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplication", reflect.TypeOf((*MockDBStorage)(nil).DeleteApplication), arg0, arg1, arg2)
 }
 
+// DeleteApplicationHistory mocks base method
+func (m *MockDBStorage) DeleteApplicationHistory(arg0, arg1, arg2, arg3 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApplicationHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApplicationHistory indicates an expected call of DeleteApplicationHistory
+func (mr *MockDBStorageMockRecorder) DeleteApplicationHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplicationHistory", reflect.TypeOf((*MockDBStorage)(nil).DeleteApplicationHistory), arg0, arg1, arg2, arg3)
+}
+
+// DeleteApplicationHistoryWithTx mocks base method
+func (m *MockDBStorage) DeleteApplicationHistoryWithTx(arg0 *sqlx.Tx, arg1, arg2, arg3, arg4 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApplicationHistoryWithTx", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApplicationHistoryWithTx indicates an expected call of DeleteApplicationHistoryWithTx
+func (mr *MockDBStorageMockRecorder) DeleteApplicationHistoryWithTx(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplicationHistoryWithTx", reflect.TypeOf((*MockDBStorage)(nil).DeleteApplicationHistoryWithTx), arg0, arg1, arg2, arg3, arg4)
+}
+
 // DeleteApplicationWithTx mocks base method
 func (m *MockDBStorage) DeleteApplicationWithTx(arg0 *sqlx.Tx, arg1, arg2, arg3 string) (sql.Result, error) {
 	m.ctrl.T.Helper()
@@ -620,6 +697,21 @@ func (mr *MockDBStorageMockRecorder) GetApplication(arg0, arg1, arg2 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplication", reflect.TypeOf((*MockDBStorage)(nil).GetApplication), arg0, arg1, arg2)
 }
 
+// GetApplicationRequest mocks base method
+func (m *MockDBStorage) GetApplicationRequest(arg0, arg1 string) (*models.ApplicationRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationRequest", arg0, arg1)
+	ret0, _ := ret[0].(*models.ApplicationRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationRequest indicates an expected call of GetApplicationRequest
+func (mr *MockDBStorageMockRecorder) GetApplicationRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationRequest", reflect.TypeOf((*MockDBStorage)(nil).GetApplicationRequest), arg0, arg1)
+}
+
 // GetBatch mocks base method
 func (m *MockDBStorage) GetBatch(arg0, arg1 string) (*models.Batch, error) {
 	m.ctrl.T.Helper()
@@ -740,6 +832,21 @@ func (mr *MockDBStorageMockRecorder) GetRecordTx(arg0, arg1, arg2, arg3 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordTx", reflect.TypeOf((*MockDBStorage)(nil).GetRecordTx), arg0, arg1, arg2, arg3)
 }
 
+// GetSoftDeletedApplication mocks base method
+func (m *MockDBStorage) GetSoftDeletedApplication(arg0, arg1 string, arg2 time.Time) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSoftDeletedApplication", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSoftDeletedApplication indicates an expected call of GetSoftDeletedApplication
+func (mr *MockDBStorageMockRecorder) GetSoftDeletedApplication(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSoftDeletedApplication", reflect.TypeOf((*MockDBStorage)(nil).GetSoftDeletedApplication), arg0, arg1, arg2)
+}
+
 // GetSysConfig mocks base method
 func (m *MockDBStorage) GetSysConfig(arg0, arg1 string) (*models.SysConfig, error) {
 	m.ctrl.T.Helper()
@@ -815,6 +922,36 @@ func (mr *MockDBStorageMockRecorder) ListApplication(arg0, arg1, arg2, arg3 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplication", reflect.TypeOf((*MockDBStorage)(nil).ListApplication), arg0, arg1, arg2, arg3)
 }
 
+// ListApplicationByTime mocks base method
+func (m *MockDBStorage) ListApplicationByTime(arg0, arg1 string, arg2, arg3 time.Time) ([]v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicationByTime", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicationByTime indicates an expected call of ListApplicationByTime
+func (mr *MockDBStorageMockRecorder) ListApplicationByTime(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicationByTime", reflect.TypeOf((*MockDBStorage)(nil).ListApplicationByTime), arg0, arg1, arg2, arg3)
+}
+
+// ListApplicationHistory mocks base method
+func (m *MockDBStorage) ListApplicationHistory(arg0, arg1 string, arg2, arg3 int) ([]models.ApplicationHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicationHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ApplicationHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicationHistory indicates an expected call of ListApplicationHistory
+func (mr *MockDBStorageMockRecorder) ListApplicationHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicationHistory", reflect.TypeOf((*MockDBStorage)(nil).ListApplicationHistory), arg0, arg1, arg2, arg3)
+}
+
 // ListBatch mocks base method
 func (m *MockDBStorage) ListBatch(arg0, arg1 string, arg2, arg3 int) ([]models.Batch, error) {
 	m.ctrl.T.Helper()
@@ -845,6 +982,21 @@ func (mr *MockDBStorageMockRecorder) ListBatchTx(arg0, arg1, arg2, arg3, arg4 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBatchTx", reflect.TypeOf((*MockDBStorage)(nil).ListBatchTx), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ListHistoryApplications mocks base method
+func (m *MockDBStorage) ListHistoryApplications() ([]models.AppItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHistoryApplications")
+	ret0, _ := ret[0].([]models.AppItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHistoryApplications indicates an expected call of ListHistoryApplications
+func (mr *MockDBStorageMockRecorder) ListHistoryApplications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHistoryApplications", reflect.TypeOf((*MockDBStorage)(nil).ListHistoryApplications))
+}
+
 // ListIndex mocks base method
 func (m *MockDBStorage) ListIndex(arg0 string, arg1, arg2 common.Resource, arg3 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -860,6 +1012,21 @@ func (mr *MockDBStorageMockRecorder) ListIndex(arg0, arg1, arg2, arg3 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIndex", reflect.TypeOf((*MockDBStorage)(nil).ListIndex), arg0, arg1, arg2, arg3)
 }
 
+// ListIndexKeys mocks base method
+func (m *MockDBStorage) ListIndexKeys(arg0 string, arg1, arg2 common.Resource) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIndexKeys", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIndexKeys indicates an expected call of ListIndexKeys
+func (mr *MockDBStorageMockRecorder) ListIndexKeys(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIndexKeys", reflect.TypeOf((*MockDBStorage)(nil).ListIndexKeys), arg0, arg1, arg2)
+}
+
 // ListIndexTx mocks base method
 func (m *MockDBStorage) ListIndexTx(arg0 *sqlx.Tx, arg1 string, arg2, arg3 common.Resource, arg4 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -935,6 +1102,50 @@ func (mr *MockDBStorageMockRecorder) ListSysConfigAll(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSysConfigAll", reflect.TypeOf((*MockDBStorage)(nil).ListSysConfigAll), arg0)
 }
 
+// Ping mocks base method
+func (m *MockDBStorage) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockDBStorageMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockDBStorage)(nil).Ping), arg0)
+}
+
+// PruneApplication mocks base method
+func (m *MockDBStorage) PruneApplication(arg0, arg1, arg2 string, arg3 int) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneApplication", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneApplication indicates an expected call of PruneApplication
+func (mr *MockDBStorageMockRecorder) PruneApplication(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneApplication", reflect.TypeOf((*MockDBStorage)(nil).PruneApplication), arg0, arg1, arg2, arg3)
+}
+
+// PruneApplicationWithTx mocks base method
+func (m *MockDBStorage) PruneApplicationWithTx(arg0 *sqlx.Tx, arg1, arg2, arg3 string, arg4 int) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneApplicationWithTx", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneApplicationWithTx indicates an expected call of PruneApplicationWithTx
+func (mr *MockDBStorageMockRecorder) PruneApplicationWithTx(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneApplicationWithTx", reflect.TypeOf((*MockDBStorage)(nil).PruneApplicationWithTx), arg0, arg1, arg2, arg3, arg4)
+}
+
 // RefreshIndex mocks base method
 func (m *MockDBStorage) RefreshIndex(arg0 string, arg1, arg2 common.Resource, arg3 string, arg4 []string) error {
 	m.ctrl.T.Helper()
@@ -949,6 +1160,80 @@ func (mr *MockDBStorageMockRecorder) RefreshIndex(arg0, arg1, arg2, arg3, arg4 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshIndex", reflect.TypeOf((*MockDBStorage)(nil).RefreshIndex), arg0, arg1, arg2, arg3, arg4)
 }
 
+// RefreshIndexes mocks base method
+func (m *MockDBStorage) RefreshIndexes(arg0 string, arg1, arg2 common.Resource, arg3 map[string][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshIndexes", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshIndexes indicates an expected call of RefreshIndexes
+func (mr *MockDBStorageMockRecorder) RefreshIndexes(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshIndexes", reflect.TypeOf((*MockDBStorage)(nil).RefreshIndexes), arg0, arg1, arg2, arg3)
+}
+
+// RestoreApplication mocks base method
+func (m *MockDBStorage) RestoreApplication(arg0, arg1, arg2 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreApplication", arg0, arg1, arg2)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreApplication indicates an expected call of RestoreApplication
+func (mr *MockDBStorageMockRecorder) RestoreApplication(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreApplication", reflect.TypeOf((*MockDBStorage)(nil).RestoreApplication), arg0, arg1, arg2)
+}
+
+// RestoreApplicationWithTx mocks base method
+func (m *MockDBStorage) RestoreApplicationWithTx(arg0 *sqlx.Tx, arg1, arg2, arg3 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreApplicationWithTx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreApplicationWithTx indicates an expected call of RestoreApplicationWithTx
+func (mr *MockDBStorageMockRecorder) RestoreApplicationWithTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreApplicationWithTx", reflect.TypeOf((*MockDBStorage)(nil).RestoreApplicationWithTx), arg0, arg1, arg2, arg3)
+}
+
+// SoftDeleteApplication mocks base method
+func (m *MockDBStorage) SoftDeleteApplication(arg0, arg1, arg2 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteApplication", arg0, arg1, arg2)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteApplication indicates an expected call of SoftDeleteApplication
+func (mr *MockDBStorageMockRecorder) SoftDeleteApplication(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteApplication", reflect.TypeOf((*MockDBStorage)(nil).SoftDeleteApplication), arg0, arg1, arg2)
+}
+
+// SoftDeleteApplicationWithTx mocks base method
+func (m *MockDBStorage) SoftDeleteApplicationWithTx(arg0 *sqlx.Tx, arg1, arg2, arg3 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteApplicationWithTx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteApplicationWithTx indicates an expected call of SoftDeleteApplicationWithTx
+func (mr *MockDBStorageMockRecorder) SoftDeleteApplicationWithTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteApplicationWithTx", reflect.TypeOf((*MockDBStorage)(nil).SoftDeleteApplicationWithTx), arg0, arg1, arg2, arg3)
+}
+
 // Transact mocks base method
 func (m *MockDBStorage) Transact(arg0 func(*sqlx.Tx) error) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,234 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/baetyl/baetyl-cloud/plugin (interfaces: DBStorageContext)
+
+// Package plugin is a generated GoMock package.
+package plugin
+
+import (
+	context "context"
+	sql "database/sql"
+	models "github.com/baetyl/baetyl-cloud/models"
+	v1 "github.com/baetyl/baetyl-go/spec/v1"
+	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
+	reflect "reflect"
+	time "time"
+)
+
+// MockDBStorageContext is a mock of DBStorageContext interface
+type MockDBStorageContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockDBStorageContextMockRecorder
+}
+
+// MockDBStorageContextMockRecorder is the mock recorder for MockDBStorageContext
+type MockDBStorageContextMockRecorder struct {
+	mock *MockDBStorageContext
+}
+
+// NewMockDBStorageContext creates a new mock instance
+func NewMockDBStorageContext(ctrl *gomock.Controller) *MockDBStorageContext {
+	mock := &MockDBStorageContext{ctrl: ctrl}
+	mock.recorder = &MockDBStorageContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDBStorageContext) EXPECT() *MockDBStorageContextMockRecorder {
+	return m.recorder
+}
+
+// CountApplicationContext mocks base method
+func (m *MockDBStorageContext) CountApplicationContext(arg0 context.Context, arg1 *sqlx.Tx, arg2, arg3 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountApplicationContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountApplicationContext indicates an expected call of CountApplicationContext
+func (mr *MockDBStorageContextMockRecorder) CountApplicationContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).CountApplicationContext), arg0, arg1, arg2, arg3)
+}
+
+// CreateApplicationContext mocks base method
+func (m *MockDBStorageContext) CreateApplicationContext(arg0 context.Context, arg1 *v1.Application) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationContext", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationContext indicates an expected call of CreateApplicationContext
+func (mr *MockDBStorageContextMockRecorder) CreateApplicationContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).CreateApplicationContext), arg0, arg1)
+}
+
+// CreateApplicationHistoryContext mocks base method
+func (m *MockDBStorageContext) CreateApplicationHistoryContext(arg0 context.Context, arg1 *v1.Application, arg2 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationHistoryContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationHistoryContext indicates an expected call of CreateApplicationHistoryContext
+func (mr *MockDBStorageContextMockRecorder) CreateApplicationHistoryContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationHistoryContext", reflect.TypeOf((*MockDBStorageContext)(nil).CreateApplicationHistoryContext), arg0, arg1, arg2)
+}
+
+// CreateApplicationRequestContext mocks base method
+func (m *MockDBStorageContext) CreateApplicationRequestContext(arg0 context.Context, arg1 *models.ApplicationRequest) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationRequestContext", arg0, arg1)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationRequestContext indicates an expected call of CreateApplicationRequestContext
+func (mr *MockDBStorageContextMockRecorder) CreateApplicationRequestContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationRequestContext", reflect.TypeOf((*MockDBStorageContext)(nil).CreateApplicationRequestContext), arg0, arg1)
+}
+
+// DeleteApplicationHistoryContext mocks base method
+func (m *MockDBStorageContext) DeleteApplicationHistoryContext(arg0 context.Context, arg1, arg2, arg3, arg4 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApplicationHistoryContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteApplicationHistoryContext indicates an expected call of DeleteApplicationHistoryContext
+func (mr *MockDBStorageContextMockRecorder) DeleteApplicationHistoryContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplicationHistoryContext", reflect.TypeOf((*MockDBStorageContext)(nil).DeleteApplicationHistoryContext), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetApplicationContext mocks base method
+func (m *MockDBStorageContext) GetApplicationContext(arg0 context.Context, arg1, arg2, arg3 string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationContext indicates an expected call of GetApplicationContext
+func (mr *MockDBStorageContextMockRecorder) GetApplicationContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).GetApplicationContext), arg0, arg1, arg2, arg3)
+}
+
+// GetApplicationRequestContext mocks base method
+func (m *MockDBStorageContext) GetApplicationRequestContext(arg0 context.Context, arg1, arg2 string) (*models.ApplicationRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationRequestContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ApplicationRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationRequestContext indicates an expected call of GetApplicationRequestContext
+func (mr *MockDBStorageContextMockRecorder) GetApplicationRequestContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationRequestContext", reflect.TypeOf((*MockDBStorageContext)(nil).GetApplicationRequestContext), arg0, arg1, arg2)
+}
+
+// GetSoftDeletedApplicationContext mocks base method
+func (m *MockDBStorageContext) GetSoftDeletedApplicationContext(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSoftDeletedApplicationContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSoftDeletedApplicationContext indicates an expected call of GetSoftDeletedApplicationContext
+func (mr *MockDBStorageContextMockRecorder) GetSoftDeletedApplicationContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSoftDeletedApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).GetSoftDeletedApplicationContext), arg0, arg1, arg2, arg3)
+}
+
+// ListApplicationByTimeContext mocks base method
+func (m *MockDBStorageContext) ListApplicationByTimeContext(arg0 context.Context, arg1, arg2 string, arg3, arg4 time.Time) ([]v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicationByTimeContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicationByTimeContext indicates an expected call of ListApplicationByTimeContext
+func (mr *MockDBStorageContextMockRecorder) ListApplicationByTimeContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicationByTimeContext", reflect.TypeOf((*MockDBStorageContext)(nil).ListApplicationByTimeContext), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListApplicationHistoryContext mocks base method
+func (m *MockDBStorageContext) ListApplicationHistoryContext(arg0 context.Context, arg1, arg2 string, arg3, arg4 int) ([]models.ApplicationHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicationHistoryContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]models.ApplicationHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicationHistoryContext indicates an expected call of ListApplicationHistoryContext
+func (mr *MockDBStorageContextMockRecorder) ListApplicationHistoryContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicationHistoryContext", reflect.TypeOf((*MockDBStorageContext)(nil).ListApplicationHistoryContext), arg0, arg1, arg2, arg3, arg4)
+}
+
+// PruneApplicationContext mocks base method
+func (m *MockDBStorageContext) PruneApplicationContext(arg0 context.Context, arg1, arg2, arg3 string, arg4 int) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneApplicationContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneApplicationContext indicates an expected call of PruneApplicationContext
+func (mr *MockDBStorageContextMockRecorder) PruneApplicationContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).PruneApplicationContext), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RestoreApplicationContext mocks base method
+func (m *MockDBStorageContext) RestoreApplicationContext(arg0 context.Context, arg1, arg2, arg3 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreApplicationContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreApplicationContext indicates an expected call of RestoreApplicationContext
+func (mr *MockDBStorageContextMockRecorder) RestoreApplicationContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).RestoreApplicationContext), arg0, arg1, arg2, arg3)
+}
+
+// SoftDeleteApplicationContext mocks base method
+func (m *MockDBStorageContext) SoftDeleteApplicationContext(arg0 context.Context, arg1, arg2, arg3 string) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteApplicationContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteApplicationContext indicates an expected call of SoftDeleteApplicationContext
+func (mr *MockDBStorageContextMockRecorder) SoftDeleteApplicationContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteApplicationContext", reflect.TypeOf((*MockDBStorageContext)(nil).SoftDeleteApplicationContext), arg0, arg1, arg2, arg3)
+}
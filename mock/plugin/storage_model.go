@@ -5,6 +5,7 @@
 package plugin
 
 import (
+	context "context"
 	models "github.com/baetyl/baetyl-cloud/models"
 	v1 "github.com/baetyl/baetyl-go/spec/v1"
 	gomock "github.com/golang/mock/gomock"
@@ -402,6 +403,20 @@ func (mr *MockModelStorageMockRecorder) ListSecret(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecret", reflect.TypeOf((*MockModelStorage)(nil).ListSecret), arg0, arg1)
 }
 
+// Ping mocks base method
+func (m *MockModelStorage) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockModelStorageMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockModelStorage)(nil).Ping), arg0)
+}
+
 // UpdateApplication mocks base method
 func (m *MockModelStorage) UpdateApplication(arg0 string, arg1 *v1.Application) (*v1.Application, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/baetyl/baetyl-cloud/plugin (interfaces: ApplicationBatchGetter)
+
+// Package plugin is a generated GoMock package.
+package plugin
+
+import (
+	v1 "github.com/baetyl/baetyl-go/spec/v1"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockApplicationBatchGetter is a mock of ApplicationBatchGetter interface
+type MockApplicationBatchGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockApplicationBatchGetterMockRecorder
+}
+
+// MockApplicationBatchGetterMockRecorder is the mock recorder for MockApplicationBatchGetter
+type MockApplicationBatchGetterMockRecorder struct {
+	mock *MockApplicationBatchGetter
+}
+
+// NewMockApplicationBatchGetter creates a new mock instance
+func NewMockApplicationBatchGetter(ctrl *gomock.Controller) *MockApplicationBatchGetter {
+	mock := &MockApplicationBatchGetter{ctrl: ctrl}
+	mock.recorder = &MockApplicationBatchGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockApplicationBatchGetter) EXPECT() *MockApplicationBatchGetterMockRecorder {
+	return m.recorder
+}
+
+// GetApplications mocks base method
+func (m *MockApplicationBatchGetter) GetApplications(arg0 string, arg1 []string) ([]v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplications", arg0, arg1)
+	ret0, _ := ret[0].([]v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplications indicates an expected call of GetApplications
+func (mr *MockApplicationBatchGetterMockRecorder) GetApplications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplications", reflect.TypeOf((*MockApplicationBatchGetter)(nil).GetApplications), arg0, arg1)
+}
@@ -0,0 +1,215 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/baetyl/baetyl-cloud/plugin (interfaces: ModelStorageContext)
+
+// Package plugin is a generated GoMock package.
+package plugin
+
+import (
+	context "context"
+	models "github.com/baetyl/baetyl-cloud/models"
+	v1 "github.com/baetyl/baetyl-go/spec/v1"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockModelStorageContext is a mock of ModelStorageContext interface
+type MockModelStorageContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockModelStorageContextMockRecorder
+}
+
+// MockModelStorageContextMockRecorder is the mock recorder for MockModelStorageContext
+type MockModelStorageContextMockRecorder struct {
+	mock *MockModelStorageContext
+}
+
+// NewMockModelStorageContext creates a new mock instance
+func NewMockModelStorageContext(ctrl *gomock.Controller) *MockModelStorageContext {
+	mock := &MockModelStorageContext{ctrl: ctrl}
+	mock.recorder = &MockModelStorageContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockModelStorageContext) EXPECT() *MockModelStorageContextMockRecorder {
+	return m.recorder
+}
+
+// CreateApplicationContext mocks base method
+func (m *MockModelStorageContext) CreateApplicationContext(arg0 context.Context, arg1 string, arg2 *v1.Application) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplicationContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplicationContext indicates an expected call of CreateApplicationContext
+func (mr *MockModelStorageContextMockRecorder) CreateApplicationContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationContext", reflect.TypeOf((*MockModelStorageContext)(nil).CreateApplicationContext), arg0, arg1, arg2)
+}
+
+// CreateConfigContext mocks base method
+func (m *MockModelStorageContext) CreateConfigContext(arg0 context.Context, arg1 string, arg2 *v1.Configuration) (*v1.Configuration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateConfigContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Configuration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateConfigContext indicates an expected call of CreateConfigContext
+func (mr *MockModelStorageContextMockRecorder) CreateConfigContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConfigContext", reflect.TypeOf((*MockModelStorageContext)(nil).CreateConfigContext), arg0, arg1, arg2)
+}
+
+// CreateSecretContext mocks base method
+func (m *MockModelStorageContext) CreateSecretContext(arg0 context.Context, arg1 string, arg2 *v1.Secret) (*v1.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecretContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecretContext indicates an expected call of CreateSecretContext
+func (mr *MockModelStorageContextMockRecorder) CreateSecretContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecretContext", reflect.TypeOf((*MockModelStorageContext)(nil).CreateSecretContext), arg0, arg1, arg2)
+}
+
+// DeleteApplicationContext mocks base method
+func (m *MockModelStorageContext) DeleteApplicationContext(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteApplicationContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteApplicationContext indicates an expected call of DeleteApplicationContext
+func (mr *MockModelStorageContextMockRecorder) DeleteApplicationContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplicationContext", reflect.TypeOf((*MockModelStorageContext)(nil).DeleteApplicationContext), arg0, arg1, arg2)
+}
+
+// GetApplicationContext mocks base method
+func (m *MockModelStorageContext) GetApplicationContext(arg0 context.Context, arg1, arg2, arg3 string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApplicationContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApplicationContext indicates an expected call of GetApplicationContext
+func (mr *MockModelStorageContextMockRecorder) GetApplicationContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationContext", reflect.TypeOf((*MockModelStorageContext)(nil).GetApplicationContext), arg0, arg1, arg2, arg3)
+}
+
+// GetConfigContext mocks base method
+func (m *MockModelStorageContext) GetConfigContext(arg0 context.Context, arg1, arg2, arg3 string) (*v1.Configuration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Configuration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigContext indicates an expected call of GetConfigContext
+func (mr *MockModelStorageContextMockRecorder) GetConfigContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigContext", reflect.TypeOf((*MockModelStorageContext)(nil).GetConfigContext), arg0, arg1, arg2, arg3)
+}
+
+// GetSecretContext mocks base method
+func (m *MockModelStorageContext) GetSecretContext(arg0 context.Context, arg1, arg2, arg3 string) (*v1.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretContext indicates an expected call of GetSecretContext
+func (mr *MockModelStorageContextMockRecorder) GetSecretContext(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretContext", reflect.TypeOf((*MockModelStorageContext)(nil).GetSecretContext), arg0, arg1, arg2, arg3)
+}
+
+// ListApplicationContext mocks base method
+func (m *MockModelStorageContext) ListApplicationContext(arg0 context.Context, arg1 string, arg2 *models.ListOptions) (*models.ApplicationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicationContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ApplicationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicationContext indicates an expected call of ListApplicationContext
+func (mr *MockModelStorageContextMockRecorder) ListApplicationContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicationContext", reflect.TypeOf((*MockModelStorageContext)(nil).ListApplicationContext), arg0, arg1, arg2)
+}
+
+// ListConfigContext mocks base method
+func (m *MockModelStorageContext) ListConfigContext(arg0 context.Context, arg1 string, arg2 *models.ListOptions) (*models.ConfigurationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConfigContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ConfigurationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConfigContext indicates an expected call of ListConfigContext
+func (mr *MockModelStorageContextMockRecorder) ListConfigContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConfigContext", reflect.TypeOf((*MockModelStorageContext)(nil).ListConfigContext), arg0, arg1, arg2)
+}
+
+// UpdateApplicationContext mocks base method
+func (m *MockModelStorageContext) UpdateApplicationContext(arg0 context.Context, arg1 string, arg2 *v1.Application) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateApplicationContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateApplicationContext indicates an expected call of UpdateApplicationContext
+func (mr *MockModelStorageContextMockRecorder) UpdateApplicationContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApplicationContext", reflect.TypeOf((*MockModelStorageContext)(nil).UpdateApplicationContext), arg0, arg1, arg2)
+}
+
+// UpdateConfigContext mocks base method
+func (m *MockModelStorageContext) UpdateConfigContext(arg0 context.Context, arg1 string, arg2 *v1.Configuration) (*v1.Configuration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConfigContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Configuration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateConfigContext indicates an expected call of UpdateConfigContext
+func (mr *MockModelStorageContextMockRecorder) UpdateConfigContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfigContext", reflect.TypeOf((*MockModelStorageContext)(nil).UpdateConfigContext), arg0, arg1, arg2)
+}
+
+// UpdateSecretContext mocks base method
+func (m *MockModelStorageContext) UpdateSecretContext(arg0 context.Context, arg1 string, arg2 *v1.Secret) (*v1.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecretContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecretContext indicates an expected call of UpdateSecretContext
+func (mr *MockModelStorageContextMockRecorder) UpdateSecretContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecretContext", reflect.TypeOf((*MockModelStorageContext)(nil).UpdateSecretContext), arg0, arg1, arg2)
+}
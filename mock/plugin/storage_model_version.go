@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/baetyl/baetyl-cloud/plugin (interfaces: ApplicationVersioner)
+
+// Package plugin is a generated GoMock package.
+package plugin
+
+import (
+	v1 "github.com/baetyl/baetyl-go/spec/v1"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockApplicationVersioner is a mock of ApplicationVersioner interface
+type MockApplicationVersioner struct {
+	ctrl     *gomock.Controller
+	recorder *MockApplicationVersionerMockRecorder
+}
+
+// MockApplicationVersionerMockRecorder is the mock recorder for MockApplicationVersioner
+type MockApplicationVersionerMockRecorder struct {
+	mock *MockApplicationVersioner
+}
+
+// NewMockApplicationVersioner creates a new mock instance
+func NewMockApplicationVersioner(ctrl *gomock.Controller) *MockApplicationVersioner {
+	mock := &MockApplicationVersioner{ctrl: ctrl}
+	mock.recorder = &MockApplicationVersionerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockApplicationVersioner) EXPECT() *MockApplicationVersionerMockRecorder {
+	return m.recorder
+}
+
+// UpdateApplicationVersion mocks base method
+func (m *MockApplicationVersioner) UpdateApplicationVersion(arg0 string, arg1 *v1.Application, arg2 string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateApplicationVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateApplicationVersion indicates an expected call of UpdateApplicationVersion
+func (mr *MockApplicationVersionerMockRecorder) UpdateApplicationVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApplicationVersion", reflect.TypeOf((*MockApplicationVersioner)(nil).UpdateApplicationVersion), arg0, arg1, arg2)
+}
@@ -5,10 +5,12 @@
 package plugin
 
 import (
+	context "context"
 	models "github.com/baetyl/baetyl-cloud/models"
 	v1 "github.com/baetyl/baetyl-go/spec/v1"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockApplicationService is a mock of ApplicationService interface
@@ -34,91 +36,643 @@ func (m *MockApplicationService) EXPECT() *MockApplicationServiceMockRecorder {
 	return m.recorder
 }
 
+// Clone mocks base method
+func (m *MockApplicationService) Clone(arg0 context.Context, arg1, arg2, arg3, arg4, arg5 string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clone", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Clone indicates an expected call of Clone
+func (mr *MockApplicationServiceMockRecorder) Clone(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clone", reflect.TypeOf((*MockApplicationService)(nil).Clone), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// Count mocks base method
+func (m *MockApplicationService) Count(arg0 context.Context, arg1 string, arg2 *models.ListOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count
+func (mr *MockApplicationServiceMockRecorder) Count(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockApplicationService)(nil).Count), arg0, arg1, arg2)
+}
+
 // Create mocks base method
-func (m *MockApplicationService) Create(arg0 string, arg1 *v1.Application) (*v1.Application, error) {
+func (m *MockApplicationService) Create(arg0 context.Context, arg1 string, arg2 *v1.Application) (*v1.Application, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*v1.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create
-func (mr *MockApplicationServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockApplicationServiceMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApplicationService)(nil).Create), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApplicationService)(nil).Create), arg0, arg1, arg2)
+}
+
+// CreateBatch mocks base method
+func (m *MockApplicationService) CreateBatch(arg0 context.Context, arg1 string, arg2 []*v1.Application) ([]*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBatch indicates an expected call of CreateBatch
+func (mr *MockApplicationServiceMockRecorder) CreateBatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockApplicationService)(nil).CreateBatch), arg0, arg1, arg2)
+}
+
+// CreateFromTemplate mocks base method
+func (m *MockApplicationService) CreateFromTemplate(arg0 context.Context, arg1 string, arg2 *v1.Application, arg3 map[string]string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFromTemplate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFromTemplate indicates an expected call of CreateFromTemplate
+func (mr *MockApplicationServiceMockRecorder) CreateFromTemplate(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFromTemplate", reflect.TypeOf((*MockApplicationService)(nil).CreateFromTemplate), arg0, arg1, arg2, arg3)
 }
 
 // CreateWithBase mocks base method
-func (m *MockApplicationService) CreateWithBase(arg0 string, arg1, arg2 *v1.Application) (*v1.Application, error) {
+func (m *MockApplicationService) CreateWithBase(arg0 context.Context, arg1 string, arg2, arg3 *v1.Application) (*v1.Application, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWithBase", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateWithBase", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*v1.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateWithBase indicates an expected call of CreateWithBase
-func (mr *MockApplicationServiceMockRecorder) CreateWithBase(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockApplicationServiceMockRecorder) CreateWithBase(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithBase", reflect.TypeOf((*MockApplicationService)(nil).CreateWithBase), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithBase", reflect.TypeOf((*MockApplicationService)(nil).CreateWithBase), arg0, arg1, arg2, arg3)
+}
+
+// CreateWithBases mocks base method
+func (m *MockApplicationService) CreateWithBases(arg0 context.Context, arg1 string, arg2 *v1.Application, arg3 []*v1.Application, arg4 *models.CreateOptions) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithBases", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithBases indicates an expected call of CreateWithBases
+func (mr *MockApplicationServiceMockRecorder) CreateWithBases(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithBases", reflect.TypeOf((*MockApplicationService)(nil).CreateWithBases), arg0, arg1, arg2, arg3, arg4)
+}
+
+// CreateWithOptions mocks base method
+func (m *MockApplicationService) CreateWithOptions(arg0 context.Context, arg1 string, arg2 *v1.Application, arg3 *models.CreateOptions) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithOptions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithOptions indicates an expected call of CreateWithOptions
+func (mr *MockApplicationServiceMockRecorder) CreateWithOptions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithOptions", reflect.TypeOf((*MockApplicationService)(nil).CreateWithOptions), arg0, arg1, arg2, arg3)
+}
+
+// CreateWithResult mocks base method
+func (m *MockApplicationService) CreateWithResult(arg0 context.Context, arg1 string, arg2 *v1.Application, arg3 *models.CreateOptions) (*models.ApplicationCreateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithResult", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ApplicationCreateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithResult indicates an expected call of CreateWithResult
+func (mr *MockApplicationServiceMockRecorder) CreateWithResult(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithResult", reflect.TypeOf((*MockApplicationService)(nil).CreateWithResult), arg0, arg1, arg2, arg3)
 }
 
 // Delete mocks base method
-func (m *MockApplicationService) Delete(arg0, arg1, arg2 string) error {
+func (m *MockApplicationService) Delete(arg0 context.Context, arg1, arg2, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockApplicationServiceMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockApplicationServiceMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockApplicationService)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// DeleteBatch mocks base method
+func (m *MockApplicationService) DeleteBatch(arg0 context.Context, arg1 string, arg2 []string) ([]string, map[string]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(map[string]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeleteBatch indicates an expected call of DeleteBatch
+func (mr *MockApplicationServiceMockRecorder) DeleteBatch(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockApplicationService)(nil).Delete), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatch", reflect.TypeOf((*MockApplicationService)(nil).DeleteBatch), arg0, arg1, arg2)
+}
+
+// DeleteByLabel mocks base method
+func (m *MockApplicationService) DeleteByLabel(arg0 context.Context, arg1, arg2 string, arg3 bool) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByLabel", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByLabel indicates an expected call of DeleteByLabel
+func (mr *MockApplicationServiceMockRecorder) DeleteByLabel(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByLabel", reflect.TypeOf((*MockApplicationService)(nil).DeleteByLabel), arg0, arg1, arg2, arg3)
+}
+
+// DeleteWithOptions mocks base method
+func (m *MockApplicationService) DeleteWithOptions(arg0 context.Context, arg1, arg2, arg3 string, arg4 *models.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithOptions", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWithOptions indicates an expected call of DeleteWithOptions
+func (mr *MockApplicationServiceMockRecorder) DeleteWithOptions(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithOptions", reflect.TypeOf((*MockApplicationService)(nil).DeleteWithOptions), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Describe mocks base method
+func (m *MockApplicationService) Describe(arg0 context.Context, arg1, arg2, arg3 string) (*models.ApplicationDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Describe", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ApplicationDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Describe indicates an expected call of Describe
+func (mr *MockApplicationServiceMockRecorder) Describe(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Describe", reflect.TypeOf((*MockApplicationService)(nil).Describe), arg0, arg1, arg2, arg3)
+}
+
+// DescribeWithOptions mocks base method
+func (m *MockApplicationService) DescribeWithOptions(arg0 context.Context, arg1, arg2, arg3 string, arg4 *models.DescribeOptions) (*models.ApplicationDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeWithOptions", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.ApplicationDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeWithOptions indicates an expected call of DescribeWithOptions
+func (mr *MockApplicationServiceMockRecorder) DescribeWithOptions(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeWithOptions", reflect.TypeOf((*MockApplicationService)(nil).DescribeWithOptions), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Diff mocks base method
+func (m *MockApplicationService) Diff(arg0 context.Context, arg1, arg2, arg3, arg4 string) (*models.ApplicationDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.ApplicationDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff
+func (mr *MockApplicationServiceMockRecorder) Diff(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockApplicationService)(nil).Diff), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Exists mocks base method
+func (m *MockApplicationService) Exists(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists
+func (mr *MockApplicationServiceMockRecorder) Exists(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockApplicationService)(nil).Exists), arg0, arg1, arg2)
+}
+
+// Export mocks base method
+func (m *MockApplicationService) Export(arg0 context.Context, arg1, arg2, arg3 string) (*models.ApplicationBundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ApplicationBundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export
+func (mr *MockApplicationServiceMockRecorder) Export(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockApplicationService)(nil).Export), arg0, arg1, arg2, arg3)
+}
+
+// ExportWithOptions mocks base method
+func (m *MockApplicationService) ExportWithOptions(arg0 context.Context, arg1, arg2, arg3 string, arg4 *models.ExportOptions) (*models.ApplicationBundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportWithOptions", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.ApplicationBundle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportWithOptions indicates an expected call of ExportWithOptions
+func (mr *MockApplicationServiceMockRecorder) ExportWithOptions(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportWithOptions", reflect.TypeOf((*MockApplicationService)(nil).ExportWithOptions), arg0, arg1, arg2, arg3, arg4)
 }
 
 // Get mocks base method
-func (m *MockApplicationService) Get(arg0, arg1, arg2 string) (*v1.Application, error) {
+func (m *MockApplicationService) Get(arg0 context.Context, arg1, arg2, arg3 string) (*v1.Application, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*v1.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get
-func (mr *MockApplicationServiceMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockApplicationServiceMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockApplicationService)(nil).Get), arg0, arg1, arg2, arg3)
+}
+
+// GetAtLeastVersion mocks base method
+func (m *MockApplicationService) GetAtLeastVersion(arg0 context.Context, arg1, arg2, arg3 string, arg4 time.Duration) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAtLeastVersion", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAtLeastVersion indicates an expected call of GetAtLeastVersion
+func (mr *MockApplicationServiceMockRecorder) GetAtLeastVersion(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockApplicationService)(nil).Get), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAtLeastVersion", reflect.TypeOf((*MockApplicationService)(nil).GetAtLeastVersion), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetBatch mocks base method
+func (m *MockApplicationService) GetBatch(arg0 context.Context, arg1 string, arg2 []models.AppRef) (map[string]*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBatch indicates an expected call of GetBatch
+func (mr *MockApplicationServiceMockRecorder) GetBatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockApplicationService)(nil).GetBatch), arg0, arg1, arg2)
+}
+
+// GetCanary mocks base method
+func (m *MockApplicationService) GetCanary(arg0 context.Context, arg1, arg2 string) (*models.ApplicationCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCanary", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ApplicationCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCanary indicates an expected call of GetCanary
+func (mr *MockApplicationServiceMockRecorder) GetCanary(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCanary", reflect.TypeOf((*MockApplicationService)(nil).GetCanary), arg0, arg1, arg2)
+}
+
+// GetDeploymentStatus mocks base method
+func (m *MockApplicationService) GetDeploymentStatus(arg0 context.Context, arg1, arg2 string) (*models.DeploymentStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeploymentStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.DeploymentStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeploymentStatus indicates an expected call of GetDeploymentStatus
+func (mr *MockApplicationServiceMockRecorder) GetDeploymentStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeploymentStatus", reflect.TypeOf((*MockApplicationService)(nil).GetDeploymentStatus), arg0, arg1, arg2)
+}
+
+// Import mocks base method
+func (m *MockApplicationService) Import(arg0 context.Context, arg1 string, arg2 *models.ApplicationBundle, arg3 *models.ImportOptions) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import
+func (mr *MockApplicationServiceMockRecorder) Import(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockApplicationService)(nil).Import), arg0, arg1, arg2, arg3)
 }
 
 // List mocks base method
-func (m *MockApplicationService) List(arg0 string, arg1 *models.ListOptions) (*models.ApplicationList, error) {
+func (m *MockApplicationService) List(arg0 context.Context, arg1 string, arg2 *models.ListOptions) (*models.ApplicationList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*models.ApplicationList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockApplicationServiceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockApplicationServiceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApplicationService)(nil).List), arg0, arg1, arg2)
+}
+
+// ListApplicationsByNodeSelector mocks base method
+func (m *MockApplicationService) ListApplicationsByNodeSelector(arg0 context.Context, arg1, arg2 string) (*models.ApplicationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicationsByNodeSelector", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ApplicationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicationsByNodeSelector indicates an expected call of ListApplicationsByNodeSelector
+func (mr *MockApplicationServiceMockRecorder) ListApplicationsByNodeSelector(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicationsByNodeSelector", reflect.TypeOf((*MockApplicationService)(nil).ListApplicationsByNodeSelector), arg0, arg1, arg2)
+}
+
+// ListHistory mocks base method
+func (m *MockApplicationService) ListHistory(arg0 context.Context, arg1, arg2 string, arg3 *models.ListOptions) (*models.ApplicationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ApplicationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHistory indicates an expected call of ListHistory
+func (mr *MockApplicationServiceMockRecorder) ListHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHistory", reflect.TypeOf((*MockApplicationService)(nil).ListHistory), arg0, arg1, arg2, arg3)
+}
+
+// ListHistoryByTime mocks base method
+func (m *MockApplicationService) ListHistoryByTime(arg0 context.Context, arg1, arg2 string, arg3, arg4 time.Time) (*models.ApplicationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHistoryByTime", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.ApplicationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHistoryByTime indicates an expected call of ListHistoryByTime
+func (mr *MockApplicationServiceMockRecorder) ListHistoryByTime(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHistoryByTime", reflect.TypeOf((*MockApplicationService)(nil).ListHistoryByTime), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListStream mocks base method
+func (m *MockApplicationService) ListStream(arg0 context.Context, arg1 string, arg2 *models.ListOptions, arg3 func(*v1.Application) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStream", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListStream indicates an expected call of ListStream
+func (mr *MockApplicationServiceMockRecorder) ListStream(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStream", reflect.TypeOf((*MockApplicationService)(nil).ListStream), arg0, arg1, arg2, arg3)
+}
+
+// Patch mocks base method
+func (m *MockApplicationService) Patch(arg0 context.Context, arg1, arg2 string, arg3 *models.ApplicationPatch) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockApplicationServiceMockRecorder) Patch(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockApplicationService)(nil).Patch), arg0, arg1, arg2, arg3)
+}
+
+// PruneHistory mocks base method
+func (m *MockApplicationService) PruneHistory(arg0 context.Context, arg1, arg2 string, arg3 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneHistory indicates an expected call of PruneHistory
+func (mr *MockApplicationServiceMockRecorder) PruneHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneHistory", reflect.TypeOf((*MockApplicationService)(nil).PruneHistory), arg0, arg1, arg2, arg3)
+}
+
+// RenderWithBase mocks base method
+func (m *MockApplicationService) RenderWithBase(arg0 context.Context, arg1 string, arg2, arg3 *v1.Application) (*models.ApplicationRenderResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderWithBase", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ApplicationRenderResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderWithBase indicates an expected call of RenderWithBase
+func (mr *MockApplicationServiceMockRecorder) RenderWithBase(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderWithBase", reflect.TypeOf((*MockApplicationService)(nil).RenderWithBase), arg0, arg1, arg2, arg3)
+}
+
+// ResolveBases mocks base method
+func (m *MockApplicationService) ResolveBases(arg0 context.Context, arg1, arg2 string) ([]*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveBases", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveBases indicates an expected call of ResolveBases
+func (mr *MockApplicationServiceMockRecorder) ResolveBases(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveBases", reflect.TypeOf((*MockApplicationService)(nil).ResolveBases), arg0, arg1, arg2)
+}
+
+// ResolveReferences mocks base method
+func (m *MockApplicationService) ResolveReferences(arg0 context.Context, arg1 string, arg2 *v1.Application) ([]models.ResolvedReference, []models.ResolvedReference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReferences", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ResolvedReference)
+	ret1, _ := ret[1].([]models.ResolvedReference)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolveReferences indicates an expected call of ResolveReferences
+func (mr *MockApplicationServiceMockRecorder) ResolveReferences(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReferences", reflect.TypeOf((*MockApplicationService)(nil).ResolveReferences), arg0, arg1, arg2)
+}
+
+// Restore mocks base method
+func (m *MockApplicationService) Restore(arg0 context.Context, arg1, arg2 string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockApplicationServiceMockRecorder) Restore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockApplicationService)(nil).Restore), arg0, arg1, arg2)
+}
+
+// Rollback mocks base method
+func (m *MockApplicationService) Rollback(arg0 context.Context, arg1, arg2, arg3 string) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback
+func (mr *MockApplicationServiceMockRecorder) Rollback(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockApplicationService)(nil).Rollback), arg0, arg1, arg2, arg3)
+}
+
+// SetCanary mocks base method
+func (m *MockApplicationService) SetCanary(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCanary", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCanary indicates an expected call of SetCanary
+func (mr *MockApplicationServiceMockRecorder) SetCanary(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApplicationService)(nil).List), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCanary", reflect.TypeOf((*MockApplicationService)(nil).SetCanary), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// SoftDelete mocks base method
+func (m *MockApplicationService) SoftDelete(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete
+func (mr *MockApplicationServiceMockRecorder) SoftDelete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockApplicationService)(nil).SoftDelete), arg0, arg1, arg2, arg3)
 }
 
 // Update mocks base method
-func (m *MockApplicationService) Update(arg0 string, arg1 *v1.Application) (*v1.Application, error) {
+func (m *MockApplicationService) Update(arg0 context.Context, arg1 string, arg2 *v1.Application) (*v1.Application, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*v1.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update
-func (mr *MockApplicationServiceMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockApplicationServiceMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockApplicationService)(nil).Update), arg0, arg1, arg2)
+}
+
+// UpdateWithOptions mocks base method
+func (m *MockApplicationService) UpdateWithOptions(arg0 context.Context, arg1 string, arg2 *v1.Application, arg3 *models.UpdateOptions) (*v1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithOptions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWithOptions indicates an expected call of UpdateWithOptions
+func (mr *MockApplicationServiceMockRecorder) UpdateWithOptions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithOptions", reflect.TypeOf((*MockApplicationService)(nil).UpdateWithOptions), arg0, arg1, arg2, arg3)
+}
+
+// UpdateWithResult mocks base method
+func (m *MockApplicationService) UpdateWithResult(arg0 context.Context, arg1 string, arg2 *v1.Application, arg3 *models.UpdateOptions) (*models.ApplicationUpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithResult", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ApplicationUpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWithResult indicates an expected call of UpdateWithResult
+func (mr *MockApplicationServiceMockRecorder) UpdateWithResult(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithResult", reflect.TypeOf((*MockApplicationService)(nil).UpdateWithResult), arg0, arg1, arg2, arg3)
+}
+
+// Validate mocks base method
+func (m *MockApplicationService) Validate(arg0 context.Context, arg1 string, arg2 *v1.Application) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate
+func (mr *MockApplicationServiceMockRecorder) Validate(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockApplicationService)(nil).Update), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockApplicationService)(nil).Validate), arg0, arg1, arg2)
 }
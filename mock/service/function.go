@@ -5,6 +5,7 @@
 package plugin
 
 import (
+	context "context"
 	models "github.com/baetyl/baetyl-cloud/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
@@ -34,48 +35,78 @@ func (m *MockFunctionService) EXPECT() *MockFunctionServiceMockRecorder {
 }
 
 // GetFunction mocks base method
-func (m *MockFunctionService) GetFunction(arg0, arg1, arg2, arg3 string) (*models.Function, error) {
+func (m *MockFunctionService) GetFunction(arg0 context.Context, arg1, arg2, arg3, arg4 string) (*models.Function, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFunction", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetFunction", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(*models.Function)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFunction indicates an expected call of GetFunction
-func (mr *MockFunctionServiceMockRecorder) GetFunction(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockFunctionServiceMockRecorder) GetFunction(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFunction", reflect.TypeOf((*MockFunctionService)(nil).GetFunction), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFunction", reflect.TypeOf((*MockFunctionService)(nil).GetFunction), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Invoke mocks base method
+func (m *MockFunctionService) Invoke(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Invoke", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Invoke indicates an expected call of Invoke
+func (mr *MockFunctionServiceMockRecorder) Invoke(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invoke", reflect.TypeOf((*MockFunctionService)(nil).Invoke), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // List mocks base method
-func (m *MockFunctionService) List(arg0, arg1 string) ([]models.Function, error) {
+func (m *MockFunctionService) List(arg0 context.Context, arg1, arg2 string) ([]models.Function, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.Function)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockFunctionServiceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockFunctionServiceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFunctionService)(nil).List), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFunctionService)(nil).List), arg0, arg1, arg2)
 }
 
 // ListFunctionVersions mocks base method
-func (m *MockFunctionService) ListFunctionVersions(arg0, arg1, arg2 string) ([]models.Function, error) {
+func (m *MockFunctionService) ListFunctionVersions(arg0 context.Context, arg1, arg2, arg3 string) ([]models.Function, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListFunctionVersions", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ListFunctionVersions", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]models.Function)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListFunctionVersions indicates an expected call of ListFunctionVersions
-func (mr *MockFunctionServiceMockRecorder) ListFunctionVersions(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockFunctionServiceMockRecorder) ListFunctionVersions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFunctionVersions", reflect.TypeOf((*MockFunctionService)(nil).ListFunctionVersions), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFunctionVersions", reflect.TypeOf((*MockFunctionService)(nil).ListFunctionVersions), arg0, arg1, arg2, arg3)
+}
+
+// ListRuntimes mocks base method
+func (m *MockFunctionService) ListRuntimes(arg0 context.Context, arg1, arg2 string) ([]models.FunctionRuntime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuntimes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.FunctionRuntime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuntimes indicates an expected call of ListRuntimes
+func (mr *MockFunctionServiceMockRecorder) ListRuntimes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuntimes", reflect.TypeOf((*MockFunctionService)(nil).ListRuntimes), arg0, arg1, arg2)
 }
 
 // ListSources mocks base method
@@ -91,3 +122,19 @@ func (mr *MockFunctionServiceMockRecorder) ListSources() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSources", reflect.TypeOf((*MockFunctionService)(nil).ListSources))
 }
+
+// ListWithOptions mocks base method
+func (m *MockFunctionService) ListWithOptions(arg0 context.Context, arg1, arg2 string, arg3 *models.ListOptions) ([]models.Function, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithOptions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Function)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListWithOptions indicates an expected call of ListWithOptions
+func (mr *MockFunctionServiceMockRecorder) ListWithOptions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithOptions", reflect.TypeOf((*MockFunctionService)(nil).ListWithOptions), arg0, arg1, arg2, arg3)
+}
@@ -33,6 +33,52 @@ func (m *MockIndexService) EXPECT() *MockIndexServiceMockRecorder {
 	return m.recorder
 }
 
+// CountReferences mocks base method
+func (m *MockIndexService) CountReferences(arg0, arg1, arg2 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReferences", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReferences indicates an expected call of CountReferences
+func (mr *MockIndexServiceMockRecorder) CountReferences(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReferences", reflect.TypeOf((*MockIndexService)(nil).CountReferences), arg0, arg1, arg2)
+}
+
+// GarbageCollect mocks base method
+func (m *MockIndexService) GarbageCollect(arg0 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GarbageCollect", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GarbageCollect indicates an expected call of GarbageCollect
+func (mr *MockIndexServiceMockRecorder) GarbageCollect(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GarbageCollect", reflect.TypeOf((*MockIndexService)(nil).GarbageCollect), arg0)
+}
+
+// IsReferenced mocks base method
+func (m *MockIndexService) IsReferenced(arg0, arg1, arg2 string) (bool, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsReferenced", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IsReferenced indicates an expected call of IsReferenced
+func (mr *MockIndexServiceMockRecorder) IsReferenced(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReferenced", reflect.TypeOf((*MockIndexService)(nil).IsReferenced), arg0, arg1, arg2)
+}
+
 // ListAppIndexByConfig mocks base method
 func (m *MockIndexService) ListAppIndexByConfig(arg0, arg1 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +109,21 @@ func (mr *MockIndexServiceMockRecorder) ListAppIndexBySecret(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppIndexBySecret", reflect.TypeOf((*MockIndexService)(nil).ListAppIndexBySecret), arg0, arg1)
 }
 
+// ListAppsByConfig mocks base method
+func (m *MockIndexService) ListAppsByConfig(arg0, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppsByConfig", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppsByConfig indicates an expected call of ListAppsByConfig
+func (mr *MockIndexServiceMockRecorder) ListAppsByConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsByConfig", reflect.TypeOf((*MockIndexService)(nil).ListAppsByConfig), arg0, arg1)
+}
+
 // ListAppsByNode mocks base method
 func (m *MockIndexService) ListAppsByNode(arg0, arg1 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +139,21 @@ func (mr *MockIndexServiceMockRecorder) ListAppsByNode(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsByNode", reflect.TypeOf((*MockIndexService)(nil).ListAppsByNode), arg0, arg1)
 }
 
+// ListAppsBySecret mocks base method
+func (m *MockIndexService) ListAppsBySecret(arg0, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppsBySecret", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppsBySecret indicates an expected call of ListAppsBySecret
+func (mr *MockIndexServiceMockRecorder) ListAppsBySecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsBySecret", reflect.TypeOf((*MockIndexService)(nil).ListAppsBySecret), arg0, arg1)
+}
+
 // ListConfigIndexByApp mocks base method
 func (m *MockIndexService) ListConfigIndexByApp(arg0, arg1 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +199,20 @@ func (mr *MockIndexServiceMockRecorder) ListNodesByApp(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodesByApp", reflect.TypeOf((*MockIndexService)(nil).ListNodesByApp), arg0, arg1)
 }
 
+// Rebuild mocks base method
+func (m *MockIndexService) Rebuild(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebuild", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rebuild indicates an expected call of Rebuild
+func (mr *MockIndexServiceMockRecorder) Rebuild(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebuild", reflect.TypeOf((*MockIndexService)(nil).Rebuild), arg0)
+}
+
 // RefreshAppIndexByConfig mocks base method
 func (m *MockIndexService) RefreshAppIndexByConfig(arg0, arg1 string, arg2 []string) error {
 	m.ctrl.T.Helper()
@@ -165,6 +255,20 @@ func (mr *MockIndexServiceMockRecorder) RefreshConfigIndexByApp(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshConfigIndexByApp", reflect.TypeOf((*MockIndexService)(nil).RefreshConfigIndexByApp), arg0, arg1, arg2)
 }
 
+// RefreshConfigIndexByApps mocks base method
+func (m *MockIndexService) RefreshConfigIndexByApps(arg0 string, arg1 map[string][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshConfigIndexByApps", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshConfigIndexByApps indicates an expected call of RefreshConfigIndexByApps
+func (mr *MockIndexServiceMockRecorder) RefreshConfigIndexByApps(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshConfigIndexByApps", reflect.TypeOf((*MockIndexService)(nil).RefreshConfigIndexByApps), arg0, arg1)
+}
+
 // RefreshIndex mocks base method
 func (m *MockIndexService) RefreshIndex(arg0 string, arg1, arg2 common.Resource, arg3 string, arg4 []string) error {
 	m.ctrl.T.Helper()
@@ -206,3 +310,17 @@ func (mr *MockIndexServiceMockRecorder) RefreshSecretIndexByApp(arg0, arg1, arg2
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSecretIndexByApp", reflect.TypeOf((*MockIndexService)(nil).RefreshSecretIndexByApp), arg0, arg1, arg2)
 }
+
+// RefreshSecretIndexByApps mocks base method
+func (m *MockIndexService) RefreshSecretIndexByApps(arg0 string, arg1 map[string][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSecretIndexByApps", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshSecretIndexByApps indicates an expected call of RefreshSecretIndexByApps
+func (mr *MockIndexServiceMockRecorder) RefreshSecretIndexByApps(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSecretIndexByApps", reflect.TypeOf((*MockIndexService)(nil).RefreshSecretIndexByApps), arg0, arg1)
+}
@@ -18,8 +18,45 @@ type AppItem struct {
 	Version           string            `json:"version,omitempty"`
 	Namespace         string            `json:"namespace,omitempty"`
 	CreationTimestamp time.Time         `json:"createTime,omitempty"`
+	UpdateTimestamp   time.Time         `json:"updateTime,omitempty"`
 	Description       string            `json:"description,omitempty"`
 	System            bool              `json:"system,omitempty"`
+	Operator          string            `json:"operator,omitempty"`
+}
+
+// ApplicationCanary the split of an app between two versions, weight is the percentage (0-100) of canary.
+// Canary is empty if there is no canary, then the stable version takes all.
+type ApplicationCanary struct {
+	Stable string `json:"stable"`
+	Canary string `json:"canary,omitempty"`
+	Weight int    `json:"weight"`
+}
+
+// DeploymentStatus the rollup of the app on its target nodes by their reports. A node is up to date if it runs
+// the current version, failed if the app fails on it, outdated if it runs another version,
+// and pending if the current version is not running yet or the app is not reported at all
+type DeploymentStatus struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Total    int    `json:"total"`
+	UpToDate int    `json:"upToDate"`
+	Outdated int    `json:"outdated"`
+	Failed   int    `json:"failed"`
+	Pending  int    `json:"pending"`
+}
+
+// AppRef references a version of an app, empty version means the latest
+type AppRef struct {
+	Name    string `json:"name" binding:"required"`
+	Version string `json:"version,omitempty"`
+}
+
+// Key the key of the app referenced in the result of a batch get, it is name or name@version
+func (r AppRef) Key() string {
+	if r.Version == "" {
+		return r.Name
+	}
+	return r.Name + "@" + r.Version
 }
 
 // ApplicationList app List
@@ -27,8 +64,264 @@ type ApplicationList struct {
 	Total       int          `json:"total"`
 	ListOptions *ListOptions `json:"listOptions"`
 	Items       []AppItem    `json:"items"`
+	// Continue the cursor of next page, empty if there is no more
+	Continue string `json:"continue,omitempty"`
 }
 
 type ServiceFunction struct {
 	Functions []specV1.ServiceFunction `json:"functions,omitempty"`
 }
+
+// sortable fields and orders of application list
+const (
+	OrderByName       = "name"
+	OrderByCreateTime = "createTime"
+	OrderByUpdateTime = "updateTime"
+	OrderAsc          = "asc"
+	OrderDesc         = "desc"
+)
+
+// CreateOptions create options
+type CreateOptions struct {
+	// RequestID the client token to make retries of create idempotent
+	RequestID string `json:"requestId,omitempty"`
+	// PinConfigVersions keeps the config versions of volumes instead of following the latest ones
+	PinConfigVersions bool `json:"pinConfigVersions,omitempty"`
+	// MergeStrategy how to merge the services and volumes of bases which are also in the app, default is MergeError
+	MergeStrategy MergeStrategy `json:"mergeStrategy,omitempty"`
+	// Operator the user who creates the app, it is recorded in history
+	Operator string `json:"operator,omitempty"`
+	// Strict rejects the app which has volumes not mounted by any service, they are only warned otherwise,
+	// the images of services without an explicit tag or digest, they are pulled as latest otherwise,
+	// and the secret volumes mounted writable, they are only warned otherwise
+	Strict bool `json:"strict,omitempty"`
+	// NoDefaultBase creates the app without the default base of namespace
+	NoDefaultBase bool `json:"noDefaultBase,omitempty"`
+	// SkipValidation creates the app without checking its spec, for migration tools loading the specs already live.
+	// It is only allowed if config.AppConfig.AllowSkipValidation is set, and each use is logged
+	SkipValidation bool `json:"skipValidation,omitempty"`
+}
+
+// MergeStrategy the strategy to resolve name conflicts between bases and app
+type MergeStrategy string
+
+// merge strategies of bases
+const (
+	// MergeError fails on conflicts
+	MergeError MergeStrategy = "error"
+	// MergeOverride lets the app's one win
+	MergeOverride MergeStrategy = "override"
+	// MergeSkip keeps the base's one
+	MergeSkip MergeStrategy = "skip"
+	// MergeDeep merges the fields of services with the same name at the base's position:
+	// the non-empty fields of app override the base's, except that env are overridden by name
+	// and volume mounts by mount path, the others of both are kept in order, base first.
+	// Volumes are overridden like MergeOverride
+	MergeDeep MergeStrategy = "deep"
+)
+
+// ApplicationRequest the app created by a request
+type ApplicationRequest struct {
+	Namespace  string    `json:"namespace,omitempty"`
+	RequestID  string    `json:"requestId,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	Digest     string    `json:"digest,omitempty"`
+	CreateTime time.Time `json:"createTime,omitempty"`
+}
+
+// UpdateOptions options of application update
+type UpdateOptions struct {
+	// Force skips the version check and overwrites the current application even if nothing is changed
+	Force bool `json:"force,omitempty"`
+	// Operator the user who updates the app, it is recorded in history
+	Operator string `json:"operator,omitempty"`
+}
+
+// ApplicationPatch the partial change of application, the fields not set are kept
+type ApplicationPatch struct {
+	// Version the patch is rejected if it is set but not the current version
+	Version     string  `json:"version,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// Labels the labels to set, the ones of empty value are removed
+	Labels        map[string]string `json:"labels,omitempty"`
+	Services      []ServicePatch    `json:"services,omitempty"`
+	AddVolumes    []specV1.Volume   `json:"addVolumes,omitempty"`
+	RemoveVolumes []string          `json:"removeVolumes,omitempty"`
+}
+
+// ServicePatch the partial change of the service of name
+type ServicePatch struct {
+	Name    string `json:"name"`
+	Image   string `json:"image,omitempty"`
+	Replica *int   `json:"replica,omitempty"`
+	// Env the environment variables to set, the ones of empty value are removed
+	Env                map[string]string    `json:"env,omitempty"`
+	AddVolumeMounts    []specV1.VolumeMount `json:"addVolumeMounts,omitempty"`
+	RemoveVolumeMounts []string             `json:"removeVolumeMounts,omitempty"`
+}
+
+// DeleteOptions the options of application delete
+type DeleteOptions struct {
+	// Operator the user who deletes the app, it is recorded in history
+	Operator string `json:"operator,omitempty"`
+	// Force deletes the app even if it is still the base of other apps
+	Force bool `json:"force,omitempty"`
+}
+
+// ApplicationHistory a version of application recorded in history
+type ApplicationHistory struct {
+	App specV1.Application
+	// Operator the user who made the last change of the version, the one who deleted it for deleted versions
+	Operator string
+}
+
+// ApplicationEventType the type of application event
+type ApplicationEventType string
+
+// application events
+const (
+	ApplicationCreated ApplicationEventType = "ApplicationCreated"
+	ApplicationUpdated ApplicationEventType = "ApplicationUpdated"
+	ApplicationDeleted ApplicationEventType = "ApplicationDeleted"
+)
+
+// ApplicationEvent published once an application is changed successfully
+type ApplicationEvent struct {
+	Type      ApplicationEventType `json:"type"`
+	Namespace string               `json:"namespace"`
+	Name      string               `json:"name"`
+	Version   string               `json:"version,omitempty"`
+	Time      time.Time            `json:"time"`
+}
+
+// ApplicationCreateResult the result of application create
+type ApplicationCreateResult struct {
+	App      *specV1.Application  `json:"app,omitempty"`
+	Warnings []ApplicationWarning `json:"warnings,omitempty"`
+}
+
+// ApplicationUpdateResult the result of application update
+type ApplicationUpdateResult struct {
+	App *specV1.Application `json:"app,omitempty"`
+	// Drifts the pinned configs which have newer versions
+	Drifts   []ConfigDrift        `json:"drifts,omitempty"`
+	Warnings []ApplicationWarning `json:"warnings,omitempty"`
+}
+
+// codes of application warning
+const (
+	// WarnImageLatest the image of service has no tag or digest, the latest is pulled
+	WarnImageLatest = "ImageLatest"
+	// WarnUnusedVolume the volume is not mounted by any service
+	WarnUnusedVolume = "UnusedVolume"
+	// WarnSecretMountWritable the secret volume is mounted without read-only
+	WarnSecretMountWritable = "SecretMountWritable"
+	// WarnFloatingConfig the config of volume follows the latest version, the app is not pinned
+	WarnFloatingConfig = "FloatingConfig"
+)
+
+// ApplicationWarning a problem of application which is not fatal, the operation succeeds anyway
+type ApplicationWarning struct {
+	Code string `json:"code"`
+	// Where the field of app, such as Services[0].Image
+	Where   string `json:"where,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ApplicationRenderResult the result of rendering application with base
+type ApplicationRenderResult struct {
+	App *specV1.Application `json:"app,omitempty"`
+	// Copies the configs and secrets of base which would be copied
+	Copies []ConfigCopy `json:"copies,omitempty"`
+}
+
+// ApplicationBundleFormat the current format of application bundle
+const ApplicationBundleFormat = "v1"
+
+// ApplicationBundle the application with the configs and secrets it references, to move it between environments
+type ApplicationBundle struct {
+	// Format the format of bundle, it is changed when the layout of bundle is changed
+	Format  string                 `json:"format"`
+	App     *specV1.Application    `json:"app"`
+	Configs []specV1.Configuration `json:"configs,omitempty"`
+	Secrets []specV1.Secret        `json:"secrets,omitempty"`
+}
+
+// ExportOptions the options of application export
+type ExportOptions struct {
+	// Redact blanks the values of secrets, the keys are kept
+	Redact bool `json:"redact,omitempty"`
+}
+
+// ApplicationDescription the application with the configs and secrets it references inlined, for troubleshooting.
+// The references which are not found are listed by name instead of failing the whole description
+type ApplicationDescription struct {
+	App            *specV1.Application    `json:"app"`
+	Configs        []specV1.Configuration `json:"configs,omitempty"`
+	Secrets        []specV1.Secret        `json:"secrets,omitempty"`
+	MissingConfigs []string               `json:"missingConfigs,omitempty"`
+	MissingSecrets []string               `json:"missingSecrets,omitempty"`
+}
+
+// DescribeOptions the options of application describe
+type DescribeOptions struct {
+	// ShowSecrets keeps the values of secrets, they are blanked by default
+	ShowSecrets bool `json:"showSecrets,omitempty"`
+}
+
+// ImportOptions the options of application import
+type ImportOptions struct {
+	// Overwrite updates the configs and secrets whose names are used instead of creating suffixed copies
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
+// ConfigCopy a config or secret of base which would be copied into the namespace of app
+type ConfigCopy struct {
+	Volume    string `json:"volume"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Conflict the name is used in the namespace of app, a suffix would be appended
+	Conflict bool `json:"conflict,omitempty"`
+}
+
+// ResolvedReference a config or secret referenced by a volume of app, and the version it's resolved to
+type ResolvedReference struct {
+	Volume  string `json:"volume,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ConfigDrift a pinned config which has a newer version
+type ConfigDrift struct {
+	Volume  string `json:"volume,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Latest  string `json:"latest,omitempty"`
+}
+
+// change types of application diff
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// ApplicationDiff the differences between two versions of application
+type ApplicationDiff struct {
+	Name        string       `json:"name"`
+	Namespace   string       `json:"namespace"`
+	FromVersion string       `json:"fromVersion"`
+	ToVersion   string       `json:"toVersion"`
+	Changes     []DiffChange `json:"changes"`
+}
+
+// DiffChange a change of spec located by path, such as services[web].image
+type DiffChange struct {
+	Type     string      `json:"type"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"oldValue,omitempty"`
+	NewValue interface{} `json:"newValue,omitempty"`
+}
@@ -8,6 +8,13 @@ type Function struct {
 	Code    FunctionCode `yaml:"code,omitempty" json:"code,omitempty"`
 }
 
+// FunctionRuntime a runtime supported by the function backend
+type FunctionRuntime struct {
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Handler the default handler template of the runtime
+	Handler string `yaml:"handler,omitempty" json:"handler,omitempty"`
+}
+
 type FunctionView struct {
 	Functions []Function `json:"functions"`
 }
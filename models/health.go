@@ -0,0 +1,18 @@
+package models
+
+// HealthStatus the health of the backing plugins
+type HealthStatus struct {
+	// Healthy is false if any critical plugin is unhealthy
+	Healthy bool `json:"healthy"`
+	// Degraded is true if any non-critical plugin is unhealthy
+	Degraded  bool           `json:"degraded"`
+	Unhealthy []PluginHealth `json:"unhealthy,omitempty"`
+}
+
+// PluginHealth the unhealthy plugin
+type PluginHealth struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error"`
+}
@@ -23,4 +23,14 @@ type ListOptions struct {
 	FieldSelector string `json:"fieldSelector,omitempty"`
 	Limit         int64  `json:"limit,omitempty"`
 	Continue      string `json:"continue,omitempty"`
+	PageNo        int    `json:"pageNo,omitempty"`
+	PageSize      int    `json:"pageSize,omitempty"`
+	// Search case-insensitive keywords, matches are filtered but not ranked
+	Search string `json:"search,omitempty"`
+	// OrderBy field to sort by, such as name, createTime or updateTime
+	OrderBy string `json:"orderBy,omitempty"`
+	// Order asc or desc
+	Order string `json:"order,omitempty"`
+	// NamePrefix only the resources whose names begin with it are listed
+	NamePrefix string `json:"namePrefix,omitempty"`
 }
@@ -1,16 +1,32 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/models"
 	"github.com/baetyl/baetyl-cloud/plugin/database/entities"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 func (d *dbStorage) CreateApplication(app *specV1.Application) (sql.Result, error) {
 	return d.CreateApplicationWithTx(nil, app)
 }
 
+func (d *dbStorage) CreateApplicationContext(ctx context.Context, app *specV1.Application) (sql.Result, error) {
+	return d.createApplicationHistory(ctx, nil, app, "")
+}
+
+func (d *dbStorage) CreateApplicationHistory(app *specV1.Application, operator string) (sql.Result, error) {
+	return d.CreateApplicationHistoryWithTx(nil, app, operator)
+}
+
+func (d *dbStorage) CreateApplicationHistoryContext(ctx context.Context, app *specV1.Application, operator string) (sql.Result, error) {
+	return d.createApplicationHistory(ctx, nil, app, operator)
+}
+
 func (d *dbStorage) UpdateApplication(app *specV1.Application, oldVersion string) (sql.Result, error) {
 	return d.UpdateApplicationWithTx(nil, app, oldVersion)
 }
@@ -19,7 +35,43 @@ func (d *dbStorage) DeleteApplication(name, namespace, version string) (sql.Resu
 	return d.DeleteApplicationWithTx(nil, name, namespace, version)
 }
 
+func (d *dbStorage) DeleteApplicationHistory(name, namespace, version, operator string) (sql.Result, error) {
+	return d.DeleteApplicationHistoryWithTx(nil, name, namespace, version, operator)
+}
+
+func (d *dbStorage) DeleteApplicationHistoryContext(ctx context.Context, name, namespace, version, operator string) (sql.Result, error) {
+	return d.deleteApplicationHistory(ctx, nil, name, namespace, version, operator)
+}
+
+func (d *dbStorage) SoftDeleteApplication(name, namespace, version string) (sql.Result, error) {
+	return d.SoftDeleteApplicationWithTx(nil, name, namespace, version)
+}
+
+func (d *dbStorage) SoftDeleteApplicationContext(ctx context.Context, name, namespace, version string) (sql.Result, error) {
+	return d.softDeleteApplication(ctx, nil, name, namespace, version)
+}
+
+func (d *dbStorage) RestoreApplication(name, namespace, version string) (sql.Result, error) {
+	return d.RestoreApplicationWithTx(nil, name, namespace, version)
+}
+
+func (d *dbStorage) RestoreApplicationContext(ctx context.Context, name, namespace, version string) (sql.Result, error) {
+	return d.restoreApplication(ctx, nil, name, namespace, version)
+}
+
+func (d *dbStorage) PruneApplication(name, namespace, activeVersion string, keep int) (sql.Result, error) {
+	return d.PruneApplicationWithTx(nil, name, namespace, activeVersion, keep)
+}
+
+func (d *dbStorage) PruneApplicationContext(ctx context.Context, name, namespace, activeVersion string, keep int) (sql.Result, error) {
+	return d.pruneApplication(ctx, nil, name, namespace, activeVersion, keep)
+}
+
 func (d *dbStorage) GetApplication(name, namespace, version string) (*specV1.Application, error) {
+	return d.GetApplicationContext(context.Background(), name, namespace, version)
+}
+
+func (d *dbStorage) GetApplicationContext(ctx context.Context, name, namespace, version string) (*specV1.Application, error) {
 	selectSQL := `
 SELECT  
 id, namespace, name, version, is_deleted, create_time, update_time, content
@@ -27,7 +79,29 @@ FROM baetyl_application_history
 WHERE namespace = ? AND name=? AND version = ? AND is_deleted = 0
 `
 	var apps []entities.Application
-	if err := d.query(nil, selectSQL, &apps, namespace, name, version); err != nil {
+	if err := d.queryContext(ctx, nil, selectSQL, &apps, namespace, name, version); err != nil {
+		return nil, err
+	}
+	if len(apps) > 0 {
+		return entities.ToApplicationModel(&apps[0])
+	}
+	return nil, nil
+}
+
+func (d *dbStorage) GetSoftDeletedApplication(name, namespace string, since time.Time) (*specV1.Application, error) {
+	return d.GetSoftDeletedApplicationContext(context.Background(), name, namespace, since)
+}
+
+func (d *dbStorage) GetSoftDeletedApplicationContext(ctx context.Context, name, namespace string, since time.Time) (*specV1.Application, error) {
+	selectSQL := `
+SELECT  
+id, namespace, name, version, is_deleted, create_time, update_time, content
+FROM baetyl_application_history 
+WHERE namespace = ? AND name=? AND is_deleted = 2 AND update_time >= ?
+ORDER BY update_time DESC, id DESC LIMIT 1
+`
+	var apps []entities.Application
+	if err := d.queryContext(ctx, nil, selectSQL, &apps, namespace, name, since.UTC()); err != nil {
 		return nil, err
 	}
 	if len(apps) > 0 {
@@ -57,17 +131,85 @@ FROM baetyl_application_history WHERE namespace = ? AND name = ? AND is_deleted
 	return result, nil
 }
 
+// ListApplicationHistory list versions of application with the operators, newest first
+func (d *dbStorage) ListApplicationHistory(name, namespace string, pageNo, pageSize int) ([]models.ApplicationHistory, error) {
+	return d.ListApplicationHistoryContext(context.Background(), name, namespace, pageNo, pageSize)
+}
+
+func (d *dbStorage) ListApplicationHistoryContext(ctx context.Context, name, namespace string, pageNo, pageSize int) ([]models.ApplicationHistory, error) {
+	selectSQL := `
+SELECT  
+id, namespace, name, version, is_deleted, create_time, update_time, content, operator
+FROM baetyl_application_history WHERE namespace = ? AND name = ? ORDER BY id DESC LIMIT ?,?
+`
+	var apps []entities.Application
+	if err := d.queryContext(ctx, nil, selectSQL, &apps, namespace, name, (pageNo-1)*pageSize, pageSize); err != nil {
+		return nil, err
+	}
+	var result []models.ApplicationHistory
+	for _, app := range apps {
+		application, err := entities.ToApplicationModel(&app)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.ApplicationHistory{App: *application, Operator: app.Operator})
+	}
+	return result, nil
+}
+
+// ListApplicationByTime list the versions created in [start, end) newest first, there is no upper bound if end is zero
+func (d *dbStorage) ListApplicationByTime(name, namespace string, start, end time.Time) ([]specV1.Application, error) {
+	return d.ListApplicationByTimeContext(context.Background(), name, namespace, start, end)
+}
+
+func (d *dbStorage) ListApplicationByTimeContext(ctx context.Context, name, namespace string, start, end time.Time) ([]specV1.Application, error) {
+	selectSQL := `
+SELECT  
+id, namespace, name, version, is_deleted, create_time, update_time, content
+FROM baetyl_application_history WHERE namespace = ? AND name = ? AND create_time >= ?
+`
+	args := []interface{}{namespace, name, start.UTC()}
+	if !end.IsZero() {
+		selectSQL += " AND create_time < ?"
+		args = append(args, end.UTC())
+	}
+	selectSQL += " ORDER BY create_time DESC, id DESC"
+	var apps []entities.Application
+	if err := d.queryContext(ctx, nil, selectSQL, &apps, args...); err != nil {
+		return nil, err
+	}
+	var result []specV1.Application
+	for _, app := range apps {
+		application, err := entities.ToApplicationModel(&app)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *application)
+	}
+	return result, nil
+}
+
 func (d *dbStorage) CreateApplicationWithTx(tx *sqlx.Tx, app *specV1.Application) (sql.Result, error) {
+	return d.CreateApplicationHistoryWithTx(tx, app, "")
+}
+
+// CreateApplicationHistoryWithTx store the version of application with the operator, empty operator is stored as common.UnknownOperator
+func (d *dbStorage) CreateApplicationHistoryWithTx(tx *sqlx.Tx, app *specV1.Application, operator string) (sql.Result, error) {
+	return d.createApplicationHistory(context.Background(), tx, app, operator)
+}
+
+func (d *dbStorage) createApplicationHistory(ctx context.Context, tx *sqlx.Tx, app *specV1.Application, operator string) (sql.Result, error) {
 	insertSQL := `
 INSERT INTO baetyl_application_history 
-(namespace, name, version, content) 
-VALUES (?, ?, ?, ?)
+(namespace, name, version, content, operator) 
+VALUES (?, ?, ?, ?, ?)
 `
 	application, err := entities.FromApplicationModel(app)
 	if err != nil {
 		return nil, err
 	}
-	return d.exec(tx, insertSQL, application.Namespace, application.Name, application.Version, application.Content)
+	return d.execContext(ctx, tx, insertSQL, application.Namespace, application.Name, application.Version, application.Content,
+		toOperator(operator))
 }
 
 func (d *dbStorage) UpdateApplicationWithTx(tx *sqlx.Tx, app *specV1.Application, oldVersion string) (sql.Result, error) {
@@ -84,15 +226,97 @@ WHERE namespace = ? AND name = ? AND version = ?
 }
 
 func (d *dbStorage) DeleteApplicationWithTx(tx *sqlx.Tx, name, namespace, version string) (sql.Result, error) {
+	return d.DeleteApplicationHistoryWithTx(tx, name, namespace, version, "")
+}
+
+// DeleteApplicationHistoryWithTx mark the version was deleted by the operator, empty operator is stored as common.UnknownOperator
+func (d *dbStorage) DeleteApplicationHistoryWithTx(tx *sqlx.Tx, name, namespace, version, operator string) (sql.Result, error) {
+	return d.deleteApplicationHistory(context.Background(), tx, name, namespace, version, operator)
+}
+
+func (d *dbStorage) deleteApplicationHistory(ctx context.Context, tx *sqlx.Tx, name, namespace, version, operator string) (sql.Result, error) {
 	deleteSQL := `
 UPDATE baetyl_application_history 
-SET is_deleted = 1
+SET is_deleted = 1, operator = ?
 where namespace=? AND name=? AND version=?
 `
-	return d.exec(tx, deleteSQL, namespace, name, version)
+	return d.execContext(ctx, tx, deleteSQL, toOperator(operator), namespace, name, version)
+}
+
+// SoftDeleteApplicationWithTx mark the application was soft deleted, update_time records the time of deletion
+func (d *dbStorage) SoftDeleteApplicationWithTx(tx *sqlx.Tx, name, namespace, version string) (sql.Result, error) {
+	return d.softDeleteApplication(context.Background(), tx, name, namespace, version)
+}
+
+func (d *dbStorage) softDeleteApplication(ctx context.Context, tx *sqlx.Tx, name, namespace, version string) (sql.Result, error) {
+	deleteSQL := `
+UPDATE baetyl_application_history 
+SET is_deleted = 2, update_time = CURRENT_TIMESTAMP
+where namespace=? AND name=? AND version=? AND is_deleted = 0
+`
+	return d.execContext(ctx, tx, deleteSQL, namespace, name, version)
+}
+
+func (d *dbStorage) RestoreApplicationWithTx(tx *sqlx.Tx, name, namespace, version string) (sql.Result, error) {
+	return d.restoreApplication(context.Background(), tx, name, namespace, version)
+}
+
+func (d *dbStorage) restoreApplication(ctx context.Context, tx *sqlx.Tx, name, namespace, version string) (sql.Result, error) {
+	restoreSQL := `
+UPDATE baetyl_application_history 
+SET is_deleted = 0, update_time = CURRENT_TIMESTAMP
+where namespace=? AND name=? AND version=? AND is_deleted = 2
+`
+	return d.execContext(ctx, tx, restoreSQL, namespace, name, version)
+}
+
+// PruneApplicationWithTx delete the history older than the active version except the newest keep ones.
+// The history written by concurrent updates is newer than the active version, so it is never touched,
+// nothing is deleted if the active version is not recorded. The soft deleted versions are left to restore.
+func (d *dbStorage) PruneApplicationWithTx(tx *sqlx.Tx, name, namespace, activeVersion string, keep int) (sql.Result, error) {
+	return d.pruneApplication(context.Background(), tx, name, namespace, activeVersion, keep)
+}
+
+func (d *dbStorage) pruneApplication(ctx context.Context, tx *sqlx.Tx, name, namespace, activeVersion string, keep int) (sql.Result, error) {
+	pruneSQL := `
+DELETE FROM baetyl_application_history 
+WHERE namespace = ? AND name = ? AND is_deleted <> 2 AND id < (
+	SELECT id FROM (SELECT MAX(id) AS id FROM baetyl_application_history WHERE namespace = ? AND name = ? AND version = ?) a
+) AND id NOT IN (
+	SELECT id FROM (SELECT id FROM baetyl_application_history 
+	WHERE namespace = ? AND name = ? AND is_deleted <> 2 AND id < (
+		SELECT MAX(id) FROM baetyl_application_history WHERE namespace = ? AND name = ? AND version = ?
+	) ORDER BY id DESC LIMIT ?) k
+)
+`
+	return d.execContext(ctx, tx, pruneSQL, namespace, name, namespace, name, activeVersion,
+		namespace, name, namespace, name, activeVersion, keep)
+}
+
+// ListHistoryApplications list the apps which have history, only name and namespace are set
+func (d *dbStorage) ListHistoryApplications() ([]models.AppItem, error) {
+	selectSQL := `
+SELECT DISTINCT namespace, name FROM baetyl_application_history ORDER BY namespace, name
+`
+	var res []struct {
+		Namespace string `db:"namespace"`
+		Name      string `db:"name"`
+	}
+	if err := d.query(nil, selectSQL, &res); err != nil {
+		return nil, err
+	}
+	items := make([]models.AppItem, 0, len(res))
+	for _, r := range res {
+		items = append(items, models.AppItem{Namespace: r.Namespace, Name: r.Name})
+	}
+	return items, nil
 }
 
 func (d *dbStorage) CountApplication(tx *sqlx.Tx, name, namespace string) (int, error) {
+	return d.CountApplicationContext(context.Background(), tx, name, namespace)
+}
+
+func (d *dbStorage) CountApplicationContext(ctx context.Context, tx *sqlx.Tx, name, namespace string) (int, error) {
 	selectSQL := `
 SELECT count(name) AS count
 FROM baetyl_application_history WHERE namespace=? AND name=?
@@ -100,8 +324,56 @@ FROM baetyl_application_history WHERE namespace=? AND name=?
 	var res []struct {
 		Count int `db:"count"`
 	}
-	if err := d.query(tx, selectSQL, &res, namespace, name); err != nil {
+	if err := d.queryContext(ctx, tx, selectSQL, &res, namespace, name); err != nil {
 		return 0, err
 	}
 	return res[0].Count, nil
 }
+
+func (d *dbStorage) CreateApplicationRequest(req *models.ApplicationRequest) (sql.Result, error) {
+	return d.CreateApplicationRequestContext(context.Background(), req)
+}
+
+func (d *dbStorage) CreateApplicationRequestContext(ctx context.Context, req *models.ApplicationRequest) (sql.Result, error) {
+	insertSQL := `
+INSERT INTO baetyl_application_request 
+(namespace, request_id, name, version, digest) 
+VALUES (?, ?, ?, ?, ?)
+`
+	return d.execContext(ctx, nil, insertSQL, req.Namespace, req.RequestID, req.Name, req.Version, req.Digest)
+}
+
+func (d *dbStorage) GetApplicationRequest(namespace, requestID string) (*models.ApplicationRequest, error) {
+	return d.GetApplicationRequestContext(context.Background(), namespace, requestID)
+}
+
+func (d *dbStorage) GetApplicationRequestContext(ctx context.Context, namespace, requestID string) (*models.ApplicationRequest, error) {
+	selectSQL := `
+SELECT  
+id, namespace, request_id, name, version, digest, create_time, update_time
+FROM baetyl_application_request 
+WHERE namespace = ? AND request_id = ?
+`
+	var reqs []entities.ApplicationRequest
+	if err := d.queryContext(ctx, nil, selectSQL, &reqs, namespace, requestID); err != nil {
+		return nil, err
+	}
+	if len(reqs) > 0 {
+		return &models.ApplicationRequest{
+			Namespace:  reqs[0].Namespace,
+			RequestID:  reqs[0].RequestID,
+			Name:       reqs[0].Name,
+			Version:    reqs[0].Version,
+			Digest:     reqs[0].Digest,
+			CreateTime: reqs[0].CreateTime,
+		}, nil
+	}
+	return nil, nil
+}
+
+func toOperator(operator string) string {
+	if operator == "" {
+		return common.UnknownOperator
+	}
+	return operator
+}
@@ -1,10 +1,14 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/models"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 var (
@@ -18,8 +22,21 @@ CREATE TABLE baetyl_application_history
     is_deleted  smallint            NOT NULL DEFAULT 0  ,
     create_time timestamp           NOT NULL DEFAULT CURRENT_TIMESTAMP,
     update_time timestamp           NOT NULL DEFAULT CURRENT_TIMESTAMP ,
-    content     BLOB                NOT NULL DEFAULT '' 
-
+    content     BLOB                NOT NULL DEFAULT '' ,
+    operator    varchar(128)        NOT NULL DEFAULT '' 
+);
+`, `
+CREATE TABLE baetyl_application_request
+(
+    id          integer             PRIMARY KEY AUTOINCREMENT,
+    namespace   varchar(64)         NOT NULL DEFAULT '' ,
+    request_id  varchar(128)        NOT NULL DEFAULT '' ,
+    name        varchar(128)        NOT NULL DEFAULT '' ,
+    version     varchar(36)         NOT NULL DEFAULT '' ,
+    digest      varchar(64)         NOT NULL DEFAULT '' ,
+    create_time timestamp           NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time timestamp           NOT NULL DEFAULT CURRENT_TIMESTAMP ,
+    UNIQUE (namespace, request_id)
 );
 `,
 	}
@@ -126,8 +143,273 @@ func TestDbStorage_GetApplication(t *testing.T) {
 
 }
 
+func TestDbStorage_ListApplication(t *testing.T) {
+	db := mockDb(t)
+	for _, version := range []string{"1", "2", "3"} {
+		_, err := db.CreateApplication(&specV1.Application{
+			Name:      "test",
+			Namespace: "default",
+			Version:   version,
+		})
+		assert.NoError(t, err)
+	}
+	_, err := db.DeleteApplication("test", "default", "3")
+	assert.NoError(t, err)
+
+	// the deleted versions are not listed
+	apps, err := db.ListApplication("test", "default", 1, 2)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 2)
+	assert.Equal(t, "1", apps[0].Version)
+	assert.Equal(t, "2", apps[1].Version)
+
+	apps, err = db.ListApplication("test", "default", 2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 0)
+
+	// the history lists all versions newest first
+	histories, err := db.ListApplicationHistory("test", "default", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 3)
+	assert.Equal(t, "3", histories[0].App.Version)
+	assert.Equal(t, "1", histories[2].App.Version)
+}
+
+func TestDbStorage_SoftDeleteApplication(t *testing.T) {
+	db := mockDb(t)
+	app := &specV1.Application{
+		Name:        "test",
+		Namespace:   "default",
+		Description: "desc",
+		Version:     "1",
+	}
+	_, err := db.CreateApplication(app)
+	assert.NoError(t, err)
+
+	res, err := db.SoftDeleteApplication(app.Name, app.Namespace, app.Version)
+	assert.NoError(t, err)
+	num, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), num)
+
+	deleted, err := db.GetApplication(app.Name, app.Namespace, app.Version)
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	deleted, err = db.GetSoftDeletedApplication(app.Name, app.Namespace, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	checkApplication(t, app, deleted)
+
+	// expired
+	deleted, err = db.GetSoftDeletedApplication(app.Name, app.Namespace, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	res, err = db.RestoreApplication(app.Name, app.Namespace, app.Version)
+	assert.NoError(t, err)
+	num, err = res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), num)
+
+	deleted, err = db.GetSoftDeletedApplication(app.Name, app.Namespace, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	// hard deleted application can't be restored
+	_, err = db.DeleteApplication(app.Name, app.Namespace, app.Version)
+	assert.NoError(t, err)
+	res, err = db.SoftDeleteApplication(app.Name, app.Namespace, app.Version)
+	assert.NoError(t, err)
+	num, err = res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), num)
+}
+
 func checkApplication(t *testing.T, expect, actual *specV1.Application) {
 	assert.Equal(t, expect.Name, actual.Name)
 	assert.Equal(t, expect.Namespace, actual.Namespace)
 	assert.Equal(t, expect.Description, actual.Description)
 }
+
+func TestDbStorage_ApplicationRequest(t *testing.T) {
+	db := mockDb(t)
+	req := &models.ApplicationRequest{Namespace: "default", RequestID: "req-1", Name: "app", Version: "1", Digest: "abc"}
+
+	res, err := db.GetApplicationRequest(req.Namespace, req.RequestID)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	_, err = db.CreateApplicationRequest(req)
+	assert.NoError(t, err)
+	// request id is unique in namespace
+	_, err = db.CreateApplicationRequest(req)
+	assert.Error(t, err)
+
+	res, err = db.GetApplicationRequest(req.Namespace, req.RequestID)
+	assert.NoError(t, err)
+	assert.Equal(t, req.Name, res.Name)
+	assert.Equal(t, req.Version, res.Version)
+	assert.Equal(t, req.Digest, res.Digest)
+
+	res, err = db.GetApplicationRequest("other", req.RequestID)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestDbStorage_PruneApplication(t *testing.T) {
+	db := mockDb(t)
+	for _, version := range []string{"1", "2", "3", "4", "5"} {
+		_, err := db.CreateApplication(&specV1.Application{
+			Name:      "test",
+			Namespace: "default",
+			Version:   version,
+		})
+		assert.NoError(t, err)
+	}
+	_, err := db.CreateApplication(&specV1.Application{Name: "other", Namespace: "default", Version: "1"})
+	assert.NoError(t, err)
+	_, err = db.SoftDeleteApplication("test", "default", "1")
+	assert.NoError(t, err)
+
+	// the active version is not recorded
+	res, err := db.PruneApplication("test", "default", "6", 0)
+	assert.NoError(t, err)
+	num, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), num)
+
+	// version 5 is newer than the active one, it is written by a concurrent update
+	res, err = db.PruneApplication("test", "default", "4", 1)
+	assert.NoError(t, err)
+	num, err = res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), num)
+
+	apps, err := db.ListApplicationHistory("test", "default", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 4)
+	assert.Equal(t, "5", apps[0].App.Version)
+	assert.Equal(t, "4", apps[1].App.Version)
+	assert.Equal(t, "3", apps[2].App.Version)
+	assert.Equal(t, "1", apps[3].App.Version)
+
+	// keep more than history
+	res, err = db.PruneApplication("test", "default", "5", 10)
+	assert.NoError(t, err)
+	num, err = res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), num)
+
+	// keep only active, the soft deleted version is left
+	res, err = db.PruneApplication("test", "default", "5", 0)
+	assert.NoError(t, err)
+	num, err = res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), num)
+	apps, err = db.ListApplicationHistory("test", "default", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 2)
+	assert.Equal(t, "5", apps[0].App.Version)
+	assert.Equal(t, "1", apps[1].App.Version)
+
+	items, err := db.ListHistoryApplications()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AppItem{{Namespace: "default", Name: "other"}, {Namespace: "default", Name: "test"}}, items)
+}
+
+func TestDbStorage_ListApplicationByTime(t *testing.T) {
+	db := mockDb(t)
+	base := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, version := range []string{"1", "2", "3", "4"} {
+		_, err := db.CreateApplication(&specV1.Application{
+			Name:      "test",
+			Namespace: "default",
+			Version:   version,
+		})
+		assert.NoError(t, err)
+		_, err = db.exec(nil, "UPDATE baetyl_application_history SET create_time = ? WHERE version = ?",
+			base.Add(time.Duration(i)*time.Hour), version)
+		assert.NoError(t, err)
+	}
+
+	// start is inclusive and end is exclusive
+	apps, err := db.ListApplicationByTime("test", "default", base.Add(time.Hour), base.Add(3*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, apps, 2)
+	assert.Equal(t, "3", apps[0].Version)
+	assert.Equal(t, "2", apps[1].Version)
+
+	apps, err = db.ListApplicationByTime("test", "default", base.Add(2*time.Hour), time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, apps, 2)
+	assert.Equal(t, "4", apps[0].Version)
+	assert.Equal(t, "3", apps[1].Version)
+
+	apps, err = db.ListApplicationByTime("test", "default", base.Add(-2*time.Hour), base)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 0)
+}
+
+func TestDbStorage_ApplicationHistoryOperator(t *testing.T) {
+	db := mockDb(t)
+	_, err := db.CreateApplicationHistory(&specV1.Application{Name: "test", Namespace: "default", Version: "1"}, "alice")
+	assert.NoError(t, err)
+	_, err = db.CreateApplicationHistory(&specV1.Application{Name: "test", Namespace: "default", Version: "2"}, "")
+	assert.NoError(t, err)
+	_, err = db.CreateApplication(&specV1.Application{Name: "test", Namespace: "default", Version: "3"})
+	assert.NoError(t, err)
+
+	histories, err := db.ListApplicationHistory("test", "default", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 3)
+	assert.Equal(t, "3", histories[0].App.Version)
+	assert.Equal(t, common.UnknownOperator, histories[0].Operator)
+	assert.Equal(t, "2", histories[1].App.Version)
+	assert.Equal(t, common.UnknownOperator, histories[1].Operator)
+	assert.Equal(t, "1", histories[2].App.Version)
+	assert.Equal(t, "alice", histories[2].Operator)
+
+	// the deleter is recorded
+	_, err = db.DeleteApplicationHistory("test", "default", "1", "bob")
+	assert.NoError(t, err)
+	_, err = db.DeleteApplicationHistory("test", "default", "3", "")
+	assert.NoError(t, err)
+	histories, err = db.ListApplicationHistory("test", "default", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, common.UnknownOperator, histories[0].Operator)
+	assert.Equal(t, "bob", histories[2].Operator)
+}
+
+func TestDbStorage_ApplicationHistoryContext(t *testing.T) {
+	db := mockDb(t)
+	app := &specV1.Application{Name: "test", Namespace: "default", Version: "1"}
+	_, err := db.CreateApplicationHistoryContext(context.Background(), app, "alice")
+	assert.NoError(t, err)
+	res, err := db.GetApplicationContext(context.Background(), "test", "default", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", res.Version)
+
+	// the calls are aborted once ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = db.CreateApplicationHistoryContext(ctx, &specV1.Application{Name: "test", Namespace: "default", Version: "2"}, "alice")
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.GetApplicationContext(ctx, "test", "default", "1")
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.ListApplicationHistoryContext(ctx, "test", "default", 1, 10)
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.DeleteApplicationHistoryContext(ctx, "test", "default", "1", "bob")
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.SoftDeleteApplicationContext(ctx, "test", "default", "1")
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.CountApplicationContext(ctx, nil, "test", "default")
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.ListApplicationByTimeContext(ctx, "test", "default", time.Time{}, time.Time{})
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.GetApplicationRequestContext(ctx, "default", "req")
+	assert.Equal(t, context.Canceled, err)
+
+	histories, err := db.ListApplicationHistoryContext(context.Background(), "test", "default", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 1)
+}
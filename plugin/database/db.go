@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/plugin"
@@ -46,6 +47,11 @@ func (d *dbStorage) Close() error {
 	return d.db.Close()
 }
 
+// Ping check whether the database is reachable
+func (d *dbStorage) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
 func (d *dbStorage) Transact(handler func(*sqlx.Tx) error) (err error) {
 	tx, err := d.db.Beginx()
 	if err != nil {
@@ -66,15 +72,25 @@ func (d *dbStorage) Transact(handler func(*sqlx.Tx) error) (err error) {
 }
 
 func (d *dbStorage) exec(tx *sqlx.Tx, sql string, args ...interface{}) (sql.Result, error) {
+	return d.execContext(context.Background(), tx, sql, args...)
+}
+
+// execContext exec sql, the statement is aborted once ctx is done
+func (d *dbStorage) execContext(ctx context.Context, tx *sqlx.Tx, sql string, args ...interface{}) (sql.Result, error) {
 	if tx == nil {
-		return d.db.Exec(sql, args...)
+		return d.db.ExecContext(ctx, sql, args...)
 	}
-	return tx.Exec(sql, args...)
+	return tx.ExecContext(ctx, sql, args...)
 }
 
 func (d *dbStorage) query(tx *sqlx.Tx, sql string, data interface{}, args ...interface{}) error {
+	return d.queryContext(context.Background(), tx, sql, data, args...)
+}
+
+// queryContext select into data, the query is aborted once ctx is done
+func (d *dbStorage) queryContext(ctx context.Context, tx *sqlx.Tx, sql string, data interface{}, args ...interface{}) error {
 	if tx == nil {
-		return d.db.Select(data, sql, args...)
+		return d.db.SelectContext(ctx, data, sql, args...)
 	}
-	return tx.Select(data, sql, args...)
+	return tx.SelectContext(ctx, data, sql, args...)
 }
@@ -1,5 +1,7 @@
 package database
 
+import "github.com/baetyl/baetyl-cloud/models"
+
 // CloudConfig baetyl-cloud config
 type CloudConfig struct {
 	Database struct {
@@ -9,4 +11,10 @@ type CloudConfig struct {
 		MaxIdleConns    int    `yaml:"maxIdleConns" json:"maxIdleConns" default:5`
 		ConnMaxLifetime int    `yaml:"connMaxLifetime" json:"connMaxLifetime" default:150`
 	} `yaml:"database" json:"database" default:"{}"`
+	Function struct {
+		// InvokeURL the address of function runtime, the payload is posted to {InvokeURL}/{name}/{version}
+		InvokeURL string `yaml:"invokeURL" json:"invokeURL"`
+		// Runtimes the runtimes advertised to users
+		Runtimes []models.FunctionRuntime `yaml:"runtimes" json:"runtimes"`
+	} `yaml:"function" json:"function"`
 }
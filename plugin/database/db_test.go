@@ -1,8 +1,12 @@
 package database
 
 import (
+	"context"
+	"testing"
+
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
 )
 
 func MockNewDB() (*dbStorage, error) {
@@ -19,3 +23,16 @@ func MockNewDB() (*dbStorage, error) {
 	}
 	return &dbStorage{db: db, cfg: cfg}, nil
 }
+
+func TestDbStorage_Ping(t *testing.T) {
+	db, err := MockNewDB()
+	assert.NoError(t, err)
+	assert.NoError(t, db.Ping(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, db.Ping(ctx))
+
+	assert.NoError(t, db.Close())
+	assert.Error(t, db.Ping(context.Background()))
+}
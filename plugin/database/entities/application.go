@@ -16,6 +16,18 @@ type Application struct {
 	CreateTime time.Time `db:"create_time"`
 	UpdateTime time.Time `db:"update_time"`
 	Content    string    `db:"content"`
+	Operator   string    `db:"operator"`
+}
+
+type ApplicationRequest struct {
+	Id         uint64    `db:"id"`
+	Namespace  string    `db:"namespace"`
+	RequestID  string    `db:"request_id"`
+	Name       string    `db:"name"`
+	Version    string    `db:"version"`
+	Digest     string    `db:"digest"`
+	CreateTime time.Time `db:"create_time"`
+	UpdateTime time.Time `db:"update_time"`
 }
 
 func ToApplicationModel(application *Application) (*specV1.Application, error) {
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-go/log"
+)
+
+type Function struct {
+	Id         uint64    `db:"id"`
+	UserID     string    `db:"user_id"`
+	Name       string    `db:"name"`
+	Version    string    `db:"version"`
+	Handler    string    `db:"handler"`
+	Runtime    string    `db:"runtime"`
+	Code       string    `db:"code"`
+	CreateTime time.Time `db:"create_time"`
+	UpdateTime time.Time `db:"update_time"`
+}
+
+func ToFunctionModel(function *Function) (*models.Function, error) {
+	fn := &models.Function{
+		Name:    function.Name,
+		Version: function.Version,
+		Handler: function.Handler,
+		Runtime: function.Runtime,
+	}
+	if function.Code != "" {
+		err := json.Unmarshal([]byte(function.Code), &fn.Code)
+		if err != nil {
+			log.L().Error("function db to function error",
+				log.Any("name", function.Name),
+				log.Any("version", function.Version))
+			return nil, err
+		}
+	}
+	return fn, nil
+}
+
+func FromFunctionModel(userID string, fn *models.Function) (*Function, error) {
+	code, err := json.Marshal(fn.Code)
+	if err != nil {
+		log.L().Error("function translate to db model error",
+			log.Any("name", fn.Name),
+			log.Any("version", fn.Version))
+		return nil, err
+	}
+	return &Function{
+		UserID:  userID,
+		Name:    fn.Name,
+		Version: fn.Version,
+		Handler: fn.Handler,
+		Runtime: fn.Runtime,
+		Code:    string(code),
+	}, nil
+}
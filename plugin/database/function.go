@@ -0,0 +1,313 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-cloud/plugin/database/entities"
+	"github.com/jmoiron/sqlx"
+)
+
+// functionStorage the function plugin backed by database, functions are stored the same as applications
+type functionStorage struct {
+	db     *dbStorage
+	client *http.Client
+}
+
+func init() {
+	plugin.RegisterFactory("databasefunction", NewFunction)
+}
+
+// NewFunction New function plugin
+func NewFunction() (plugin.Plugin, error) {
+	d, err := New()
+	if err != nil {
+		return nil, err
+	}
+	return &functionStorage{db: d.(*dbStorage), client: http.DefaultClient}, nil
+}
+
+// List the latest version of each function, ordered by name
+func (f *functionStorage) List(userID string, listOptions *models.ListOptions) ([]models.Function, int, error) {
+	return f.ListContext(context.Background(), userID, listOptions)
+}
+
+func (f *functionStorage) ListContext(ctx context.Context, userID string, listOptions *models.ListOptions) ([]models.Function, int, error) {
+	filterSQL := `
+FROM baetyl_function 
+WHERE id IN (SELECT MAX(id) FROM baetyl_function WHERE user_id = ? GROUP BY name) AND name LIKE ? ESCAPE '!'
+`
+	prefix := ""
+	if listOptions != nil {
+		prefix = listOptions.NamePrefix
+	}
+	args := []interface{}{userID, escapeLike(prefix) + "%"}
+
+	var res []struct {
+		Count int `db:"count"`
+	}
+	if err := f.db.queryContext(ctx, nil, "SELECT count(id) AS count"+filterSQL, &res, args...); err != nil {
+		return nil, 0, err
+	}
+
+	selectSQL := `
+SELECT  
+id, user_id, name, version, handler, runtime, code, create_time, update_time` + filterSQL + "ORDER BY name"
+	if offset, limit := pageOf(listOptions); limit > 0 {
+		selectSQL += " LIMIT ?,?"
+		args = append(args, offset, limit)
+	}
+	fns, err := f.listFunctions(ctx, selectSQL, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return fns, res[0].Count, nil
+}
+
+// ListFunctionVersions the newest version first
+func (f *functionStorage) ListFunctionVersions(userID, name string) ([]models.Function, error) {
+	return f.ListFunctionVersionsContext(context.Background(), userID, name)
+}
+
+func (f *functionStorage) ListFunctionVersionsContext(ctx context.Context, userID, name string) ([]models.Function, error) {
+	selectSQL := `
+SELECT  
+id, user_id, name, version, handler, runtime, code, create_time, update_time
+FROM baetyl_function 
+WHERE user_id = ? AND name = ? ORDER BY id DESC
+`
+	return f.listFunctions(ctx, selectSQL, userID, name)
+}
+
+func (f *functionStorage) Get(userID, name, version string) (*models.Function, error) {
+	return f.GetContext(context.Background(), userID, name, version)
+}
+
+func (f *functionStorage) GetContext(ctx context.Context, userID, name, version string) (*models.Function, error) {
+	if version == "" {
+		fns, err := f.ListFunctionVersionsContext(ctx, userID, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(fns) == 0 {
+			return nil, common.Error(common.ErrResourceNotFound,
+				common.Field("type", "function"),
+				common.Field("name", name))
+		}
+		latest := fns[0]
+		for _, fn := range fns[1:] {
+			if common.CompareVersion(fn.Version, latest.Version) > 0 {
+				latest = fn
+			}
+		}
+		return &latest, nil
+	}
+	fn, err := f.getFunction(ctx, nil, userID, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if fn == nil {
+		return nil, common.Error(common.ErrResourceNotFound,
+			common.Field("type", "function"),
+			common.Field("name", name+":"+version))
+	}
+	return fn, nil
+}
+
+func (f *functionStorage) Create(userID string, fn *models.Function) (*models.Function, error) {
+	var res *models.Function
+	err := f.db.Transact(func(tx *sqlx.Tx) error {
+		old, err := f.GetFunctionWithTx(tx, userID, fn.Name, fn.Version)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			return common.Error(common.ErrResourceConflict,
+				common.Field("type", "function"),
+				common.Field("name", fn.Name+":"+fn.Version))
+		}
+		if _, err = f.CreateFunctionWithTx(tx, userID, fn); err != nil {
+			return err
+		}
+		res, err = f.GetFunctionWithTx(tx, userID, fn.Name, fn.Version)
+		return err
+	})
+	return res, err
+}
+
+func (f *functionStorage) Update(userID string, fn *models.Function) (*models.Function, error) {
+	var res *models.Function
+	err := f.db.Transact(func(tx *sqlx.Tx) error {
+		result, err := f.UpdateFunctionWithTx(tx, userID, fn)
+		if err != nil {
+			return err
+		}
+		if num, err := result.RowsAffected(); err != nil {
+			return err
+		} else if num == 0 {
+			return common.Error(common.ErrResourceNotFound,
+				common.Field("type", "function"),
+				common.Field("name", fn.Name+":"+fn.Version))
+		}
+		res, err = f.GetFunctionWithTx(tx, userID, fn.Name, fn.Version)
+		return err
+	})
+	return res, err
+}
+
+func (f *functionStorage) Delete(userID, name, version string) error {
+	deleteSQL := `
+DELETE FROM baetyl_function WHERE user_id = ? AND name = ? AND version = ?
+`
+	_, err := f.db.exec(nil, deleteSQL, userID, name, version)
+	return err
+}
+
+func (f *functionStorage) GetFunctionWithTx(tx *sqlx.Tx, userID, name, version string) (*models.Function, error) {
+	return f.getFunction(context.Background(), tx, userID, name, version)
+}
+
+func (f *functionStorage) getFunction(ctx context.Context, tx *sqlx.Tx, userID, name, version string) (*models.Function, error) {
+	selectSQL := `
+SELECT  
+id, user_id, name, version, handler, runtime, code, create_time, update_time
+FROM baetyl_function 
+WHERE user_id = ? AND name = ? AND version = ?
+`
+	var fns []entities.Function
+	if err := f.db.queryContext(ctx, tx, selectSQL, &fns, userID, name, version); err != nil {
+		return nil, err
+	}
+	if len(fns) > 0 {
+		return entities.ToFunctionModel(&fns[0])
+	}
+	return nil, nil
+}
+
+func (f *functionStorage) CreateFunctionWithTx(tx *sqlx.Tx, userID string, fn *models.Function) (sql.Result, error) {
+	insertSQL := `
+INSERT INTO baetyl_function 
+(user_id, name, version, handler, runtime, code) 
+VALUES (?, ?, ?, ?, ?, ?)
+`
+	function, err := entities.FromFunctionModel(userID, fn)
+	if err != nil {
+		return nil, err
+	}
+	return f.db.exec(tx, insertSQL, function.UserID, function.Name, function.Version, function.Handler, function.Runtime, function.Code)
+}
+
+func (f *functionStorage) UpdateFunctionWithTx(tx *sqlx.Tx, userID string, fn *models.Function) (sql.Result, error) {
+	updateSQL := `
+UPDATE baetyl_function SET handler = ?, runtime = ?, code = ?, update_time = CURRENT_TIMESTAMP
+WHERE user_id = ? AND name = ? AND version = ?
+`
+	function, err := entities.FromFunctionModel(userID, fn)
+	if err != nil {
+		return nil, err
+	}
+	return f.db.exec(tx, updateSQL, function.Handler, function.Runtime, function.Code, function.UserID, function.Name, function.Version)
+}
+
+// Invoke post the payload to the function runtime, a response whose status is not 2xx is returned as ErrFunction
+func (f *functionStorage) Invoke(userID, name, version string, payload []byte) ([]byte, error) {
+	return f.InvokeContext(context.Background(), userID, name, version, payload)
+}
+
+// InvokeContext the request to the function runtime is aborted once ctx is done
+func (f *functionStorage) InvokeContext(ctx context.Context, userID, name, version string, payload []byte) ([]byte, error) {
+	if f.db.cfg.Function.InvokeURL == "" {
+		return nil, common.Error(common.ErrFunction, common.Field("error", "the function runtime is not configured"))
+	}
+	if _, err := f.GetContext(ctx, userID, name, version); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(f.db.cfg.Function.InvokeURL, "/"), name, version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, common.Error(common.ErrFunction, common.Field("error", err.Error()))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, common.Error(common.ErrFunction, common.Field("error", err.Error()))
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, common.Error(common.ErrFunction, common.Field("error", err.Error()))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, common.Error(common.ErrFunction,
+			common.Field("error", fmt.Sprintf("[%d] %s", resp.StatusCode, string(data))))
+	}
+	return data, nil
+}
+
+// ListRuntimes the runtimes are configured by function.runtimes
+func (f *functionStorage) ListRuntimes(_ string) ([]models.FunctionRuntime, error) {
+	res := make([]models.FunctionRuntime, len(f.db.cfg.Function.Runtimes))
+	copy(res, f.db.cfg.Function.Runtimes)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// Ping check whether the database storing functions is reachable
+func (f *functionStorage) Ping(ctx context.Context) error {
+	return f.db.Ping(ctx)
+}
+
+// Close Close
+func (f *functionStorage) Close() error {
+	return f.db.Close()
+}
+
+// pageOf the page is selected by PageNo and PageSize, or the first Limit ones
+func pageOf(listOptions *models.ListOptions) (int, int) {
+	if listOptions == nil {
+		return 0, 0
+	}
+	if listOptions.PageSize > 0 {
+		pageNo := listOptions.PageNo
+		if pageNo <= 0 {
+			pageNo = 1
+		}
+		return (pageNo - 1) * listOptions.PageSize, listOptions.PageSize
+	}
+	return 0, int(listOptions.Limit)
+}
+
+// escapeLike escape the wildcards of LIKE with !, which means the same in mysql and sqlite
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func (f *functionStorage) listFunctions(ctx context.Context, selectSQL string, args ...interface{}) ([]models.Function, error) {
+	var fns []entities.Function
+	if err := f.db.queryContext(ctx, nil, selectSQL, &fns, args...); err != nil {
+		return nil, err
+	}
+	result := make([]models.Function, 0, len(fns))
+	for _, fn := range fns {
+		function, err := entities.ToFunctionModel(&fn)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *function)
+	}
+	return result, nil
+}
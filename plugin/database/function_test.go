@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-go/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	functionTables = []string{`
+CREATE TABLE baetyl_function
+(
+    id          integer             PRIMARY KEY AUTOINCREMENT,
+    user_id     varchar(64)         NOT NULL DEFAULT '' ,
+    name        varchar(128)        NOT NULL DEFAULT '' ,
+    version     varchar(36)         NOT NULL DEFAULT '' ,
+    handler     varchar(128)        NOT NULL DEFAULT '' ,
+    runtime     varchar(64)         NOT NULL DEFAULT '' ,
+    code        text                NOT NULL DEFAULT '' ,
+    create_time timestamp           NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time timestamp           NOT NULL DEFAULT CURRENT_TIMESTAMP ,
+    UNIQUE (user_id, name, version)
+);
+`,
+	}
+)
+
+func mockFunctionStorage(t *testing.T) *functionStorage {
+	db, err := MockNewDB()
+	if err != nil {
+		fmt.Printf("get mock sqlite3 error = %s", err.Error())
+		t.Fail()
+		return nil
+	}
+	for _, sql := range functionTables {
+		_, err := db.exec(nil, sql)
+		if err != nil {
+			panic(fmt.Sprintf("create table exception: %s", err.Error()))
+		}
+	}
+	return &functionStorage{db: db, client: http.DefaultClient}
+}
+
+func TestFunctionStorage(t *testing.T) {
+	f := mockFunctionStorage(t)
+	defer f.Close()
+
+	fn := &models.Function{
+		Name:    "process",
+		Version: "1",
+		Handler: "index.handler",
+		Runtime: "python3",
+		Code:    models.FunctionCode{Size: 10, Sha256: "abc", Location: "http://test/1"},
+	}
+	res, err := f.Create("user", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, fn, res)
+
+	// the same name and version is conflicted
+	_, err = f.Create("user", fn)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceConflict, err.(errors.Coder).Code())
+
+	// another user
+	_, err = f.Create("other", fn)
+	assert.NoError(t, err)
+
+	fn2 := &models.Function{Name: "process", Version: "2", Handler: "index.handler", Runtime: "python3"}
+	_, err = f.Create("user", fn2)
+	assert.NoError(t, err)
+	fn3 := &models.Function{Name: "filter", Version: "1", Handler: "index.handler", Runtime: "nodejs10"}
+	_, err = f.Create("user", fn3)
+	assert.NoError(t, err)
+
+	res, err = f.Get("user", "process", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, fn, res)
+	_, err = f.Get("user", "process", "3")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	list, total, err := f.List("user", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []models.Function{*fn3, *fn2}, list)
+
+	list, err = f.ListFunctionVersions("user", "process")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Function{*fn2, *fn}, list)
+
+	fn.Handler = "main.handler"
+	res, err = f.Update("user", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, "main.handler", res.Handler)
+	res, err = f.Get("other", "process", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "index.handler", res.Handler)
+
+	_, err = f.Update("user", &models.Function{Name: "process", Version: "3"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	err = f.Delete("user", "process", "1")
+	assert.NoError(t, err)
+	_, err = f.Get("user", "process", "1")
+	assert.Error(t, err)
+	list, err = f.ListFunctionVersions("user", "process")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Function{*fn2}, list)
+	err = f.Delete("user", "process", "1")
+	assert.NoError(t, err)
+}
+
+func TestFunctionStorage_List(t *testing.T) {
+	f := mockFunctionStorage(t)
+	defer f.Close()
+
+	// empty
+	list, total, err := f.List("user", &models.ListOptions{PageNo: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, list)
+
+	for _, name := range []string{"proc-a", "proc-b", "proc-c", "filter", "proc_d"} {
+		_, err = f.Create("user", &models.Function{Name: name, Version: "1"})
+		assert.NoError(t, err)
+	}
+	_, err = f.Create("user", &models.Function{Name: "proc-a", Version: "2"})
+	assert.NoError(t, err)
+	_, err = f.Create("other", &models.Function{Name: "proc-e", Version: "1"})
+	assert.NoError(t, err)
+	names := func(fns []models.Function) []string {
+		var res []string
+		for _, fn := range fns {
+			res = append(res, fn.Name+":"+fn.Version)
+		}
+		return res
+	}
+
+	// a partial page
+	list, total, err = f.List("user", &models.ListOptions{PageNo: 3, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []string{"proc_d:1"}, names(list))
+	list, total, err = f.List("user", &models.ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []string{"filter:1", "proc-a:2"}, names(list))
+
+	// by prefix
+	list, total, err = f.List("user", &models.ListOptions{NamePrefix: "proc-", PageNo: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"proc-a:2", "proc-b:1"}, names(list))
+	list, total, err = f.List("user", &models.ListOptions{NamePrefix: "proc_"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []string{"proc_d:1"}, names(list))
+	list, total, err = f.List("user", &models.ListOptions{NamePrefix: "none"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, list)
+}
+
+func TestFunctionStorage_Invoke(t *testing.T) {
+	f := mockFunctionStorage(t)
+	defer f.Close()
+
+	_, err := f.Invoke("user", "process", "1", nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrFunction, err.(errors.Coder).Code())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path != "/process/1" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("crashed"))
+			return
+		}
+		w.Write(append([]byte("echo "), body...))
+	}))
+	defer server.Close()
+	f.db.cfg.Function.InvokeURL = server.URL + "/"
+
+	_, err = f.Invoke("user", "process", "1", nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	_, err = f.Create("user", &models.Function{Name: "process", Version: "1"})
+	assert.NoError(t, err)
+	_, err = f.Create("user", &models.Function{Name: "process", Version: "2"})
+	assert.NoError(t, err)
+
+	res, err := f.Invoke("user", "process", "1", []byte("hi"))
+	assert.NoError(t, err)
+	assert.Equal(t, "echo hi", string(res))
+
+	_, err = f.Invoke("user", "process", "2", []byte("hi"))
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrFunction, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "[500] crashed")
+}
+
+func TestFunctionStorage_InvokeContext(t *testing.T) {
+	f := mockFunctionStorage(t)
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	f.db.cfg.Function.InvokeURL = server.URL
+
+	_, err := f.Create("user", &models.Function{Name: "process", Version: "1"})
+	assert.NoError(t, err)
+
+	// the request is aborted once ctx is done
+	_, err = f.InvokeContext(ctx, "user", "process", "1", []byte("hi"))
+	assert.Equal(t, context.Canceled, err)
+	_, err = f.GetContext(ctx, "user", "process", "1")
+	assert.Equal(t, context.Canceled, err)
+	_, _, err = f.ListContext(ctx, "user", nil)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestFunctionStorage_GetLatest(t *testing.T) {
+	f := mockFunctionStorage(t)
+	defer f.Close()
+
+	_, err := f.Get("user", "process", "")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	// the latest isn't the last created
+	for _, version := range []string{"1.9", "1.10", "1.2"} {
+		_, err = f.Create("user", &models.Function{Name: "process", Version: version, Handler: "h" + version})
+		assert.NoError(t, err)
+	}
+	res, err := f.Get("user", "process", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.10", res.Version)
+	assert.Equal(t, "h1.10", res.Handler)
+
+	res, err = f.Get("user", "process", "1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2", res.Version)
+}
+
+func TestFunctionStorage_ListRuntimes(t *testing.T) {
+	f := mockFunctionStorage(t)
+	defer f.Close()
+
+	res, err := f.ListRuntimes("user")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FunctionRuntime{}, res)
+
+	f.db.cfg.Function.Runtimes = []models.FunctionRuntime{
+		{Name: "python3", Handler: "index.handler"},
+		{Name: "nodejs10", Handler: "index.handler"},
+		{Name: "golang", Handler: "main"},
+	}
+	res, err = f.ListRuntimes("user")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FunctionRuntime{
+		{Name: "golang", Handler: "main"},
+		{Name: "nodejs10", Handler: "index.handler"},
+		{Name: "python3", Handler: "index.handler"},
+	}, res)
+	// the config isn't reordered
+	assert.Equal(t, "python3", f.db.cfg.Function.Runtimes[0].Name)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/jmoiron/sqlx"
+	"sort"
 )
 
 var cache = map[string]string{}
@@ -21,6 +22,11 @@ func (d *dbStorage) DeleteIndex(namespace string, keyA, byKeyB common.Resource,
 	return d.DeleteIndexTx(nil, namespace, keyA, byKeyB, valueB)
 }
 
+// ListIndexKeys list the distinct values of keyA in the index of keyA and keyB
+func (d *dbStorage) ListIndexKeys(namespace string, keyA, keyB common.Resource) ([]string, error) {
+	return d.ListIndexKeysTx(nil, namespace, keyA, keyB)
+}
+
 func (d *dbStorage) CreateIndexTx(tx *sqlx.Tx, namespace string, keyA, keyB common.Resource, valueA, valueB string) (sql.Result, error) {
 	selectSQL := fmt.Sprintf(`INSERT INTO %s (namespace, %s, %s) VALUES (?, ?, ?)`, getTable(keyA, keyB), keyA, keyB)
 	return d.exec(tx, selectSQL, namespace, valueA, valueB)
@@ -35,6 +41,15 @@ func (d *dbStorage) ListIndexTx(tx *sqlx.Tx, namespace string, keyA, byKeyB comm
 	return res, nil
 }
 
+func (d *dbStorage) ListIndexKeysTx(tx *sqlx.Tx, namespace string, keyA, keyB common.Resource) ([]string, error) {
+	selectSQL := fmt.Sprintf(`SELECT DISTINCT %s FROM %s WHERE namespace = ? ORDER BY %s`, keyA, getTable(keyA, keyB), keyA)
+	var res []string
+	if err := d.query(tx, selectSQL, &res, namespace); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (d *dbStorage) DeleteIndexTx(tx *sqlx.Tx, namespace string, keyA, byKeyB common.Resource, valueB string) (sql.Result, error) {
 	selectSQL := fmt.Sprintf(`DELETE FROM %s WHERE namespace = ? and %s = ?`, getTable(keyA, byKeyB), byKeyB)
 	return d.exec(tx, selectSQL, namespace, valueB)
@@ -54,6 +69,28 @@ func (d *dbStorage) RefreshIndex(namespace string, keyA, keyB common.Resource, v
 	})
 }
 
+// RefreshIndexes the valueAs are refreshed in order, all or none of them are written
+func (d *dbStorage) RefreshIndexes(namespace string, keyA, keyB common.Resource, values map[string][]string) error {
+	valueAs := make([]string, 0, len(values))
+	for a := range values {
+		valueAs = append(valueAs, a)
+	}
+	sort.Strings(valueAs)
+	return d.Transact(func(tx *sqlx.Tx) error {
+		for _, a := range valueAs {
+			if _, err := d.DeleteIndexTx(tx, namespace, keyB, keyA, a); err != nil {
+				return err
+			}
+			for _, b := range values[a] {
+				if _, err := d.CreateIndexTx(tx, namespace, keyA, keyB, a, b); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
 func getTable(keyA, keyB common.Resource) string {
 	keyAB := string(keyA) + "_" + string(keyB)
 	if v, ok := cache[keyAB]; ok {
@@ -145,3 +145,109 @@ func TestDbStorage_RefreshIndex(t *testing.T) {
 	db.RefreshIndex(namespace, common.Node, common.Application, valueB, []string{valueA})
 	db.RefreshIndex(namespace, common.Application, common.Node, valueA, []string{valueB})
 }
+
+func TestDbStorage_RefreshIndexes(t *testing.T) {
+	db, err := MockNewDB()
+	if err != nil {
+		fmt.Printf("get mock sqlite3 error = %s", err.Error())
+		t.Fail()
+		return
+	}
+	db.MockCreateIndexTable()
+
+	namespace := "default"
+	assert.NoError(t, db.RefreshIndex(namespace, common.Application, common.Config, "app0", []string{"config0"}))
+	assert.NoError(t, db.RefreshIndex(namespace, common.Application, common.Config, "app1", []string{"config0"}))
+	assert.NoError(t, db.RefreshIndex(namespace, common.Application, common.Config, "app2", []string{"config0"}))
+	assert.NoError(t, db.RefreshIndex("other", common.Application, common.Config, "app0", []string{"config0"}))
+
+	err = db.RefreshIndexes(namespace, common.Application, common.Config, map[string][]string{
+		"app0": {"config1", "config2"},
+		"app1": {},
+		"app3": {"config0"},
+	})
+	assert.NoError(t, err)
+	for app, expected := range map[string][]string{
+		"app0": {"config1", "config2"},
+		"app1": nil,
+		"app2": {"config0"},
+		"app3": {"config0"},
+	} {
+		res, err := db.ListIndex(namespace, common.Config, common.Application, app)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, expected, res, app)
+	}
+	res, err := db.ListIndex("other", common.Config, common.Application, "app0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config0"}, res)
+
+	assert.NoError(t, db.RefreshIndexes(namespace, common.Application, common.Config, nil))
+
+	// the table of secret is missing
+	err = db.RefreshIndexes(namespace, common.Application, common.Secret, map[string][]string{"app0": {"secret0"}})
+	assert.Error(t, err)
+}
+
+func BenchmarkDbStorage_RefreshIndex(b *testing.B) {
+	namespace := "default"
+	values := map[string][]string{}
+	for i := 0; i < 100; i++ {
+		values[fmt.Sprintf("app%d", i)] = []string{"config0", "config1", "config2"}
+	}
+	setup := func(b *testing.B) *dbStorage {
+		db, err := MockNewDB()
+		if err != nil {
+			b.Fatal(err)
+		}
+		db.MockCreateIndexTable()
+		return db
+	}
+
+	b.Run("PerApp", func(b *testing.B) {
+		db := setup(b)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			for app, configs := range values {
+				if err := db.RefreshIndex(namespace, common.Application, common.Config, app, configs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("Batched", func(b *testing.B) {
+		db := setup(b)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			if err := db.RefreshIndexes(namespace, common.Application, common.Config, values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestDbStorage_ListIndexKeys(t *testing.T) {
+	db, err := MockNewDB()
+	if err != nil {
+		fmt.Printf("get mock sqlite3 error = %s", err.Error())
+		t.Fail()
+		return
+	}
+	db.MockCreateIndexTable()
+
+	namespace := "default"
+	assert.NoError(t, db.RefreshIndex(namespace, common.Application, common.Config, "app1", []string{"config0", "config1"}))
+	assert.NoError(t, db.RefreshIndex(namespace, common.Application, common.Config, "app0", []string{"config0"}))
+	assert.NoError(t, db.RefreshIndex("other", common.Application, common.Config, "app2", []string{"config0"}))
+
+	keys, err := db.ListIndexKeys(namespace, common.Application, common.Config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app0", "app1"}, keys)
+
+	keys, err = db.ListIndexKeys(namespace, common.Config, common.Application)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config0", "config1"}, keys)
+
+	keys, err = db.ListIndexKeys("empty", common.Application, common.Config)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 0)
+}
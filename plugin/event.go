@@ -0,0 +1,15 @@
+package plugin
+
+import (
+	"io"
+
+	"github.com/baetyl/baetyl-cloud/models"
+)
+
+//go:generate mockgen -destination=../mock/plugin/event.go -package=plugin github.com/baetyl/baetyl-cloud/plugin EventSink
+
+// EventSink receives the events of application lifecycle, e.g. to drive CI or notifications
+type EventSink interface {
+	Publish(event *models.ApplicationEvent) error
+	io.Closer
+}
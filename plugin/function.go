@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"context"
 	"io"
 
 	"github.com/baetyl/baetyl-cloud/models"
@@ -10,8 +11,33 @@ import (
 
 // Function interface of Function
 type Function interface {
-	List(userID string) ([]models.Function, error)
+	// List returns a page of functions which is selected by PageNo and PageSize or Limit, and the total of matched ones.
+	// All functions are returned if listOptions is nil
+	List(userID string, listOptions *models.ListOptions) ([]models.Function, int, error)
 	ListFunctionVersions(userID, name string) ([]models.Function, error)
+	// Get returns the latest version if version is empty, which is the greatest of ListFunctionVersions by common.CompareVersion
 	Get(userID, name, version string) (*models.Function, error)
+	// Create returns ErrResourceConflict if the name and version exists
+	Create(userID string, fn *models.Function) (*models.Function, error)
+	// Update returns ErrResourceNotFound if the name and version doesn't exist
+	Update(userID string, fn *models.Function) (*models.Function, error)
+	Delete(userID, name, version string) error
+	// Invoke send the payload to the function runtime and return the response
+	Invoke(userID, name, version string, payload []byte) ([]byte, error)
+	// ListRuntimes returns the supported runtimes sorted by name
+	ListRuntimes(userID string) ([]models.FunctionRuntime, error)
+	// Ping check whether the function backend is reachable
+	Ping(ctx context.Context) error
 	io.Closer
 }
+
+//go:generate mockgen -destination=../mock/plugin/function_context.go -package=plugin github.com/baetyl/baetyl-cloud/plugin FunctionContext
+
+// FunctionContext is optionally implemented by Function to take ctx, the call is aborted once ctx is done.
+// The results and errors are the same as the ones of Function
+type FunctionContext interface {
+	ListContext(ctx context.Context, userID string, listOptions *models.ListOptions) ([]models.Function, int, error)
+	ListFunctionVersionsContext(ctx context.Context, userID, name string) ([]models.Function, error)
+	GetContext(ctx context.Context, userID, name, version string) (*models.Function, error)
+	InvokeContext(ctx context.Context, userID, name, version string, payload []byte) ([]byte, error)
+}
@@ -1,6 +1,7 @@
 package kube
 
 import (
+	"context"
 	"fmt"
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/models"
@@ -8,7 +9,10 @@ import (
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/baetyl/baetyl-go/utils"
 	"github.com/jinzhu/copier"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"time"
 )
 
 func toAppModel(app *v1alpha1.Application) *specV1.Application {
@@ -16,7 +20,7 @@ func toAppModel(app *v1alpha1.Application) *specV1.Application {
 	res := &specV1.Application{
 		Name:        app.ObjectMeta.Name,
 		Namespace:   app.ObjectMeta.Namespace,
-		Version:     app.ObjectMeta.ResourceVersion,
+		Version:     appVersion(&app.ObjectMeta),
 		Description: description,
 		Labels:      app.ObjectMeta.Labels,
 	}
@@ -29,26 +33,44 @@ func toAppModel(app *v1alpha1.Application) *specV1.Application {
 	return res
 }
 
+// appVersion the version recorded by UpdateApplicationVersion, or the resource version
+func appVersion(meta *metav1.ObjectMeta) string {
+	if v, ok := meta.Annotations[common.AnnotationVersion]; ok {
+		return v
+	}
+	return meta.ResourceVersion
+}
+
 func toAppListModel(list *v1alpha1.ApplicationList) *models.ApplicationList {
 	res := &models.ApplicationList{
 		Items: make([]models.AppItem, 0),
 	}
 	for _, item := range list.Items {
 		description, _ := item.Annotations[common.AnnotationDescription]
-		res.Items = append(res.Items, models.AppItem{
+		appItem := models.AppItem{
 			Name:              item.ObjectMeta.Name,
 			Type:              item.Spec.Type,
 			Namespace:         item.ObjectMeta.Namespace,
-			Version:           item.ObjectMeta.ResourceVersion,
+			Version:           appVersion(&item.ObjectMeta),
 			Labels:            item.ObjectMeta.Labels,
 			Selector:          item.Spec.Selector,
 			CreationTimestamp: item.CreationTimestamp.Time.UTC(),
+			UpdateTimestamp:   item.CreationTimestamp.Time.UTC(),
 			Description:       description,
 			System:            item.Spec.System,
-		})
+		}
+		// the application never updated has no update timestamp
+		if us, ok := item.Annotations[common.AnnotationUpdateTimestamp]; ok {
+			appItem.UpdateTimestamp, _ = time.Parse(common.TimeFormat, us)
+		}
+		res.Items = append(res.Items, appItem)
 	}
 
+	// the items of a limited list are only a page, the server tells how many remain
 	res.Total = len(list.Items)
+	if list.RemainingItemCount != nil {
+		res.Total += int(*list.RemainingItemCount)
+	}
 	return res
 }
 
@@ -88,19 +110,47 @@ func fromListOptionsModel(listOptions *models.ListOptions) *metav1.ListOptions {
 }
 
 func (c *client) GetApplication(namespace, name, version string) (*specV1.Application, error) {
+	return c.GetApplicationContext(context.Background(), namespace, name, version)
+}
+
+func (c *client) GetApplicationContext(ctx context.Context, namespace, name, version string) (*specV1.Application, error) {
 	defer utils.Trace(c.log.Debug, "GetApplication")()
 	options := metav1.GetOptions{ResourceVersion: version}
-	app, err := c.customClient.CloudV1alpha1().Applications(namespace).Get(name, options)
+	app, err := c.applications(ctx, namespace).Get(name, options)
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return toAppModel(app), nil
 }
 
+// GetApplications list the apps of namespace in one request and pick the ones of names
+func (c *client) GetApplications(namespace string, names []string) ([]specV1.Application, error) {
+	defer utils.Trace(c.log.Debug, "GetApplications")()
+	list, err := c.customClient.CloudV1alpha1().Applications(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	res := make([]specV1.Application, 0, len(names))
+	for i := range list.Items {
+		if wanted[list.Items[i].Name] {
+			res = append(res, *toAppModel(&list.Items[i]))
+		}
+	}
+	return res, nil
+}
+
 func (c *client) CreateApplication(namespace string, application *specV1.Application) (*specV1.Application, error) {
+	return c.CreateApplicationContext(context.Background(), namespace, application)
+}
+
+func (c *client) CreateApplicationContext(ctx context.Context, namespace string, application *specV1.Application) (*specV1.Application, error) {
 	app := fromAppModel(namespace, application)
 	defer utils.Trace(c.log.Debug, "CreateApplication")()
-	app, err := c.customClient.CloudV1alpha1().Applications(namespace).Create(app)
+	app, err := c.applications(ctx, namespace).Create(app)
 	if err != nil {
 		return nil, err
 	}
@@ -109,24 +159,69 @@ func (c *client) CreateApplication(namespace string, application *specV1.Applica
 }
 
 func (c *client) UpdateApplication(namespace string, application *specV1.Application) (*specV1.Application, error) {
-	app := fromAppModel(namespace, application)
+	return c.UpdateApplicationContext(context.Background(), namespace, application)
+}
+
+func (c *client) UpdateApplicationContext(ctx context.Context, namespace string, application *specV1.Application) (*specV1.Application, error) {
 	defer utils.Trace(c.log.Debug, "UpdateApplication")()
-	app, err := c.customClient.CloudV1alpha1().Applications(namespace).Update(app)
+	return c.updateApplication(ctx, namespace, application, "")
+}
+
+// UpdateApplicationVersion record version in annotation, the resource version is still checked by kube-apiserver
+func (c *client) UpdateApplicationVersion(namespace string, application *specV1.Application, version string) (*specV1.Application, error) {
+	defer utils.Trace(c.log.Debug, "UpdateApplicationVersion")()
+	return c.updateApplication(context.Background(), namespace, application, version)
+}
+
+func (c *client) updateApplication(ctx context.Context, namespace string, application *specV1.Application, version string) (*specV1.Application, error) {
+	app := fromAppModel(namespace, application)
+	app.Annotations[common.AnnotationUpdateTimestamp] = time.Now().UTC().Format(common.TimeFormat)
+	current, err := c.applications(ctx, namespace).Get(application.Name, metav1.GetOptions{})
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
+	}
+	// the annotations not managed by cloud, such as owner or ticket link set by operators, are passed through
+	for k, v := range current.Annotations {
+		if !strings.HasPrefix(k, common.BaetylCloudGroup+"/") {
+			app.Annotations[k] = v
+		}
+	}
+	// the recorded version can't be checked by kube-apiserver, so it is compared here
+	// and the update is made on the resource version read above
+	if v, ok := current.Annotations[common.AnnotationVersion]; ok {
+		if application.Version != v {
+			return nil, kerrors.NewConflict(v1alpha1.Resource("applications"), application.Name,
+				fmt.Errorf("the version %s is outdated, the current version is %s", application.Version, v))
+		}
+		app.ResourceVersion = current.ResourceVersion
+	}
+	if version != "" {
+		app.Annotations[common.AnnotationVersion] = version
+	}
+	app, err = c.applications(ctx, namespace).Update(app)
+	if err != nil {
+		return nil, toStorageError(err)
 	}
 	return toAppModel(app), nil
 }
 
 func (c *client) DeleteApplication(namespace, name string) error {
+	return c.DeleteApplicationContext(context.Background(), namespace, name)
+}
+
+func (c *client) DeleteApplicationContext(ctx context.Context, namespace, name string) error {
 	defer utils.Trace(c.log.Debug, "DeleteApplication")()
-	err := c.customClient.CloudV1alpha1().Applications(namespace).Delete(name, &metav1.DeleteOptions{})
-	return err
+	err := c.applications(ctx, namespace).Delete(name, &metav1.DeleteOptions{})
+	return toStorageError(err)
 }
 
 func (c *client) ListApplication(namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	return c.ListApplicationContext(context.Background(), namespace, listOptions)
+}
+
+func (c *client) ListApplicationContext(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
 	defer utils.Trace(c.log.Debug, "ListApplication")()
-	list, err := c.customClient.CloudV1alpha1().Applications(namespace).List(*fromListOptionsModel(listOptions))
+	list, err := c.applications(ctx, namespace).List(*fromListOptionsModel(listOptions))
 	listOptions.Continue = list.Continue
 	if err != nil {
 		return nil, err
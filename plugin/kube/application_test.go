@@ -1,15 +1,20 @@
 package kube
 
 import (
+	"errors"
 	"github.com/baetyl/baetyl-go/log"
 	"testing"
+	"time"
 
+	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
 	"github.com/baetyl/baetyl-cloud/plugin/kube/apis/cloud/v1alpha1"
 	"github.com/baetyl/baetyl-cloud/plugin/kube/client/clientset/versioned/fake"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -226,10 +231,23 @@ func TestGetApplication(t *testing.T) {
 	c := initApplicationClient()
 	_, err := c.GetApplication("default", "test", "")
 	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, plugin.ErrNotFound))
 	cfg, err := c.GetApplication("default", "test_name", "")
 	assert.Equal(t, cfg.Name, "test_name")
 }
 
+func TestGetApplications(t *testing.T) {
+	c := initApplicationClient()
+	apps, err := c.GetApplications("default", []string{"test_name", "missing"})
+	assert.NoError(t, err)
+	assert.Len(t, apps, 1)
+	assert.Equal(t, "test_name", apps[0].Name)
+
+	apps, err = c.GetApplications("default", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, apps)
+}
+
 func TestCreateApplication(t *testing.T) {
 	c := initApplicationClient()
 	cfg := &specV1.Application{
@@ -259,12 +277,78 @@ func TestUpdateApplication(t *testing.T) {
 	cfg.Name = cfg.Name + "NULL"
 	_, err = c.UpdateApplication(cfg.Namespace, cfg)
 	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, plugin.ErrNotFound))
+}
+
+func TestUpdateApplicationVersion(t *testing.T) {
+	c := initApplicationClient()
+	app, err := c.GetApplication("default", "test_name", "")
+	assert.NoError(t, err)
+
+	res, err := c.UpdateApplicationVersion(app.Namespace, app, "100")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", res.Version)
+	res, err = c.GetApplication(app.Namespace, app.Name, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", res.Version)
+	list, err := c.ListApplication(app.Namespace, &models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "100", list.Items[0].Version)
+
+	// the outdated version conflicts
+	_, err = c.UpdateApplicationVersion(app.Namespace, app, "101")
+	assert.Error(t, err)
+	assert.True(t, kerrors.IsConflict(err))
+
+	// the resource version is used again after a plain update
+	res.Description = "plain"
+	res, err = c.UpdateApplication(res.Namespace, res)
+	assert.NoError(t, err)
+	obj, err := c.customClient.CloudV1alpha1().Applications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, obj.ResourceVersion, res.Version)
+	assert.NotContains(t, obj.Annotations, common.AnnotationVersion)
+}
+
+func TestApplicationMetadata(t *testing.T) {
+	c := initApplicationClient()
+	app := &specV1.Application{
+		Name:        "meta",
+		Namespace:   "default",
+		Labels:      map[string]string{"owner": "alice", "team": "edge"},
+		Description: "see https://example.com/ticket/1",
+	}
+	_, err := c.CreateApplication(app.Namespace, app)
+	assert.NoError(t, err)
+	res, err := c.GetApplication(app.Namespace, app.Name, "")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Labels, res.Labels)
+	assert.Equal(t, app.Description, res.Description)
+
+	// the annotation set by others is kept on update
+	obj, err := c.customClient.CloudV1alpha1().Applications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	obj.Annotations["example.com/ticket"] = "https://example.com/ticket/1"
+	_, err = c.customClient.CloudV1alpha1().Applications(app.Namespace).Update(obj)
+	assert.NoError(t, err)
+
+	app.Description = ""
+	res, err = c.UpdateApplication(app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, app.Labels, res.Labels)
+	assert.Empty(t, res.Description)
+	obj, err = c.customClient.CloudV1alpha1().Applications(app.Namespace).Get(app.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/ticket/1", obj.Annotations["example.com/ticket"])
+	assert.NotContains(t, obj.Annotations, common.AnnotationDescription)
 }
 
 func TestDeleteApplication(t *testing.T) {
 	c := initApplicationClient()
 	err := c.DeleteApplication("default", "test_name")
 	assert.NoError(t, err)
+	err = c.DeleteApplication("default", "test_name")
+	assert.True(t, errors.Is(err, plugin.ErrNotFound))
 }
 
 func TestListListApplication(t *testing.T) {
@@ -272,4 +356,28 @@ func TestListListApplication(t *testing.T) {
 	l, err := c.ListApplication("default", &models.ListOptions{})
 	assert.NoError(t, err)
 	assert.Equal(t, l.Total, 1)
+	assert.Equal(t, l.Items[0].CreationTimestamp, l.Items[0].UpdateTimestamp)
+
+	_, err = c.UpdateApplication("default", &specV1.Application{Name: "test_name", Namespace: "default"})
+	assert.NoError(t, err)
+	l, err = c.ListApplication("default", &models.ListOptions{})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), l.Items[0].UpdateTimestamp, time.Minute)
+}
+
+func TestToAppListModel(t *testing.T) {
+	list := &v1alpha1.ApplicationList{Items: []v1alpha1.Application{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	}}
+	res := toAppListModel(list)
+	assert.Equal(t, 2, res.Total)
+	assert.Len(t, res.Items, 2)
+
+	// a page of the limited list
+	remaining := int64(338)
+	list.RemainingItemCount = &remaining
+	res = toAppListModel(list)
+	assert.Equal(t, 340, res.Total)
+	assert.Len(t, res.Items, 2)
 }
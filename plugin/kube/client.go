@@ -1,10 +1,13 @@
 package kube
 
 import (
+	"context"
+
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/plugin"
 	clientset "github.com/baetyl/baetyl-cloud/plugin/kube/client/clientset/versioned"
 	"github.com/baetyl/baetyl-go/log"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -24,6 +27,19 @@ func (c *client) Close() error {
 	return nil
 }
 
+// Ping check whether the kube-apiserver is healthy
+func (c *client) Ping(ctx context.Context) error {
+	return c.coreV1.RESTClient().Get().AbsPath("/healthz").Context(ctx).Do().Error()
+}
+
+// toStorageError wrap the not found error of kube-apiserver as plugin.ErrNotFound
+func toStorageError(err error) error {
+	if kerrors.IsNotFound(err) {
+		return plugin.NotFound(err)
+	}
+	return err
+}
+
 func init() {
 	plugin.RegisterFactory("kubernetes", New)
 }
@@ -1,6 +1,7 @@
 package kube
 
 import (
+	"context"
 	"fmt"
 	"github.com/baetyl/baetyl-go/utils"
 	"time"
@@ -66,21 +67,27 @@ func fromConfigurationModel(config *specV1.Configuration) *v1alpha1.Configuratio
 }
 
 func (c *client) GetConfig(namespace, name, version string) (*specV1.Configuration, error) {
+	return c.GetConfigContext(context.Background(), namespace, name, version)
+}
+
+func (c *client) GetConfigContext(ctx context.Context, namespace, name, version string) (*specV1.Configuration, error) {
 	options := metav1.GetOptions{ResourceVersion: version}
 	defer utils.Trace(c.log.Debug, "GetConfig")()
-	config, err := c.customClient.CloudV1alpha1().Configurations(namespace).Get(name, options)
+	config, err := c.configurations(ctx, namespace).Get(name, options)
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return toConfigurationModel(config), nil
 }
 
 func (c *client) CreateConfig(namespace string, configModel *specV1.Configuration) (*specV1.Configuration, error) {
+	return c.CreateConfigContext(context.Background(), namespace, configModel)
+}
+
+func (c *client) CreateConfigContext(ctx context.Context, namespace string, configModel *specV1.Configuration) (*specV1.Configuration, error) {
 	configModel.UpdateTimestamp = time.Now()
 	defer utils.Trace(c.log.Debug, "CreateConfig")()
-	config, err := c.customClient.CloudV1alpha1().
-		Configurations(namespace).
-		Create(fromConfigurationModel(configModel))
+	config, err := c.configurations(ctx, namespace).Create(fromConfigurationModel(configModel))
 	if err != nil {
 		return nil, err
 	}
@@ -88,24 +95,30 @@ func (c *client) CreateConfig(namespace string, configModel *specV1.Configuratio
 }
 
 func (c *client) UpdateConfig(namespace string, configurationModel *specV1.Configuration) (*specV1.Configuration, error) {
+	return c.UpdateConfigContext(context.Background(), namespace, configurationModel)
+}
+
+func (c *client) UpdateConfigContext(ctx context.Context, namespace string, configurationModel *specV1.Configuration) (*specV1.Configuration, error) {
 	defer utils.Trace(c.log.Debug, "UpdateConfig")()
-	configuration, err := c.customClient.CloudV1alpha1().
-		Configurations(namespace).
-		Update(fromConfigurationModel(configurationModel))
+	configuration, err := c.configurations(ctx, namespace).Update(fromConfigurationModel(configurationModel))
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return toConfigurationModel(configuration), err
 }
 
 func (c *client) DeleteConfig(namespace, name string) error {
 	defer utils.Trace(c.log.Debug, "DeleteConfig")()
-	return c.customClient.CloudV1alpha1().Configurations(namespace).Delete(name, &metav1.DeleteOptions{})
+	return toStorageError(c.customClient.CloudV1alpha1().Configurations(namespace).Delete(name, &metav1.DeleteOptions{}))
 }
 
 func (c *client) ListConfig(namespace string, listOptions *models.ListOptions) (*models.ConfigurationList, error) {
+	return c.ListConfigContext(context.Background(), namespace, listOptions)
+}
+
+func (c *client) ListConfigContext(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ConfigurationList, error) {
 	defer utils.Trace(c.log.Debug, "ListConfig")()
-	list, err := c.customClient.CloudV1alpha1().Configurations(namespace).List(*fromListOptionsModel(listOptions))
+	list, err := c.configurations(ctx, namespace).List(*fromListOptionsModel(listOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,214 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/plugin/kube/apis/cloud/v1alpha1"
+	"github.com/baetyl/baetyl-cloud/plugin/kube/client/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// The typed clients can't take ctx, so the requests are built on the rest client as the generated code does
+// once ctx can be done. The typed clients are still used for the ctx never done, which keeps the fake clientset working.
+
+// applicationClient the calls of the typed application client used by the plugin
+type applicationClient interface {
+	Get(name string, options metav1.GetOptions) (*v1alpha1.Application, error)
+	List(opts metav1.ListOptions) (*v1alpha1.ApplicationList, error)
+	Create(application *v1alpha1.Application) (*v1alpha1.Application, error)
+	Update(application *v1alpha1.Application) (*v1alpha1.Application, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+}
+
+// configurationClient the calls of the typed configuration client used by the plugin
+type configurationClient interface {
+	Get(name string, options metav1.GetOptions) (*v1alpha1.Configuration, error)
+	List(opts metav1.ListOptions) (*v1alpha1.ConfigurationList, error)
+	Create(configuration *v1alpha1.Configuration) (*v1alpha1.Configuration, error)
+	Update(configuration *v1alpha1.Configuration) (*v1alpha1.Configuration, error)
+}
+
+// secretClient the calls of the typed secret client used by the plugin
+type secretClient interface {
+	Get(name string, options metav1.GetOptions) (*v1alpha1.Secret, error)
+	Create(secret *v1alpha1.Secret) (*v1alpha1.Secret, error)
+	Update(secret *v1alpha1.Secret) (*v1alpha1.Secret, error)
+}
+
+func (c *client) applications(ctx context.Context, namespace string) applicationClient {
+	if ctx.Done() == nil {
+		return c.customClient.CloudV1alpha1().Applications(namespace)
+	}
+	return &contextApplications{c.resource(ctx, namespace, "applications")}
+}
+
+func (c *client) configurations(ctx context.Context, namespace string) configurationClient {
+	if ctx.Done() == nil {
+		return c.customClient.CloudV1alpha1().Configurations(namespace)
+	}
+	return &contextConfigurations{c.resource(ctx, namespace, "configurations")}
+}
+
+func (c *client) secrets(ctx context.Context, namespace string) secretClient {
+	if ctx.Done() == nil {
+		return c.customClient.CloudV1alpha1().Secrets(namespace)
+	}
+	return &contextSecrets{c.resource(ctx, namespace, "secrets")}
+}
+
+func (c *client) resource(ctx context.Context, namespace, resource string) *contextResource {
+	return &contextResource{ctx: ctx, client: c.customClient.CloudV1alpha1().RESTClient(), ns: namespace, resource: resource}
+}
+
+// contextResource the requests of a resource in namespace, they are aborted once ctx is done and ctx.Err() is returned then
+type contextResource struct {
+	ctx      context.Context
+	client   rest.Interface
+	ns       string
+	resource string
+}
+
+func (r *contextResource) get(name string, options metav1.GetOptions, result runtime.Object) error {
+	err := r.client.Get().
+		Namespace(r.ns).
+		Resource(r.resource).
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Context(r.ctx).
+		Do().
+		Into(result)
+	return r.error(err)
+}
+
+func (r *contextResource) list(opts metav1.ListOptions, result runtime.Object) error {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	err := r.client.Get().
+		Namespace(r.ns).
+		Resource(r.resource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Context(r.ctx).
+		Do().
+		Into(result)
+	return r.error(err)
+}
+
+func (r *contextResource) create(obj, result runtime.Object) error {
+	err := r.client.Post().
+		Namespace(r.ns).
+		Resource(r.resource).
+		Body(obj).
+		Context(r.ctx).
+		Do().
+		Into(result)
+	return r.error(err)
+}
+
+func (r *contextResource) update(name string, obj, result runtime.Object) error {
+	err := r.client.Put().
+		Namespace(r.ns).
+		Resource(r.resource).
+		Name(name).
+		Body(obj).
+		Context(r.ctx).
+		Do().
+		Into(result)
+	return r.error(err)
+}
+
+func (r *contextResource) delete(name string, options *metav1.DeleteOptions) error {
+	err := r.client.Delete().
+		Namespace(r.ns).
+		Resource(r.resource).
+		Name(name).
+		Body(options).
+		Context(r.ctx).
+		Do().
+		Error()
+	return r.error(err)
+}
+
+// error the transport error of an aborted request is replaced by ctx.Err()
+func (r *contextResource) error(err error) error {
+	if err != nil && r.ctx.Err() != nil {
+		return r.ctx.Err()
+	}
+	return err
+}
+
+type contextApplications struct {
+	*contextResource
+}
+
+func (c *contextApplications) Get(name string, options metav1.GetOptions) (*v1alpha1.Application, error) {
+	result := &v1alpha1.Application{}
+	return result, c.get(name, options, result)
+}
+
+func (c *contextApplications) List(opts metav1.ListOptions) (*v1alpha1.ApplicationList, error) {
+	result := &v1alpha1.ApplicationList{}
+	return result, c.list(opts, result)
+}
+
+func (c *contextApplications) Create(application *v1alpha1.Application) (*v1alpha1.Application, error) {
+	result := &v1alpha1.Application{}
+	return result, c.create(application, result)
+}
+
+func (c *contextApplications) Update(application *v1alpha1.Application) (*v1alpha1.Application, error) {
+	result := &v1alpha1.Application{}
+	return result, c.update(application.Name, application, result)
+}
+
+func (c *contextApplications) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.delete(name, options)
+}
+
+type contextConfigurations struct {
+	*contextResource
+}
+
+func (c *contextConfigurations) Get(name string, options metav1.GetOptions) (*v1alpha1.Configuration, error) {
+	result := &v1alpha1.Configuration{}
+	return result, c.get(name, options, result)
+}
+
+func (c *contextConfigurations) List(opts metav1.ListOptions) (*v1alpha1.ConfigurationList, error) {
+	result := &v1alpha1.ConfigurationList{}
+	return result, c.list(opts, result)
+}
+
+func (c *contextConfigurations) Create(configuration *v1alpha1.Configuration) (*v1alpha1.Configuration, error) {
+	result := &v1alpha1.Configuration{}
+	return result, c.create(configuration, result)
+}
+
+func (c *contextConfigurations) Update(configuration *v1alpha1.Configuration) (*v1alpha1.Configuration, error) {
+	result := &v1alpha1.Configuration{}
+	return result, c.update(configuration.Name, configuration, result)
+}
+
+type contextSecrets struct {
+	*contextResource
+}
+
+func (c *contextSecrets) Get(name string, options metav1.GetOptions) (*v1alpha1.Secret, error) {
+	result := &v1alpha1.Secret{}
+	return result, c.get(name, options, result)
+}
+
+func (c *contextSecrets) Create(secret *v1alpha1.Secret) (*v1alpha1.Secret, error) {
+	result := &v1alpha1.Secret{}
+	return result, c.create(secret, result)
+}
+
+func (c *contextSecrets) Update(secret *v1alpha1.Secret) (*v1alpha1.Secret, error) {
+	result := &v1alpha1.Secret{}
+	return result, c.update(secret.Name, secret, result)
+}
@@ -0,0 +1,68 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	clientset "github.com/baetyl/baetyl-cloud/plugin/kube/client/clientset/versioned"
+	"github.com/baetyl/baetyl-go/log"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestApplicationContext(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", ResourceVersion: "12"},
+		})
+	}))
+	defer server.Close()
+
+	cs, err := clientset.NewForConfig(&rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+	var c plugin.ModelStorageContext = &client{customClient: cs, log: log.With(log.Any("plugin", "kube"))}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app, err := c.GetApplicationContext(ctx, "default", "app", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "app", app.Name)
+	assert.Equal(t, "12", app.Version)
+	_, err = c.GetConfigContext(ctx, "default", "conf", "")
+	assert.NoError(t, err)
+	_, err = c.UpdateConfigContext(ctx, "default", &specV1.Configuration{Name: "conf"})
+	assert.NoError(t, err)
+	_, err = c.GetSecretContext(ctx, "default", "secret", "")
+	assert.NoError(t, err)
+	_, err = c.CreateSecretContext(ctx, "default", &specV1.Secret{Name: "secret"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.DeleteApplicationContext(ctx, "default", "app"))
+	assert.Equal(t, []string{
+		"GET /apis/cloud.baetyl.io/v1alpha1/namespaces/default/applications/app",
+		"GET /apis/cloud.baetyl.io/v1alpha1/namespaces/default/configurations/conf",
+		"PUT /apis/cloud.baetyl.io/v1alpha1/namespaces/default/configurations/conf",
+		"GET /apis/cloud.baetyl.io/v1alpha1/namespaces/default/secrets/secret",
+		"POST /apis/cloud.baetyl.io/v1alpha1/namespaces/default/secrets",
+		"DELETE /apis/cloud.baetyl.io/v1alpha1/namespaces/default/applications/app",
+	}, paths)
+
+	// the request is aborted once ctx is done
+	cancel()
+	_, err = c.ListApplicationContext(ctx, "default", &models.ListOptions{})
+	assert.Equal(t, context.Canceled, err)
+	_, err = c.GetApplicationContext(ctx, "default", "app", "")
+	assert.Equal(t, context.Canceled, err)
+	_, err = c.ListConfigContext(ctx, "default", &models.ListOptions{})
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, paths, 6)
+}
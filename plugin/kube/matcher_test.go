@@ -15,6 +15,14 @@ func TestLabelMatcher_Match(t *testing.T) {
 	res, _ := matcher.IsLabelMatch(sl, labels)
 	assert.Equal(t, true, res)
 
+	sl = "a notin (b),c=d"
+	res, _ = matcher.IsLabelMatch(sl, labels)
+	assert.Equal(t, false, res)
+
+	sl = "a!=c,e notin (f)"
+	res, _ = matcher.IsLabelMatch(sl, labels)
+	assert.Equal(t, true, res)
+
 	sl = "a=bc=d"
 	_, err := matcher.IsLabelMatch(sl, labels)
 	assert.Equal(t, true, err != nil)
@@ -31,7 +31,7 @@ func (c *client) GetNamespace(namespace string) (*models.Namespace, error) {
 	defer utils.Trace(c.log.Debug, "GetNamespace")()
 	n, err := c.coreV1.Namespaces().Get(namespace, metav1.GetOptions{})
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return toNamespaceModel(n), nil
 }
@@ -49,10 +49,10 @@ func (c *client) DeleteNamespace(namespace *models.Namespace) error {
 	defer utils.Trace(c.log.Debug, "DeleteNamespace")()
 	err := c.coreV1.Namespaces().Delete(namespace.Name, &metav1.DeleteOptions{})
 	if err != nil {
-		return err
+		return toStorageError(err)
 	}
 	if n, _ := c.coreV1.Namespaces().Get(namespace.Name, metav1.GetOptions{}); n != nil {
 		_, err = c.coreV1.Namespaces().Finalize(fromNamespaceModel(namespace))
 	}
-	return err
+	return toStorageError(err)
 }
@@ -92,7 +92,7 @@ func (c *client) GetNode(namespace, name string) (*specV1.Node, error) {
 	defer utils.Trace(c.log.Debug, "GetNode")()
 	node, err := c.customClient.CloudV1alpha1().Nodes(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 
 	return toNodeModel(node), nil
@@ -116,20 +116,20 @@ func (c *client) CreateNode(namespace string, node *specV1.Node) (*specV1.Node,
 func (c *client) UpdateNode(namespace string, node *specV1.Node) (*specV1.Node, error) {
 	n, err := fromNodeModel(node)
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	defer utils.Trace(c.log.Debug, "UpdateNode")()
 	n, err = c.customClient.CloudV1alpha1().Nodes(namespace).Update(n)
 	if err != nil {
 		log.L().Error("update node error", log.Error(err))
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return toNodeModel(n), nil
 }
 
 func (c *client) DeleteNode(namespace, name string) error {
 	defer utils.Trace(c.log.Debug, "DeleteNode")()
-	return c.customClient.CloudV1alpha1().Nodes(namespace).Delete(name, &metav1.DeleteOptions{})
+	return toStorageError(c.customClient.CloudV1alpha1().Nodes(namespace).Delete(name, &metav1.DeleteOptions{}))
 }
 
 func (c *client) ListNode(namespace string, listOptions *models.ListOptions) (*models.NodeList, error) {
@@ -1,6 +1,7 @@
 package kube
 
 import (
+	"context"
 	"fmt"
 	"github.com/baetyl/baetyl-go/utils"
 	"time"
@@ -77,16 +78,24 @@ func (c *client) fromSecretModel(secret *specV1.Secret) (*v1alpha1.Secret, error
 }
 
 func (c *client) GetSecret(namespace, name, version string) (*specV1.Secret, error) {
+	return c.GetSecretContext(context.Background(), namespace, name, version)
+}
+
+func (c *client) GetSecretContext(ctx context.Context, namespace, name, version string) (*specV1.Secret, error) {
 	options := metav1.GetOptions{ResourceVersion: version}
 	defer utils.Trace(c.log.Debug, "GetSecret")()
-	Secret, err := c.customClient.CloudV1alpha1().Secrets(namespace).Get(name, options)
+	Secret, err := c.secrets(ctx, namespace).Get(name, options)
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return c.toSecretModel(Secret), nil
 }
 
 func (c *client) CreateSecret(namespace string, secretModel *specV1.Secret) (*specV1.Secret, error) {
+	return c.CreateSecretContext(context.Background(), namespace, secretModel)
+}
+
+func (c *client) CreateSecretContext(ctx context.Context, namespace string, secretModel *specV1.Secret) (*specV1.Secret, error) {
 	secretModel.UpdateTimestamp = time.Now()
 
 	model, err := c.fromSecretModel(secretModel)
@@ -95,9 +104,7 @@ func (c *client) CreateSecret(namespace string, secretModel *specV1.Secret) (*sp
 	}
 
 	defer utils.Trace(c.log.Debug, "CreateSecret")()
-	Secret, err := c.customClient.CloudV1alpha1().
-		Secrets(namespace).
-		Create(model)
+	Secret, err := c.secrets(ctx, namespace).Create(model)
 	if err != nil {
 		return nil, err
 	}
@@ -105,16 +112,18 @@ func (c *client) CreateSecret(namespace string, secretModel *specV1.Secret) (*sp
 }
 
 func (c *client) UpdateSecret(namespace string, secretMapModel *specV1.Secret) (*specV1.Secret, error) {
+	return c.UpdateSecretContext(context.Background(), namespace, secretMapModel)
+}
+
+func (c *client) UpdateSecretContext(ctx context.Context, namespace string, secretMapModel *specV1.Secret) (*specV1.Secret, error) {
 	model, err := c.fromSecretModel(secretMapModel)
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	defer utils.Trace(c.log.Debug, "UpdateSecret")()
-	SecretMap, err := c.customClient.CloudV1alpha1().
-		Secrets(namespace).
-		Update(model)
+	SecretMap, err := c.secrets(ctx, namespace).Update(model)
 	if err != nil {
-		return nil, err
+		return nil, toStorageError(err)
 	}
 	return c.toSecretModel(SecretMap), err
 }
@@ -122,7 +131,7 @@ func (c *client) UpdateSecret(namespace string, secretMapModel *specV1.Secret) (
 func (c *client) DeleteSecret(namespace, name string) error {
 	defer utils.Trace(c.log.Debug, "DeleteSecret")()
 	err := c.customClient.CloudV1alpha1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
-	return err
+	return toStorageError(err)
 }
 
 func (c *client) ListSecret(namespace string, listOptions *models.ListOptions) (*models.SecretList, error) {
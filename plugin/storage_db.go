@@ -1,13 +1,35 @@
 package plugin
 
 import (
+	"context"
 	"database/sql"
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/models"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
+//go:generate mockgen -destination=../mock/plugin/storage_db_context.go -package=plugin github.com/baetyl/baetyl-cloud/plugin DBStorageContext
+
+// DBStorageContext is optionally implemented by DBStorage to take ctx in the calls of application history,
+// the query is aborted once ctx is done. The results and errors are the same as the ones of DBStorage
+type DBStorageContext interface {
+	GetApplicationContext(ctx context.Context, name, namespace, version string) (*specV1.Application, error)
+	ListApplicationHistoryContext(ctx context.Context, name, namespace string, pageNo, pageSize int) ([]models.ApplicationHistory, error)
+	CreateApplicationHistoryContext(ctx context.Context, app *specV1.Application, operator string) (sql.Result, error)
+	DeleteApplicationHistoryContext(ctx context.Context, name, namespace, version, operator string) (sql.Result, error)
+	CreateApplicationContext(ctx context.Context, app *specV1.Application) (sql.Result, error)
+	SoftDeleteApplicationContext(ctx context.Context, name, namespace, version string) (sql.Result, error)
+	RestoreApplicationContext(ctx context.Context, name, namespace, version string) (sql.Result, error)
+	PruneApplicationContext(ctx context.Context, name, namespace, activeVersion string, keep int) (sql.Result, error)
+	GetSoftDeletedApplicationContext(ctx context.Context, name, namespace string, since time.Time) (*specV1.Application, error)
+	ListApplicationByTimeContext(ctx context.Context, name, namespace string, start, end time.Time) ([]specV1.Application, error)
+	CountApplicationContext(ctx context.Context, tx *sqlx.Tx, name, namespace string) (int, error)
+	CreateApplicationRequestContext(ctx context.Context, req *models.ApplicationRequest) (sql.Result, error)
+	GetApplicationRequestContext(ctx context.Context, namespace, requestID string) (*models.ApplicationRequest, error)
+}
+
 //go:generate mockgen -destination=../mock/plugin/storage_db.go -package=plugin github.com/baetyl/baetyl-cloud/plugin DBStorage
 
 // DBStorage DBStorage
@@ -22,6 +44,9 @@ type DBStorage interface {
 	ListIndexTx(tx *sqlx.Tx, namespace string, keyA, byKeyB common.Resource, valueB string) ([]string, error)
 	DeleteIndexTx(tx *sqlx.Tx, namespace string, keyA, byKeyB common.Resource, valueB string) (sql.Result, error)
 	RefreshIndex(namespace string, keyA, keyB common.Resource, valueA string, valueBs []string) error
+	// RefreshIndexes refresh the index of many valueAs in one transaction
+	RefreshIndexes(namespace string, keyA, keyB common.Resource, values map[string][]string) error
+	ListIndexKeys(namespace string, keyA, keyB common.Resource) ([]string, error)
 
 	// batch
 	GetBatch(name, ns string) (*models.Batch, error)
@@ -79,14 +104,30 @@ type DBStorage interface {
 
 	// application
 	CreateApplication(app *specV1.Application) (sql.Result, error)
+	CreateApplicationHistory(app *specV1.Application, operator string) (sql.Result, error)
 	UpdateApplication(app *specV1.Application, oldVersion string) (sql.Result, error)
 	DeleteApplication(name, namespace, version string) (sql.Result, error)
+	DeleteApplicationHistory(name, namespace, version, operator string) (sql.Result, error)
+	SoftDeleteApplication(name, namespace, version string) (sql.Result, error)
+	RestoreApplication(name, namespace, version string) (sql.Result, error)
 	GetApplication(name, namespace, version string) (*specV1.Application, error)
+	GetSoftDeletedApplication(name, namespace string, since time.Time) (*specV1.Application, error)
 	ListApplication(name, namespace string, pageNo, pageSize int) ([]specV1.Application, error)
+	ListApplicationHistory(name, namespace string, pageNo, pageSize int) ([]models.ApplicationHistory, error)
+	ListApplicationByTime(name, namespace string, start, end time.Time) ([]specV1.Application, error)
+	ListHistoryApplications() ([]models.AppItem, error)
+	PruneApplication(name, namespace, activeVersion string, keep int) (sql.Result, error)
+	PruneApplicationWithTx(tx *sqlx.Tx, name, namespace, activeVersion string, keep int) (sql.Result, error)
 	CreateApplicationWithTx(tx *sqlx.Tx, app *specV1.Application) (sql.Result, error)
+	CreateApplicationHistoryWithTx(tx *sqlx.Tx, app *specV1.Application, operator string) (sql.Result, error)
 	UpdateApplicationWithTx(tx *sqlx.Tx, app *specV1.Application, oldVersion string) (sql.Result, error)
 	DeleteApplicationWithTx(tx *sqlx.Tx, name, namespace, version string) (sql.Result, error)
+	DeleteApplicationHistoryWithTx(tx *sqlx.Tx, name, namespace, version, operator string) (sql.Result, error)
+	SoftDeleteApplicationWithTx(tx *sqlx.Tx, name, namespace, version string) (sql.Result, error)
+	RestoreApplicationWithTx(tx *sqlx.Tx, name, namespace, version string) (sql.Result, error)
 	CountApplication(tx *sqlx.Tx, name, namespace string) (int, error)
+	CreateApplicationRequest(req *models.ApplicationRequest) (sql.Result, error)
+	GetApplicationRequest(namespace, requestID string) (*models.ApplicationRequest, error)
 	// system config
 	GetSysConfig(tp, key string) (*models.SysConfig, error)
 	ListSysConfig(tp string, page, size int) ([]models.SysConfig, error)
@@ -95,5 +136,8 @@ type DBStorage interface {
 	UpdateSysConfig(sysConfig *models.SysConfig) (sql.Result, error)
 	DeleteSysConfig(tp, key string) (sql.Result, error)
 
+	// Ping check whether the database is reachable
+	Ping(ctx context.Context) error
+
 	Shadow
 }
@@ -1,13 +1,80 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+
 	"github.com/baetyl/baetyl-cloud/models"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 )
 
 //go:generate mockgen -destination=../mock/plugin/storage_model.go -package=plugin github.com/baetyl/baetyl-cloud/plugin ModelStorage
 
-// ModelStorage ModelStorage
+// ErrNotFound is matched by errors.Is with the errors which ModelStorage returns for missing resources
+var ErrNotFound = errors.New("not found")
+
+// NotFound wrap the error of a backend for a missing resource as ErrNotFound, the message is kept
+func NotFound(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &notFoundError{err: err}
+}
+
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+//go:generate mockgen -destination=../mock/plugin/storage_model_batch.go -package=plugin github.com/baetyl/baetyl-cloud/plugin ApplicationBatchGetter
+
+// ApplicationBatchGetter is optionally implemented by ModelStorage to get the latest versions of many apps in a single query,
+// the apps not found are left out of the result
+type ApplicationBatchGetter interface {
+	GetApplications(namespace string, names []string) ([]specV1.Application, error)
+}
+
+//go:generate mockgen -destination=../mock/plugin/storage_model_version.go -package=plugin github.com/baetyl/baetyl-cloud/plugin ApplicationVersioner
+
+// ApplicationVersioner is optionally implemented by ModelStorage to take the versions of apps from callers.
+// UpdateApplicationVersion updates the app whose version is still app.Version and records version as the new one,
+// the following UpdateApplication without version makes the storage assign versions again
+type ApplicationVersioner interface {
+	UpdateApplicationVersion(namespace string, app *specV1.Application, version string) (*specV1.Application, error)
+}
+
+//go:generate mockgen -destination=../mock/plugin/storage_model_context.go -package=plugin github.com/baetyl/baetyl-cloud/plugin ModelStorageContext
+
+// ModelStorageContext is optionally implemented by ModelStorage to take ctx in the calls of applications, configs and secrets,
+// the request is aborted once ctx is done. The results and errors are the same as the ones of ModelStorage
+type ModelStorageContext interface {
+	GetApplicationContext(ctx context.Context, namespace, name, version string) (*specV1.Application, error)
+	CreateApplicationContext(ctx context.Context, namespace string, application *specV1.Application) (*specV1.Application, error)
+	UpdateApplicationContext(ctx context.Context, namespace string, application *specV1.Application) (*specV1.Application, error)
+	DeleteApplicationContext(ctx context.Context, namespace, name string) error
+	ListApplicationContext(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error)
+	GetConfigContext(ctx context.Context, namespace, name, version string) (*specV1.Configuration, error)
+	CreateConfigContext(ctx context.Context, namespace string, config *specV1.Configuration) (*specV1.Configuration, error)
+	UpdateConfigContext(ctx context.Context, namespace string, config *specV1.Configuration) (*specV1.Configuration, error)
+	ListConfigContext(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ConfigurationList, error)
+	GetSecretContext(ctx context.Context, namespace, name, version string) (*specV1.Secret, error)
+	CreateSecretContext(ctx context.Context, namespace string, secret *specV1.Secret) (*specV1.Secret, error)
+	UpdateSecretContext(ctx context.Context, namespace string, secret *specV1.Secret) (*specV1.Secret, error)
+}
+
+// ModelStorage ModelStorage, the Get, Update and Delete methods return an error matching ErrNotFound
+// if the resource doesn't exist
 type ModelStorage interface {
 	GetNamespace(namespace string) (*models.Namespace, error)
 	CreateNamespace(namespace *models.Namespace) (*models.Namespace, error)
@@ -39,5 +106,8 @@ type ModelStorage interface {
 
 	IsLabelMatch(labelSelector string, labels map[string]string) (bool, error)
 
+	// Ping check whether the storage is reachable
+	Ping(ctx context.Context) error
+
 	Shadow
 }
@@ -10,12 +10,40 @@ CREATE TABLE IF NOT EXISTS `baetyl_application_history` (
   `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   `content` mediumtext COMMENT 'app详情',
+  `operator` varchar(128) NOT NULL DEFAULT '' COMMENT '操作人',
   PRIMARY KEY (`id`),
   KEY `idx_app_history` (`namespace`,`name`,`version`),
   KEY `idx_app_date` (`namespace`,`create_time`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='application历史信息表';
 
 
+CREATE TABLE IF NOT EXISTS `baetyl_application_request` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID,主键',
+  `namespace` varchar(64) NOT NULL DEFAULT '' COMMENT '命名空间',
+  `request_id` varchar(128) NOT NULL DEFAULT '' COMMENT '客户端请求ID',
+  `name` varchar(128) NOT NULL DEFAULT '' COMMENT 'app名称',
+  `version` varchar(36) NOT NULL DEFAULT '' COMMENT 'app版本',
+  `digest` varchar(64) NOT NULL DEFAULT '' COMMENT 'app请求内容摘要',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `unique_request` (`namespace`,`request_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='application创建请求表';
+
+CREATE TABLE IF NOT EXISTS `baetyl_function` (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID,主键',
+  `user_id` varchar(64) NOT NULL DEFAULT '' COMMENT '用户ID',
+  `name` varchar(128) NOT NULL DEFAULT '' COMMENT '函数名称',
+  `version` varchar(36) NOT NULL DEFAULT '' COMMENT '函数版本',
+  `handler` varchar(128) NOT NULL DEFAULT '' COMMENT '函数入口',
+  `runtime` varchar(64) NOT NULL DEFAULT '' COMMENT '函数运行时',
+  `code` text COMMENT '函数代码信息',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `unique_function` (`user_id`,`name`,`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='函数表';
+
 CREATE TABLE IF NOT EXISTS `baetyl_batch` (
   `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'ID,主键',
   `name` varchar(128) NOT NULL DEFAULT '' COMMENT '批号',
@@ -0,0 +1,4 @@
+USE `baetyl_cloud`;
+
+-- the operator of application history, run once on the databases created before it was added to tables.sql
+ALTER TABLE `baetyl_application_history` ADD COLUMN `operator` varchar(128) NOT NULL DEFAULT '' COMMENT '操作人' AFTER `content`;
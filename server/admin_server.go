@@ -19,6 +19,7 @@ type AdminServer struct {
 	api     *api.API
 	auth    service.AuthService
 	license service.LicenseService
+	health  service.HealthService
 }
 
 // NewAdminServer create admin server
@@ -37,6 +38,11 @@ func NewAdminServer(config *config.CloudConfig) (*AdminServer, error) {
 		return nil, err
 	}
 
+	hs, err := service.NewHealthService(config)
+	if err != nil {
+		return nil, err
+	}
+
 	router := gin.New()
 	server := &http.Server{
 		Addr:           config.AdminServer.Port,
@@ -52,6 +58,7 @@ func NewAdminServer(config *config.CloudConfig) (*AdminServer, error) {
 		auth:    auth,
 		api:     api,
 		license: ls,
+		health:  hs,
 	}, nil
 }
 
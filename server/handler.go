@@ -6,6 +6,7 @@ import (
 	"github.com/baetyl/baetyl-go/log"
 	"github.com/gin-gonic/gin"
 	"io/ioutil"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -59,6 +60,16 @@ func health(c *gin.Context) {
 	c.JSON(common.PackageResponse(nil))
 }
 
+// healthz report the health of backing plugins, it is unavailable only if a critical plugin is unhealthy
+func (s *AdminServer) healthz(c *gin.Context) {
+	status := s.health.HealthCheck(c.Request.Context())
+	if !status.Healthy {
+		c.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
 func extractNodeCommonNameFromCert(c *gin.Context) {
 	cc := common.NewContext(c)
 	if len(c.Request.TLS.PeerCertificates) == 0 {
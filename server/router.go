@@ -4,6 +4,7 @@ import (
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-go/log"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // InitRoute init router
@@ -11,6 +12,8 @@ func (s *AdminServer) InitRoute() {
 	s.router.NoRoute(noRouteHandler)
 	s.router.NoMethod(noMethodHandler)
 	s.router.GET("/health", health)
+	s.router.GET("/healthz", s.healthz)
+	s.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	s.router.Use(requestIDHandler)
 	s.router.Use(loggerHandler)
@@ -1,32 +1,107 @@
 package service
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/config"
 	"github.com/baetyl/baetyl-cloud/models"
 	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-go/errors"
 	"github.com/baetyl/baetyl-go/log"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 //go:generate mockgen -destination=../mock/service/application.go -package=plugin github.com/baetyl/baetyl-cloud/service ApplicationService
 
 // ApplicationService ApplicationService
 type ApplicationService interface {
-	Get(namespace, name, version string) (*specV1.Application, error)
-	Create(namespace string, app *specV1.Application) (*specV1.Application, error)
-	Update(namespace string, app *specV1.Application) (*specV1.Application, error)
-	Delete(namespace, name, version string) error
-	List(namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error)
-	CreateWithBase(namespace string, app, base *specV1.Application) (*specV1.Application, error)
+	Get(ctx context.Context, namespace, name, version string) (*specV1.Application, error)
+	// Exists report whether the app exists, a missing app is not an error
+	Exists(ctx context.Context, namespace, name string) (bool, error)
+	// GetBatch get the apps of refs keyed by AppRef.Key, the ones not found are left out instead of failing the call
+	GetBatch(ctx context.Context, namespace string, refs []models.AppRef) (map[string]*specV1.Application, error)
+	// ListApplicationsByNodeSelector list the apps targeting the node group of selector, whose node selectors are equivalent to it
+	ListApplicationsByNodeSelector(ctx context.Context, namespace, selector string) (*models.ApplicationList, error)
+	// GetAtLeastVersion poll the app until its version reaches minVersion, so that the writes are read back from a lagging storage.
+	// It returns ErrRequestTimeout if the version isn't reached in timeout
+	GetAtLeastVersion(ctx context.Context, namespace, name, minVersion string, timeout time.Duration) (*specV1.Application, error)
+	Create(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error)
+	CreateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*specV1.Application, error)
+	CreateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*models.ApplicationCreateResult, error)
+	Update(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error)
+	UpdateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*specV1.Application, error)
+	UpdateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*models.ApplicationUpdateResult, error)
+	Patch(ctx context.Context, namespace, name string, patch *models.ApplicationPatch) (*specV1.Application, error)
+	Delete(ctx context.Context, namespace, name, version string) error
+	DeleteWithOptions(ctx context.Context, namespace, name, version string, opts *models.DeleteOptions) error
+	List(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error)
+	// ListStream call fn with each application matched by the filters of List until fn returns an error, pagination and order are ignored
+	ListStream(ctx context.Context, namespace string, listOptions *models.ListOptions, fn func(*specV1.Application) error) error
+	Count(ctx context.Context, namespace string, listOptions *models.ListOptions) (int, error)
+	CreateWithBase(ctx context.Context, namespace string, app, base *specV1.Application) (*specV1.Application, error)
+	CreateWithBases(ctx context.Context, namespace string, app *specV1.Application, bases []*specV1.Application, opts *models.CreateOptions) (*specV1.Application, error)
+	// CreateFromTemplate create the app of template whose ${VAR} placeholders in string fields are replaced by params,
+	// it returns ErrMissingTemplateParam if any placeholder isn't in params
+	CreateFromTemplate(ctx context.Context, namespace string, template *specV1.Application, params map[string]string) (*specV1.Application, error)
+	RenderWithBase(ctx context.Context, namespace string, app, base *specV1.Application) (*models.ApplicationRenderResult, error)
+	Export(ctx context.Context, namespace, name, version string) (*models.ApplicationBundle, error)
+	ExportWithOptions(ctx context.Context, namespace, name, version string, opts *models.ExportOptions) (*models.ApplicationBundle, error)
+	Import(ctx context.Context, namespace string, bundle *models.ApplicationBundle, opts *models.ImportOptions) (*specV1.Application, error)
+	// Describe get the app with the configs and secrets it references, the values of secrets are blanked
+	Describe(ctx context.Context, namespace, name, version string) (*models.ApplicationDescription, error)
+	DescribeWithOptions(ctx context.Context, namespace, name, version string, opts *models.DescribeOptions) (*models.ApplicationDescription, error)
+	// ResolveBases follow the base chain of the app, the bases are returned root first and end with the app itself
+	ResolveBases(ctx context.Context, namespace, name string) ([]*specV1.Application, error)
+	Rollback(ctx context.Context, namespace, name, targetVersion string) (*specV1.Application, error)
+	// SetCanary split the app between the stable and canary versions, weight is the percentage of canary.
+	// An empty canaryVersion clears the canary, the stable version takes all again
+	SetCanary(ctx context.Context, namespace, name, stableVersion, canaryVersion string, weight int) error
+	GetCanary(ctx context.Context, namespace, name string) (*models.ApplicationCanary, error)
+	// GetDeploymentStatus count the target nodes of the app by whether they run its current version, see models.DeploymentStatus
+	GetDeploymentStatus(ctx context.Context, namespace, name string) (*models.DeploymentStatus, error)
+	ListHistory(ctx context.Context, namespace, name string, listOptions *models.ListOptions) (*models.ApplicationList, error)
+	ListHistoryByTime(ctx context.Context, namespace, name string, start, end time.Time) (*models.ApplicationList, error)
+	PruneHistory(ctx context.Context, namespace, name string, keep int) (pruned int, err error)
+	Diff(ctx context.Context, namespace, name, fromVersion, toVersion string) (*models.ApplicationDiff, error)
+	Validate(ctx context.Context, namespace string, app *specV1.Application) error
+	// ResolveReferences the configs and secrets the app would be indexed by with their resolved versions,
+	// in the order of volumes, nothing is persisted and app is not changed
+	ResolveReferences(ctx context.Context, namespace string, app *specV1.Application) (configs []models.ResolvedReference, secrets []models.ResolvedReference, err error)
+	SoftDelete(ctx context.Context, namespace, name, version string) error
+	Restore(ctx context.Context, namespace, name string) (*specV1.Application, error)
+	CreateBatch(ctx context.Context, namespace string, apps []*specV1.Application) ([]*specV1.Application, error)
+	DeleteBatch(ctx context.Context, namespace string, names []string) (deleted []string, failed map[string]error, err error)
+	DeleteByLabel(ctx context.Context, namespace, labelSelector string, all bool) (deleted []string, err error)
+	Clone(ctx context.Context, srcNamespace, name, version, dstNamespace, newName string) (*specV1.Application, error)
 }
 
 type applicationService struct {
-	storage      plugin.ModelStorage
-	dbStorage    plugin.DBStorage
-	indexService IndexService
+	storage         plugin.ModelStorage
+	dbStorage       plugin.DBStorage
+	indexService    IndexService
+	functionService FunctionService
+	sources         []string
+	events          plugin.EventSink
+	shadow          plugin.Shadow
+	versions        VersionStrategy
+	hooks           []namedHook
+	conf            config.AppConfig
 }
 
 // NewApplicationService NewApplicationService
@@ -35,234 +110,3425 @@ func NewApplicationService(config *config.CloudConfig) (ApplicationService, erro
 	if err != nil {
 		return nil, err
 	}
-	db, err := plugin.GetPlugin(config.Plugin.DatabaseStorage)
+	// the database storage keeps history and the features built on it
+	var dbStorage plugin.DBStorage
+	if !config.Application.History.Disabled {
+		db, err := plugin.GetPlugin(config.Plugin.DatabaseStorage)
+		if err != nil {
+			return nil, err
+		}
+		dbStorage = db.(plugin.DBStorage)
+	}
+	is, err := NewIndexService(config)
 	if err != nil {
 		return nil, err
 	}
-	is, err := NewIndexService(config)
+	fs, err := NewFunctionService(config)
 	if err != nil {
 		return nil, err
 	}
-	return &applicationService{
-		storage:      ms.(plugin.ModelStorage),
-		indexService: is,
-		dbStorage:    db.(plugin.DBStorage),
-	}, nil
+	storage := ms.(plugin.ModelStorage)
+	shadow, err := plugin.GetPlugin(config.Plugin.Shadow)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := NewVersionStrategy(config.Application.VersionStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := versions.(storageVersion); !ok {
+		if _, ok = storage.(plugin.ApplicationVersioner); !ok {
+			return nil, common.Error(common.ErrNotSupported, common.Field("name", "version strategy "+config.Application.VersionStrategy),
+				common.Field("error", "the model storage doesn't take versions"))
+		}
+	}
+	if config.Plugin.ModelStorageReplica != "" {
+		rs, err := plugin.GetPlugin(config.Plugin.ModelStorageReplica)
+		if err != nil {
+			return nil, err
+		}
+		storage = withReplica(storage, rs.(plugin.ModelStorage), config.Plugin.ModelStorageReplicaLag)
+	}
+	var events plugin.EventSink
+	if config.Plugin.EventSink != "" {
+		es, err := plugin.GetPlugin(config.Plugin.EventSink)
+		if err != nil {
+			return nil, err
+		}
+		events = es.(plugin.EventSink)
+	}
+	return withMetrics(withTracing(withRateLimit(withCache(&applicationService{
+		storage:         storage,
+		indexService:    is,
+		dbStorage:       dbStorage,
+		functionService: fs,
+		sources:         config.Plugin.Functions,
+		events:          events,
+		shadow:          shadow.(plugin.Shadow),
+		versions:        versions,
+		hooks:           registeredPostCreateHooks(),
+		conf:            config.Application,
+	}, config.Application.Cache), config.Application.RateLimit), nil)), nil
 }
 
-// Get get application
-func (a *applicationService) Get(namespace, name, version string) (*specV1.Application, error) {
-	app, err := a.storage.GetApplication(namespace, name, version)
-	if err != nil && strings.Contains(err.Error(), "not found") {
-		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "app"),
-			common.Field("name", name))
+// modelStorage the storage of models bound to ctx, see withStorageContext
+func (a *applicationService) modelStorage(ctx context.Context) plugin.ModelStorage {
+	return withStorageContext(ctx, a.storage)
+}
+
+// historyStorage the storage of app history bound to ctx, it is nil if the history is disabled
+func (a *applicationService) historyStorage(ctx context.Context) plugin.DBStorage {
+	return withDBContext(ctx, a.dbStorage)
+}
+
+// Get get application, it returns the error of ctx once ctx is done. The version is canonicalized first, see canonicalVersion
+func (a *applicationService) Get(ctx context.Context, namespace, name, version string) (*specV1.Application, error) {
+	version = canonicalVersion(version)
+	app, err := a.modelStorage(ctx).GetApplication(namespace, name, version)
+	if err != nil {
+		if goerrors.Is(err, plugin.ErrNotFound) {
+			return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "app"),
+				common.Field("name", name))
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+// GetAtLeastVersion get the latest version of app until it is not older than minVersion, see versionAtLeast.
+// The app not found yet is taken as stale, other errors are returned at once
+func (a *applicationService) GetAtLeastVersion(ctx context.Context, namespace, name, minVersion string, timeout time.Duration) (*specV1.Application, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		app, err := a.modelStorage(ctx).GetApplication(namespace, name, "")
+		if err != nil && !goerrors.Is(err, plugin.ErrNotFound) {
+			return nil, err
+		}
+		if app != nil && versionAtLeast(app.Version, minVersion) {
+			return app, nil
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			current := ""
+			if app != nil {
+				current = app.Version
+			}
+			return nil, common.Error(common.ErrRequestTimeout, common.Field("timeout", timeout),
+				common.Field("error", fmt.Sprintf("the version %s of app %s is not reached, the current is %s", minVersion, name, current)))
+		}
+		if wait > versionPollInterval {
+			wait = versionPollInterval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// versionPollInterval the interval of GetAtLeastVersion polling the storage
+const versionPollInterval = 50 * time.Millisecond
+
+// versionAtLeast compare the decimal versions by number, the versions of other forms are only equal to themselves
+func versionAtLeast(version, minVersion string) bool {
+	if minVersion == "" {
+		return true
+	}
+	v, min := canonicalVersion(version), canonicalVersion(minVersion)
+	if !isDecimal(v) || !isDecimal(min) {
+		return version == minVersion
+	}
+	return common.CompareVersion(v, min) >= 0
+}
+
+// sameVersion report whether the versions are equal in canonical form, see canonicalVersion
+func sameVersion(a, b string) bool {
+	return common.CompareVersion(canonicalVersion(a), canonicalVersion(b)) == 0
+}
+
+// GetBatch get the latest versions of the apps referenced at once, then the versions of refs from history
+func (a *applicationService) GetBatch(ctx context.Context, namespace string, refs []models.AppRef) (map[string]*specV1.Application, error) {
+	res := map[string]*specV1.Application{}
+	if len(refs) == 0 {
+		return res, nil
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		if !seen[ref.Name] {
+			seen[ref.Name] = true
+			names = append(names, ref.Name)
+		}
+	}
+	apps, err := getApplications(ctx, a.storage, namespace, names)
+	if err != nil {
+		return nil, err
+	}
+	currents := make(map[string]*specV1.Application, len(apps))
+	for i := range apps {
+		currents[apps[i].Name] = &apps[i]
+	}
+	for _, ref := range refs {
+		current, ok := currents[ref.Name]
+		if !ok {
+			continue
+		}
+		app, err := a.getVersion(ctx, current, ref.Version)
+		if err != nil {
+			if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
+				continue
+			}
+			return nil, err
+		}
+		res[ref.Key()] = app
+	}
+	return res, nil
+}
+
+// getApplications get the latest versions of the apps of names in a single query if storage supports,
+// the ones not found are left out
+func getApplications(ctx context.Context, storage plugin.ModelStorage, namespace string, names []string) ([]specV1.Application, error) {
+	if bg, ok := storage.(plugin.ApplicationBatchGetter); ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return bg.GetApplications(namespace, names)
+	}
+	return getEachApplication(withStorageContext(ctx, storage), namespace, names)
+}
+
+func getEachApplication(storage plugin.ModelStorage, namespace string, names []string) ([]specV1.Application, error) {
+	res := make([]specV1.Application, 0, len(names))
+	for _, name := range names {
+		app, err := storage.GetApplication(namespace, name, "")
+		if err != nil {
+			if goerrors.Is(err, plugin.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		res = append(res, *app)
 	}
+	return res, nil
+}
 
-	return app, err
+// Exists probe the app in storage, the spec is neither returned nor copied
+func (a *applicationService) Exists(ctx context.Context, namespace, name string) (bool, error) {
+	_, err := a.modelStorage(ctx).GetApplication(namespace, name, "")
+	if err != nil {
+		if goerrors.Is(err, plugin.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
-// Create create application
-func (a *applicationService) Create(namespace string, app *specV1.Application) (*specV1.Application, error) {
-	configs, secrets, err := a.getConfigsAndSecrets(namespace, app)
-	if err = a.indexService.RefreshConfigIndexByApp(namespace, app.Name, configs); err != nil {
+// CreateWithOptions create application with options, a retry with the same RequestID returns the app created before
+func (a *applicationService) CreateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*specV1.Application, error) {
+	if opts == nil {
+		return a.Create(ctx, namespace, app)
+	}
+	if !opts.NoDefaultBase {
+		return a.CreateWithBases(ctx, namespace, app, nil, opts)
+	}
+	if opts.SkipValidation {
+		if !a.conf.AllowSkipValidation {
+			return nil, common.Error(common.ErrRequestAccessDenied,
+				common.Field("error", "creating application without validation is not allowed"))
+		}
+		if opts.Strict {
+			return nil, common.Error(common.ErrRequestParamInvalid,
+				common.Field("error", "strict and skip validation can't be set together"))
+		}
+	}
+	if opts.PinConfigVersions {
+		labels := map[string]string{}
+		for k, v := range app.Labels {
+			labels[k] = v
+		}
+		labels[common.LabelConfigPinned] = "true"
+		app.Labels = labels
+	}
+	if opts.RequestID == "" {
+		return a.create(ctx, namespace, app, opts)
+	}
+	if err := a.historyEnabled("create with request id"); err != nil {
 		return nil, err
 	}
-	if err = a.indexService.RefreshSecretIndexByApp(namespace, app.Name, secrets); err != nil {
+	data, err := json.Marshal(app)
+	if err != nil {
 		return nil, err
 	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
 
-	// create application
-	app, err = a.storage.CreateApplication(namespace, app)
+	req, err := a.historyStorage(ctx).GetApplicationRequest(namespace, opts.RequestID)
 	if err != nil {
 		return nil, err
 	}
+	if req != nil {
+		if req.Digest != digest {
+			return nil, common.Error(common.ErrResourceConflict, common.Field("type", "app"),
+				common.Field("name", app.Name),
+				common.Field("error", fmt.Sprintf("request id %s is used by a different app", opts.RequestID)))
+		}
+		created, err := a.historyStorage(ctx).GetApplication(req.Name, namespace, req.Version)
+		if err != nil {
+			return nil, err
+		}
+		if created != nil {
+			return created, nil
+		}
+		return a.Get(ctx, namespace, req.Name, req.Version)
+	}
 
-	// store application history to db
-	if _, err := a.dbStorage.CreateApplication(app); err != nil {
-		log.L().Error("store application to db error",
-			log.Any("name", app.Name),
-			log.Any("namespace", app.Namespace),
-			log.Any("version", app.Version),
+	created, err := a.create(ctx, namespace, app, opts)
+	if err != nil {
+		return nil, err
+	}
+	// a retry without the record creates again and fails with conflict. err can ignore
+	if _, err = a.historyStorage(detachedContext{ctx}).CreateApplicationRequest(&models.ApplicationRequest{
+		Namespace: namespace,
+		RequestID: opts.RequestID,
+		Name:      created.Name,
+		Version:   created.Version,
+		Digest:    digest,
+	}); err != nil {
+		log.L().Error("store application request error",
+			log.Any("namespace", namespace),
+			log.Any("name", created.Name),
+			log.Any("requestId", opts.RequestID),
 			log.Error(err))
 	}
-
-	return app, nil
+	return created, nil
 }
 
-// Update update application
-func (a *applicationService) Update(namespace string, app *specV1.Application) (*specV1.Application, error) {
-	err := a.validName(app)
+// CreateWithResult create application with options, the problems which don't fail the creation are returned as warnings
+func (a *applicationService) CreateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*models.ApplicationCreateResult, error) {
+	res, err := a.CreateWithOptions(ctx, namespace, app, opts)
 	if err != nil {
 		return nil, err
 	}
+	return &models.ApplicationCreateResult{App: res, Warnings: appWarnings(res)}, nil
+}
+
+// validApp the checks of app before it's created, shared by create, CreateBatch, Import and Validate. All violations
+// are returned together, the references of volumes are checked by getConfigsAndSecrets or validReferences then
+func (a *applicationService) validApp(ctx context.Context, namespace string, app *specV1.Application, strict bool) error {
+	errs := &common.MultiError{}
+	errs.Append(a.validSchema(app))
+	errs.Append(a.validName(app))
+	errs.Append(validDNSNames(app))
+	errs.Append(a.validFunctions(ctx, namespace, app))
+	if strict {
+		errs.Append(validStrict(app))
+	}
+	return errs.ErrorOrNil()
+}
+
+// validStrict the checks of CreateOptions.Strict, the problems of them are only warnings otherwise
+func validStrict(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	if unused := unusedVolumes(app); len(unused) > 0 {
+		errs.Append(common.Error(common.ErrUnusedVolume, common.Field("name", strings.Join(unused, ","))))
+	}
+	errs.Append(validateImages(app, true))
+	if writable := writableSecretMounts(app); len(writable) > 0 {
+		errs.Append(common.Error(common.ErrSecretMountWritable, common.Field("where", strings.Join(writable, ","))))
+	}
+	return errs.ErrorOrNil()
+}
 
-	configs, secrets, err := a.getConfigsAndSecrets(namespace, app)
+// Create create application, the steps not started yet are skipped and the done ones are reverted once ctx is done
+func (a *applicationService) Create(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	base, err := a.defaultBase(ctx, namespace, app)
 	if err != nil {
 		return nil, err
 	}
+	if base != nil {
+		if err = a.applyBases(ctx, namespace, app, []*specV1.Application{base}, models.MergeError); err != nil {
+			return nil, err
+		}
+	}
+	return a.create(ctx, namespace, app, nil)
+}
 
-	newApp, err := a.storage.UpdateApplication(namespace, app)
+// create the app is validated unless opts.SkipValidation, opts can be nil
+func (a *applicationService) create(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*specV1.Application, error) {
+	if opts == nil {
+		opts = &models.CreateOptions{}
+	}
+	operator := opts.Operator
+	err := bindNamespace(namespace, app)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := a.indexService.RefreshConfigIndexByApp(namespace, newApp.Name, configs); err != nil {
+	if err = normalizeNames(app); err != nil {
+		return nil, err
+	}
+	if opts.SkipValidation {
+		log.L().Warn("application is created without validation",
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("app", app.Name),
+			log.Any("operator", operator))
+	} else if err = a.validApp(ctx, namespace, app, opts.Strict); err != nil {
 		return nil, err
 	}
-	if err := a.indexService.RefreshSecretIndexByApp(namespace, newApp.Name, secrets); err != nil {
+	if unused := unusedVolumes(app); len(unused) > 0 {
+		log.L().Warn("volumes are not mounted by any service",
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("app", app.Name),
+			log.Any("volumes", unused))
+	}
+	if writable := writableSecretMounts(app); len(writable) > 0 {
+		log.L().Warn("secret volumes are mounted writable",
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("app", app.Name),
+			log.Any("mounts", writable))
+	}
+	if err = a.checkQuota(ctx, namespace, 1); err != nil {
 		return nil, err
 	}
 
-	// store app history to db
-	if app.Version != newApp.Version {
-		if _, err := a.dbStorage.CreateApplication(newApp); err != nil {
-			log.L().Error("store application to db error",
-				log.Any("name", newApp.Name),
-				log.Any("namespace", newApp.Namespace),
-				log.Any("version", newApp.Version), log.Error(err))
-		}
+	configs, secrets, _, err := a.getConfigsAndSecrets(ctx, namespace, app)
+	if err != nil {
+		return nil, err
+	}
+
+	// the index is refreshed only after the application is created, and all of them are reverted if any fails
+	name := app.Name
+	t := &txn{ctx: ctx}
+	t.do(func() error {
+		app, err = a.modelStorage(ctx).CreateApplication(namespace, app)
+		return err
+	}, func() error {
+		return a.modelStorage(ctx).DeleteApplication(namespace, name)
+	})
+	// the new app has no index to clear, so none is written if it references nothing
+	if len(configs) > 0 {
+		t.do(func() error {
+			return a.indexService.RefreshConfigIndexByApp(namespace, name, configs)
+		}, func() error {
+			return a.indexService.RefreshConfigIndexByApp(namespace, name, []string{})
+		})
+	}
+	if len(secrets) > 0 {
+		t.do(func() error {
+			return a.indexService.RefreshSecretIndexByApp(namespace, name, secrets)
+		}, func() error {
+			return a.indexService.RefreshSecretIndexByApp(namespace, name, []string{})
+		})
+	}
+	t.do(func() error {
+		return a.refreshBaseIndex(namespace, name, nil, app)
+	}, func() error {
+		return a.refreshBaseIndex(namespace, name, app, nil)
+	})
+	t.do(func() error {
+		return a.runPostCreateHooks(ctx, namespace, app)
+	}, nil)
+	if err = t.end(log.Any("type", common.Application),
+		log.Any(common.KeyContextNamespace, namespace),
+		log.Any("name", name)); err != nil {
+		return nil, err
 	}
 
-	return newApp, nil
+	// store application history to db
+	a.storeHistory(ctx, app, operator)
+	a.publish(models.ApplicationCreated, namespace, app.Name, app.Version)
+
+	return app, nil
 }
 
-// Delete delete application
-func (a *applicationService) Delete(namespace, name, version string) error {
-	if err := a.storage.DeleteApplication(namespace, name); err != nil {
-		return err
+// CreateBatch create applications, all of them are validated before creation.
+// If any creation fails, the applications already created are removed.
+func (a *applicationService) CreateBatch(ctx context.Context, namespace string, apps []*specV1.Application) ([]*specV1.Application, error) {
+	errs := &common.MultiError{}
+	names := make(map[string]bool)
+	configs, secrets := make([][]string, len(apps)), make([][]string, len(apps))
+	for i, app := range apps {
+		if err := bindNamespace(namespace, app); err != nil {
+			errs.Append(err)
+			continue
+		}
+		if err := normalizeNames(app); err != nil {
+			errs.Append(err)
+			continue
+		}
+		if _, ok := names[app.Name]; ok {
+			errs.Append(common.Error(common.ErrAppNameConflict,
+				common.Field("where", fmt.Sprintf("apps[%d]", i)),
+				common.Field("name", app.Name)))
+		}
+		names[app.Name] = true
+		// the default base is merged as Create does
+		base, err := a.defaultBase(ctx, namespace, app)
+		if err != nil {
+			errs.Append(err)
+			continue
+		}
+		if base != nil {
+			if err = a.applyBases(ctx, namespace, app, []*specV1.Application{base}, models.MergeError); err != nil {
+				errs.Append(err)
+				continue
+			}
+		}
+		errs.Append(a.validApp(ctx, namespace, app, false))
+
+		configs[i], secrets[i], _, err = a.getConfigsAndSecrets(ctx, namespace, app)
+		errs.Append(err)
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	if err := a.checkQuota(ctx, namespace, len(apps)); err != nil {
+		return nil, err
 	}
 
-	// TODO: Where dirty data comes from
-	if err := a.indexService.RefreshConfigIndexByApp(namespace, name, []string{}); err != nil {
-		log.L().Error("Application clean config index error", log.Error(err))
+	created := make([]*specV1.Application, 0, len(apps))
+	for _, app := range apps {
+		res, err := a.modelStorage(ctx).CreateApplication(namespace, app)
+		if err != nil {
+			a.rollbackBatch(ctx, namespace, created)
+			return nil, err
+		}
+		created = append(created, res)
 	}
-	if err := a.indexService.RefreshSecretIndexByApp(namespace, name, []string{}); err != nil {
-		log.L().Error("Application clean secret index error", log.Error(err))
+
+	appConfigs, appSecrets := make(map[string][]string), make(map[string][]string)
+	for i, app := range created {
+		if len(configs[i]) > 0 {
+			appConfigs[app.Name] = configs[i]
+		}
+		if len(secrets[i]) > 0 {
+			appSecrets[app.Name] = secrets[i]
+		}
+	}
+	if err := a.indexService.RefreshConfigIndexByApps(namespace, appConfigs); err != nil {
+		a.rollbackBatch(ctx, namespace, created)
+		return nil, err
+	}
+	if err := a.indexService.RefreshSecretIndexByApps(namespace, appSecrets); err != nil {
+		a.rollbackBatch(ctx, namespace, created)
+		return nil, err
+	}
+	for _, app := range created {
+		if err := a.refreshBaseIndex(namespace, app.Name, nil, app); err != nil {
+			a.rollbackBatch(ctx, namespace, created)
+			return nil, err
+		}
+	}
+	for _, app := range created {
+		if err := a.runPostCreateHooks(ctx, namespace, app); err != nil {
+			a.rollbackBatch(ctx, namespace, created)
+			return nil, err
+		}
 	}
 
-	// mark the application was deleted. err can ignore
-	if _, err := a.dbStorage.DeleteApplication(namespace, name, version); err != nil {
-		log.L().Error("delete application history error",
-			log.Any("name", name),
-			log.Any("namespace", namespace),
-			log.Any("version", version),
-			log.Error(err))
+	for _, app := range created {
+		a.storeHistory(ctx, app, "")
+		a.publish(models.ApplicationCreated, namespace, app.Name, app.Version)
 	}
-	return nil
+	return created, nil
 }
 
-// List get list config
-func (a *applicationService) List(namespace string,
-	listOptions *models.ListOptions) (*models.ApplicationList, error) {
-	return a.storage.ListApplication(namespace, listOptions)
+// rollbackBatch remove the applications created in a failed batch. err can ignore
+func (a *applicationService) rollbackBatch(ctx context.Context, namespace string, apps []*specV1.Application) {
+	for _, app := range apps {
+		if err := a.deleteApp(ctx, namespace, app.Name); err != nil {
+			common.LogDirtyData(err,
+				log.Any("type", common.Application),
+				log.Any(common.KeyContextNamespace, namespace),
+				log.Any("name", app.Name))
+		}
+	}
 }
 
-// CreateBaseOther create application with base
-func (a *applicationService) CreateWithBase(namespace string, app, base *specV1.Application) (*specV1.Application, error) {
-	if base != nil {
-		if namespace != base.Namespace {
-			err := a.constuctConfig(namespace, base)
-			if err != nil {
-				return nil, err
+// Update update application, the version of app must be the current one
+func (a *applicationService) Update(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	return a.UpdateWithOptions(ctx, namespace, app, nil)
+}
+
+// UpdateWithResult update application with options, the pinned configs which have newer versions are returned as drifts,
+// and the problems which don't fail the update as warnings
+func (a *applicationService) UpdateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*models.ApplicationUpdateResult, error) {
+	return a.update(ctx, namespace, app, opts)
+}
+
+// UpdateWithOptions update application with options, it isn't updated if ctx is done before the storage write
+func (a *applicationService) UpdateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*specV1.Application, error) {
+	res, err := a.update(ctx, namespace, app, opts)
+	if err != nil {
+		return nil, err
+	}
+	return res.App, nil
+}
+
+func (a *applicationService) update(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*models.ApplicationUpdateResult, error) {
+	err := normalizeNames(app)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.validSchema(app); err != nil {
+		return nil, err
+	}
+	if err = a.validName(app); err != nil {
+		return nil, err
+	}
+	if err = a.validFunctions(ctx, namespace, app); err != nil {
+		return nil, err
+	}
+
+	current, err := a.Get(ctx, namespace, app.Name, "")
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Force {
+		app.Version = current.Version
+	} else if !sameVersion(app.Version, current.Version) {
+		return nil, common.Error(common.ErrResourceConflict, common.Field("type", "app"),
+			common.Field("name", app.Name),
+			common.Field("error", fmt.Sprintf("version %s is outdated, the current version is %s", app.Version, current.Version)))
+	}
+
+	// the pin mode is decided at create time
+	if v, ok := current.Labels[common.LabelConfigPinned]; ok {
+		if _, ok = app.Labels[common.LabelConfigPinned]; !ok {
+			labels := map[string]string{common.LabelConfigPinned: v}
+			for k, v := range app.Labels {
+				labels[k] = v
 			}
+			app.Labels = labels
 		}
-		app.Services = append(base.Services, app.Services...)
-		app.Volumes = append(base.Volumes, app.Volumes...)
 	}
 
-	err := a.validName(app)
+	configs, secrets, drifts, err := a.getConfigsAndSecrets(ctx, namespace, app)
 	if err != nil {
 		return nil, err
 	}
+	for _, d := range drifts {
+		log.L().Warn("pinned config has a newer version",
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("app", app.Name),
+			log.Any("config", d.Name),
+			log.Any("version", d.Version),
+			log.Any("latest", d.Latest))
+	}
 
-	return a.Create(namespace, app)
-}
+	// the identical spec is not written, so the version isn't bumped and nodes are not synced again
+	if (opts == nil || !opts.Force) && sameSpec(current, app) {
+		return &models.ApplicationUpdateResult{App: current, Drifts: drifts, Warnings: appWarnings(current)}, nil
+	}
 
-func (a *applicationService) constuctConfig(namespace string, base *specV1.Application) error {
-	for _, v := range base.Volumes {
-		if v.Config != nil {
-			cfg, err := a.storage.GetConfig(base.Namespace, v.Config.Name, "")
-			if err != nil {
-				log.L().Error("failed to get system config",
-					log.Any(common.KeyContextNamespace, base.Namespace),
-					log.Any("name", v.Config.Name))
-				return common.Error(common.ErrResourceNotFound,
-					common.Field("type", "config"),
-					common.Field(common.KeyContextNamespace, base.Namespace),
-					common.Field("name", v.Config.Name))
-			}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	newApp, err := a.updateStorage(ctx, namespace, app)
+	if err != nil {
+		return nil, err
+	}
 
-			config, err := a.storage.CreateConfig(namespace, cfg)
-			if err != nil {
-				log.L().Error("failed to create user config",
-					log.Any(common.KeyContextNamespace, namespace),
-					log.Any("name", v.Config.Name))
-				cfg.Name = cfg.Name + "-" + common.RandString(9)
-				config, err = a.storage.CreateConfig(namespace, cfg)
-				if err != nil {
-					return err
-				}
-				v.Config.Name = config.Name
-			}
-			v.Config.Version = config.Version
+	// the index is written unless the app referenced nothing before and still doesn't
+	hadConfigs, hadSecrets := hasReferences(current)
+	if len(configs) > 0 || hadConfigs {
+		if err := a.indexService.RefreshConfigIndexByApp(namespace, newApp.Name, configs); err != nil {
+			return nil, err
 		}
 	}
-	return nil
-}
-
-// get App secrets
-func (a *applicationService) getConfigsAndSecrets(namespace string, app *specV1.Application) ([]string, []string, error) {
-	var configs []string
-	var secrets []string
-	for _, vol := range app.Volumes {
-		if vol.Config != nil {
-			// set the lastest config version
-			config, err := a.storage.GetConfig(namespace, vol.Config.Name, "")
-			if err != nil {
-				return nil, nil, err
-			}
-			vol.Config.Version = config.Version
-			configs = append(configs, vol.Config.Name)
+	if len(secrets) > 0 || hadSecrets {
+		if err := a.indexService.RefreshSecretIndexByApp(namespace, newApp.Name, secrets); err != nil {
+			return nil, err
 		}
-		if vol.Secret != nil {
-			secret, err := a.storage.GetSecret(namespace, vol.Secret.Name, "")
-			if err != nil {
-				return nil, nil, err
-			}
-			vol.Secret.Version = secret.Version
-			secrets = append(secrets, vol.Secret.Name)
+	}
+	if err := a.refreshBaseIndex(namespace, newApp.Name, current, newApp); err != nil {
+		return nil, err
+	}
+
+	// store app history to db
+	if !sameVersion(app.Version, newApp.Version) {
+		operator := ""
+		if opts != nil {
+			operator = opts.Operator
 		}
+		a.storeHistory(ctx, newApp, operator)
 	}
+	a.publish(models.ApplicationUpdated, namespace, newApp.Name, newApp.Version)
 
-	return configs, secrets, nil
+	return &models.ApplicationUpdateResult{App: newApp, Drifts: drifts, Warnings: appWarnings(newApp)}, nil
 }
 
-func (a *applicationService) validName(app *specV1.Application) error {
-	sf, vf := make(map[string]bool), make(map[string]bool)
-	for _, v := range app.Volumes {
-		if _, ok := vf[v.Name]; ok {
-			return common.Error(common.ErrAppNameConflict,
-				common.Field("where", "Volumes[]"),
-				common.Field("name", v.Name))
-		}
+// updateStorage write app with the next version of the strategy, the storage assigns it if there is no strategy
+func (a *applicationService) updateStorage(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	if a.versions == nil {
+		return a.modelStorage(ctx).UpdateApplication(namespace, app)
+	}
+	next, err := a.versions.Next(app.Version)
+	if err != nil {
+		return nil, err
+	}
+	if next == "" {
+		return a.modelStorage(ctx).UpdateApplication(namespace, app)
+	}
+	versioner, ok := a.storage.(plugin.ApplicationVersioner)
+	if !ok {
+		return nil, common.Error(common.ErrNotSupported, common.Field("name", "update with version"))
+	}
+	return versioner.UpdateApplicationVersion(namespace, app, next)
+}
 
-		vf[v.Name] = true
+// Patch apply the patch on the current application, the patched one is validated and updated as Update
+func (a *applicationService) Patch(ctx context.Context, namespace, name string, patch *models.ApplicationPatch) (*specV1.Application, error) {
+	if patch == nil {
+		return nil, common.Error(common.ErrRequestParamInvalid, common.Field("error", "the patch is empty"))
+	}
+	current, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return nil, err
+	}
+	if patch.Version != "" && !sameVersion(patch.Version, current.Version) {
+		return nil, common.Error(common.ErrResourceConflict, common.Field("type", "app"),
+			common.Field("name", name),
+			common.Field("error", fmt.Sprintf("version %s is outdated, the current version is %s", patch.Version, current.Version)))
+	}
+	app, err := copyApplication(current)
+	if err != nil {
+		return nil, err
+	}
+	if err = applyPatch(app, patch); err != nil {
+		return nil, err
 	}
+	return a.UpdateWithOptions(ctx, namespace, app, nil)
+}
 
-	for _, s := range app.Services {
-		if _, ok := sf[s.Name]; ok {
-			return common.Error(common.ErrAppNameConflict,
-				common.Field("where", "Services[]"),
-				common.Field("name", s.Name))
+func applyPatch(app *specV1.Application, patch *models.ApplicationPatch) error {
+	if patch.Description != nil {
+		app.Description = *patch.Description
+	}
+	if len(patch.Labels) > 0 && app.Labels == nil {
+		app.Labels = map[string]string{}
+	}
+	for k, v := range patch.Labels {
+		if v == "" {
+			delete(app.Labels, k)
+		} else {
+			app.Labels[k] = v
 		}
-		for _, vm := range s.VolumeMounts {
-			if _, ok := vf[vm.Name]; !ok {
-				return common.Error(common.ErrVolumeNotFoundWhenMount,
-					common.Field("name", vm.Name))
+	}
+	for _, sp := range patch.Services {
+		i := -1
+		for j := range app.Services {
+			if app.Services[j].Name == sp.Name {
+				i = j
+				break
 			}
 		}
-		sf[s.Name] = true
+		if i < 0 {
+			return common.Error(common.ErrResourceNotFound, common.Field("type", "service"), common.Field("name", sp.Name))
+		}
+		if err := applyServicePatch(&app.Services[i], &sp); err != nil {
+			return err
+		}
 	}
-
-	return nil
+	for _, name := range patch.RemoveVolumes {
+		i := -1
+		for j := range app.Volumes {
+			if app.Volumes[j].Name == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return common.Error(common.ErrResourceNotFound, common.Field("type", "volume"), common.Field("name", name))
+		}
+		app.Volumes = append(app.Volumes[:i], app.Volumes[i+1:]...)
+	}
+	app.Volumes = append(app.Volumes, patch.AddVolumes...)
+	return nil
+}
+
+func applyServicePatch(s *specV1.Service, sp *models.ServicePatch) error {
+	if sp.Image != "" {
+		s.Image = sp.Image
+	}
+	if sp.Replica != nil {
+		s.Replica = *sp.Replica
+	}
+	if len(sp.Env) > 0 {
+		envs := make([]specV1.Environment, 0, len(s.Env)+len(sp.Env))
+		set := map[string]bool{}
+		for _, e := range s.Env {
+			if v, ok := sp.Env[e.Name]; ok {
+				set[e.Name] = true
+				if v == "" {
+					continue
+				}
+				e.Value = v
+			}
+			envs = append(envs, e)
+		}
+		// the new ones are appended in order of names
+		names := make([]string, 0, len(sp.Env))
+		for k, v := range sp.Env {
+			if !set[k] && v != "" {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			envs = append(envs, specV1.Environment{Name: k, Value: sp.Env[k]})
+		}
+		s.Env = envs
+	}
+	for _, name := range sp.RemoveVolumeMounts {
+		i := -1
+		for j := range s.VolumeMounts {
+			if s.VolumeMounts[j].Name == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return common.Error(common.ErrResourceNotFound, common.Field("type", "volumeMount"), common.Field("name", name))
+		}
+		s.VolumeMounts = append(s.VolumeMounts[:i], s.VolumeMounts[i+1:]...)
+	}
+	s.VolumeMounts = append(s.VolumeMounts, sp.AddVolumeMounts...)
+	return nil
+}
+
+// DeleteWithOptions delete application with options, the operator is recorded in history
+func (a *applicationService) DeleteWithOptions(ctx context.Context, namespace, name, version string, opts *models.DeleteOptions) error {
+	operator, force := "", false
+	if opts != nil {
+		operator, force = opts.Operator, opts.Force
+	}
+	return a.delete(ctx, namespace, name, version, operator, force)
+}
+
+// Delete delete application, it isn't deleted if ctx is done before the storage write
+func (a *applicationService) Delete(ctx context.Context, namespace, name, version string) error {
+	return a.delete(ctx, namespace, name, version, "", false)
+}
+
+func (a *applicationService) delete(ctx context.Context, namespace, name, version, operator string, force bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !force {
+		if err := a.checkNotBase(namespace, name); err != nil {
+			return err
+		}
+	}
+	current, err := a.modelStorage(ctx).GetApplication(namespace, name, "")
+	if err != nil && !goerrors.Is(err, plugin.ErrNotFound) {
+		return err
+	}
+	if err := a.deleteApp(ctx, namespace, name); err != nil {
+		return err
+	}
+	if err := a.refreshBaseIndex(namespace, name, current, nil); err != nil {
+		log.L().Error("Application clean base index error", log.Error(err))
+	}
+
+	// mark the application was deleted even if ctx is done. err can ignore
+	if !a.conf.History.Disabled {
+		a.retryHistory("delete application history error", func() error {
+			_, err := a.historyStorage(detachedContext{ctx}).DeleteApplicationHistory(name, namespace, version, operator)
+			return err
+		}, log.Any("name", name),
+			log.Any("namespace", namespace),
+			log.Any("version", version))
+	}
+	a.publish(models.ApplicationDeleted, namespace, name, version)
+	return nil
+}
+
+// DeleteBatch delete applications one by one, a failed deletion doesn't stop the others.
+// The returned err aggregates the errors of failed deletions.
+func (a *applicationService) DeleteBatch(ctx context.Context, namespace string, names []string) ([]string, map[string]error, error) {
+	errs := &common.MultiError{}
+	deleted, failed := make([]string, 0, len(names)), make(map[string]error)
+	visited := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := visited[name]; ok {
+			continue
+		}
+		visited[name] = true
+
+		if err := a.Delete(ctx, namespace, name, ""); err != nil {
+			err = toNotFoundError(err, common.APP, namespace, name)
+			failed[name] = err
+			errs.Append(err)
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+	return deleted, failed, errs.ErrorOrNil()
+}
+
+// DeleteByLabel delete the applications matching labelSelector one by one, a failed deletion doesn't stop the others.
+// The returned err aggregates the errors of failed deletions. An empty selector matches everything, so all must be set for it.
+func (a *applicationService) DeleteByLabel(ctx context.Context, namespace, labelSelector string, all bool) ([]string, error) {
+	if labelSelector == "" && !all {
+		return nil, common.Error(common.ErrRequestParamInvalid,
+			common.Field("error", "the empty label selector matches all applications, set all to delete them"))
+	}
+	list, err := a.modelStorage(ctx).ListApplication(namespace, &models.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	errs := &common.MultiError{}
+	deleted := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		if err := a.Delete(ctx, namespace, item.Name, item.Version); err != nil {
+			errs.Append(toNotFoundError(err, common.APP, namespace, item.Name))
+			continue
+		}
+		deleted = append(deleted, item.Name)
+	}
+	return deleted, errs.ErrorOrNil()
+}
+
+// SoftDelete delete application but keep it restorable within the retention window
+func (a *applicationService) SoftDelete(ctx context.Context, namespace, name, version string) error {
+	if err := a.historyEnabled("soft delete"); err != nil {
+		return err
+	}
+	if err := a.checkNotBase(namespace, name); err != nil {
+		return err
+	}
+	app, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		version = app.Version
+	}
+
+	// the spec must be kept in history, otherwise it can't be restored
+	res, err := a.historyStorage(ctx).SoftDeleteApplication(name, namespace, version)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		app.Version = version
+		if _, err = a.historyStorage(ctx).CreateApplication(app); err != nil {
+			return err
+		}
+		if _, err = a.historyStorage(ctx).SoftDeleteApplication(name, namespace, version); err != nil {
+			return err
+		}
+	}
+
+	if err = a.deleteApp(ctx, namespace, name); err != nil {
+		return err
+	}
+	// it's indexed again once restored
+	if err = a.refreshBaseIndex(namespace, name, app, nil); err != nil {
+		log.L().Error("Application clean base index error", log.Error(err))
+	}
+	a.publish(models.ApplicationDeleted, namespace, name, version)
+	return nil
+}
+
+// Restore reinstate the most recent soft deleted application
+func (a *applicationService) Restore(ctx context.Context, namespace, name string) (*specV1.Application, error) {
+	if err := a.historyEnabled("restore"); err != nil {
+		return nil, err
+	}
+	app, err := a.historyStorage(ctx).GetSoftDeletedApplication(name, namespace, time.Now().Add(-a.conf.SoftDeleteRetention))
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "app"),
+			common.Field("name", name), common.Field("namespace", namespace))
+	}
+
+	version := app.Version
+	app.Namespace = namespace
+	app.Version = ""
+	restored, err := a.Create(ctx, namespace, app)
+	if err != nil {
+		return nil, err
+	}
+
+	// the soft deleted version becomes ordinary history again. err can ignore
+	if _, err := a.historyStorage(detachedContext{ctx}).RestoreApplication(name, namespace, version); err != nil {
+		log.L().Error("restore application history error",
+			log.Any("name", name),
+			log.Any("namespace", namespace),
+			log.Any("version", version),
+			log.Error(err))
+	}
+	return restored, nil
+}
+
+func (a *applicationService) deleteApp(ctx context.Context, namespace, name string) error {
+	if err := a.modelStorage(ctx).DeleteApplication(namespace, name); err != nil {
+		return err
+	}
+
+	// the index left behind if cleaning fails here is pruned by IndexService.GarbageCollect
+	if err := a.indexService.RefreshConfigIndexByApp(namespace, name, []string{}); err != nil {
+		log.L().Error("Application clean config index error", log.Error(err))
+	}
+	if err := a.indexService.RefreshSecretIndexByApp(namespace, name, []string{}); err != nil {
+		log.L().Error("Application clean secret index error", log.Error(err))
+	}
+	return nil
+}
+
+// hasReferences tell whether app mounts any config or secret, by the volumes without reading storage
+func hasReferences(app *specV1.Application) (configs, secrets bool) {
+	for _, v := range app.Volumes {
+		configs = configs || v.Config != nil
+		secrets = secrets || v.Secret != nil
+	}
+	return
+}
+
+// checkNotBase return ErrResourceInUse listing the apps whose base is the app
+func (a *applicationService) checkNotBase(namespace, name string) error {
+	dependents, err := a.indexService.ListIndex(namespace, common.Application, common.Base, name)
+	if err != nil {
+		return err
+	}
+	if len(dependents) > 0 {
+		sort.Strings(dependents)
+		return common.Error(common.ErrResourceInUse, common.Field("type", common.Application),
+			common.Field("name", name), common.Field("users", strings.Join(dependents, ",")))
+	}
+	return nil
+}
+
+// refreshBaseIndex move the app from the base index of old to the one of new, either may be nil.
+// The index is kept in the namespace of each base, where the app is named as namespace/name if the namespaces differ.
+func (a *applicationService) refreshBaseIndex(namespace, name string, old, new *specV1.Application) error {
+	olds, news := basesByNamespace(namespace, old), basesByNamespace(namespace, new)
+	// the namespaces keeping no base of the app are cleaned first
+	for _, ns := range sortedKeys(olds) {
+		if _, ok := news[ns]; ok {
+			continue
+		}
+		err := a.indexService.RefreshIndex(ns, common.Application, common.Base, dependentName(ns, namespace, name), []string{})
+		if err != nil {
+			return err
+		}
+	}
+	for _, ns := range sortedKeys(news) {
+		if reflect.DeepEqual(olds[ns], news[ns]) {
+			continue
+		}
+		err := a.indexService.RefreshIndex(ns, common.Application, common.Base, dependentName(ns, namespace, name), news[ns])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appBases the bases named by LabelBaseApp in the order they are merged, the namespace defaults to the app's
+func appBases(namespace string, app *specV1.Application) []config.AppBaseRef {
+	if app == nil || app.Labels[common.LabelBaseApp] == "" {
+		return nil
+	}
+	var refs []config.AppBaseRef
+	visited := map[config.AppBaseRef]bool{}
+	for _, base := range strings.Split(app.Labels[common.LabelBaseApp], ",") {
+		ref := config.AppBaseRef{Namespace: namespace, Name: strings.TrimSpace(base)}
+		if i := strings.Index(ref.Name, "/"); i >= 0 {
+			ref.Namespace, ref.Name = ref.Name[:i], ref.Name[i+1:]
+		}
+		if ref.Name == "" || visited[ref] {
+			continue
+		}
+		visited[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// basesByNamespace the names of the bases of app grouped by their namespaces
+func basesByNamespace(namespace string, app *specV1.Application) map[string][]string {
+	res := map[string][]string{}
+	for _, ref := range appBases(namespace, app) {
+		res[ref.Namespace] = append(res[ref.Namespace], ref.Name)
+	}
+	return res
+}
+
+// formatBases the value of LabelBaseApp for the app in namespace, the bases in namespace are named without it
+func formatBases(namespace string, refs []config.AppBaseRef) string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, dependentName(namespace, ref.Namespace, ref.Name))
+	}
+	return strings.Join(names, ",")
+}
+
+// setAppBases record the bases merged into app by LabelBaseApp after the ones it has, so that they are protected
+// from deletion and not merged again once the app is created from its spec, such as by Restore or Clone
+func setAppBases(namespace string, app *specV1.Application, bases []*specV1.Application) {
+	if len(bases) == 0 {
+		return
+	}
+	refs := appBases(namespace, app)
+	for _, base := range bases {
+		ref := config.AppBaseRef{Namespace: base.Namespace, Name: base.Name}
+		if ref.Namespace == "" {
+			ref.Namespace = namespace
+		}
+		if !containsRef(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	labels := map[string]string{}
+	for k, v := range app.Labels {
+		labels[k] = v
+	}
+	labels[common.LabelBaseApp] = formatBases(namespace, refs)
+	app.Labels = labels
+}
+
+func containsRef(refs []config.AppBaseRef, ref config.AppBaseRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dependentName(baseNamespace, namespace, name string) string {
+	if baseNamespace == namespace {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// List get list config. Items are filtered by LabelSelector and Search (both apply) and sorted by OrderBy (name as default),
+// Continue is the cursor of next page and PageNo/PageSize are used for offset pagination if no cursor is supplied.
+// Search matches every keyword against name and description case-insensitively, the matches are not ranked.
+// It returns the error of ctx once ctx is done
+func (a *applicationService) List(ctx context.Context, namespace string,
+	listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	if listOptions == nil {
+		listOptions = &models.ListOptions{}
+	}
+	list, err := a.listFiltered(ctx, namespace, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err = sortApps(list, listOptions); err != nil {
+		return nil, err
+	}
+	if err = paginateApps(list, listOptions); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// listStreamPageSize the number of applications listed from storage at a time by ListStream
+const listStreamPageSize = 100
+
+// ListStream page through storage, so only a page of applications is in memory at a time.
+// The specs of each page are got in a single query if storage supports, see getApplications
+func (a *applicationService) ListStream(ctx context.Context, namespace string, listOptions *models.ListOptions, fn func(*specV1.Application) error) error {
+	if listOptions == nil {
+		listOptions = &models.ListOptions{}
+	}
+	opts := *listOptions
+	opts.Limit, opts.Continue, opts.PageNo, opts.PageSize, opts.Search = listStreamPageSize, "", 0, 0, ""
+	for {
+		list, err := a.modelStorage(ctx).ListApplication(namespace, &opts)
+		if err != nil {
+			return err
+		}
+		if err = a.filterApps(list, listOptions); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+		// the specs of a page are got at once, the apps deleted after listed are left out
+		apps, err := getApplications(ctx, a.storage, namespace, names)
+		if err != nil {
+			return err
+		}
+		for i := range apps {
+			if err = fn(&apps[i]); err != nil {
+				return err
+			}
+		}
+		if list.ListOptions == nil || list.ListOptions.Continue == "" {
+			return nil
+		}
+		opts.Continue = list.ListOptions.Continue
+	}
+}
+
+// Count count the applications matched by the same filters of List, pagination is ignored. It returns the error of ctx once ctx is done
+func (a *applicationService) Count(ctx context.Context, namespace string, listOptions *models.ListOptions) (int, error) {
+	if listOptions == nil {
+		listOptions = &models.ListOptions{}
+	}
+	// the keywords are only matched in memory, the rest filters are counted by storage
+	if strings.TrimSpace(listOptions.Search) == "" {
+		return a.countStored(ctx, namespace, listOptions.LabelSelector)
+	}
+	list, err := a.listFiltered(ctx, namespace, listOptions)
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// countStored count the applications matched by selector in storage. Only one item is listed if storage tells how many remain,
+// otherwise it is listed again without limit
+func (a *applicationService) countStored(ctx context.Context, namespace, selector string) (int, error) {
+	list, err := a.modelStorage(ctx).ListApplication(namespace, &models.ListOptions{LabelSelector: selector, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	if list.ListOptions == nil || list.ListOptions.Continue == "" || list.Total > len(list.Items) {
+		return list.Total, nil
+	}
+	list, err = a.modelStorage(ctx).ListApplication(namespace, &models.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return 0, err
+	}
+	return list.Total, nil
+}
+
+// listFiltered push the label selector down to storage, which returns items without specs, then apply the rest filters
+func (a *applicationService) listFiltered(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	opts := *listOptions
+	opts.Limit, opts.Continue, opts.PageNo, opts.PageSize, opts.Search = 0, "", 0, 0, ""
+	list, err := a.modelStorage(ctx).ListApplication(namespace, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.filterApps(list, listOptions); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateBaseOther create application with base
+func (a *applicationService) CreateWithBase(ctx context.Context, namespace string, app, base *specV1.Application) (*specV1.Application, error) {
+	var bases []*specV1.Application
+	if base != nil {
+		bases = append(bases, base)
+	}
+	return a.CreateWithBases(ctx, namespace, app, bases, nil)
+}
+
+// CreateWithBases create application on top of the bases, their services and volumes are merged in order before the app's.
+// The default base of namespace goes first unless opts.NoDefaultBase is set.
+// The name conflicts between bases and app are resolved by opts.MergeStrategy
+func (a *applicationService) CreateWithBases(ctx context.Context, namespace string, app *specV1.Application, bases []*specV1.Application, opts *models.CreateOptions) (*specV1.Application, error) {
+	// the names are trimmed before merging, so that they conflict with the same names of bases
+	if err := normalizeNames(app); err != nil {
+		return nil, err
+	}
+	if opts == nil || !opts.NoDefaultBase {
+		base, err := a.defaultBase(ctx, namespace, app)
+		if err != nil {
+			return nil, err
+		}
+		if base != nil && !containsBase(bases, base) {
+			bases = append([]*specV1.Application{base}, bases...)
+		}
+		o := models.CreateOptions{}
+		if opts != nil {
+			o = *opts
+		}
+		o.NoDefaultBase = true
+		opts = &o
+	}
+	strategy := models.MergeError
+	if opts != nil && opts.MergeStrategy != "" {
+		strategy = opts.MergeStrategy
+	}
+	switch strategy {
+	case models.MergeError, models.MergeOverride, models.MergeSkip, models.MergeDeep:
+	default:
+		return nil, common.Error(common.ErrRequestParamInvalid,
+			common.Field("error", fmt.Sprintf("merge strategy %s is not supported", strategy)))
+	}
+	if err := a.applyBases(ctx, namespace, app, bases, strategy); err != nil {
+		return nil, err
+	}
+	return a.CreateWithOptions(ctx, namespace, app, opts)
+}
+
+// applyBases merge the bases into app, the configs and secrets of bases in other namespaces are copied into namespace
+func (a *applicationService) applyBases(ctx context.Context, namespace string, app *specV1.Application, bases []*specV1.Application, strategy models.MergeStrategy) error {
+	if err := validBaseNames(bases); err != nil {
+		return err
+	}
+	for _, base := range bases {
+		if namespace != base.Namespace {
+			copies, err := a.sharedCopies(ctx, namespace, app, base)
+			if err != nil {
+				return err
+			}
+			if err = validSelfReference(app, copies); err != nil {
+				return err
+			}
+			if err = a.constuctConfig(ctx, namespace, base); err != nil {
+				return err
+			}
+		}
+	}
+	mergeBases(app, bases, strategy)
+	setAppBases(namespace, app, bases)
+	return nil
+}
+
+// sharedCopies the copies of base whose configs or secrets are also referenced by the volumes of app,
+// like renderCopies but only these are looked up in namespace
+func (a *applicationService) sharedCopies(ctx context.Context, namespace string, app, base *specV1.Application) ([]models.ConfigCopy, error) {
+	refs := map[string]bool{}
+	for _, v := range app.Volumes {
+		if v.Config != nil {
+			refs[string(common.Config)+"/"+v.Config.Name] = true
+		}
+		if v.Secret != nil {
+			refs[string(common.Secret)+"/"+v.Secret.Name] = true
+		}
+	}
+	var copies []models.ConfigCopy
+	for _, v := range base.Volumes {
+		var err error
+		c := models.ConfigCopy{Volume: v.Name, Namespace: base.Namespace}
+		switch {
+		case v.Config != nil:
+			c.Type, c.Name = string(common.Config), v.Config.Name
+			if !refs[c.Type+"/"+c.Name] {
+				continue
+			}
+			_, err = a.modelStorage(ctx).GetConfig(namespace, c.Name, "")
+		case v.Secret != nil:
+			c.Type, c.Name = string(common.Secret), v.Secret.Name
+			if !refs[c.Type+"/"+c.Name] {
+				continue
+			}
+			_, err = a.modelStorage(ctx).GetSecret(namespace, c.Name, "")
+		default:
+			continue
+		}
+		if err != nil && !goerrors.Is(err, plugin.ErrNotFound) {
+			return nil, err
+		}
+		c.Conflict = err == nil
+		copies = append(copies, c)
+	}
+	return copies, nil
+}
+
+// validSelfReference reject the volumes of app referencing the configs or secrets which the copies of base create,
+// they are only resolved because the creation of app generates them. The conflicting copies are suffixed, so they are not referenced
+func validSelfReference(app *specV1.Application, copies []models.ConfigCopy) error {
+	created := map[string]bool{}
+	for _, c := range copies {
+		if !c.Conflict {
+			created[c.Type+"/"+c.Name] = true
+		}
+	}
+	for _, v := range app.Volumes {
+		var tp common.Resource
+		var name string
+		switch {
+		case v.Config != nil:
+			tp, name = common.Config, v.Config.Name
+		case v.Secret != nil:
+			tp, name = common.Secret, v.Secret.Name
+		default:
+			continue
+		}
+		if created[string(tp)+"/"+name] {
+			return common.Error(common.ErrSelfReference,
+				common.Field("type", tp),
+				common.Field("name", name),
+				common.Field("volume", v.Name),
+				common.Field("error", "it is copied from the base by the same creation"))
+		}
+	}
+	return nil
+}
+
+// defaultBase get the latest default base of namespace, nil if there is none, the app is the base itself
+// or the base is merged already as LabelBaseApp tells
+func (a *applicationService) defaultBase(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	ref := config.AppBaseRef{Namespace: a.conf.DefaultBase.Namespace, Name: a.conf.DefaultBase.Name}
+	if r, ok := a.conf.DefaultBase.Namespaces[namespace]; ok {
+		ref = r
+	}
+	if ref.Name == "" {
+		return nil, nil
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = namespace
+	}
+	if ref.Namespace == namespace && ref.Name == app.Name || containsRef(appBases(namespace, app), ref) {
+		return nil, nil
+	}
+	base, err := a.Get(ctx, ref.Namespace, ref.Name, "")
+	if err != nil {
+		return nil, err
+	}
+	// the base is changed by merging
+	return copyApplication(base)
+}
+
+func containsBase(bases []*specV1.Application, base *specV1.Application) bool {
+	for _, b := range bases {
+		if b.Namespace == base.Namespace && b.Name == base.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderWithBase a dry run of CreateWithBase, it returns the merged app without creating anything.
+// The configs and secrets of base which would be copied into namespace are reported instead,
+// the volumes of the merged app reference them by the original names.
+func (a *applicationService) RenderWithBase(ctx context.Context, namespace string, app, base *specV1.Application) (*models.ApplicationRenderResult, error) {
+	app, err := copyApplication(app)
+	if err != nil {
+		return nil, err
+	}
+	res := &models.ApplicationRenderResult{App: app}
+	if base == nil {
+		return res, nil
+	}
+	if base, err = copyApplication(base); err != nil {
+		return nil, err
+	}
+	bases := []*specV1.Application{base}
+	if err = validBaseNames(bases); err != nil {
+		return nil, err
+	}
+	if namespace != base.Namespace {
+		if res.Copies, err = a.renderCopies(ctx, namespace, base); err != nil {
+			return nil, err
+		}
+		if err = validSelfReference(app, res.Copies); err != nil {
+			return nil, err
+		}
+	}
+	mergeBases(app, bases, models.MergeError)
+	if err = a.validName(app); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// renderCopies report the configs and secrets which constuctConfig would copy from base, nothing is created
+func (a *applicationService) renderCopies(ctx context.Context, namespace string, base *specV1.Application) ([]models.ConfigCopy, error) {
+	var copies []models.ConfigCopy
+	for _, v := range base.Volumes {
+		var tp common.Resource
+		var ref *specV1.ObjectReference
+		var get func(ns, name string) error
+		switch {
+		case v.Config != nil:
+			tp, ref = common.Config, v.Config
+			get = func(ns, name string) error {
+				_, err := a.modelStorage(ctx).GetConfig(ns, name, "")
+				return err
+			}
+		case v.Secret != nil:
+			tp, ref = common.Secret, v.Secret
+			get = func(ns, name string) error {
+				_, err := a.modelStorage(ctx).GetSecret(ns, name, "")
+				return err
+			}
+		default:
+			continue
+		}
+		if err := get(base.Namespace, ref.Name); err != nil {
+			return nil, common.Error(common.ErrResourceNotFound,
+				common.Field("type", tp),
+				common.Field(common.KeyContextNamespace, base.Namespace),
+				common.Field("name", ref.Name))
+		}
+		copies = append(copies, models.ConfigCopy{
+			Volume:    v.Name,
+			Type:      string(tp),
+			Name:      ref.Name,
+			Namespace: base.Namespace,
+			// the copy is suffixed if the name is used
+			Conflict: get(namespace, ref.Name) == nil,
+		})
+	}
+	return copies, nil
+}
+
+// Export export the application with the configs and secrets referenced by its volumes
+func (a *applicationService) Export(ctx context.Context, namespace, name, version string) (*models.ApplicationBundle, error) {
+	return a.ExportWithOptions(ctx, namespace, name, version, nil)
+}
+
+// ExportWithOptions export the application, the values of secrets are blanked if opts.Redact is set
+func (a *applicationService) ExportWithOptions(ctx context.Context, namespace, name, version string, opts *models.ExportOptions) (*models.ApplicationBundle, error) {
+	app, err := a.Get(ctx, namespace, name, version)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &models.ApplicationBundle{Format: models.ApplicationBundleFormat, App: app}
+	configs, secrets := map[string]bool{}, map[string]bool{}
+	for _, v := range app.Volumes {
+		switch {
+		case v.Config != nil:
+			if configs[v.Config.Name] {
+				continue
+			}
+			configs[v.Config.Name] = true
+			cfg, err := a.modelStorage(ctx).GetConfig(namespace, v.Config.Name, "")
+			if err != nil {
+				return nil, err
+			}
+			bundle.Configs = append(bundle.Configs, *cfg)
+		case v.Secret != nil:
+			if secrets[v.Secret.Name] {
+				continue
+			}
+			secrets[v.Secret.Name] = true
+			scr, err := a.modelStorage(ctx).GetSecret(namespace, v.Secret.Name, "")
+			if err != nil {
+				return nil, err
+			}
+			if opts != nil && opts.Redact {
+				redactSecret(scr)
+			}
+			bundle.Secrets = append(bundle.Secrets, *scr)
+		}
+	}
+	return bundle, nil
+}
+
+// redactSecret blank the values of secret, the keys are kept
+func redactSecret(scr *specV1.Secret) {
+	data := make(map[string][]byte, len(scr.Data))
+	for k := range scr.Data {
+		data[k] = []byte{}
+	}
+	scr.Data = data
+}
+
+// Describe get the app with the configs and secrets it references, the values of secrets are blanked
+func (a *applicationService) Describe(ctx context.Context, namespace, name, version string) (*models.ApplicationDescription, error) {
+	return a.DescribeWithOptions(ctx, namespace, name, version, nil)
+}
+
+// DescribeWithOptions get the app with the configs and secrets it references, the latest versions of them are read
+func (a *applicationService) DescribeWithOptions(ctx context.Context, namespace, name, version string, opts *models.DescribeOptions) (*models.ApplicationDescription, error) {
+	app, err := a.Get(ctx, namespace, name, version)
+	if err != nil {
+		return nil, err
+	}
+	desc := &models.ApplicationDescription{App: app}
+	configs, secrets := map[string]bool{}, map[string]bool{}
+	for _, v := range app.Volumes {
+		switch {
+		case v.Config != nil:
+			if configs[v.Config.Name] {
+				continue
+			}
+			configs[v.Config.Name] = true
+			cfg, err := a.modelStorage(ctx).GetConfig(namespace, v.Config.Name, "")
+			if goerrors.Is(err, plugin.ErrNotFound) {
+				desc.MissingConfigs = append(desc.MissingConfigs, v.Config.Name)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			desc.Configs = append(desc.Configs, *cfg)
+		case v.Secret != nil:
+			if secrets[v.Secret.Name] {
+				continue
+			}
+			secrets[v.Secret.Name] = true
+			scr, err := a.modelStorage(ctx).GetSecret(namespace, v.Secret.Name, "")
+			if goerrors.Is(err, plugin.ErrNotFound) {
+				desc.MissingSecrets = append(desc.MissingSecrets, v.Secret.Name)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if opts == nil || !opts.ShowSecrets {
+				redactSecret(scr)
+			}
+			desc.Secrets = append(desc.Secrets, *scr)
+		}
+	}
+	return desc, nil
+}
+
+// Import create the configs, secrets and application of bundle in namespace, the bundle is validated before any write.
+// The configs and secrets whose names are used are copied with suffixed names, or updated if opts.Overwrite is set.
+func (a *applicationService) Import(ctx context.Context, namespace string, bundle *models.ApplicationBundle, opts *models.ImportOptions) (*specV1.Application, error) {
+	if err := a.validBundle(ctx, namespace, bundle); err != nil {
+		return nil, err
+	}
+	overwrite := opts != nil && opts.Overwrite
+
+	configs := map[string]specV1.ObjectReference{}
+	for _, cfg := range bundle.Configs {
+		name := cfg.Name
+		ref, err := a.importConfig(ctx, namespace, cfg, overwrite)
+		if err != nil {
+			return nil, err
+		}
+		configs[name] = *ref
+	}
+	secrets := map[string]specV1.ObjectReference{}
+	for _, scr := range bundle.Secrets {
+		name := scr.Name
+		ref, err := a.importSecret(ctx, namespace, scr, overwrite)
+		if err != nil {
+			return nil, err
+		}
+		secrets[name] = *ref
+	}
+
+	app := *bundle.App
+	app.Namespace = namespace
+	app.Version = ""
+	app.CreationTimestamp = time.Time{}
+	app.Volumes = make([]specV1.Volume, 0, len(bundle.App.Volumes))
+	for _, v := range bundle.App.Volumes {
+		if v.Config != nil {
+			ref := configs[v.Config.Name]
+			v.Config = &ref
+		}
+		if v.Secret != nil {
+			ref := secrets[v.Secret.Name]
+			v.Secret = &ref
+		}
+		app.Volumes = append(app.Volumes, v)
+	}
+	return a.create(ctx, namespace, &app, nil)
+}
+
+// validBundle check the bundle can be imported into namespace
+func (a *applicationService) validBundle(ctx context.Context, namespace string, bundle *models.ApplicationBundle) error {
+	if bundle == nil || bundle.App == nil {
+		return common.Error(common.ErrRequestParamInvalid, common.Field("error", "the bundle has no app"))
+	}
+	if bundle.Format != models.ApplicationBundleFormat {
+		return common.Error(common.ErrRequestParamInvalid,
+			common.Field("error", fmt.Sprintf("the bundle format %s is not supported", bundle.Format)))
+	}
+	configs, secrets := map[string]bool{}, map[string]bool{}
+	for _, cfg := range bundle.Configs {
+		configs[cfg.Name] = true
+	}
+	for _, scr := range bundle.Secrets {
+		secrets[scr.Name] = true
+	}
+	for _, v := range bundle.App.Volumes {
+		if v.Config != nil && !configs[v.Config.Name] {
+			return common.Error(common.ErrRequestParamInvalid,
+				common.Field("error", fmt.Sprintf("the config %s of volume %s is not in the bundle", v.Config.Name, v.Name)))
+		}
+		if v.Secret != nil && !secrets[v.Secret.Name] {
+			return common.Error(common.ErrRequestParamInvalid,
+				common.Field("error", fmt.Sprintf("the secret %s of volume %s is not in the bundle", v.Secret.Name, v.Name)))
+		}
+	}
+
+	// the checks of create are run on a copy before anything is written, so a bad bundle imports nothing
+	app, err := copyApplication(bundle.App)
+	if err != nil {
+		return err
+	}
+	app.Namespace = namespace
+	if err = normalizeNames(app); err != nil {
+		return err
+	}
+	errs := &common.MultiError{}
+	errs.Append(a.validApp(ctx, namespace, app, false))
+	for _, v := range app.Volumes {
+		errs.Append(validVolumeSource(v))
+	}
+	if err = errs.ErrorOrNil(); err != nil {
+		return err
+	}
+	if _, err = a.modelStorage(ctx).GetApplication(namespace, app.Name, ""); err == nil {
+		return common.Error(common.ErrResourceConflict, common.Field("type", "app"), common.Field("name", app.Name))
+	}
+	return a.checkQuota(ctx, namespace, 1)
+}
+
+// importConfig create the config in namespace, the existing one of the same name is updated if overwrite is set
+func (a *applicationService) importConfig(ctx context.Context, namespace string, cfg specV1.Configuration, overwrite bool) (*specV1.ObjectReference, error) {
+	cfg.Namespace, cfg.Version = namespace, ""
+	if overwrite {
+		if current, err := a.modelStorage(ctx).GetConfig(namespace, cfg.Name, ""); err == nil {
+			cfg.Version = current.Version
+			res, err := a.modelStorage(ctx).UpdateConfig(namespace, &cfg)
+			if err != nil {
+				return nil, err
+			}
+			return &specV1.ObjectReference{Name: res.Name, Version: res.Version}, nil
+		}
+	}
+	ref := &specV1.ObjectReference{Name: cfg.Name}
+	err := a.constuctRef(namespace, namespace, common.Config, ref, func() (func(string) (*specV1.ObjectReference, error), error) {
+		return func(name string) (*specV1.ObjectReference, error) {
+			cfg.Name = name
+			res, err := a.modelStorage(ctx).CreateConfig(namespace, &cfg)
+			if err != nil {
+				return nil, err
+			}
+			return &specV1.ObjectReference{Name: res.Name, Version: res.Version}, nil
+		}, nil
+	})
+	return ref, err
+}
+
+// importSecret create the secret in namespace, the existing one of the same name is updated if overwrite is set
+func (a *applicationService) importSecret(ctx context.Context, namespace string, scr specV1.Secret, overwrite bool) (*specV1.ObjectReference, error) {
+	scr.Namespace, scr.Version = namespace, ""
+	if overwrite {
+		if current, err := a.modelStorage(ctx).GetSecret(namespace, scr.Name, ""); err == nil {
+			scr.Version = current.Version
+			res, err := a.modelStorage(ctx).UpdateSecret(namespace, &scr)
+			if err != nil {
+				return nil, err
+			}
+			return &specV1.ObjectReference{Name: res.Name, Version: res.Version}, nil
+		}
+	}
+	ref := &specV1.ObjectReference{Name: scr.Name}
+	err := a.constuctRef(namespace, namespace, common.Secret, ref, func() (func(string) (*specV1.ObjectReference, error), error) {
+		return func(name string) (*specV1.ObjectReference, error) {
+			scr.Name = name
+			res, err := a.modelStorage(ctx).CreateSecret(namespace, &scr)
+			if err != nil {
+				return nil, err
+			}
+			return &specV1.ObjectReference{Name: res.Name, Version: res.Version}, nil
+		}, nil
+	})
+	return ref, err
+}
+
+// mergeBases prepend the services and volumes of bases to app, the conflicts are resolved by strategy
+func mergeBases(app *specV1.Application, bases []*specV1.Application, strategy models.MergeStrategy) {
+	if len(bases) == 0 {
+		return
+	}
+	var services []specV1.Service
+	var volumes []specV1.Volume
+	for _, base := range bases {
+		services = append(services, base.Services...)
+		volumes = append(volumes, base.Volumes...)
+	}
+	services, app.Services = mergeServices(services, app.Services, strategy)
+	volumes, app.Volumes = mergeVolumes(volumes, app.Volumes, strategy)
+	app.Services = append(services, app.Services...)
+	app.Volumes = append(volumes, app.Volumes...)
+}
+
+// mergeServices drop the conflicted services of base or app by strategy, MergeError keeps both and lets validName fail
+func mergeServices(base, app []specV1.Service, strategy models.MergeStrategy) ([]specV1.Service, []specV1.Service) {
+	names := make(map[string]int)
+	for i, s := range app {
+		names[s.Name] = i
+	}
+	var resBase, resApp []specV1.Service
+	for _, s := range base {
+		i, ok := names[s.Name]
+		if ok && strategy == models.MergeOverride {
+			continue
+		}
+		if ok && strategy == models.MergeDeep {
+			s = deepMergeService(s, app[i])
+		}
+		resBase = append(resBase, s)
+	}
+	conflicted := make(map[string]bool)
+	for _, s := range base {
+		conflicted[s.Name] = true
+	}
+	for _, s := range app {
+		if conflicted[s.Name] && (strategy == models.MergeSkip || strategy == models.MergeDeep) {
+			continue
+		}
+		resApp = append(resApp, s)
+	}
+	return resBase, resApp
+}
+
+// deepMergeService merge the fields of app service into the base's, see models.MergeDeep
+func deepMergeService(base, app specV1.Service) specV1.Service {
+	res := base
+	if app.Hostname != "" {
+		res.Hostname = app.Hostname
+	}
+	if app.Image != "" {
+		res.Image = app.Image
+	}
+	if app.Replica != 0 {
+		res.Replica = app.Replica
+	}
+	if len(app.Ports) > 0 {
+		res.Ports = app.Ports
+	}
+	if len(app.Devices) > 0 {
+		res.Devices = app.Devices
+	}
+	if len(app.Args) > 0 {
+		res.Args = app.Args
+	}
+	if app.Resources != nil {
+		res.Resources = app.Resources
+	}
+	if app.Runtime != "" {
+		res.Runtime = app.Runtime
+	}
+	if app.SecurityContext != nil {
+		res.SecurityContext = app.SecurityContext
+	}
+	res.HostNetwork = base.HostNetwork || app.HostNetwork
+	if app.FunctionConfig != nil {
+		res.FunctionConfig = app.FunctionConfig
+	}
+	if len(app.Functions) > 0 {
+		res.Functions = app.Functions
+	}
+	if len(base.Labels)+len(app.Labels) > 0 {
+		res.Labels = make(map[string]string)
+		for k, v := range base.Labels {
+			res.Labels[k] = v
+		}
+		for k, v := range app.Labels {
+			res.Labels[k] = v
+		}
+	}
+
+	envs := make(map[string]int)
+	res.Env = nil
+	for _, e := range base.Env {
+		envs[e.Name] = len(res.Env)
+		res.Env = append(res.Env, e)
+	}
+	for _, e := range app.Env {
+		if i, ok := envs[e.Name]; ok {
+			res.Env[i].Value = e.Value
+			continue
+		}
+		envs[e.Name] = len(res.Env)
+		res.Env = append(res.Env, e)
+	}
+
+	mounts := make(map[string]int)
+	res.VolumeMounts = nil
+	for _, vm := range base.VolumeMounts {
+		mounts[vm.MountPath] = len(res.VolumeMounts)
+		res.VolumeMounts = append(res.VolumeMounts, vm)
+	}
+	for _, vm := range app.VolumeMounts {
+		if i, ok := mounts[vm.MountPath]; ok {
+			res.VolumeMounts[i] = vm
+			continue
+		}
+		mounts[vm.MountPath] = len(res.VolumeMounts)
+		res.VolumeMounts = append(res.VolumeMounts, vm)
+	}
+	return res
+}
+
+// mergeVolumes the same as mergeServices for volumes
+func mergeVolumes(base, app []specV1.Volume, strategy models.MergeStrategy) ([]specV1.Volume, []specV1.Volume) {
+	names := make(map[string]bool)
+	for _, v := range app {
+		names[v.Name] = true
+	}
+	var resBase, resApp []specV1.Volume
+	for _, v := range base {
+		if (strategy == models.MergeOverride || strategy == models.MergeDeep) && names[v.Name] {
+			continue
+		}
+		resBase = append(resBase, v)
+	}
+	names = make(map[string]bool)
+	for _, v := range base {
+		names[v.Name] = true
+	}
+	for _, v := range app {
+		if strategy == models.MergeSkip && names[v.Name] {
+			continue
+		}
+		resApp = append(resApp, v)
+	}
+	return resBase, resApp
+}
+
+// ResolveBases the bases of an app are named by its label LabelBaseApp, they are returned root first in the order merged
+// and end with the app itself. A chain running into an app on its own path returns ErrCircularReference
+func (a *applicationService) ResolveBases(ctx context.Context, namespace, name string) ([]*specV1.Application, error) {
+	var bases []*specV1.Application
+	resolved := map[string]bool{}
+	var resolve func(ref config.AppBaseRef, path []string) error
+	resolve = func(ref config.AppBaseRef, path []string) error {
+		key := ref.Namespace + "/" + ref.Name
+		path = append(path, key)
+		for _, p := range path[:len(path)-1] {
+			if p == key {
+				return common.Error(common.ErrCircularReference,
+					common.Field("type", common.Application),
+					common.Field("name", key),
+					common.Field("cycle", strings.Join(path, " -> ")))
+			}
+		}
+		// a base shared by several bases is returned once
+		if resolved[key] {
+			return nil
+		}
+		app, err := a.Get(ctx, ref.Namespace, ref.Name, "")
+		if err != nil {
+			return err
+		}
+		for _, base := range appBases(ref.Namespace, app) {
+			if err = resolve(base, path); err != nil {
+				return err
+			}
+		}
+		resolved[key] = true
+		bases = append(bases, app)
+		return nil
+	}
+	if err := resolve(config.AppBaseRef{Namespace: namespace, Name: name}, nil); err != nil {
+		return nil, err
+	}
+	return bases, nil
+}
+
+// validBaseNames check that the service and volume names aren't conflicted between bases
+func validBaseNames(bases []*specV1.Application) error {
+	errs := &common.MultiError{}
+	sf, vf := make(map[string]bool), make(map[string]bool)
+	for i, base := range bases {
+		for j, s := range base.Services {
+			if sf[s.Name] {
+				errs.Append(common.Error(common.ErrAppNameConflict,
+					common.Field("where", fmt.Sprintf("bases[%d](%s).Services[%d]", i, base.Name, j)),
+					common.Field("name", s.Name)))
+			}
+			sf[s.Name] = true
+		}
+		for j, v := range base.Volumes {
+			if vf[v.Name] {
+				errs.Append(common.Error(common.ErrAppNameConflict,
+					common.Field("where", fmt.Sprintf("bases[%d](%s).Volumes[%d]", i, base.Name, j)),
+					common.Field("name", v.Name)))
+			}
+			vf[v.Name] = true
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// Rollback restore application to a version recorded in history
+func (a *applicationService) Rollback(ctx context.Context, namespace, name, targetVersion string) (*specV1.Application, error) {
+	if err := a.historyEnabled("rollback"); err != nil {
+		return nil, err
+	}
+	history, err := a.historyStorage(ctx).GetApplication(name, namespace, targetVersion)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "app"),
+			common.Field("name", name), common.Field("namespace", namespace))
+	}
+
+	current, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the restored spec is written over the current version, storage will assign a new one
+	history.Namespace = namespace
+	history.Version = current.Version
+	return a.UpdateWithOptions(ctx, namespace, history, nil)
+}
+
+// SetCanary the split is stored in the labels of app, see common.LabelCanaryStable, so it is synced to nodes with the app.
+// The versions must exist, the stable version defaults to the current one
+func (a *applicationService) SetCanary(ctx context.Context, namespace, name, stableVersion, canaryVersion string, weight int) error {
+	if weight < 0 || weight > 100 {
+		return common.Error(common.ErrRequestParamInvalid,
+			common.Field("error", fmt.Sprintf("the canary weight %d is out of range [0, 100]", weight)))
+	}
+	current, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return err
+	}
+	app, err := copyApplication(current)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{}
+	for k, v := range current.Labels {
+		if k != common.LabelCanaryStable && k != common.LabelCanaryVersion && k != common.LabelCanaryWeight {
+			labels[k] = v
+		}
+	}
+	if canaryVersion != "" {
+		if stableVersion == "" {
+			stableVersion = current.Version
+		}
+		stableVersion, canaryVersion = canonicalVersion(stableVersion), canonicalVersion(canaryVersion)
+		if sameVersion(stableVersion, canaryVersion) {
+			return common.Error(common.ErrRequestParamInvalid,
+				common.Field("error", "the canary version is the same as the stable one "+stableVersion))
+		}
+		for _, v := range []string{stableVersion, canaryVersion} {
+			if _, err = a.getVersion(ctx, current, v); err != nil {
+				return err
+			}
+		}
+		labels[common.LabelCanaryStable] = stableVersion
+		labels[common.LabelCanaryVersion] = canaryVersion
+		labels[common.LabelCanaryWeight] = strconv.Itoa(weight)
+	}
+	app.Labels = labels
+	_, err = a.UpdateWithOptions(ctx, namespace, app, nil)
+	return err
+}
+
+// GetCanary the current version is stable if there is no canary
+func (a *applicationService) GetCanary(ctx context.Context, namespace, name string) (*models.ApplicationCanary, error) {
+	app, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return nil, err
+	}
+	canary := app.Labels[common.LabelCanaryVersion]
+	if canary == "" {
+		return &models.ApplicationCanary{Stable: app.Version}, nil
+	}
+	weight, err := strconv.Atoi(app.Labels[common.LabelCanaryWeight])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the canary weight of app %s: %s", name, err.Error())
+	}
+	return &models.ApplicationCanary{Stable: app.Labels[common.LabelCanaryStable], Canary: canary, Weight: weight}, nil
+}
+
+// GetDeploymentStatus count the target nodes of the app by whether they run its current version
+func (a *applicationService) GetDeploymentStatus(ctx context.Context, namespace, name string) (*models.DeploymentStatus, error) {
+	if a.shadow == nil {
+		return nil, common.Error(common.ErrNotSupported, common.Field("name", "deployment status"),
+			common.Field("error", "no shadow storage is configured"))
+	}
+	app, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := a.indexService.ListNodesByApp(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.DeploymentStatus{Name: app.Name, Version: app.Version, Total: len(nodes)}
+	if len(nodes) == 0 {
+		return status, nil
+	}
+	nodeList := &models.NodeList{Items: make([]specV1.Node, 0, len(nodes))}
+	for _, n := range nodes {
+		nodeList.Items = append(nodeList.Items, specV1.Node{Namespace: namespace, Name: n})
+	}
+	shadows, err := a.shadow.List(namespace, nodeList)
+	if err != nil {
+		return nil, err
+	}
+	shadowMap := toShadowMap(shadows)
+	for _, n := range nodes {
+		var stats *specV1.AppStats
+		if shadow, ok := shadowMap[n]; ok && shadow.Report != nil {
+			for _, s := range shadow.Report.AppStats(app.System) {
+				if s.Name == app.Name {
+					s := s
+					stats = &s
+					break
+				}
+			}
+		}
+		switch {
+		case stats == nil:
+			status.Pending++
+		case stats.Status == specV1.Failed:
+			status.Failed++
+		case !sameVersion(stats.Version, app.Version):
+			status.Outdated++
+		case stats.Status == specV1.Running:
+			status.UpToDate++
+		default:
+			status.Pending++
+		}
+	}
+	return status, nil
+}
+
+// ListHistory list versions of application recorded in history, newest first.
+// The versions of deleted application are also listed, the operator of each version is who made its last change.
+func (a *applicationService) ListHistory(ctx context.Context, namespace, name string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	if err := a.historyEnabled("list history"); err != nil {
+		return nil, err
+	}
+	// the defaults are applied to a copy, the options of the caller are left as they are
+	opts := models.ListOptions{}
+	if listOptions != nil {
+		opts = *listOptions
+	}
+	listOptions = &opts
+	if listOptions.PageNo <= 0 {
+		listOptions.PageNo = 1
+	}
+	if listOptions.PageSize <= 0 {
+		listOptions.PageSize = 20
+	}
+
+	total, err := a.historyStorage(ctx).CountApplication(nil, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := a.historyStorage(ctx).ListApplicationHistory(name, namespace, listOptions.PageNo, listOptions.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.ApplicationList{
+		Total:       total,
+		ListOptions: listOptions,
+		Items:       make([]models.AppItem, 0),
+	}
+	for i := range histories {
+		item := toAppItem(&histories[i].App)
+		item.Operator = histories[i].Operator
+		res.Items = append(res.Items, item)
+	}
+	return res, nil
+}
+
+// ListHistoryByTime list versions of application created in [start, end), newest first.
+// start is inclusive and end is exclusive, there is no upper bound if end is zero.
+func (a *applicationService) ListHistoryByTime(ctx context.Context, namespace, name string, start, end time.Time) (*models.ApplicationList, error) {
+	if err := a.historyEnabled("list history"); err != nil {
+		return nil, err
+	}
+	if !end.IsZero() && !start.Before(end) {
+		return nil, common.Error(common.ErrRequestParamInvalid, common.Field("error", "start should be before end"))
+	}
+	apps, err := a.historyStorage(ctx).ListApplicationByTime(name, namespace, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.ApplicationList{
+		Total:       len(apps),
+		ListOptions: &models.ListOptions{},
+		Items:       make([]models.AppItem, 0),
+	}
+	for i := range apps {
+		res.Items = append(res.Items, toAppItem(&apps[i]))
+	}
+	return res, nil
+}
+
+// PruneHistory delete all but the newest keep versions from history, the active version is always kept besides them
+func (a *applicationService) PruneHistory(ctx context.Context, namespace, name string, keep int) (int, error) {
+	if err := a.historyEnabled("prune history"); err != nil {
+		return 0, err
+	}
+	if keep < 0 {
+		return 0, common.Error(common.ErrRequestParamInvalid, common.Field("error", "keep should not be negative"))
+	}
+	app, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return 0, err
+	}
+	res, err := a.historyStorage(ctx).PruneApplication(name, namespace, app.Version, keep)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// Diff compare services, volumes and volume mounts of two versions of application
+func (a *applicationService) Diff(ctx context.Context, namespace, name, fromVersion, toVersion string) (*models.ApplicationDiff, error) {
+	current, err := a.Get(ctx, namespace, name, "")
+	if err != nil {
+		return nil, err
+	}
+	from, err := a.getVersion(ctx, current, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := a.getVersion(ctx, current, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ApplicationDiff{
+		Name:        name,
+		Namespace:   namespace,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     diffApplication(from, to),
+	}, nil
+}
+
+// Validate check application without persisting it, all violations are returned together
+func (a *applicationService) Validate(ctx context.Context, namespace string, app *specV1.Application) error {
+	if err := normalizeNames(app); err != nil {
+		return err
+	}
+	errs := &common.MultiError{}
+	if app.Namespace != "" && app.Namespace != namespace {
+		errs.Append(bindNamespace(namespace, app))
+	}
+	errs.Append(a.validApp(ctx, namespace, app, false))
+	errs.Append(a.validReferences(ctx, namespace, app))
+	return errs.ErrorOrNil()
+}
+
+// validReferences check the configs and secrets referenced by the volumes of app as getConfigsAndSecrets,
+// but all violations are returned together and app is not changed
+func (a *applicationService) validReferences(ctx context.Context, namespace string, app *specV1.Application) error {
+	errs := &common.MultiError{}
+	for _, v := range app.Volumes {
+		if err := validVolumeSource(v); err != nil {
+			errs.Append(err)
+			continue
+		}
+		if v.Config != nil {
+			config, err := a.modelStorage(ctx).GetConfig(namespace, v.Config.Name, "")
+			if err != nil {
+				errs.Append(a.toVolumeSourceError(ctx, err, namespace, v.Name, common.Config, v.Config.Name))
+			} else {
+				errs.Append(sameNamespace(namespace, v.Name, common.Config, v.Config.Name, config.Namespace))
+			}
+		}
+		if v.Secret != nil {
+			secret, err := a.modelStorage(ctx).GetSecret(namespace, v.Secret.Name, "")
+			if err != nil {
+				errs.Append(a.toVolumeSourceError(ctx, err, namespace, v.Name, common.Secret, v.Secret.Name))
+			} else {
+				errs.Append(sameNamespace(namespace, v.Name, common.Secret, v.Secret.Name, secret.Namespace))
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// ResolveReferences the configs and secrets the app would be indexed by with their resolved versions, nothing is persisted
+func (a *applicationService) ResolveReferences(ctx context.Context, namespace string, app *specV1.Application) ([]models.ResolvedReference, []models.ResolvedReference, error) {
+	resolved, err := copyApplication(app)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = normalizeNames(resolved); err != nil {
+		return nil, nil, err
+	}
+	if _, _, _, err = a.getConfigsAndSecrets(ctx, namespace, resolved); err != nil {
+		return nil, nil, err
+	}
+	var configs, secrets []models.ResolvedReference
+	for _, v := range resolved.Volumes {
+		if v.Config != nil {
+			configs = append(configs, models.ResolvedReference{Volume: v.Name, Name: v.Config.Name, Version: v.Config.Version})
+		}
+		if v.Secret != nil {
+			secrets = append(secrets, models.ResolvedReference{Volume: v.Name, Name: v.Secret.Name, Version: v.Secret.Version})
+		}
+	}
+	return configs, secrets, nil
+}
+
+// getVersion get the specified version of application, the current one is read from storage and others from history
+func (a *applicationService) getVersion(ctx context.Context, current *specV1.Application, version string) (*specV1.Application, error) {
+	if version == "" || sameVersion(version, current.Version) {
+		return current, nil
+	}
+	if err := a.historyEnabled("get version from history"); err != nil {
+		return nil, err
+	}
+	var app *specV1.Application
+	for _, v := range versionForms(version) {
+		var err error
+		if app, err = a.historyStorage(ctx).GetApplication(current.Name, current.Namespace, v); err != nil {
+			return nil, err
+		}
+		if app != nil {
+			break
+		}
+	}
+	if app == nil {
+		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "app"),
+			common.Field("name", current.Name+"@"+version), common.Field("namespace", current.Namespace))
+	}
+	return app, nil
+}
+
+// canonicalVersion the canonical form of a version is the decimal resource version issued by the model storage,
+// such as "12". The prefixed form "v12" and leading zeros are accepted, versions of other forms are kept as they are.
+func canonicalVersion(version string) string {
+	v := strings.TrimSpace(version)
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') {
+		v = v[1:]
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return version
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// versionForms the forms which a version may be stored in history, the canonical one first
+func versionForms(version string) []string {
+	canonical := canonicalVersion(version)
+	if !isDecimal(canonical) {
+		return []string{version}
+	}
+	return []string{canonical, "v" + canonical}
+}
+
+func isDecimal(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// Clone copy application as a new one, the referenced configs and secrets are also copied if namespace differs
+func (a *applicationService) Clone(ctx context.Context, srcNamespace, name, version, dstNamespace, newName string) (*specV1.Application, error) {
+	current, err := a.Get(ctx, srcNamespace, name, "")
+	if err != nil {
+		return nil, err
+	}
+	src, err := a.getVersion(ctx, current, version)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = a.Get(ctx, dstNamespace, newName, "")
+	if err == nil {
+		return nil, common.Error(common.ErrAppNameConflict,
+			common.Field("where", dstNamespace),
+			common.Field("name", newName))
+	}
+	if e, ok := err.(errors.Coder); !ok || e.Code() != common.ErrResourceNotFound {
+		return nil, err
+	}
+
+	app, err := copyApplication(src)
+	if err != nil {
+		return nil, err
+	}
+	app.Namespace = srcNamespace
+	if dstNamespace != srcNamespace {
+		if err = a.constuctConfig(ctx, dstNamespace, app); err != nil {
+			return nil, err
+		}
+		// the bases in srcNamespace are named with it in dstNamespace
+		if refs := appBases(srcNamespace, app); len(refs) > 0 {
+			app.Labels[common.LabelBaseApp] = formatBases(dstNamespace, refs)
+		}
+	}
+
+	app.Name = newName
+	app.Namespace = dstNamespace
+	app.Version = ""
+	app.CreationTimestamp = time.Time{}
+	return a.Create(ctx, dstNamespace, app)
+}
+
+// constuctConfig copy the configs and secrets referenced by base into namespace, the volumes of base are updated to reference the copies
+func (a *applicationService) constuctConfig(ctx context.Context, namespace string, base *specV1.Application) error {
+	for _, v := range base.Volumes {
+		if v.Config != nil {
+			err := a.constuctRef(namespace, base.Namespace, common.Config, v.Config, func() (func(string) (*specV1.ObjectReference, error), error) {
+				cfg, err := a.modelStorage(ctx).GetConfig(base.Namespace, v.Config.Name, "")
+				if err != nil {
+					return nil, err
+				}
+				digest, err := configDigest(cfg)
+				if err != nil {
+					return nil, err
+				}
+				if shared := a.sharedConfig(ctx, namespace, cfg, digest); shared != nil {
+					return func(string) (*specV1.ObjectReference, error) {
+						return shared, nil
+					}, nil
+				}
+				if cfg.Labels == nil {
+					cfg.Labels = map[string]string{}
+				}
+				cfg.Labels[common.LabelConfigDigest] = digest
+				return func(name string) (*specV1.ObjectReference, error) {
+					cfg.Name = name
+					config, err := a.modelStorage(ctx).CreateConfig(namespace, cfg)
+					if err != nil {
+						return nil, err
+					}
+					return &specV1.ObjectReference{Name: config.Name, Version: config.Version}, nil
+				}, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return a.constuctSecret(ctx, namespace, base)
+}
+
+// configDigest the digest of the data of config, it fits in a label value
+func configDigest(cfg *specV1.Configuration) (string, error) {
+	data, err := json.Marshal(cfg.Data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// sharedConfig the config in namespace copied before with the same data as cfg, it is nil if there is none.
+// The data is compared since a shared config may be updated afterwards, failures only cause a new copy.
+func (a *applicationService) sharedConfig(ctx context.Context, namespace string, cfg *specV1.Configuration, digest string) *specV1.ObjectReference {
+	list, err := a.modelStorage(ctx).ListConfig(namespace, &models.ListOptions{
+		LabelSelector: common.LabelConfigDigest + "=" + digest,
+	})
+	if err != nil {
+		log.L().Warn("failed to list shared configs",
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("digest", digest),
+			log.Error(err))
+		return nil
+	}
+	for _, c := range list.Items {
+		if sameData(c.Data, cfg.Data) {
+			return &specV1.ObjectReference{Name: c.Name, Version: c.Version}
+		}
+	}
+	return nil
+}
+
+func sameData(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *applicationService) constuctSecret(ctx context.Context, namespace string, base *specV1.Application) error {
+	for _, v := range base.Volumes {
+		if v.Secret != nil {
+			err := a.constuctRef(namespace, base.Namespace, common.Secret, v.Secret, func() (func(string) (*specV1.ObjectReference, error), error) {
+				scr, err := a.modelStorage(ctx).GetSecret(base.Namespace, v.Secret.Name, "")
+				if err != nil {
+					return nil, err
+				}
+				return func(name string) (*specV1.ObjectReference, error) {
+					scr.Name = name
+					secret, err := a.modelStorage(ctx).CreateSecret(namespace, scr)
+					if err != nil {
+						return nil, err
+					}
+					return &specV1.ObjectReference{Name: secret.Name, Version: secret.Version}, nil
+				}, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// constuctRef get the object referenced in baseNamespace and create it in namespace, with a suffix if the name is used
+func (a *applicationService) constuctRef(namespace, baseNamespace string, tp common.Resource, ref *specV1.ObjectReference,
+	get func() (func(name string) (*specV1.ObjectReference, error), error)) error {
+	create, err := get()
+	if err != nil {
+		log.L().Error("failed to get system "+string(tp),
+			log.Any(common.KeyContextNamespace, baseNamespace),
+			log.Any("name", ref.Name))
+		return common.Error(common.ErrResourceNotFound,
+			common.Field("type", tp),
+			common.Field(common.KeyContextNamespace, baseNamespace),
+			common.Field("name", ref.Name))
+	}
+
+	name := ref.Name
+	res, err := create(name)
+	for attempt := 1; err != nil; attempt++ {
+		log.L().Error("failed to create user "+string(tp),
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("name", name),
+			log.Error(err))
+		// the volume must reference the suffixed one which is actually created
+		if name = a.copyName(ref.Name, attempt); name == "" {
+			return err
+		}
+		res, err = create(name)
+	}
+	ref.Name = res.Name
+	ref.Version = res.Version
+	return nil
+}
+
+// copyName the name to retry creating the copy of name after attempt failures, it is empty if no more retry
+func (a *applicationService) copyName(name string, attempt int) string {
+	conf := a.conf.CopyName
+	if conf.Strategy == config.CopyNameCounter {
+		max := conf.MaxAttempts
+		if max <= 0 {
+			max = 10
+		}
+		if attempt > max {
+			return ""
+		}
+		return fmt.Sprintf("%s-%d", name, attempt)
+	}
+	if attempt > 1 {
+		return ""
+	}
+	length := conf.Length
+	if length <= 0 {
+		length = 9
+	}
+	return name + "-" + common.RandString(length)
+}
+
+func (a *applicationService) storeHistory(ctx context.Context, app *specV1.Application, operator string) {
+	if a.conf.History.Disabled {
+		return
+	}
+	// the app is stored already, its history is stored even if ctx is done
+	a.retryHistory("store application to db error", func() error {
+		_, err := a.historyStorage(detachedContext{ctx}).CreateApplicationHistory(app, operator)
+		return err
+	}, log.Any("name", app.Name),
+		log.Any("namespace", app.Namespace),
+		log.Any("version", app.Version),
+		log.Any("operator", operator))
+}
+
+// historyEnabled return ErrNotSupported for the operation if history is disabled
+func (a *applicationService) historyEnabled(operation string) error {
+	if a.conf.History.Disabled {
+		return common.Error(common.ErrNotSupported, common.Field("name", operation),
+			common.Field("error", "application history is disabled"))
+	}
+	return nil
+}
+
+// publish send the event of application to the sink if set. err can ignore, it is logged
+func (a *applicationService) publish(tp models.ApplicationEventType, namespace, name, version string) {
+	if a.events == nil {
+		return
+	}
+	event := &models.ApplicationEvent{
+		Type:      tp,
+		Namespace: namespace,
+		Name:      name,
+		Version:   version,
+		Time:      time.Now(),
+	}
+	if err := a.events.Publish(event); err != nil {
+		log.L().Error("publish application event error",
+			log.Any("type", tp),
+			log.Any("namespace", namespace),
+			log.Any("name", name),
+			log.Any("version", version),
+			log.Error(err))
+	}
+}
+
+// retryHistory write application history with exponential backoff. err can ignore, it is logged and counted
+func (a *applicationService) retryHistory(msg string, write func() error, fields ...log.Field) {
+	err := common.Retry(a.conf.HistoryRetryAttempts, a.conf.HistoryRetryDelay, write)
+	if err != nil {
+		appHistoryLost.Inc()
+		log.L().Error(msg, append(fields, log.Error(err))...)
+	}
+}
+
+// checkQuota check that n more applications can be created in namespace
+func (a *applicationService) checkQuota(ctx context.Context, namespace string, n int) error {
+	limit := a.conf.Quota.Limit
+	if l, ok := a.conf.Quota.Namespaces[namespace]; ok {
+		limit = l
+	}
+	if limit <= 0 {
+		return nil
+	}
+	count, err := a.countStored(ctx, namespace, "")
+	if err != nil {
+		return err
+	}
+	if count+n > limit {
+		return common.Error(common.ErrQuotaExceeded, common.Field("type", "app"),
+			common.Field("namespace", namespace), common.Field("limit", limit))
+	}
+	return nil
+}
+
+// configTypeFunction the type in the metadata of the function data of configs, see api.ConfigTypeFunction
+const configTypeFunction = "function"
+
+// validFunctions check that the functions of the function configs referenced by volumes exist in one of the function sources.
+// The functions belong to the user who made the config, or the namespace if the user is not recorded.
+// Nothing is checked without function source, the missing configs are left to the checks of references
+func (a *applicationService) validFunctions(ctx context.Context, namespace string, app *specV1.Application) error {
+	if a.conf.SkipFunctionCheck || a.functionService == nil || len(a.sources) == 0 {
+		return nil
+	}
+	for _, vol := range app.Volumes {
+		if vol.Config == nil {
+			continue
+		}
+		cfg, err := a.modelStorage(ctx).GetConfig(namespace, vol.Config.Name, "")
+		if err != nil {
+			if goerrors.Is(err, plugin.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		for k, v := range cfg.Data {
+			if !strings.HasPrefix(k, common.ConfigObjectPrefix) {
+				continue
+			}
+			var obj specV1.ConfigurationObject
+			if err = json.Unmarshal([]byte(v), &obj); err != nil {
+				return err
+			}
+			if obj.Metadata["type"] != configTypeFunction || obj.Metadata["function"] == "" {
+				continue
+			}
+			user := obj.Metadata["userID"]
+			if user == "" {
+				user = namespace
+			}
+			if err = a.validFunction(ctx, user, obj.Metadata["function"], obj.Metadata["version"]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validFunction check that the version of function exists in one of the function sources, empty version means the latest
+func (a *applicationService) validFunction(ctx context.Context, user, name, version string) error {
+	for _, source := range a.sources {
+		_, err := a.functionService.GetFunction(ctx, user, name, version, source)
+		if err == nil {
+			return nil
+		}
+		if e, ok := err.(errors.Coder); !ok || e.Code() != common.ErrResourceNotFound {
+			return err
+		}
+	}
+	if version != "" {
+		name = name + ":" + version
+	}
+	return common.Error(common.ErrResourceNotFound,
+		common.Field("type", "function"),
+		common.Field("name", name),
+		common.Field(common.KeyContextNamespace, user))
+}
+
+// get App configs and secrets, and the pinned configs which have newer versions
+func (a *applicationService) getConfigsAndSecrets(ctx context.Context, namespace string, app *specV1.Application) ([]string, []string, []models.ConfigDrift, error) {
+	var configs []string
+	var secrets []string
+	var drifts []models.ConfigDrift
+	pinned := app.Labels[common.LabelConfigPinned] == "true"
+	for _, vol := range app.Volumes {
+		if err := validVolumeSource(vol); err != nil {
+			return nil, nil, nil, err
+		}
+		if vol.Config != nil {
+			config, err := a.modelStorage(ctx).GetConfig(namespace, vol.Config.Name, "")
+			if err != nil {
+				return nil, nil, nil, a.toVolumeSourceError(ctx, err, namespace, vol.Name, common.Config, vol.Config.Name)
+			}
+			if err = sameNamespace(namespace, vol.Name, common.Config, vol.Config.Name, config.Namespace); err != nil {
+				return nil, nil, nil, err
+			}
+			if !pinned || vol.Config.Version == "" {
+				// set the lastest config version
+				vol.Config.Version = config.Version
+			} else if !sameVersion(vol.Config.Version, config.Version) {
+				drifts = append(drifts, models.ConfigDrift{
+					Volume:  vol.Name,
+					Name:    vol.Config.Name,
+					Version: vol.Config.Version,
+					Latest:  config.Version,
+				})
+			}
+			configs = append(configs, vol.Config.Name)
+		}
+		if vol.Secret != nil {
+			secret, err := a.modelStorage(ctx).GetSecret(namespace, vol.Secret.Name, "")
+			if err != nil {
+				return nil, nil, nil, a.toVolumeSourceError(ctx, err, namespace, vol.Name, common.Secret, vol.Secret.Name)
+			}
+			if err = sameNamespace(namespace, vol.Name, common.Secret, vol.Secret.Name, secret.Namespace); err != nil {
+				return nil, nil, nil, err
+			}
+			vol.Secret.Version = secret.Version
+			secrets = append(secrets, vol.Secret.Name)
+		}
+	}
+
+	return configs, secrets, drifts, nil
+}
+
+// bindNamespace set the namespace of app to the one it's created in, an app of another namespace is rejected
+func bindNamespace(namespace string, app *specV1.Application) error {
+	if app.Namespace != "" && app.Namespace != namespace {
+		return common.Error(common.ErrRequestParamInvalid,
+			common.Field("error", fmt.Sprintf("the namespace (%s) of app %s is not the namespace (%s) it's created in", app.Namespace, app.Name, namespace)))
+	}
+	app.Namespace = namespace
+	return nil
+}
+
+// normalizeNames trim the surrounding whitespace of the names of app, services and volumes (and the volumes mounted)
+// before they are checked, a name of only whitespace is invalid
+func normalizeNames(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	trim := func(where string, name *string) {
+		if *name == "" {
+			return
+		}
+		if *name = strings.TrimSpace(*name); *name == "" {
+			errs.Append(common.Error(common.ErrInvalidName,
+				common.Field("where", where),
+				common.Field("error", "the name is only whitespace")))
+		}
+	}
+	trim("app", &app.Name)
+	for i := range app.Services {
+		trim(fmt.Sprintf("Services[%d]", i), &app.Services[i].Name)
+		for j := range app.Services[i].VolumeMounts {
+			trim(fmt.Sprintf("Services[%d].VolumeMounts[%d]", i, j), &app.Services[i].VolumeMounts[j].Name)
+		}
+	}
+	for i := range app.Volumes {
+		trim(fmt.Sprintf("Volumes[%d]", i), &app.Volumes[i].Name)
+	}
+	return errs.ErrorOrNil()
+}
+
+// sameNamespace check the config or secret referenced by volume resolves in the namespace of app, the ones of bases
+// in other namespaces must be copied in by constuctConfig. The empty namespace is left by storages which don't record it.
+func sameNamespace(namespace, volume string, tp common.Resource, name, resolved string) error {
+	if resolved == "" || resolved == namespace {
+		return nil
+	}
+	return common.Error(common.ErrCrossNamespaceReference, common.Field("type", tp), common.Field("name", name),
+		common.Field("volume", volume), common.Field("namespace", namespace), common.Field("other", resolved))
+}
+
+// validDNSNames check the names of app, services and volumes are DNS-1123 labels, they are turned into kubernetes objects on nodes
+func validDNSNames(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	check := func(where, name string) {
+		if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+			errs.Append(common.Error(common.ErrInvalidName,
+				common.Field("where", where),
+				common.Field("name", name),
+				common.Field("error", strings.Join(msgs, "; "))))
+		}
+	}
+	check("app", app.Name)
+	for i, s := range app.Services {
+		check(fmt.Sprintf("Services[%d]", i), s.Name)
+	}
+	for i, v := range app.Volumes {
+		check(fmt.Sprintf("Volumes[%d]", i), v.Name)
+	}
+	return errs.ErrorOrNil()
+}
+
+func (a *applicationService) validName(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	sf, vf := make(map[string]bool), make(map[string]bool)
+	for i, v := range app.Volumes {
+		if _, ok := vf[v.Name]; ok {
+			errs.Append(common.Error(common.ErrAppNameConflict,
+				common.Field("where", fmt.Sprintf("Volumes[%d]", i)),
+				common.Field("name", v.Name)))
+			continue
+		}
+
+		vf[v.Name] = true
+	}
+
+	for i, s := range app.Services {
+		if _, ok := sf[s.Name]; ok {
+			errs.Append(common.Error(common.ErrAppNameConflict,
+				common.Field("where", fmt.Sprintf("Services[%d]", i)),
+				common.Field("name", s.Name)))
+		}
+		mf := make(map[string]bool)
+		for j, vm := range s.VolumeMounts {
+			if _, ok := vf[vm.Name]; !ok {
+				errs.Append(common.Error(common.ErrVolumeNotFoundWhenMount,
+					common.Field("where", fmt.Sprintf("Services[%d].VolumeMounts[%d]", i, j)),
+					common.Field("name", vm.Name)))
+			}
+			if _, ok := mf[vm.MountPath]; ok {
+				errs.Append(common.Error(common.ErrVolumeMountPathConflict,
+					common.Field("name", s.Name),
+					common.Field("path", vm.MountPath)))
+			}
+			mf[vm.MountPath] = true
+		}
+		sf[s.Name] = true
+	}
+	errs.Append(validatePorts(app))
+	errs.Append(validNodeSelector(app))
+	errs.Append(validateEnv(app))
+	errs.Append(validateResources(app))
+	errs.Append(validateImages(app, false))
+	errs.Append(a.validSpecSize(app))
+
+	return errs.ErrorOrNil()
+}
+
+// validSpecSize check the app against the limits, a huge spec breaks both storage and the sync of nodes
+func (a *applicationService) validSpecSize(app *specV1.Application) error {
+	limit := a.conf.Limit
+	errs := &common.MultiError{}
+	if limit.Services > 0 && len(app.Services) > limit.Services {
+		errs.Append(common.Error(common.ErrSpecTooLarge, common.Field("name", app.Name),
+			common.Field("type", "services"), common.Field("size", len(app.Services)), common.Field("limit", limit.Services)))
+	}
+	if limit.Volumes > 0 && len(app.Volumes) > limit.Volumes {
+		errs.Append(common.Error(common.ErrSpecTooLarge, common.Field("name", app.Name),
+			common.Field("type", "volumes"), common.Field("size", len(app.Volumes)), common.Field("limit", limit.Volumes)))
+	}
+	if limit.SpecBytes > 0 {
+		data, err := json.Marshal(app)
+		if err != nil {
+			return err
+		}
+		if len(data) > limit.SpecBytes {
+			errs.Append(common.Error(common.ErrSpecTooLarge, common.Field("name", app.Name),
+				common.Field("size", len(data)), common.Field("limit", limit.SpecBytes)))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// appWarnings the problems of app which are allowed but worth a look
+func appWarnings(app *specV1.Application) []models.ApplicationWarning {
+	var warnings []models.ApplicationWarning
+	for i, s := range app.Services {
+		if m := imageRefRegexp.FindStringSubmatch(s.Image); m != nil && m[2] == "" && m[3] == "" {
+			warnings = append(warnings, models.ApplicationWarning{
+				Code:    models.WarnImageLatest,
+				Where:   fmt.Sprintf("Services[%d].Image", i),
+				Name:    s.Name,
+				Message: fmt.Sprintf("the image %s has no tag or digest, the latest is pulled", s.Image),
+			})
+		}
+	}
+	for _, name := range unusedVolumes(app) {
+		warnings = append(warnings, models.ApplicationWarning{
+			Code:    models.WarnUnusedVolume,
+			Name:    name,
+			Message: fmt.Sprintf("the volume %s is not mounted by any service", name),
+		})
+	}
+	for _, where := range writableSecretMounts(app) {
+		warnings = append(warnings, models.ApplicationWarning{
+			Code:    models.WarnSecretMountWritable,
+			Where:   where,
+			Message: "the secret volume is mounted writable, it should be read-only",
+		})
+	}
+	if _, ok := app.Labels[common.LabelConfigPinned]; !ok {
+		for i, v := range app.Volumes {
+			if v.Config == nil {
+				continue
+			}
+			warnings = append(warnings, models.ApplicationWarning{
+				Code:    models.WarnFloatingConfig,
+				Where:   fmt.Sprintf("Volumes[%d].Config", i),
+				Name:    v.Name,
+				Message: fmt.Sprintf("the volume %s follows the latest version of config %s", v.Name, v.Config.Name),
+			})
+		}
+	}
+	return warnings
+}
+
+// unusedVolumes return the names of volumes which no service mounts, they are usually typos of mount names
+func unusedVolumes(app *specV1.Application) []string {
+	mounted := make(map[string]bool)
+	for _, s := range app.Services {
+		for _, vm := range s.VolumeMounts {
+			mounted[vm.Name] = true
+		}
+	}
+	var unused []string
+	for _, v := range app.Volumes {
+		if !mounted[v.Name] {
+			unused = append(unused, v.Name)
+		}
+	}
+	return unused
+}
+
+// writableSecretMounts return the mounts of secret volumes which are not read-only, such as Services[0].VolumeMounts[1]
+func writableSecretMounts(app *specV1.Application) []string {
+	secrets := make(map[string]bool)
+	for _, v := range app.Volumes {
+		if v.Secret != nil {
+			secrets[v.Name] = true
+		}
+	}
+	var writable []string
+	for i, s := range app.Services {
+		for j, vm := range s.VolumeMounts {
+			if secrets[vm.Name] && !vm.ReadOnly {
+				writable = append(writable, fmt.Sprintf("Services[%d].VolumeMounts[%d]", i, j))
+			}
+		}
+	}
+	return writable
+}
+
+// validateResources check that resource values are valid quantities and requests don't exceed limits
+func validateResources(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	for i, s := range app.Services {
+		if s.Resources == nil {
+			continue
+		}
+		parse := func(kind string, values map[string]string) map[string]resource.Quantity {
+			res := make(map[string]resource.Quantity)
+			for k, v := range values {
+				q, err := resource.ParseQuantity(v)
+				if err != nil {
+					errs.Append(common.Error(common.ErrInvalidResourceSpec,
+						common.Field("where", fmt.Sprintf("Services[%d].Resources.%s.%s", i, kind, k)),
+						common.Field("name", s.Name),
+						common.Field("error", fmt.Sprintf("%s is not a valid quantity", v))))
+					continue
+				}
+				res[k] = q
+			}
+			return res
+		}
+		limits := parse("Limits", s.Resources.Limits)
+		requests := parse("Requests", s.Resources.Requests)
+		keys := make([]string, 0, len(requests))
+		for k := range requests {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			limit, ok := limits[k]
+			if !ok {
+				continue
+			}
+			request := requests[k]
+			if request.Cmp(limit) > 0 {
+				errs.Append(common.Error(common.ErrInvalidResourceSpec,
+					common.Field("where", fmt.Sprintf("Services[%d].Resources.Requests.%s", i, k)),
+					common.Field("name", s.Name),
+					common.Field("error", fmt.Sprintf("request %s exceeds limit %s", request.String(), limit.String()))))
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// the grammar of image reference, same as github.com/docker/distribution/reference
+const (
+	imageDomainComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	imageDomain          = imageDomainComponent + `(?:\.` + imageDomainComponent + `)*(?::[0-9]+)?`
+	imageNameComponent   = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	imageName            = `(?:` + imageDomain + `/)?` + imageNameComponent + `(?:/` + imageNameComponent + `)*`
+	imageTag             = `[\w][\w.-]{0,127}`
+	imageDigest          = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}`
+	imageNameMaxLength   = 255
+)
+
+var imageRefRegexp = regexp.MustCompile(`^(` + imageName + `)(?::(` + imageTag + `))?(?:@(` + imageDigest + `))?$`)
+
+// validateImages check that images of services are valid references, and have an explicit tag or digest if strict.
+// The empty image is left to the required binding.
+func validateImages(app *specV1.Application, strict bool) error {
+	errs := &common.MultiError{}
+	for _, s := range app.Services {
+		if s.Image == "" {
+			continue
+		}
+		var msg string
+		m := imageRefRegexp.FindStringSubmatch(s.Image)
+		switch {
+		case m == nil:
+			msg = "invalid reference format"
+		case len(m[1]) > imageNameMaxLength:
+			msg = fmt.Sprintf("repository name must not be more than %d characters", imageNameMaxLength)
+		case strict && m[2] == "" && m[3] == "":
+			msg = "tag or digest is required"
+		default:
+			continue
+		}
+		errs.Append(common.Error(common.ErrInvalidImageRef,
+			common.Field("name", s.Name),
+			common.Field("image", s.Image),
+			common.Field("error", msg)))
+	}
+	return errs.ErrorOrNil()
+}
+
+// validateEnv check that env names of each service are valid and unique
+func validateEnv(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	for _, s := range app.Services {
+		ef := make(map[string]bool)
+		for _, e := range s.Env {
+			if !envNameRegexp.MatchString(e.Name) {
+				errs.Append(common.Error(common.ErrInvalidEnvName,
+					common.Field("name", s.Name),
+					common.Field("env", e.Name)))
+				continue
+			}
+			if ef[e.Name] {
+				errs.Append(common.Error(common.ErrEnvNameConflict,
+					common.Field("name", s.Name),
+					common.Field("env", e.Name)))
+				continue
+			}
+			ef[e.Name] = true
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// validNodeSelector check the node selector of app is in the syntax of label selector, an invalid one matches no node
+func validNodeSelector(app *specV1.Application) error {
+	if _, err := parseNodeSelector(app.Selector); err != nil {
+		return common.Error(common.ErrInvalidNodeSelector, common.Field("name", app.Name),
+			common.Field("selector", app.Selector), common.Field("error", err.Error()))
+	}
+	return nil
+}
+
+// parseNodeSelector parse selector as the label filters of storage do, the empty selector selects nothing
+func parseNodeSelector(selector string) (labels.Selector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return labels.Nothing(), nil
+	}
+	return labels.Parse(selector)
+}
+
+// validatePorts check that host ports are not bound by more than one service
+func validatePorts(app *specV1.Application) error {
+	errs := &common.MultiError{}
+	pf := make(map[string]string)
+	for _, s := range app.Services {
+		for _, p := range s.Ports {
+			if p.HostPort == 0 {
+				continue
+			}
+			protocol := strings.ToUpper(p.Protocol)
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			key := fmt.Sprintf("%s:%d/%s", p.HostIP, p.HostPort, protocol)
+			if other, ok := pf[key]; ok {
+				errs.Append(common.Error(common.ErrServicePortConflict,
+					common.Field("name", s.Name),
+					common.Field("other", other),
+					common.Field("port", p.HostPort)))
+				continue
+			}
+			pf[key] = s.Name
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// sameSpec check whether the specs of applications are the same, the fields set by storage are ignored
+func sameSpec(x, y *specV1.Application) bool {
+	a, b := *x, *y
+	a.Namespace, b.Namespace = "", ""
+	a.Version, b.Version = "", ""
+	a.CreationTimestamp, b.CreationTimestamp = time.Time{}, time.Time{}
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ja) == string(jb)
+}
+
+func toAppItem(app *specV1.Application) models.AppItem {
+	return models.AppItem{
+		Name:              app.Name,
+		Type:              app.Type,
+		Labels:            app.Labels,
+		Selector:          app.Selector,
+		Version:           app.Version,
+		Namespace:         app.Namespace,
+		CreationTimestamp: app.CreationTimestamp,
+		Description:       app.Description,
+		System:            app.System,
+	}
+}
+
+func diffApplication(from, to *specV1.Application) []models.DiffChange {
+	changes := make([]models.DiffChange, 0)
+
+	fromServices, toServices := map[string]specV1.Service{}, map[string]specV1.Service{}
+	for _, s := range from.Services {
+		fromServices[s.Name] = s
+	}
+	for _, s := range to.Services {
+		toServices[s.Name] = s
+	}
+	for _, s := range from.Services {
+		path := fmt.Sprintf("services[%s]", s.Name)
+		t, ok := toServices[s.Name]
+		if !ok {
+			changes = append(changes, models.DiffChange{Type: models.DiffRemoved, Path: path, OldValue: s})
+			continue
+		}
+		changes = append(changes, diffService(path, &s, &t)...)
+	}
+	for _, s := range to.Services {
+		if _, ok := fromServices[s.Name]; !ok {
+			changes = append(changes, models.DiffChange{Type: models.DiffAdded, Path: fmt.Sprintf("services[%s]", s.Name), NewValue: s})
+		}
+	}
+
+	fromVolumes, toVolumes := map[string]specV1.Volume{}, map[string]specV1.Volume{}
+	for _, v := range from.Volumes {
+		fromVolumes[v.Name] = v
+	}
+	for _, v := range to.Volumes {
+		toVolumes[v.Name] = v
+	}
+	for _, v := range from.Volumes {
+		path := fmt.Sprintf("volumes[%s]", v.Name)
+		t, ok := toVolumes[v.Name]
+		if !ok {
+			changes = append(changes, models.DiffChange{Type: models.DiffRemoved, Path: path, OldValue: v})
+		} else if !reflect.DeepEqual(v, t) {
+			changes = append(changes, models.DiffChange{Type: models.DiffChanged, Path: path, OldValue: v, NewValue: t})
+		}
+	}
+	for _, v := range to.Volumes {
+		if _, ok := fromVolumes[v.Name]; !ok {
+			changes = append(changes, models.DiffChange{Type: models.DiffAdded, Path: fmt.Sprintf("volumes[%s]", v.Name), NewValue: v})
+		}
+	}
+	return changes
+}
+
+func diffService(path string, from, to *specV1.Service) []models.DiffChange {
+	var changes []models.DiffChange
+	fv, tv := reflect.ValueOf(*from), reflect.ValueOf(*to)
+	for i := 0; i < fv.NumField(); i++ {
+		field := fv.Type().Field(i)
+		if field.Name == "Name" || field.Name == "VolumeMounts" {
+			continue
+		}
+		o, n := fv.Field(i).Interface(), tv.Field(i).Interface()
+		if !reflect.DeepEqual(o, n) {
+			tag := strings.Split(field.Tag.Get("json"), ",")[0]
+			changes = append(changes, models.DiffChange{Type: models.DiffChanged, Path: path + "." + tag, OldValue: o, NewValue: n})
+		}
+	}
+
+	fromMounts, toMounts := map[string]specV1.VolumeMount{}, map[string]specV1.VolumeMount{}
+	for _, vm := range from.VolumeMounts {
+		fromMounts[vm.Name] = vm
+	}
+	for _, vm := range to.VolumeMounts {
+		toMounts[vm.Name] = vm
+	}
+	for _, vm := range from.VolumeMounts {
+		p := fmt.Sprintf("%s.volumeMounts[%s]", path, vm.Name)
+		t, ok := toMounts[vm.Name]
+		if !ok {
+			changes = append(changes, models.DiffChange{Type: models.DiffRemoved, Path: p, OldValue: vm})
+		} else if vm != t {
+			changes = append(changes, models.DiffChange{Type: models.DiffChanged, Path: p, OldValue: vm, NewValue: t})
+		}
+	}
+	for _, vm := range to.VolumeMounts {
+		if _, ok := fromMounts[vm.Name]; !ok {
+			changes = append(changes, models.DiffChange{Type: models.DiffAdded, Path: fmt.Sprintf("%s.volumeMounts[%s]", path, vm.Name), NewValue: vm})
+		}
+	}
+	return changes
+}
+
+// validVolumeSource check that a volume doesn't reference both config and secret
+func validVolumeSource(vol specV1.Volume) error {
+	if vol.Config != nil && vol.Secret != nil {
+		return common.Error(common.ErrVolumeType, common.Field("name", vol.Name),
+			common.Field("type", "config or secret"),
+			common.Field("error", "both config and secret are set"))
+	}
+	return nil
+}
+
+// toVolumeSourceError tell that the object referenced by volume is of the other kind if it isn't found as the expected one
+func (a *applicationService) toVolumeSourceError(ctx context.Context, err error, namespace, volume string, tp common.Resource, name string) error {
+	if !goerrors.Is(err, plugin.ErrNotFound) {
+		return err
+	}
+	var other common.Resource
+	var otherErr error
+	switch tp {
+	case common.Config:
+		other = common.Secret
+		_, otherErr = a.modelStorage(ctx).GetSecret(namespace, name, "")
+	case common.Secret:
+		other = common.Config
+		_, otherErr = a.modelStorage(ctx).GetConfig(namespace, name, "")
+	}
+	if other != "" && otherErr == nil {
+		return common.Error(common.ErrVolumeType, common.Field("name", volume),
+			common.Field("type", other),
+			common.Field("error", fmt.Sprintf("%s (%s) is not found but %s (%s) exists", tp, name, other, name)))
+	}
+	return toNotFoundError(err, tp, namespace, name)
+}
+
+func toNotFoundError(err error, tp common.Resource, namespace, name string) error {
+	if !goerrors.Is(err, plugin.ErrNotFound) {
+		return err
+	}
+	return common.Error(common.ErrResourceNotFound, common.Field("type", tp),
+		common.Field("name", name), common.Field("namespace", namespace))
+}
+
+func copyApplication(app *specV1.Application) (*specV1.Application, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	res := new(specV1.Application)
+	if err = json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// sortApps sort the items by OrderBy and Order, ties are broken by name
+func sortApps(list *models.ApplicationList, listOptions *models.ListOptions) error {
+	var less func(a, b *models.AppItem) bool
+	switch listOptions.OrderBy {
+	case "", models.OrderByName:
+		less = func(a, b *models.AppItem) bool { return false }
+	case models.OrderByCreateTime:
+		less = func(a, b *models.AppItem) bool { return a.CreationTimestamp.Before(b.CreationTimestamp) }
+	case models.OrderByUpdateTime:
+		less = func(a, b *models.AppItem) bool { return a.UpdateTimestamp.Before(b.UpdateTimestamp) }
+	default:
+		return common.Error(common.ErrRequestParamInvalid, common.Field("error", "orderBy is invalid: "+listOptions.OrderBy))
+	}
+	var desc bool
+	switch strings.ToLower(listOptions.Order) {
+	case "", models.OrderAsc:
+	case models.OrderDesc:
+		desc = true
+	default:
+		return common.Error(common.ErrRequestParamInvalid, common.Field("error", "order is invalid: "+listOptions.Order))
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		a, b := &list.Items[i], &list.Items[j]
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Name < b.Name
+	})
+	return nil
+}
+
+// paginateApps page the sorted items, the cursor encodes the name of last item
+func paginateApps(list *models.ApplicationList, listOptions *models.ListOptions) error {
+	list.Total = len(list.Items)
+
+	start, limit := 0, int(listOptions.Limit)
+	if listOptions.Continue != "" {
+		data, err := base64.RawURLEncoding.DecodeString(listOptions.Continue)
+		if err != nil {
+			return common.Error(common.ErrRequestParamInvalid, common.Field("error", "continue is invalid"))
+		}
+		last := string(data)
+		start = -1
+		for i := range list.Items {
+			if list.Items[i].Name == last {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			// the last item has been deleted, only the order of name can be resumed
+			if listOptions.OrderBy != "" && listOptions.OrderBy != models.OrderByName {
+				return common.Error(common.ErrRequestParamInvalid, common.Field("error", "continue is expired"))
+			}
+			desc := strings.ToLower(listOptions.Order) == models.OrderDesc
+			start = sort.Search(len(list.Items), func(i int) bool {
+				if desc {
+					return list.Items[i].Name < last
+				}
+				return list.Items[i].Name > last
+			})
+		}
+	} else if listOptions.PageSize > 0 {
+		pageNo := listOptions.PageNo
+		if pageNo <= 0 {
+			pageNo = 1
+		}
+		start, limit = (pageNo-1)*listOptions.PageSize, listOptions.PageSize
+	}
+	if start > len(list.Items) {
+		start = len(list.Items)
+	}
+	end := len(list.Items)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	list.Items = list.Items[start:end]
+
+	opts := *listOptions
+	opts.Continue = ""
+	if end < list.Total && len(list.Items) > 0 {
+		opts.Continue = base64.RawURLEncoding.EncodeToString([]byte(list.Items[len(list.Items)-1].Name))
+	}
+	list.Continue = opts.Continue
+	list.ListOptions = &opts
+	return nil
+}
+
+// ListApplicationsByNodeSelector the selectors are compared in the canonical form, so "b=2,a=1" is equivalent to "a=1, b=2"
+func (a *applicationService) ListApplicationsByNodeSelector(ctx context.Context, namespace, selector string) (*models.ApplicationList, error) {
+	target, err := parseNodeSelector(selector)
+	if err != nil || strings.TrimSpace(selector) == "" {
+		msg := "selector is empty"
+		if err != nil {
+			msg = err.Error()
+		}
+		return nil, common.Error(common.ErrInvalidNodeSelector, common.Field("selector", selector), common.Field("error", msg))
+	}
+	list, err := a.modelStorage(ctx).ListApplication(namespace, &models.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.AppItem, 0)
+	for _, item := range list.Items {
+		if strings.TrimSpace(item.Selector) == "" {
+			continue
+		}
+		s, err := parseNodeSelector(item.Selector)
+		if err != nil {
+			log.L().Warn("the node selector of app is invalid", log.Any("namespace", namespace),
+				log.Any("app", item.Name), log.Any("selector", item.Selector), log.Error(err))
+			continue
+		}
+		if s.String() == target.String() {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return &models.ApplicationList{Total: len(items), Items: items, ListOptions: &models.ListOptions{}}, nil
+}
+
+// filterApps filter items by label selector in kubernetes syntax and search keywords, empty ones match all
+func (a *applicationService) filterApps(list *models.ApplicationList, listOptions *models.ListOptions) error {
+	selector := strings.TrimSpace(listOptions.LabelSelector)
+	keywords := strings.Fields(strings.ToLower(listOptions.Search))
+	if selector == "" && len(keywords) == 0 {
+		return nil
+	}
+	if selector != "" {
+		if _, err := a.storage.IsLabelMatch(selector, map[string]string{}); err != nil {
+			return common.Error(common.ErrRequestParamInvalid, common.Field("error", "selector is invalid: "+err.Error()))
+		}
+	}
+	items := make([]models.AppItem, 0, len(list.Items))
+	for _, item := range list.Items {
+		if !matchKeywords(item, keywords) {
+			continue
+		}
+		if selector != "" {
+			ok, err := a.storage.IsLabelMatch(selector, item.Labels)
+			if err != nil {
+				return common.Error(common.ErrRequestParamInvalid, common.Field("error", "selector is invalid: "+err.Error()))
+			}
+			if !ok {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	list.Items = items
+	return nil
+}
+
+func matchKeywords(item models.AppItem, keywords []string) bool {
+	text := strings.ToLower(item.Name + " " + item.Description)
+	for _, k := range keywords {
+		if !strings.Contains(text, k) {
+			return false
+		}
+	}
+	return true
 }
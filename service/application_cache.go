@@ -0,0 +1,187 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/config"
+	"github.com/baetyl/baetyl-cloud/models"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+)
+
+// cachedApplicationService a read-through LRU cache of Get, the entries of an app are invalidated once it is changed
+type cachedApplicationService struct {
+	ApplicationService
+	conf   config.AppCacheConfig
+	now    func() time.Time
+	mutex  sync.Mutex
+	lru    *list.List
+	keys   map[appCacheKey]*list.Element
+	byName map[appCacheKey]map[string]*list.Element
+}
+
+type appCacheKey struct {
+	namespace, name, version string
+}
+
+type appCacheEntry struct {
+	key     appCacheKey
+	app     *specV1.Application
+	expires time.Time
+}
+
+func withCache(as ApplicationService, conf config.AppCacheConfig) ApplicationService {
+	if conf.Size <= 0 {
+		return as
+	}
+	return &cachedApplicationService{
+		ApplicationService: as,
+		conf:               conf,
+		now:                time.Now,
+		lru:                list.New(),
+		keys:               map[appCacheKey]*list.Element{},
+		byName:             map[appCacheKey]map[string]*list.Element{},
+	}
+}
+
+func (c *cachedApplicationService) Get(ctx context.Context, namespace, name, version string) (*specV1.Application, error) {
+	key := appCacheKey{namespace: namespace, name: name, version: version}
+	if app := c.load(key); app != nil {
+		return copyApplication(app)
+	}
+	app, err := c.ApplicationService.Get(ctx, namespace, name, version)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := copyApplication(app)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, cached)
+	return app, nil
+}
+
+// Exists is answered by a cached latest version without copying it
+func (c *cachedApplicationService) Exists(ctx context.Context, namespace, name string) (bool, error) {
+	if app := c.load(appCacheKey{namespace: namespace, name: name}); app != nil {
+		return true, nil
+	}
+	return c.ApplicationService.Exists(ctx, namespace, name)
+}
+
+func (c *cachedApplicationService) load(key appCacheKey) *specV1.Application {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.keys[key]
+	if !ok {
+		return nil
+	}
+	entry := e.Value.(*appCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(e)
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	return entry.app
+}
+
+func (c *cachedApplicationService) store(key appCacheKey, app *specV1.Application) {
+	ttl := c.conf.VersionTTL
+	if key.version == "" {
+		ttl = c.conf.LatestTTL
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.keys[key]; ok {
+		c.remove(e)
+	}
+	e := c.lru.PushFront(&appCacheEntry{key: key, app: app, expires: c.now().Add(ttl)})
+	c.keys[key] = e
+	name := appCacheKey{namespace: key.namespace, name: key.name}
+	if c.byName[name] == nil {
+		c.byName[name] = map[string]*list.Element{}
+	}
+	c.byName[name][key.version] = e
+	for c.lru.Len() > c.conf.Size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *cachedApplicationService) remove(e *list.Element) {
+	key := e.Value.(*appCacheEntry).key
+	c.lru.Remove(e)
+	delete(c.keys, key)
+	name := appCacheKey{namespace: key.namespace, name: key.name}
+	delete(c.byName[name], key.version)
+	if len(c.byName[name]) == 0 {
+		delete(c.byName, name)
+	}
+}
+
+// invalidate remove all the cached versions of app
+func (c *cachedApplicationService) invalidate(namespace string, names ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, name := range names {
+		for _, e := range c.byName[appCacheKey{namespace: namespace, name: name}] {
+			c.remove(e)
+		}
+	}
+}
+
+func (c *cachedApplicationService) Update(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	return c.UpdateWithOptions(ctx, namespace, app, nil)
+}
+
+func (c *cachedApplicationService) UpdateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*models.ApplicationUpdateResult, error) {
+	defer c.invalidate(namespace, app.Name)
+	return c.ApplicationService.UpdateWithResult(ctx, namespace, app, opts)
+}
+
+func (c *cachedApplicationService) UpdateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*specV1.Application, error) {
+	defer c.invalidate(namespace, app.Name)
+	return c.ApplicationService.UpdateWithOptions(ctx, namespace, app, opts)
+}
+
+func (c *cachedApplicationService) Patch(ctx context.Context, namespace, name string, patch *models.ApplicationPatch) (*specV1.Application, error) {
+	defer c.invalidate(namespace, name)
+	return c.ApplicationService.Patch(ctx, namespace, name, patch)
+}
+
+func (c *cachedApplicationService) DeleteWithOptions(ctx context.Context, namespace, name, version string, opts *models.DeleteOptions) error {
+	defer c.invalidate(namespace, name)
+	return c.ApplicationService.DeleteWithOptions(ctx, namespace, name, version, opts)
+}
+
+func (c *cachedApplicationService) Delete(ctx context.Context, namespace, name, version string) error {
+	defer c.invalidate(namespace, name)
+	return c.ApplicationService.Delete(ctx, namespace, name, version)
+}
+
+func (c *cachedApplicationService) SoftDelete(ctx context.Context, namespace, name, version string) error {
+	defer c.invalidate(namespace, name)
+	return c.ApplicationService.SoftDelete(ctx, namespace, name, version)
+}
+
+func (c *cachedApplicationService) DeleteBatch(ctx context.Context, namespace string, names []string) ([]string, map[string]error, error) {
+	defer c.invalidate(namespace, names...)
+	return c.ApplicationService.DeleteBatch(ctx, namespace, names)
+}
+
+func (c *cachedApplicationService) DeleteByLabel(ctx context.Context, namespace, labelSelector string, all bool) ([]string, error) {
+	deleted, err := c.ApplicationService.DeleteByLabel(ctx, namespace, labelSelector, all)
+	c.invalidate(namespace, deleted...)
+	return deleted, err
+}
+
+func (c *cachedApplicationService) Rollback(ctx context.Context, namespace, name, targetVersion string) (*specV1.Application, error) {
+	defer c.invalidate(namespace, name)
+	return c.ApplicationService.Rollback(ctx, namespace, name, targetVersion)
+}
+
+func (c *cachedApplicationService) SetCanary(ctx context.Context, namespace, name, stableVersion, canaryVersion string, weight int) error {
+	defer c.invalidate(namespace, name)
+	return c.ApplicationService.SetCanary(ctx, namespace, name, stableVersion, canaryVersion, weight)
+}
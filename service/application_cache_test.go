@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/config"
+	ms "github.com/baetyl/baetyl-cloud/mock/service"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedApplicationService(t *testing.T) {
+	ctx := context.Background()
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockApp := ms.NewMockApplicationService(ctl)
+	assert.Equal(t, mockApp, withCache(mockApp, config.AppCacheConfig{}))
+
+	now := time.Now()
+	as := withCache(mockApp, config.AppCacheConfig{Size: 2, LatestTTL: time.Second, VersionTTL: time.Hour}).(*cachedApplicationService)
+	as.now = func() time.Time { return now }
+
+	v1 := &specV1.Application{Namespace: "default", Name: "abc", Version: "1"}
+	v2 := &specV1.Application{Namespace: "default", Name: "abc", Version: "2"}
+
+	// read through
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "").Return(v1, nil).Times(1)
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "1").Return(v1, nil).Times(1)
+	for i := 0; i < 3; i++ {
+		app, err := as.Get(ctx, "default", "abc", "")
+		assert.NoError(t, err)
+		assert.Equal(t, v1, app)
+		app, err = as.Get(ctx, "default", "abc", "1")
+		assert.NoError(t, err)
+		assert.Equal(t, v1, app)
+	}
+
+	// exists is answered by the cached latest version
+	ok, err := as.Exists(ctx, "default", "abc")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	mockApp.EXPECT().Exists(gomock.Any(), "default", "missing").Return(false, nil)
+	ok, err = as.Exists(ctx, "default", "missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the cached one is not affected by callers
+	app, err := as.Get(ctx, "default", "abc", "")
+	assert.NoError(t, err)
+	app.Version = "changed"
+	app, err = as.Get(ctx, "default", "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", app.Version)
+
+	// stale latest is not served past the ttl, version-pinned one is still cached
+	now = now.Add(2 * time.Second)
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "").Return(v2, nil).Times(1)
+	app, err = as.Get(ctx, "default", "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, v2, app)
+	app, err = as.Get(ctx, "default", "abc", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, v1, app)
+
+	// invalidated on update
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), "default", v2, nil).Return(v2, nil)
+	_, err = as.Update(ctx, "default", v2)
+	assert.NoError(t, err)
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "").Return(v2, nil).Times(1)
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "1").Return(v1, nil).Times(1)
+	_, err = as.Get(ctx, "default", "abc", "")
+	assert.NoError(t, err)
+	_, err = as.Get(ctx, "default", "abc", "1")
+	assert.NoError(t, err)
+
+	// invalidated on delete, errors are not cached
+	mockApp.EXPECT().Delete(gomock.Any(), "default", "abc", "").Return(nil)
+	assert.NoError(t, as.Delete(ctx, "default", "abc", ""))
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "").Return(nil, fmt.Errorf("not found")).Times(2)
+	_, err = as.Get(ctx, "default", "abc", "")
+	assert.Error(t, err)
+	_, err = as.Get(ctx, "default", "abc", "")
+	assert.Error(t, err)
+
+	// the least recently used is evicted
+	mockApp.EXPECT().Get(gomock.Any(), "default", gomock.Any(), "1").Return(v1, nil).Times(4)
+	_, _ = as.Get(ctx, "default", "a", "1")
+	_, _ = as.Get(ctx, "default", "b", "1")
+	_, _ = as.Get(ctx, "default", "a", "1")
+	_, _ = as.Get(ctx, "default", "c", "1")
+	_, _ = as.Get(ctx, "default", "a", "1")
+	_, _ = as.Get(ctx, "default", "b", "1")
+	assert.Equal(t, 2, as.lru.Len())
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-go/log"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+)
+
+// PostCreateHook a side effect run after app is created and indexed, such as creating a monitoring dashboard of it
+type PostCreateHook func(ctx context.Context, namespace string, app *specV1.Application) error
+
+type namedHook struct {
+	name string
+	hook PostCreateHook
+}
+
+var (
+	hooksMutex      sync.Mutex
+	postCreateHooks []namedHook
+)
+
+// RegisterPostCreateHook adds a hook run by the application services created afterwards, hooks run in the order registered.
+// The failure of hook is only logged unless its name is in config.AppConfig.FatalHooks, the creation is rolled back then
+func RegisterPostCreateHook(name string, hook PostCreateHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	for _, h := range postCreateHooks {
+		if h.name == name {
+			log.L().Info("post create hook already exists, skip", log.Any("hook", name))
+			return
+		}
+	}
+	postCreateHooks = append(postCreateHooks, namedHook{name: name, hook: hook})
+}
+
+func registeredPostCreateHooks() []namedHook {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	return append([]namedHook(nil), postCreateHooks...)
+}
+
+// runPostCreateHooks run the hooks in order, it stops at the first fatal failure and returns it
+func (a *applicationService) runPostCreateHooks(ctx context.Context, namespace string, app *specV1.Application) error {
+	for _, h := range a.hooks {
+		err := h.hook(ctx, namespace, app)
+		if err == nil {
+			continue
+		}
+		if a.fatalHook(h.name) {
+			return err
+		}
+		log.L().Warn("failed to run post create hook",
+			log.Any("hook", h.name),
+			log.Any(common.KeyContextNamespace, namespace),
+			log.Any("app", app.Name),
+			log.Error(err))
+	}
+	return nil
+}
+
+func (a *applicationService) fatalHook(name string) bool {
+	for _, n := range a.conf.FatalHooks {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/config"
+	ms "github.com/baetyl/baetyl-cloud/mock/service"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterPostCreateHook(t *testing.T) {
+	defer func(hooks []namedHook) { postCreateHooks = hooks }(postCreateHooks)
+	postCreateHooks = nil
+
+	noop := func(context.Context, string, *specV1.Application) error { return nil }
+	RegisterPostCreateHook("a", noop)
+	RegisterPostCreateHook("b", noop)
+	RegisterPostCreateHook("a", noop)
+	hooks := registeredPostCreateHooks()
+	assert.Len(t, hooks, 2)
+	assert.Equal(t, "a", hooks[0].name)
+	assert.Equal(t, "b", hooks[1].name)
+}
+
+func TestDefaultApplicationService_PostCreateHooks(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	var called []string
+	hook := func(name string, err error) namedHook {
+		return namedHook{name: name, hook: func(_ context.Context, namespace string, app *specV1.Application) error {
+			called = append(called, name+":"+namespace+"/"+app.Name)
+			return err
+		}}
+	}
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+		hooks:        []namedHook{hook("dashboard", fmt.Errorf("dashboard error")), hook("audit", nil)},
+	}
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+
+	// the non-fatal failure is logged, the later hooks still run
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil),
+		mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{"agent-conf"}).Return(nil),
+		mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{"test-secret-02"}).Return(nil),
+		mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "").Return(nil, nil),
+	)
+	res, err := as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+	assert.Equal(t, []string{"dashboard:default/abc", "audit:default/abc"}, called)
+
+	// the fatal failure rolls back the creation
+	called = nil
+	as.conf = config.AppConfig{FatalHooks: []string{"dashboard"}}
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil),
+		mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{"agent-conf"}).Return(nil),
+		mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{"test-secret-02"}).Return(nil),
+		mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{}).Return(nil),
+		mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{}).Return(nil),
+		mockObject.modelStorage.EXPECT().DeleteApplication(app.Namespace, app.Name).Return(nil),
+	)
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.EqualError(t, err, "dashboard error")
+	assert.Equal(t, []string{"dashboard:default/abc"}, called)
+}
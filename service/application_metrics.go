@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/models"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	appOperationTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "baetyl_application_operation_total",
+		Help: "The number of application operations.",
+	}, []string{"method", "namespace", "result"})
+	appOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "baetyl_application_operation_duration_seconds",
+		Help:    "The latency of application operations.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "namespace", "result"})
+	// appHistoryLost the application history writes which still fail after retries, operators can alarm on it
+	appHistoryLost = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "baetyl_application_history_lost_total",
+		Help: "The number of application history writes lost after retries.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(appOperationTotal, appOperationDuration, appHistoryLost)
+}
+
+// metricsApplicationService records the count and latency of operations, the rest are passed through
+type metricsApplicationService struct {
+	ApplicationService
+}
+
+func withMetrics(as ApplicationService) ApplicationService {
+	return &metricsApplicationService{ApplicationService: as}
+}
+
+func observe(method, namespace string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	appOperationTotal.WithLabelValues(method, namespace, result).Inc()
+	appOperationDuration.WithLabelValues(method, namespace, result).Observe(time.Since(start).Seconds())
+}
+
+func (m *metricsApplicationService) Get(ctx context.Context, namespace, name, version string) (app *specV1.Application, err error) {
+	defer func(start time.Time) { observe("get", namespace, start, err) }(time.Now())
+	return m.ApplicationService.Get(ctx, namespace, name, version)
+}
+
+func (m *metricsApplicationService) Exists(ctx context.Context, namespace, name string) (ok bool, err error) {
+	defer func(start time.Time) { observe("exists", namespace, start, err) }(time.Now())
+	return m.ApplicationService.Exists(ctx, namespace, name)
+}
+
+func (m *metricsApplicationService) GetBatch(ctx context.Context, namespace string, refs []models.AppRef) (res map[string]*specV1.Application, err error) {
+	defer func(start time.Time) { observe("get_batch", namespace, start, err) }(time.Now())
+	return m.ApplicationService.GetBatch(ctx, namespace, refs)
+}
+
+func (m *metricsApplicationService) GetAtLeastVersion(ctx context.Context, namespace, name, minVersion string, timeout time.Duration) (app *specV1.Application, err error) {
+	defer func(start time.Time) { observe("get_at_least_version", namespace, start, err) }(time.Now())
+	return m.ApplicationService.GetAtLeastVersion(ctx, namespace, name, minVersion, timeout)
+}
+
+func (m *metricsApplicationService) CreateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (res *specV1.Application, err error) {
+	defer func(start time.Time) { observe("create", namespace, start, err) }(time.Now())
+	return m.ApplicationService.CreateWithOptions(ctx, namespace, app, opts)
+}
+
+func (m *metricsApplicationService) CreateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (res *models.ApplicationCreateResult, err error) {
+	defer func(start time.Time) { observe("create", namespace, start, err) }(time.Now())
+	return m.ApplicationService.CreateWithResult(ctx, namespace, app, opts)
+}
+
+func (m *metricsApplicationService) Create(ctx context.Context, namespace string, app *specV1.Application) (res *specV1.Application, err error) {
+	defer func(start time.Time) { observe("create", namespace, start, err) }(time.Now())
+	return m.ApplicationService.Create(ctx, namespace, app)
+}
+
+func (m *metricsApplicationService) Update(ctx context.Context, namespace string, app *specV1.Application) (res *specV1.Application, err error) {
+	return m.UpdateWithOptions(ctx, namespace, app, nil)
+}
+
+func (m *metricsApplicationService) UpdateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (res *models.ApplicationUpdateResult, err error) {
+	defer func(start time.Time) { observe("update", namespace, start, err) }(time.Now())
+	return m.ApplicationService.UpdateWithResult(ctx, namespace, app, opts)
+}
+
+func (m *metricsApplicationService) UpdateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (res *specV1.Application, err error) {
+	defer func(start time.Time) { observe("update", namespace, start, err) }(time.Now())
+	return m.ApplicationService.UpdateWithOptions(ctx, namespace, app, opts)
+}
+
+func (m *metricsApplicationService) Patch(ctx context.Context, namespace, name string, patch *models.ApplicationPatch) (res *specV1.Application, err error) {
+	defer func(start time.Time) { observe("update", namespace, start, err) }(time.Now())
+	return m.ApplicationService.Patch(ctx, namespace, name, patch)
+}
+
+func (m *metricsApplicationService) DeleteWithOptions(ctx context.Context, namespace, name, version string, opts *models.DeleteOptions) (err error) {
+	defer func(start time.Time) { observe("delete", namespace, start, err) }(time.Now())
+	return m.ApplicationService.DeleteWithOptions(ctx, namespace, name, version, opts)
+}
+
+func (m *metricsApplicationService) Delete(ctx context.Context, namespace, name, version string) (err error) {
+	defer func(start time.Time) { observe("delete", namespace, start, err) }(time.Now())
+	return m.ApplicationService.Delete(ctx, namespace, name, version)
+}
+
+func (m *metricsApplicationService) ListApplicationsByNodeSelector(ctx context.Context, namespace, selector string) (list *models.ApplicationList, err error) {
+	defer func(start time.Time) { observe("list_by_node_selector", namespace, start, err) }(time.Now())
+	return m.ApplicationService.ListApplicationsByNodeSelector(ctx, namespace, selector)
+}
+
+func (m *metricsApplicationService) List(ctx context.Context, namespace string, listOptions *models.ListOptions) (list *models.ApplicationList, err error) {
+	defer func(start time.Time) { observe("list", namespace, start, err) }(time.Now())
+	return m.ApplicationService.List(ctx, namespace, listOptions)
+}
+
+func (m *metricsApplicationService) ListStream(ctx context.Context, namespace string, listOptions *models.ListOptions, fn func(*specV1.Application) error) (err error) {
+	defer func(start time.Time) { observe("list", namespace, start, err) }(time.Now())
+	return m.ApplicationService.ListStream(ctx, namespace, listOptions, fn)
+}
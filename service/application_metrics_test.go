@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	ms "github.com/baetyl/baetyl-cloud/mock/service"
+	"github.com/baetyl/baetyl-cloud/models"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsApplicationService(t *testing.T) {
+	ctx := context.Background()
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockApp := ms.NewMockApplicationService(ctl)
+	as := withMetrics(mockApp)
+	namespace := "metrics"
+
+	app := &specV1.Application{Name: "abc"}
+	mockApp.EXPECT().Get(gomock.Any(), namespace, "abc", "").Return(app, nil)
+	mockApp.EXPECT().Get(gomock.Any(), namespace, "abc", "1").Return(nil, fmt.Errorf("error"))
+	mockApp.EXPECT().Create(gomock.Any(), namespace, app).Return(app, nil)
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), namespace, app, nil).Return(app, nil)
+	mockApp.EXPECT().Delete(gomock.Any(), namespace, "abc", "").Return(nil)
+	mockApp.EXPECT().List(gomock.Any(), namespace, nil).Return(&models.ApplicationList{}, nil)
+	mockApp.EXPECT().Validate(gomock.Any(), namespace, app).Return(nil)
+
+	res, err := as.Get(ctx, namespace, "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+	_, err = as.Get(ctx, namespace, "abc", "1")
+	assert.Error(t, err)
+	_, err = as.Create(ctx, namespace, app)
+	assert.NoError(t, err)
+	_, err = as.Update(ctx, namespace, app)
+	assert.NoError(t, err)
+	assert.NoError(t, as.Delete(ctx, namespace, "abc", ""))
+	_, err = as.List(ctx, namespace, nil)
+	assert.NoError(t, err)
+	// not instrumented methods are passed through
+	assert.NoError(t, as.Validate(ctx, namespace, app))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(appOperationTotal.WithLabelValues("get", namespace, "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(appOperationTotal.WithLabelValues("get", namespace, "failure")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(appOperationTotal.WithLabelValues("create", namespace, "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(appOperationTotal.WithLabelValues("update", namespace, "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(appOperationTotal.WithLabelValues("delete", namespace, "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(appOperationTotal.WithLabelValues("list", namespace, "success")))
+}
+
+func TestAppHistoryLost(t *testing.T) {
+	// exposed with the other metrics of apps
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	var names []string
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "baetyl_application_history_lost_total")
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/config"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-go/errors"
+	"github.com/baetyl/baetyl-go/log"
+)
+
+// HistoryPruner prune the history of all applications periodically by the retention of config
+type HistoryPruner struct {
+	app  ApplicationService
+	db   plugin.DBStorage
+	conf config.AppHistoryConfig
+	done chan struct{}
+}
+
+// NewHistoryPruner NewHistoryPruner
+func NewHistoryPruner(config *config.CloudConfig) (*HistoryPruner, error) {
+	p := &HistoryPruner{
+		conf: config.Application.History,
+		done: make(chan struct{}),
+	}
+	if p.conf.Disabled {
+		return p, nil
+	}
+	db, err := plugin.GetPlugin(config.Plugin.DatabaseStorage)
+	if err != nil {
+		return nil, err
+	}
+	as, err := NewApplicationService(config)
+	if err != nil {
+		return nil, err
+	}
+	p.app, p.db = as, db.(plugin.DBStorage)
+	return p, nil
+}
+
+// Run prune history every interval until closed, it returns at once if the pruner or history is disabled
+func (p *HistoryPruner) Run() {
+	if p.conf.Interval <= 0 || p.conf.Disabled {
+		return
+	}
+	ticker := time.NewTicker(p.conf.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if n, err := p.Prune(); err != nil {
+				log.L().Error("prune application history error", log.Any("pruned", n), log.Error(err))
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Prune prune the history of every application once, the history of deleted applications is left alone
+func (p *HistoryPruner) Prune() (int, error) {
+	apps, err := p.db.ListHistoryApplications()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	errs := &common.MultiError{}
+	for _, app := range apps {
+		n, err := p.app.PruneHistory(context.Background(), app.Namespace, app.Name, p.conf.Keep)
+		if err != nil {
+			if e, ok := err.(errors.Coder); ok && e.Code() == common.ErrResourceNotFound {
+				continue
+			}
+			errs.Append(err)
+			continue
+		}
+		total += n
+	}
+	return total, errs.ErrorOrNil()
+}
+
+// Close stop the pruner
+func (p *HistoryPruner) Close() {
+	close(p.done)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/config"
+	"github.com/baetyl/baetyl-cloud/models"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+)
+
+// rateLimitedApplicationService a token bucket per namespace in front of the writes, reads are passed through.
+// A batch takes a single token as a call, a clone takes the one of its destination namespace
+type rateLimitedApplicationService struct {
+	ApplicationService
+	conf    config.AppRateLimitConfig
+	now     func() time.Time
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func withRateLimit(as ApplicationService, conf config.AppRateLimitConfig) ApplicationService {
+	if conf.Rate <= 0 {
+		return as
+	}
+	if conf.Burst <= 0 {
+		conf.Burst = 1
+	}
+	return &rateLimitedApplicationService{
+		ApplicationService: as,
+		conf:               conf,
+		now:                time.Now,
+		buckets:            map[string]*tokenBucket{},
+	}
+}
+
+// allow take a token of namespace, ErrTooManyRequests is returned if the bucket is empty
+func (r *rateLimitedApplicationService) allow(namespace string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := r.now()
+	b, ok := r.buckets[namespace]
+	if !ok {
+		b = &tokenBucket{tokens: float64(r.conf.Burst), last: now}
+		r.buckets[namespace] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * r.conf.Rate
+		if b.tokens > float64(r.conf.Burst) {
+			b.tokens = float64(r.conf.Burst)
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return common.Error(common.ErrTooManyRequests, common.Field("namespace", namespace))
+	}
+	b.tokens--
+	return nil
+}
+
+func (r *rateLimitedApplicationService) CreateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.CreateWithOptions(ctx, namespace, app, opts)
+}
+
+func (r *rateLimitedApplicationService) CreateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (*models.ApplicationCreateResult, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.CreateWithResult(ctx, namespace, app, opts)
+}
+
+func (r *rateLimitedApplicationService) Create(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Create(ctx, namespace, app)
+}
+
+func (r *rateLimitedApplicationService) CreateFromTemplate(ctx context.Context, namespace string, template *specV1.Application, params map[string]string) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.CreateFromTemplate(ctx, namespace, template, params)
+}
+
+func (r *rateLimitedApplicationService) Update(ctx context.Context, namespace string, app *specV1.Application) (*specV1.Application, error) {
+	return r.UpdateWithOptions(ctx, namespace, app, nil)
+}
+
+func (r *rateLimitedApplicationService) UpdateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*models.ApplicationUpdateResult, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.UpdateWithResult(ctx, namespace, app, opts)
+}
+
+func (r *rateLimitedApplicationService) UpdateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.UpdateWithOptions(ctx, namespace, app, opts)
+}
+
+func (r *rateLimitedApplicationService) Patch(ctx context.Context, namespace, name string, patch *models.ApplicationPatch) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Patch(ctx, namespace, name, patch)
+}
+
+func (r *rateLimitedApplicationService) DeleteWithOptions(ctx context.Context, namespace, name, version string, opts *models.DeleteOptions) error {
+	if err := r.allow(namespace); err != nil {
+		return err
+	}
+	return r.ApplicationService.DeleteWithOptions(ctx, namespace, name, version, opts)
+}
+
+func (r *rateLimitedApplicationService) Delete(ctx context.Context, namespace, name, version string) error {
+	if err := r.allow(namespace); err != nil {
+		return err
+	}
+	return r.ApplicationService.Delete(ctx, namespace, name, version)
+}
+
+func (r *rateLimitedApplicationService) CreateWithBase(ctx context.Context, namespace string, app, base *specV1.Application) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.CreateWithBase(ctx, namespace, app, base)
+}
+
+func (r *rateLimitedApplicationService) CreateWithBases(ctx context.Context, namespace string, app *specV1.Application, bases []*specV1.Application, opts *models.CreateOptions) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.CreateWithBases(ctx, namespace, app, bases, opts)
+}
+
+func (r *rateLimitedApplicationService) CreateBatch(ctx context.Context, namespace string, apps []*specV1.Application) ([]*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.CreateBatch(ctx, namespace, apps)
+}
+
+func (r *rateLimitedApplicationService) Import(ctx context.Context, namespace string, bundle *models.ApplicationBundle, opts *models.ImportOptions) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Import(ctx, namespace, bundle, opts)
+}
+
+func (r *rateLimitedApplicationService) Clone(ctx context.Context, srcNamespace, name, version, dstNamespace, newName string) (*specV1.Application, error) {
+	if err := r.allow(dstNamespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Clone(ctx, srcNamespace, name, version, dstNamespace, newName)
+}
+
+func (r *rateLimitedApplicationService) Rollback(ctx context.Context, namespace, name, targetVersion string) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Rollback(ctx, namespace, name, targetVersion)
+}
+
+func (r *rateLimitedApplicationService) SetCanary(ctx context.Context, namespace, name, stableVersion, canaryVersion string, weight int) error {
+	if err := r.allow(namespace); err != nil {
+		return err
+	}
+	return r.ApplicationService.SetCanary(ctx, namespace, name, stableVersion, canaryVersion, weight)
+}
+
+func (r *rateLimitedApplicationService) SoftDelete(ctx context.Context, namespace, name, version string) error {
+	if err := r.allow(namespace); err != nil {
+		return err
+	}
+	return r.ApplicationService.SoftDelete(ctx, namespace, name, version)
+}
+
+func (r *rateLimitedApplicationService) Restore(ctx context.Context, namespace, name string) (*specV1.Application, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.Restore(ctx, namespace, name)
+}
+
+func (r *rateLimitedApplicationService) DeleteBatch(ctx context.Context, namespace string, names []string) ([]string, map[string]error, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, nil, err
+	}
+	return r.ApplicationService.DeleteBatch(ctx, namespace, names)
+}
+
+func (r *rateLimitedApplicationService) DeleteByLabel(ctx context.Context, namespace, labelSelector string, all bool) ([]string, error) {
+	if err := r.allow(namespace); err != nil {
+		return nil, err
+	}
+	return r.ApplicationService.DeleteByLabel(ctx, namespace, labelSelector, all)
+}
+
+func (r *rateLimitedApplicationService) PruneHistory(ctx context.Context, namespace, name string, keep int) (int, error) {
+	if err := r.allow(namespace); err != nil {
+		return 0, err
+	}
+	return r.ApplicationService.PruneHistory(ctx, namespace, name, keep)
+}
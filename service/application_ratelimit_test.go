@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/config"
+	ms "github.com/baetyl/baetyl-cloud/mock/service"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-go/errors"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedApplicationService(t *testing.T) {
+	ctx := context.Background()
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockApp := ms.NewMockApplicationService(ctl)
+	assert.Equal(t, mockApp, withRateLimit(mockApp, config.AppRateLimitConfig{}))
+
+	now := time.Now()
+	as := withRateLimit(mockApp, config.AppRateLimitConfig{Rate: 2, Burst: 3}).(*rateLimitedApplicationService)
+	as.now = func() time.Time { return now }
+
+	app := &specV1.Application{Namespace: "default", Name: "abc"}
+	mockApp.EXPECT().Create(gomock.Any(), "default", app).Return(app, nil)
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), "default", app, nil).Return(app, nil)
+	mockApp.EXPECT().Delete(gomock.Any(), "default", "abc", "").Return(nil)
+	_, err := as.Create(ctx, "default", app)
+	assert.NoError(t, err)
+	_, err = as.Update(ctx, "default", app)
+	assert.NoError(t, err)
+	assert.NoError(t, as.Delete(ctx, "default", "abc", ""))
+
+	// the burst is used up
+	_, err = as.Update(ctx, "default", app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrTooManyRequests, err.(errors.Coder).Code())
+	err = as.Delete(ctx, "default", "abc", "")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrTooManyRequests, err.(errors.Coder).Code())
+
+	// reads and other namespaces are unaffected
+	mockApp.EXPECT().Get(gomock.Any(), "default", "abc", "").Return(app, nil)
+	_, err = as.Get(ctx, "default", "abc", "")
+	assert.NoError(t, err)
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), "other", app, nil).Return(app, nil)
+	_, err = as.Update(ctx, "other", app)
+	assert.NoError(t, err)
+
+	// a token is refilled every half second, but no more than burst
+	now = now.Add(500 * time.Millisecond)
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), "default", app, nil).Return(app, nil)
+	_, err = as.Update(ctx, "default", app)
+	assert.NoError(t, err)
+	_, err = as.Update(ctx, "default", app)
+	assert.Error(t, err)
+
+	now = now.Add(time.Hour)
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), "default", app, nil).Return(app, nil).Times(3)
+	for i := 0; i < 4; i++ {
+		_, err = as.Update(ctx, "default", app)
+		if i < 3 {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+
+	// exactly burst writes pass when they race
+	now = now.Add(time.Hour)
+	mockApp.EXPECT().UpdateWithOptions(gomock.Any(), "default", app, nil).Return(app, nil).Times(3)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	rejected := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := as.Update(ctx, "default", app); err != nil {
+				mutex.Lock()
+				rejected++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 7, rejected)
+}
+
+func TestRateLimitedApplicationService_Writes(t *testing.T) {
+	ctx := context.Background()
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockApp := ms.NewMockApplicationService(ctl)
+	as := withRateLimit(mockApp, config.AppRateLimitConfig{Rate: 1, Burst: 1}).(*rateLimitedApplicationService)
+	now := time.Now()
+	as.now = func() time.Time { return now }
+	app := &specV1.Application{Namespace: "default", Name: "abc"}
+
+	// every write takes a token, the second one in the same instant is rejected without calling the service
+	writes := map[string]func() error{
+		"CreateWithBase": func() error { _, err := as.CreateWithBase(ctx, "default", app, app); return err },
+		"CreateWithBases": func() error {
+			_, err := as.CreateWithBases(ctx, "default", app, []*specV1.Application{app}, nil)
+			return err
+		},
+		"CreateBatch":   func() error { _, err := as.CreateBatch(ctx, "default", []*specV1.Application{app}); return err },
+		"DeleteBatch":   func() error { _, _, err := as.DeleteBatch(ctx, "default", []string{"abc"}); return err },
+		"DeleteByLabel": func() error { _, err := as.DeleteByLabel(ctx, "default", "a=b", false); return err },
+		"SoftDelete":    func() error { return as.SoftDelete(ctx, "default", "abc", "") },
+		"Restore":       func() error { _, err := as.Restore(ctx, "default", "abc"); return err },
+		"Import": func() error {
+			_, err := as.Import(ctx, "default", &models.ApplicationBundle{App: app}, nil)
+			return err
+		},
+		"Clone":        func() error { _, err := as.Clone(ctx, "other", "abc", "", "default", "copy"); return err },
+		"Rollback":     func() error { _, err := as.Rollback(ctx, "default", "abc", "1"); return err },
+		"SetCanary":    func() error { return as.SetCanary(ctx, "default", "abc", "1", "2", 10) },
+		"PruneHistory": func() error { _, err := as.PruneHistory(ctx, "default", "abc", 1); return err },
+	}
+	mockApp.EXPECT().CreateWithBase(gomock.Any(), "default", app, app).Return(app, nil)
+	mockApp.EXPECT().CreateWithBases(gomock.Any(), "default", app, gomock.Any(), nil).Return(app, nil)
+	mockApp.EXPECT().CreateBatch(gomock.Any(), "default", gomock.Any()).Return(nil, nil)
+	mockApp.EXPECT().DeleteBatch(gomock.Any(), "default", []string{"abc"}).Return(nil, nil, nil)
+	mockApp.EXPECT().DeleteByLabel(gomock.Any(), "default", "a=b", false).Return(nil, nil)
+	mockApp.EXPECT().SoftDelete(gomock.Any(), "default", "abc", "").Return(nil)
+	mockApp.EXPECT().Restore(gomock.Any(), "default", "abc").Return(app, nil)
+	mockApp.EXPECT().Import(gomock.Any(), "default", gomock.Any(), nil).Return(app, nil)
+	mockApp.EXPECT().Clone(gomock.Any(), "other", "abc", "", "default", "copy").Return(app, nil)
+	mockApp.EXPECT().Rollback(gomock.Any(), "default", "abc", "1").Return(app, nil)
+	mockApp.EXPECT().SetCanary(gomock.Any(), "default", "abc", "1", "2", 10).Return(nil)
+	mockApp.EXPECT().PruneHistory(gomock.Any(), "default", "abc", 1).Return(1, nil)
+	for name, write := range writes {
+		now = now.Add(time.Second)
+		assert.NoError(t, write(), name)
+		err := write()
+		assert.Error(t, err, name)
+		assert.Equal(t, common.ErrTooManyRequests, err.(errors.Coder).Code(), name)
+	}
+}
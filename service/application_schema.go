@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+)
+
+// applicationSchema the JSON schema of specV1.Application, only the keywords type, properties,
+// additionalProperties (the schema of map values), items, enum, minimum and maximum are supported
+const applicationSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"type": {"type": "string", "enum": ["container", "function"]},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"namespace": {"type": "string"},
+		"createTime": {"type": "string"},
+		"version": {"type": "string"},
+		"selector": {"type": "string"},
+		"description": {"type": "string"},
+		"system": {"type": "boolean"},
+		"services": {"type": "array", "items": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"hostname": {"type": "string"},
+				"image": {"type": "string"},
+				"replica": {"type": "integer", "minimum": 0},
+				"volumeMounts": {"type": "array", "items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"mountPath": {"type": "string"},
+						"readOnly": {"type": "boolean"},
+						"immutable": {"type": "boolean"}
+					}
+				}},
+				"ports": {"type": "array", "items": {
+					"type": "object",
+					"properties": {
+						"hostPort": {"type": "integer", "minimum": 0, "maximum": 65535},
+						"containerPort": {"type": "integer", "minimum": 0, "maximum": 65535},
+						"protocol": {"type": "string"},
+						"hostIP": {"type": "string"}
+					}
+				}},
+				"devices": {"type": "array", "items": {
+					"type": "object",
+					"properties": {
+						"devicePath": {"type": "string"},
+						"policy": {"type": "string"},
+						"description": {"type": "string"}
+					}
+				}},
+				"args": {"type": "array", "items": {"type": "string"}},
+				"env": {"type": "array", "items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"value": {"type": "string"}
+					}
+				}},
+				"resources": {
+					"type": "object",
+					"properties": {
+						"limits": {"type": "object", "additionalProperties": {"type": "string"}},
+						"requests": {"type": "object", "additionalProperties": {"type": "string"}}
+					}
+				},
+				"runtime": {"type": "string"},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"security": {
+					"type": "object",
+					"properties": {
+						"privileged": {"type": "boolean"}
+					}
+				},
+				"hostNetwork": {"type": "boolean"},
+				"functionConfig": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"runtime": {"type": "string"}
+					}
+				},
+				"functions": {"type": "array", "items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"handler": {"type": "string"},
+						"codedir": {"type": "string"}
+					}
+				}}
+			}
+		}},
+		"volumes": {"type": "array", "items": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"hostPath": {
+					"type": "object",
+					"properties": {
+						"path": {"type": "string"}
+					}
+				},
+				"config": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"version": {"type": "string"}
+					}
+				},
+				"secret": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"version": {"type": "string"}
+					}
+				}
+			}
+		}}
+	}
+}`
+
+type jsonSchema struct {
+	Type                 string                 `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+}
+
+var appSchema = mustParseSchema(applicationSchema)
+
+func mustParseSchema(data string) *jsonSchema {
+	s := new(jsonSchema)
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ValidateApplicationSchema check the JSON of an app against the schema, each violation is reported with its JSON path
+// such as $.services[0].replica. The fields unknown to the schema are only violations if strict.
+func ValidateApplicationSchema(data []byte, strict bool) error {
+	var value interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return common.Error(common.ErrSchemaViolation, common.Field("path", "$"), common.Field("error", err.Error()))
+	}
+	errs := &common.MultiError{}
+	appSchema.validate("$", value, strict, errs)
+	return errs.ErrorOrNil()
+}
+
+// validSchema check app against the schema before the semantic checks
+func (a *applicationService) validSchema(app *specV1.Application) error {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return err
+	}
+	return ValidateApplicationSchema(data, a.conf.StrictSchema)
+}
+
+func (s *jsonSchema) validate(path string, value interface{}, strict bool, errs *common.MultiError) {
+	// null is accepted as the zero value of any type, as encoding/json does
+	if value == nil {
+		return
+	}
+	violate := func(format string, args ...interface{}) {
+		errs.Append(common.Error(common.ErrSchemaViolation, common.Field("path", path),
+			common.Field("error", fmt.Sprintf(format, args...))))
+	}
+	if s.Type != "" && !matchType(s.Type, value) {
+		violate("expected %s but got %s", s.Type, typeOf(value))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		violate("%v is not one of %v", value, s.Enum)
+	}
+	if n, ok := value.(json.Number); ok {
+		f, _ := n.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			violate("%v is less than the minimum %v", n, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			violate("%v is greater than the maximum %v", n, *s.Maximum)
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "." + k
+			if sub, ok := s.Properties[k]; ok {
+				sub.validate(p, v[k], strict, errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(p, v[k], strict, errs)
+			} else if strict && s.Properties != nil {
+				errs.Append(common.Error(common.ErrSchemaViolation, common.Field("path", p),
+					common.Field("error", "unknown field")))
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, strict, errs)
+			}
+		}
+	}
+}
+
+func matchType(tp string, value interface{}) bool {
+	switch tp {
+	case "integer":
+		n, ok := value.(json.Number)
+		return ok && !strings.ContainsAny(n.String(), ".eE")
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+	return typeOf(value) == tp
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return "null"
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
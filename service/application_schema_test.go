@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-go/errors"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateApplicationSchema(t *testing.T) {
+	valid := `{"name": "app", "type": "container", "labels": {"a": "b"}, "services": [{"name": "s", "image": "i", "replica": 1,
+		"ports": [{"hostPort": 80, "containerPort": 80}], "resources": {"limits": {"cpu": "1"}}}],
+		"volumes": [{"name": "v", "config": {"name": "c", "version": "1"}}], "selector": null}`
+	assert.NoError(t, ValidateApplicationSchema([]byte(valid), true))
+
+	cases := []struct {
+		name, data, path string
+		strict           bool
+	}{
+		{"type mismatch", `{"name": "app", "services": [{"name": "s", "replica": "1"}]}`, "$.services[0].replica", false},
+		{"not integer", `{"services": [{"ports": [{"hostPort": 1.5}]}]}`, "$.services[0].ports[0].hostPort", false},
+		{"map value", `{"labels": {"a": 1}}`, "$.labels.a", false},
+		{"enum", `{"type": "vm"}`, "$.type", false},
+		{"minimum", `{"services": [{"replica": -1}]}`, "$.services[0].replica", false},
+		{"maximum", `{"services": [{"ports": [{"containerPort": 65536}]}]}`, "$.services[0].ports[0].containerPort", false},
+		{"unknown field", `{"name": "app", "volumes": [{"name": "v", "hostpath": {"path": "/"}}]}`, "$.volumes[0].hostpath", true},
+		{"malformed", `{"name": `, "$", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateApplicationSchema([]byte(c.data), c.strict)
+			assert.Error(t, err)
+			assert.Equal(t, common.ErrSchemaViolation, err.(errors.Coder).Code())
+			assert.Contains(t, err.Error(), "("+c.path+")")
+		})
+	}
+
+	// unknown fields are ignored unless strict
+	assert.NoError(t, ValidateApplicationSchema([]byte(`{"name": "app", "volumes": [{"hostpath": {}}]}`), false))
+}
+
+func TestDefaultApplicationService_validSchema(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage, conf: mockObject.conf.Application}
+
+	// the app is rejected before the semantic checks
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Times(0)
+	_, err := as.Create(ctx, "default", &specV1.Application{Name: "app", Services: []specV1.Service{{Name: "s", Replica: -1}}})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrSchemaViolation, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "($.services[0].replica)")
+
+	_, err = as.Update(ctx, "default", &specV1.Application{Name: "app", Type: "vm"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrSchemaViolation, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "($.type)")
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+)
+
+// templateParamRegexp a ${VAR} placeholder of template, the name is an identifier
+var templateParamRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// CreateFromTemplate the template is substituted on a copy, which is validated and created as Create does.
+// Only string fields can hold placeholders, such as image and labels, the typed ones such as replica can't be parameterized
+func (a *applicationService) CreateFromTemplate(ctx context.Context, namespace string, template *specV1.Application, params map[string]string) (*specV1.Application, error) {
+	app, err := renderTemplate(template, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.Create(ctx, namespace, app)
+}
+
+// renderTemplate replace the placeholders in the string fields of template, all missing params are reported together
+func renderTemplate(template *specV1.Application, params map[string]string) (*specV1.Application, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	missing := map[string]bool{}
+	value = substitute(value, params, missing)
+	if len(missing) > 0 {
+		keys := make([]string, 0, len(missing))
+		for k := range missing {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return nil, common.Error(common.ErrMissingTemplateParam,
+			common.Field("name", template.Name),
+			common.Field("params", strings.Join(keys, ",")))
+	}
+	if data, err = json.Marshal(value); err != nil {
+		return nil, err
+	}
+	app := new(specV1.Application)
+	if err = json.Unmarshal(data, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func substitute(value interface{}, params map[string]string, missing map[string]bool) interface{} {
+	switch v := value.(type) {
+	case string:
+		return templateParamRegexp.ReplaceAllStringFunc(v, func(p string) string {
+			key := p[2 : len(p)-1]
+			if param, ok := params[key]; ok {
+				return param
+			}
+			missing[key] = true
+			return p
+		})
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = substitute(item, params, missing)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substitute(item, params, missing)
+		}
+	}
+	return value
+}
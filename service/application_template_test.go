@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	ms "github.com/baetyl/baetyl-cloud/mock/service"
+	"github.com/baetyl/baetyl-go/errors"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultApplicationService_CreateFromTemplate(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig("default", "site-1-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Secret{}, nil).AnyTimes()
+
+	template, _ := genAppTestCase()
+	template.Name = "abc-${SITE}"
+	template.Labels = map[string]string{"site": "${SITE}"}
+	template.Services[0].Image = "hub.baidubce.com/baetyl/baetyl-agent:${TAG}"
+	template.Volumes[0].Config.Name = "${SITE}-conf"
+
+	// full substitution, the template is not changed
+	params := map[string]string{"SITE": "site-1", "TAG": "1.1.0"}
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+		return app, nil
+	})
+	app, err := as.CreateFromTemplate(ctx, "default", template, params)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc-site-1", app.Name)
+	assert.Equal(t, "site-1", app.Labels["site"])
+	assert.Equal(t, "hub.baidubce.com/baetyl/baetyl-agent:1.1.0", app.Services[0].Image)
+	assert.Equal(t, "site-1-conf", app.Volumes[0].Config.Name)
+	assert.Equal(t, "1", app.Volumes[0].Config.Version)
+	assert.Equal(t, "abc-${SITE}", template.Name)
+
+	// all missing params are listed
+	_, err = as.CreateFromTemplate(ctx, "default", template, map[string]string{"OTHER": "x"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrMissingTemplateParam, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(SITE,TAG)")
+
+	// the substituted app is validated
+	_, err = as.CreateFromTemplate(ctx, "default", template, map[string]string{"SITE": "Site_1", "TAG": "1.1.0"})
+	assert.Error(t, err)
+}
@@ -1,11 +1,20 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/config"
+	mockPlugin "github.com/baetyl/baetyl-cloud/mock/plugin"
 	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-go/errors"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"encoding/json"
@@ -22,7 +31,7 @@ func genAppTestCase() (*specV1.Application, *specV1.Application) {
 		Version:   "2",
 		Services: []specV1.Service{
 			{
-				Name:     "Agent",
+				Name:     "agent",
 				Hostname: "test-agent",
 				Image:    "hub.baidubce.com/baetyl/baetyl-agent:1.0.0",
 				Replica:  1,
@@ -61,7 +70,7 @@ func genAppTestCase() (*specV1.Application, *specV1.Application) {
 		Version:   "1",
 		Services: []specV1.Service{
 			{
-				Name:     "Agent-02",
+				Name:     "agent-02",
 				Hostname: "test-agent",
 				Image:    "hub.baidubce.com/baetyl/baetyl-agent:1.0.0",
 				Replica:  1,
@@ -98,27 +107,3515 @@ func TestDefaultApplicationService_Get(t *testing.T) {
 	mockObject.modelStorage.EXPECT().GetApplication(namespace, name, "").Return(nil, nil).AnyTimes()
 	cs, err := NewApplicationService(mockObject.conf)
 	assert.NoError(t, err)
-	_, err = cs.Get(namespace, name, "")
+	_, err = cs.Get(context.Background(), namespace, name, "")
 	assert.NoError(t, err)
 }
 
+func TestDefaultApplicationService_Exists(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(app, nil)
+	ok, err := as.Exists(ctx, app.Namespace, app.Name)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, "missing", "").
+		Return(nil, plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"missing\" not found")))
+	ok, err = as.Exists(ctx, app.Namespace, "missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(nil, fmt.Errorf("error"))
+	ok, err = as.Exists(ctx, app.Namespace, app.Name)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestDefaultApplicationService_GetNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+
+	// the wording of backends doesn't matter once the error matches plugin.ErrNotFound
+	for _, err := range []error{
+		plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"abc\" not found")),
+		plugin.NotFound(fmt.Errorf("no such key: default/abc")),
+		fmt.Errorf("query app: %w", plugin.ErrNotFound),
+	} {
+		mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(nil, err)
+		_, err = as.Get(ctx, "default", "abc", "")
+		assert.Error(t, err)
+		assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+	}
+
+	// other errors are returned as they are, even if they mention not found
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(nil, fmt.Errorf("route not found"))
+	_, err := as.Get(ctx, "default", "abc", "")
+	assert.EqualError(t, err, "route not found")
+}
+
+type batchModelStorage struct {
+	*mockPlugin.MockModelStorage
+	*mockPlugin.MockApplicationBatchGetter
+}
+
+func TestDefaultApplicationService_GetAtLeastVersion(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage}
+
+	// the replica serves nothing, then the stale version, then the fresh one
+	stale, fresh := &specV1.Application{Name: "abc", Version: "2"}, &specV1.Application{Name: "abc", Version: "10"}
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(nil, plugin.NotFound(fmt.Errorf("abc not found"))),
+		mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(stale, nil),
+		mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(fresh, nil),
+	)
+	app, err := as.GetAtLeastVersion(ctx, "default", "abc", "v10", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, fresh, app)
+
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(stale, nil).MinTimes(1)
+	_, err = as.GetAtLeastVersion(ctx, "default", "abc", "3", 2*versionPollInterval)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestTimeout, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "the current is 2")
+
+	mockObject.modelStorage.EXPECT().GetApplication("default", "bcd", "").Return(nil, fmt.Errorf("error"))
+	_, err = as.GetAtLeastVersion(ctx, "default", "bcd", "3", time.Second)
+	assert.EqualError(t, err, "error")
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.True(t, versionAtLeast("2", ""))
+	assert.True(t, versionAtLeast("10", "9"))
+	assert.True(t, versionAtLeast("v10", "010"))
+	assert.False(t, versionAtLeast("9", "v10"))
+	assert.True(t, versionAtLeast("1.0.0", "1.0.0"))
+	assert.False(t, versionAtLeast("1.0.1", "1.0.0"))
+	assert.False(t, versionAtLeast("10", "1.0.0"))
+}
+
+func TestSameVersion(t *testing.T) {
+	assert.True(t, sameVersion("12", "12"))
+	assert.True(t, sameVersion("v12", "012"))
+	assert.True(t, sameVersion("", ""))
+	assert.True(t, sameVersion("1.0", "1.0"))
+	assert.False(t, sameVersion("12", "13"))
+	assert.False(t, sameVersion("1.0", "1"))
+	assert.False(t, sameVersion("12", ""))
+}
+
+func TestDefaultApplicationService_GetBatch(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+
+	// empty input
+	res, err := as.GetBatch(ctx, "default", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+
+	// all found, one by one if the storage doesn't support batch
+	abc := &specV1.Application{Namespace: "default", Name: "abc", Version: "3"}
+	def := &specV1.Application{Namespace: "default", Name: "def", Version: "5"}
+	old := &specV1.Application{Namespace: "default", Name: "abc", Version: "2"}
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(abc, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("default", "def", "").Return(def, nil)
+	mockObject.dbStorage.EXPECT().GetApplication("abc", "default", "2").Return(old, nil)
+	res, err = as.GetBatch(ctx, "default", []models.AppRef{{Name: "abc"}, {Name: "def", Version: "5"}, {Name: "abc", Version: "2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*specV1.Application{"abc": abc, "def@5": def, "abc@2": old}, res)
+
+	// some missing, in a single query if the storage supports batch
+	batch := mockPlugin.NewMockApplicationBatchGetter(mockObject.ctl)
+	as.storage = &batchModelStorage{MockModelStorage: mockObject.modelStorage, MockApplicationBatchGetter: batch}
+	batch.EXPECT().GetApplications("default", []string{"abc", "missing", "def"}).Return([]specV1.Application{*abc, *def}, nil)
+	mockObject.dbStorage.EXPECT().GetApplication("def", "default", "1").Return(nil, nil)
+	mockObject.dbStorage.EXPECT().GetApplication("def", "default", "v1").Return(nil, nil)
+	res, err = as.GetBatch(ctx, "default", []models.AppRef{{Name: "abc"}, {Name: "missing"}, {Name: "def", Version: "1"}})
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, abc, res["abc"])
+
+	// other errors fail the call
+	batch.EXPECT().GetApplications("default", []string{"abc"}).Return(nil, fmt.Errorf("error"))
+	_, err = as.GetBatch(ctx, "default", []models.AppRef{{Name: "abc"}})
+	assert.Error(t, err)
+	batch.EXPECT().GetApplications("default", []string{"abc"}).Return([]specV1.Application{*abc}, nil)
+	mockObject.dbStorage.EXPECT().GetApplication("abc", "default", "1").Return(nil, fmt.Errorf("error"))
+	_, err = as.GetBatch(ctx, "default", []models.AppRef{{Name: "abc", Version: "1"}})
+	assert.Error(t, err)
+}
+
 func TestDefaultApplicationService_List(t *testing.T) {
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
 
-	namespace := "default"
-	selector := &models.ListOptions{
-		LabelSelector: "a=a",
+	namespace := "default"
+	selector := &models.ListOptions{
+		LabelSelector: "a=a",
+	}
+
+	createTime := time.Now().Add(-time.Hour).UTC()
+	updateTime := time.Now().UTC()
+	list := &models.ApplicationList{
+		Total: 1,
+		Items: []models.AppItem{{Name: "abc", CreationTimestamp: createTime, UpdateTimestamp: updateTime}},
+	}
+	mockObject.modelStorage.EXPECT().ListApplication(namespace, selector).Return(list, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().IsLabelMatch("a=a", gomock.Any()).Return(true, nil).AnyTimes()
+	cs, err := NewApplicationService(mockObject.conf)
+	assert.NoError(t, err)
+	res, err := cs.List(context.Background(), namespace, selector)
+	assert.NoError(t, err)
+	assert.Equal(t, createTime, res.Items[0].CreationTimestamp)
+	assert.Equal(t, updateTime, res.Items[0].UpdateTimestamp)
+}
+
+func TestDefaultApplicationService_ListContext(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+
+	// storage is not called with a cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := as.List(ctx, "default", nil)
+	assert.Equal(t, context.Canceled, err)
+	_, err = as.Count(ctx, "default", nil)
+	assert.Equal(t, context.Canceled, err)
+	_, err = as.Get(ctx, "default", "abc", "")
+	assert.Equal(t, context.Canceled, err)
+	err = as.Delete(ctx, "default", "abc", "")
+	assert.Equal(t, context.Canceled, err)
+
+	// ctx is passed to the storage which takes ctx, a slow call is aborted by it
+	mctx := mockPlugin.NewMockModelStorageContext(mockObject.ctl)
+	as.storage = &contextStorage{mockObject.modelStorage, mctx}
+	mctx.EXPECT().ListApplicationContext(gomock.Any(), "default", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ *models.ListOptions) (*models.ApplicationList, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = as.List(ctx, "default", nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestDefaultApplicationService_ListPagination(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	genList := func() *models.ApplicationList {
+		return &models.ApplicationList{
+			Items: []models.AppItem{{Name: "d"}, {Name: "b"}, {Name: "a"}, {Name: "c"}, {Name: "e"}},
+		}
+	}
+	mockObject.modelStorage.EXPECT().IsLabelMatch("a=a", gomock.Any()).Return(true, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{LabelSelector: "a=a"}).
+		DoAndReturn(func(_ string, _ *models.ListOptions) (*models.ApplicationList, error) {
+			return genList(), nil
+		}).AnyTimes()
+
+	names := func(list *models.ApplicationList) []string {
+		var res []string
+		for _, item := range list.Items {
+			res = append(res, item.Name)
+		}
+		return res
+	}
+
+	// cursor
+	opts := &models.ListOptions{LabelSelector: "a=a", Limit: 2}
+	list, err := as.List(ctx, "default", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, list.Total)
+	assert.Equal(t, []string{"a", "b"}, names(list))
+	assert.NotEmpty(t, list.Continue)
+	assert.Equal(t, list.Continue, list.ListOptions.Continue)
+
+	opts.Continue = list.Continue
+	list, err = as.List(ctx, "default", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, names(list))
+
+	opts.Continue = list.Continue
+	list, err = as.List(ctx, "default", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e"}, names(list))
+	assert.Empty(t, list.Continue)
+
+	opts.Continue = "!invalid!"
+	_, err = as.List(ctx, "default", opts)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	// offset
+	list, err = as.List(ctx, "default", &models.ListOptions{LabelSelector: "a=a", PageNo: 2, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, list.Total)
+	assert.Equal(t, []string{"c", "d"}, names(list))
+
+	list, err = as.List(ctx, "default", &models.ListOptions{LabelSelector: "a=a", PageNo: 4, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 0)
+	assert.Empty(t, list.Continue)
+
+	// all
+	list, err = as.List(ctx, "default", &models.ListOptions{LabelSelector: "a=a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, names(list))
+	assert.Empty(t, list.Continue)
+}
+
+func TestDefaultApplicationService_ListSelector(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	prod := map[string]string{"env": "prod"}
+	test := map[string]string{"env": "test"}
+	genList := func(_ string, _ *models.ListOptions) (*models.ApplicationList, error) {
+		return &models.ApplicationList{
+			Items: []models.AppItem{{Name: "a", Labels: prod}, {Name: "b", Labels: test}, {Name: "c"}},
+		}, nil
+	}
+	mockObject.modelStorage.EXPECT().ListApplication("default", gomock.Any()).DoAndReturn(genList).AnyTimes()
+
+	// equality
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", map[string]string{}).Return(false, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", prod).Return(true, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", test).Return(false, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", nil).Return(false, nil)
+	list, err := as.List(ctx, "default", &models.ListOptions{LabelSelector: "env=prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, "a", list.Items[0].Name)
+
+	// set membership
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env in (prod,test)", map[string]string{}).Return(false, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env in (prod,test)", prod).Return(true, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env in (prod,test)", test).Return(true, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env in (prod,test)", nil).Return(false, nil)
+	list, err = as.List(ctx, "default", &models.ListOptions{LabelSelector: "env in (prod,test)"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, list.Total)
+	assert.Equal(t, "a", list.Items[0].Name)
+	assert.Equal(t, "b", list.Items[1].Name)
+
+	// empty selector matches all
+	list, err = as.List(ctx, "default", &models.ListOptions{LabelSelector: " "})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, list.Total)
+
+	// malformed
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod=", map[string]string{}).Return(false, fmt.Errorf("invalid"))
+	_, err = as.List(ctx, "default", &models.ListOptions{LabelSelector: "env=prod="})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_ListSearch(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	prod := map[string]string{"env": "prod"}
+	genList := func(_ string, _ *models.ListOptions) (*models.ApplicationList, error) {
+		return &models.ApplicationList{
+			Items: []models.AppItem{
+				{Name: "broker", Description: "MQTT Broker for devices", Labels: prod},
+				{Name: "rule", Description: "route messages from broker to cloud"},
+				{Name: "function", Description: "python runtime", Labels: prod},
+			},
+		}, nil
+	}
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{}).DoAndReturn(genList).AnyTimes()
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{LabelSelector: "env=prod"}).DoAndReturn(genList).AnyTimes()
+
+	list, err := as.List(ctx, "default", &models.ListOptions{Search: "RUNTIME"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "function", list.Items[0].Name)
+
+	list, err = as.List(ctx, "default", &models.ListOptions{Search: "broker"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, list.Total)
+
+	list, err = as.List(ctx, "default", &models.ListOptions{Search: "messages cloud"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "rule", list.Items[0].Name)
+
+	// search and selector both apply
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", map[string]string{}).Return(false, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", prod).Return(true, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", nil).Return(false, nil)
+	list, err = as.List(ctx, "default", &models.ListOptions{Search: "broker", LabelSelector: "env=prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "broker", list.Items[0].Name)
+}
+
+func TestDefaultApplicationService_ListOrder(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	now := time.Now()
+	genList := func(_ string, _ *models.ListOptions) (*models.ApplicationList, error) {
+		return &models.ApplicationList{
+			Items: []models.AppItem{
+				{Name: "b", CreationTimestamp: now.Add(-time.Hour), UpdateTimestamp: now.Add(-time.Minute)},
+				{Name: "c", CreationTimestamp: now.Add(-2 * time.Hour), UpdateTimestamp: now.Add(-2 * time.Minute)},
+				{Name: "a", CreationTimestamp: now.Add(-3 * time.Hour), UpdateTimestamp: now},
+			},
+		}, nil
+	}
+	mockObject.modelStorage.EXPECT().ListApplication("default", gomock.Any()).DoAndReturn(genList).AnyTimes()
+
+	names := func(list *models.ApplicationList) []string {
+		var res []string
+		for _, item := range list.Items {
+			res = append(res, item.Name)
+		}
+		return res
+	}
+	cases := []struct {
+		orderBy, order string
+		expect         []string
+	}{
+		{"", "", []string{"a", "b", "c"}},
+		{models.OrderByName, models.OrderAsc, []string{"a", "b", "c"}},
+		{models.OrderByName, models.OrderDesc, []string{"c", "b", "a"}},
+		{models.OrderByCreateTime, models.OrderAsc, []string{"a", "c", "b"}},
+		{models.OrderByCreateTime, models.OrderDesc, []string{"b", "c", "a"}},
+		{models.OrderByUpdateTime, models.OrderAsc, []string{"c", "b", "a"}},
+		{models.OrderByUpdateTime, "DESC", []string{"a", "b", "c"}},
+	}
+	for _, c := range cases {
+		list, err := as.List(ctx, "default", &models.ListOptions{OrderBy: c.orderBy, Order: c.order})
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, names(list), c.orderBy+" "+c.order)
+	}
+
+	// sort before pagination
+	opts := &models.ListOptions{OrderBy: models.OrderByCreateTime, Order: models.OrderDesc, Limit: 2}
+	list, err := as.List(ctx, "default", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, names(list))
+	opts.Continue = list.Continue
+	list, err = as.List(ctx, "default", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, names(list))
+
+	_, err = as.List(ctx, "default", &models.ListOptions{OrderBy: "version"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	_, err = as.List(ctx, "default", &models.ListOptions{Order: "up"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_ListStableOrder(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	// storage yields the same apps in different orders
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().ListApplication("default", gomock.Any()).Return(&models.ApplicationList{
+			Items: []models.AppItem{{Name: "c"}, {Name: "a"}, {Name: "b"}},
+		}, nil),
+		mockObject.modelStorage.EXPECT().ListApplication("default", gomock.Any()).Return(&models.ApplicationList{
+			Items: []models.AppItem{{Name: "b"}, {Name: "c"}, {Name: "a"}},
+		}, nil),
+	)
+	first, err := as.List(ctx, "default", nil)
+	assert.NoError(t, err)
+	second, err := as.List(ctx, "default", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, first.Items, second.Items)
+	assert.Equal(t, []models.AppItem{{Name: "a"}, {Name: "b"}, {Name: "c"}}, first.Items)
+}
+
+func TestDefaultApplicationService_Count(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	prod := map[string]string{"env": "prod"}
+	genList := func(_ string, _ *models.ListOptions) (*models.ApplicationList, error) {
+		return &models.ApplicationList{
+			Items: []models.AppItem{
+				{Name: "broker", Description: "mqtt broker", Labels: prod},
+				{Name: "rule", Description: "route messages of broker"},
+				{Name: "function", Labels: prod},
+			},
+		}, nil
+	}
+	// without keywords storage counts, one item is listed and the rest are told by storage
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{Limit: 1}).
+		Return(&models.ApplicationList{Total: 3, ListOptions: &models.ListOptions{Continue: "YQ"}, Items: []models.AppItem{{Name: "broker"}}}, nil)
+	count, err := as.Count(ctx, "default", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// no item remains
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{LabelSelector: "env=prod", Limit: 1}).
+		Return(&models.ApplicationList{Total: 1, ListOptions: &models.ListOptions{}, Items: []models.AppItem{{Name: "broker"}}}, nil)
+	count, err = as.Count(ctx, "default", &models.ListOptions{LabelSelector: "env=prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// storage doesn't tell how many remain, it is listed again without limit
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{LabelSelector: "env=prod", Limit: 1}).
+		Return(&models.ApplicationList{Total: 1, ListOptions: &models.ListOptions{Continue: "YQ"}, Items: []models.AppItem{{Name: "broker"}}}, nil)
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{LabelSelector: "env=prod"}).
+		Return(&models.ApplicationList{Total: 2}, nil)
+	count, err = as.Count(ctx, "default", &models.ListOptions{LabelSelector: "env=prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{}).DoAndReturn(genList).AnyTimes()
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{LabelSelector: "env=prod"}).DoAndReturn(genList).AnyTimes()
+
+	// the keywords are matched in memory, pagination is ignored
+	count, err = as.Count(ctx, "default", &models.ListOptions{Search: "broker", Limit: 1, Continue: "YQ"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", map[string]string{}).Return(false, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", prod).Return(true, nil)
+	mockObject.modelStorage.EXPECT().IsLabelMatch("env=prod", nil).Return(false, nil)
+	count, err = as.Count(ctx, "default", &models.ListOptions{Search: "broker", LabelSelector: "env=prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	mockObject.modelStorage.EXPECT().ListApplication("other", gomock.Any()).Return(nil, fmt.Errorf("error"))
+	_, err = as.Count(ctx, "other", nil)
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_Delete(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	newApp, _ := genAppTestCase()
+	mockIndexService.EXPECT().ListIndex(gomock.Any(), common.Application, common.Base, gomock.Any()).Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil).AnyTimes()
+
+	mockObject.modelStorage.EXPECT().DeleteApplication(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).Times(1)
+	err := as.Delete(ctx, newApp.Namespace, newApp.Name, "")
+	assert.NotNil(t, err)
+
+	mockObject.modelStorage.EXPECT().DeleteApplication(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(nil, fmt.Errorf("error"))
+	err = as.Delete(ctx, newApp.Namespace, newApp.Name, "")
+	assert.NoError(t, err)
+
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(nil, nil)
+
+	err = as.Delete(ctx, newApp.Namespace, newApp.Name, "")
+	assert.NoError(t, err)
+}
+
+func TestDefaultApplicationService_DeleteBatch(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().ListIndex(gomock.Any(), common.Application, common.Base, gomock.Any()).Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication("default", gomock.Any(), "").Return(&specV1.Application{Namespace: "default"}, nil).AnyTimes()
+
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", "a").Return(nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", "b").Return(plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"b\" not found")))
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", "c").Return(nil)
+	for _, name := range []string{"a", "c"} {
+		mockIndexService.EXPECT().RefreshConfigIndexByApp("default", name, []string{}).Return(nil)
+		mockIndexService.EXPECT().RefreshSecretIndexByApp("default", name, []string{}).Return(nil)
+		mockObject.dbStorage.EXPECT().DeleteApplicationHistory(name, "default", "", "").Return(nil, nil)
+	}
+
+	deleted, failed, err := as.DeleteBatch(ctx, "default", []string{"a", "b", "c", "a"})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a", "c"}, deleted)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, common.ErrResourceNotFound, failed["b"].(errors.Coder).Code())
+
+	deleted, failed, err = as.DeleteBatch(ctx, "default", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.Empty(t, failed)
+}
+
+func TestDefaultApplicationService_DeleteByLabel(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().ListIndex(gomock.Any(), common.Application, common.Base, gomock.Any()).Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication("default", gomock.Any(), "").Return(&specV1.Application{Namespace: "default"}, nil).AnyTimes()
+
+	items := []models.AppItem{
+		{Name: "a", Version: "1", Labels: map[string]string{"site": "oldsite"}},
+		{Name: "b", Version: "2", Labels: map[string]string{"site": "newsite"}},
+		{Name: "c", Version: "3", Labels: map[string]string{"site": "oldsite", "env": "prod"}},
+		{Name: "d", Version: "4"},
+		{Name: "e", Version: "5", Labels: map[string]string{"site": "oldsite"}},
+	}
+	// the storage filters by the selector
+	genList := func(_ string, opts *models.ListOptions) (*models.ApplicationList, error) {
+		res := &models.ApplicationList{}
+		for _, item := range items {
+			if opts.LabelSelector == "" || opts.LabelSelector == "site="+item.Labels["site"] {
+				res.Items = append(res.Items, item)
+			}
+		}
+		return res, nil
+	}
+	mockObject.modelStorage.EXPECT().ListApplication("default", gomock.Any()).DoAndReturn(genList).AnyTimes()
+
+	_, err := as.DeleteByLabel(ctx, "default", "", false)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", "a").Return(nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", "c").Return(plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"c\" not found")))
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", "e").Return(nil)
+	for _, item := range []models.AppItem{items[0], items[4]} {
+		mockIndexService.EXPECT().RefreshConfigIndexByApp("default", item.Name, []string{}).Return(nil)
+		mockIndexService.EXPECT().RefreshSecretIndexByApp("default", item.Name, []string{}).Return(nil)
+		mockObject.dbStorage.EXPECT().DeleteApplicationHistory(item.Name, "default", item.Version, "").Return(nil, nil)
+	}
+	deleted, err := as.DeleteByLabel(ctx, "default", "site=oldsite", false)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+	assert.Equal(t, []string{"a", "e"}, deleted)
+
+	deleted, err = as.DeleteByLabel(ctx, "default", "site=nosite", false)
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	// all applications
+	for _, item := range items {
+		mockObject.modelStorage.EXPECT().DeleteApplication("default", item.Name).Return(nil)
+		mockIndexService.EXPECT().RefreshConfigIndexByApp("default", item.Name, []string{}).Return(nil)
+		mockIndexService.EXPECT().RefreshSecretIndexByApp("default", item.Name, []string{}).Return(nil)
+		mockObject.dbStorage.EXPECT().DeleteApplicationHistory(item.Name, "default", item.Version, "").Return(nil, nil)
+	}
+	deleted, err = as.DeleteByLabel(ctx, "default", "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, deleted)
+
+	mockObject.modelStorage.EXPECT().ListApplication("other", gomock.Any()).Return(nil, fmt.Errorf("error"))
+	_, err = as.DeleteByLabel(ctx, "other", "site=oldsite", false)
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_SkipEmptyIndex(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig("default", "c", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	stateless := &specV1.Application{Namespace: "default", Name: "app", Version: "1", Services: []specV1.Service{{Name: "s", Image: "i"}}}
+	withConfig := &specV1.Application{Namespace: "default", Name: "app", Version: "1", Services: []specV1.Service{{Name: "s", Image: "i",
+		VolumeMounts: []specV1.VolumeMount{{Name: "c", MountPath: "/etc/c"}}}},
+		Volumes: []specV1.Volume{{Name: "c", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "c"}}}}}
+	update := func(current, app *specV1.Application) {
+		app, _ = copyApplication(app)
+		app.Description = "updated"
+		updated, _ := copyApplication(app)
+		updated.Version = "2"
+		mockObject.modelStorage.EXPECT().GetApplication("default", "app", "").Return(current, nil)
+		mockObject.modelStorage.EXPECT().UpdateApplication("default", gomock.Any()).Return(updated, nil)
+		_, err := as.Update(ctx, "default", app)
+		assert.NoError(t, err)
+	}
+
+	// nothing is referenced, neither before
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).Return(stateless, nil)
+	_, err := as.Create(ctx, "default", stateless)
+	assert.NoError(t, err)
+	update(stateless, stateless)
+
+	// the old references are cleared
+	mockIndexService.EXPECT().RefreshConfigIndexByApp("default", "app", []string(nil)).Return(nil)
+	update(withConfig, stateless)
+
+	// only the index of configs is written
+	mockIndexService.EXPECT().RefreshConfigIndexByApp("default", "app", []string{"c"}).Return(nil)
+	update(stateless, withConfig)
+}
+
+func TestDefaultApplicationService_DeleteBase(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	base, _ := genAppTestCase()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	// the base still used isn't deleted
+	mockIndexService.EXPECT().ListIndex("default", common.Application, common.Base, base.Name).Return([]string{"other/x", "b"}, nil)
+	err := as.Delete(ctx, base.Namespace, base.Name, "")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceInUse, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "b,other/x")
+
+	mockIndexService.EXPECT().ListIndex("default", common.Application, common.Base, base.Name).Return(nil, fmt.Errorf("error"))
+	assert.Error(t, as.Delete(ctx, base.Namespace, base.Name, ""))
+
+	// unless forced
+	mockObject.modelStorage.EXPECT().GetApplication(base.Namespace, base.Name, "").Return(base, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(base.Namespace, base.Name).Return(nil)
+	assert.NoError(t, as.DeleteWithOptions(ctx, base.Namespace, base.Name, "", &models.DeleteOptions{Force: true}))
+
+	// the deleted app leaves the index of its base
+	app := &specV1.Application{Namespace: "default", Name: "b", Labels: map[string]string{common.LabelBaseApp: base.Name}}
+	mockIndexService.EXPECT().ListIndex("default", common.Application, common.Base, app.Name).Return([]string{}, nil)
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(app, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(app.Namespace, app.Name).Return(nil)
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, app.Name, []string{}).Return(nil)
+	assert.NoError(t, as.Delete(ctx, app.Namespace, app.Name, ""))
+}
+
+func TestDefaultApplicationService_refreshBaseIndex(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{indexService: mockIndexService}
+	genApp := func(base string) *specV1.Application {
+		app := &specV1.Application{Namespace: "default", Name: "a"}
+		if base != "" {
+			app.Labels = map[string]string{common.LabelBaseApp: base}
+		}
+		return app
+	}
+
+	// nothing changes
+	assert.NoError(t, as.refreshBaseIndex("default", "a", nil, nil))
+	assert.NoError(t, as.refreshBaseIndex("default", "a", genApp("b"), genApp("b")))
+
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "a", []string{"b"}).Return(nil)
+	assert.NoError(t, as.refreshBaseIndex("default", "a", nil, genApp("b")))
+
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "a", []string{"c"}).Return(nil)
+	assert.NoError(t, as.refreshBaseIndex("default", "a", genApp("b"), genApp("c")))
+
+	// the base in another namespace keeps the index there
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "a", []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshIndex("other", common.Application, common.Base, "default/a", []string{"b"}).Return(nil)
+	assert.NoError(t, as.refreshBaseIndex("default", "a", genApp("c"), genApp("other/b")))
+
+	// the bases merged together are indexed in their namespaces
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "a", []string{"c", "d"}).Return(nil)
+	assert.NoError(t, as.refreshBaseIndex("default", "a", genApp("other/b"), genApp("other/b,c,d")))
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "a", []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshIndex("other", common.Application, common.Base, "default/a", []string{"e"}).Return(nil)
+	assert.NoError(t, as.refreshBaseIndex("default", "a", genApp("other/b,c,d"), genApp("other/e")))
+
+	mockIndexService.EXPECT().RefreshIndex("other", common.Application, common.Base, "default/a", []string{}).Return(fmt.Errorf("error"))
+	assert.Error(t, as.refreshBaseIndex("default", "a", genApp("other/b"), nil))
+}
+
+func TestDefaultApplicationService_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	app, _ := genAppTestCase()
+	app.Labels = map[string]string{common.LabelBaseApp: "baetyl-cloud/logging"}
+
+	// the base of other apps
+	mockIndexService.EXPECT().ListIndex(app.Namespace, common.Application, common.Base, app.Name).Return([]string{"b", "a"}, nil)
+	err := as.SoftDelete(ctx, app.Namespace, app.Name, "")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceInUse, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "a,b")
+
+	mockIndexService.EXPECT().ListIndex(app.Namespace, common.Application, common.Base, app.Name).Return([]string{}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(app, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().SoftDeleteApplication(app.Name, app.Namespace, app.Version).Return(nil, fmt.Errorf("error"))
+	err = as.SoftDelete(ctx, app.Namespace, app.Name, "")
+	assert.Error(t, err)
+
+	// the app leaves the base index of its base
+	mockObject.dbStorage.EXPECT().SoftDeleteApplication(app.Name, app.Namespace, app.Version).Return(&mockSQLResult{affect: 1}, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(app.Namespace, app.Name).Return(nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshIndex("baetyl-cloud", common.Application, common.Base, app.Namespace+"/"+app.Name, []string{}).Return(nil)
+	err = as.SoftDelete(ctx, app.Namespace, app.Name, "")
+	assert.NoError(t, err)
+
+	// history is missing, store it before deletion
+	mockObject.dbStorage.EXPECT().SoftDeleteApplication(app.Name, app.Namespace, app.Version).Return(&mockSQLResult{affect: 0}, nil)
+	mockObject.dbStorage.EXPECT().CreateApplication(app).Return(nil, nil)
+	mockObject.dbStorage.EXPECT().SoftDeleteApplication(app.Name, app.Namespace, app.Version).Return(&mockSQLResult{affect: 1}, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(app.Namespace, app.Name).Return(nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshIndex("baetyl-cloud", common.Application, common.Base, app.Namespace+"/"+app.Name, []string{}).Return(fmt.Errorf("error"))
+	err = as.SoftDelete(ctx, app.Namespace, app.Name, app.Version)
+	assert.NoError(t, err)
+}
+
+func TestDefaultApplicationService_Restore(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+		conf:         config.AppConfig{SoftDeleteRetention: time.Hour},
+	}
+	app, _ := genAppTestCase()
+
+	// expired or hard deleted
+	mockObject.dbStorage.EXPECT().GetSoftDeletedApplication(app.Name, app.Namespace, gomock.Any()).
+		DoAndReturn(func(_, _ string, since time.Time) (*specV1.Application, error) {
+			assert.WithinDuration(t, time.Now().Add(-time.Hour), since, time.Minute)
+			return nil, nil
+		})
+	_, err := as.Restore(ctx, app.Namespace, app.Name)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	restored := &specV1.Application{Namespace: app.Namespace, Name: app.Name, Version: "10"}
+	mockObject.dbStorage.EXPECT().GetSoftDeletedApplication(app.Name, app.Namespace, gomock.Any()).Return(app, nil)
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{"agent-conf"}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{"test-secret-02"}).Return(nil)
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			assert.Empty(t, a.Version)
+			return restored, nil
+		})
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(restored, "").Return(nil, nil)
+	mockObject.dbStorage.EXPECT().RestoreApplication(app.Name, app.Namespace, "2").Return(nil, fmt.Errorf("error"))
+	res, err := as.Restore(ctx, app.Namespace, app.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, restored, res)
+}
+
+func TestDefaultApplicationService_Create(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+
+	// the index is untouched if storage fails
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(nil, fmt.Errorf("error"))
+	_, err := as.Create(ctx, app.Namespace, app)
+	assert.EqualError(t, err, "error")
+
+	// the application and index are reverted if index fails
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil),
+		mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{"agent-conf"}).Return(nil),
+		mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{"test-secret-02"}).Return(fmt.Errorf("index error")),
+		mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{}).Return(nil),
+		mockObject.modelStorage.EXPECT().DeleteApplication(app.Namespace, app.Name).Return(nil),
+	)
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.EqualError(t, err, "index error")
+
+	// config not found
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "missing", "").Return(nil, plugin.NotFound(fmt.Errorf("configs not found")))
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "missing", "").Return(nil, plugin.NotFound(fmt.Errorf("secrets not found")))
+	missing, _ := genAppTestCase()
+	missing.Volumes[0].Config.Name = "missing"
+	_, err = as.Create(ctx, app.Namespace, missing)
+	assert.Error(t, err)
+
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil),
+		mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{"agent-conf"}).Return(nil),
+		mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{"test-secret-02"}).Return(nil),
+		mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "").Return(nil, nil),
+	)
+	res, err := as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+}
+
+func TestDefaultApplicationService_ResolveReferences(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "3"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{Version: "5"}, nil).AnyTimes()
+
+	// nothing is persisted and the app is not changed
+	configs, secrets, err := as.ResolveReferences(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ResolvedReference{{Volume: "test", Name: "agent-conf", Version: "3"}}, configs)
+	assert.Equal(t, []models.ResolvedReference{{Volume: "test-2", Name: "test-secret-02", Version: "5"}}, secrets)
+	assert.Equal(t, "", app.Volumes[0].Config.Version)
+
+	// the same as Create indexes
+	var indexedConfigs, indexedSecrets []string
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, gomock.Any()).Do(func(_, _ string, names []string) {
+		indexedConfigs = names
+	}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, gomock.Any()).Do(func(_, _ string, names []string) {
+		indexedSecrets = names
+	}).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "").Return(nil, nil)
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, indexedConfigs, []string{configs[0].Name})
+	assert.Equal(t, indexedSecrets, []string{secrets[0].Name})
+	assert.Equal(t, configs[0].Version, app.Volumes[0].Config.Version)
+	assert.Equal(t, secrets[0].Version, app.Volumes[1].Secret.Version)
+
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "missing", "").Return(nil, plugin.NotFound(fmt.Errorf("configs not found")))
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "missing", "").Return(nil, plugin.NotFound(fmt.Errorf("secrets not found")))
+	missing, _ := genAppTestCase()
+	missing.Volumes[0].Config.Name = "missing"
+	_, _, err = as.ResolveReferences(ctx, app.Namespace, missing)
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_CreateNamespace(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Configuration{}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Secret{}, nil).AnyTimes()
+
+	// matching
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().CreateApplication("default", app).Return(app, nil)
+	_, err := as.Create(ctx, "default", app)
+	assert.NoError(t, err)
+
+	// empty, it's set from the parameter
+	app, _ = genAppTestCase()
+	app.Namespace = ""
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+		assert.Equal(t, "default", app.Namespace)
+		return app, nil
+	})
+	_, err = as.Create(ctx, "default", app)
+	assert.NoError(t, err)
+	assert.Equal(t, "default", app.Namespace)
+
+	// conflicting
+	app, _ = genAppTestCase()
+	_, err = as.Create(ctx, "other", app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(default)")
+	_, err = as.CreateBatch(ctx, "other", []*specV1.Application{app})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_CreateWithOptions(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	opts := &models.CreateOptions{RequestID: "req-1"}
+	app, _ := genAppTestCase()
+	created := &specV1.Application{Namespace: app.Namespace, Name: app.Name, Version: "100"}
+
+	// first request
+	var req *models.ApplicationRequest
+	mockObject.dbStorage.EXPECT().GetApplicationRequest(app.Namespace, "req-1").Return(nil, nil)
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(created, nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, []string{"agent-conf"}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, []string{"test-secret-02"}).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(created, "").Return(nil, nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationRequest(gomock.Any()).
+		DoAndReturn(func(r *models.ApplicationRequest) (sql.Result, error) {
+			req = r
+			return nil, nil
+		})
+	res, err := as.CreateWithOptions(ctx, app.Namespace, app, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, created, res)
+	assert.Equal(t, "req-1", req.RequestID)
+	assert.Equal(t, "100", req.Version)
+
+	// happy retry returns the app created before
+	retry, _ := genAppTestCase()
+	mockObject.dbStorage.EXPECT().GetApplicationRequest(app.Namespace, "req-1").Return(req, nil)
+	mockObject.dbStorage.EXPECT().GetApplication(app.Name, app.Namespace, "100").Return(created, nil)
+	res, err = as.CreateWithOptions(ctx, app.Namespace, retry, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, created, res)
+
+	// retry with a different spec
+	retry, _ = genAppTestCase()
+	retry.Services[0].Image = "other"
+	mockObject.dbStorage.EXPECT().GetApplicationRequest(app.Namespace, "req-1").Return(req, nil)
+	_, err = as.CreateWithOptions(ctx, app.Namespace, retry, opts)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceConflict, err.(errors.Coder).Code())
+
+	mockObject.dbStorage.EXPECT().GetApplicationRequest(app.Namespace, "req-2").Return(nil, fmt.Errorf("error"))
+	_, err = as.CreateWithOptions(ctx, app.Namespace, retry, &models.CreateOptions{RequestID: "req-2"})
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_storeHistory(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		dbStorage: mockObject.dbStorage,
+		conf:      config.AppConfig{HistoryRetryAttempts: 3, HistoryRetryDelay: time.Millisecond},
+	}
+	app, _ := genAppTestCase()
+
+	// flaky db fails twice then succeeds
+	lost := testutil.ToFloat64(appHistoryLost)
+	gomock.InOrder(
+		mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "").Return(nil, fmt.Errorf("error")),
+		mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "").Return(nil, fmt.Errorf("error")),
+		mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "").Return(nil, nil),
+	)
+	as.storeHistory(context.Background(), app, "")
+	assert.Equal(t, lost, testutil.ToFloat64(appHistoryLost))
+
+	// counted after exhausting retries
+	mockObject.dbStorage.EXPECT().DeleteApplication(app.Name, app.Namespace, app.Version).Return(nil, fmt.Errorf("error")).Times(3)
+	as.retryHistory("delete application history error", func() error {
+		_, err := as.dbStorage.DeleteApplication(app.Name, app.Namespace, app.Version)
+		return err
+	})
+	assert.Equal(t, lost+1, testutil.ToFloat64(appHistoryLost))
+}
+
+func TestDefaultApplicationService_CreateBatch(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+
+	app1, _ := genAppTestCase()
+	app2, _ := genAppTestCase()
+
+	// validation fails before any creation
+	_, err := as.CreateBatch(ctx, app1.Namespace, []*specV1.Application{app1, app2})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "where=apps[1].")
+
+	// the checks of create are run for each app, such as the schema
+	app2.Name = "def"
+	app2.Type = "vm"
+	_, err = as.CreateBatch(ctx, app1.Namespace, []*specV1.Application{app1, app2})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrSchemaViolation, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "($.type)")
+	app2.Type = ""
+
+	// the created one is removed when the next creation fails
+	app2.Name = "def"
+	mockObject.modelStorage.EXPECT().CreateApplication(app1.Namespace, app1).Return(app1, nil)
+	mockObject.modelStorage.EXPECT().CreateApplication(app1.Namespace, app2).Return(nil, fmt.Errorf("error"))
+	mockObject.modelStorage.EXPECT().DeleteApplication(app1.Namespace, app1.Name).Return(nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app1.Namespace, app1.Name, []string{}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app1.Namespace, app1.Name, []string{}).Return(nil)
+	_, err = as.CreateBatch(ctx, app1.Namespace, []*specV1.Application{app1, app2})
+	assert.Error(t, err)
+
+	// indexes are refreshed only after all applications are created
+	gomock.InOrder(
+		mockObject.modelStorage.EXPECT().CreateApplication(app1.Namespace, app1).Return(app1, nil),
+		mockObject.modelStorage.EXPECT().CreateApplication(app1.Namespace, app2).Return(app2, nil),
+		mockIndexService.EXPECT().RefreshConfigIndexByApps(app1.Namespace, map[string][]string{
+			app1.Name: {"agent-conf"},
+			app2.Name: {"agent-conf"},
+		}).Return(nil),
+	)
+	mockIndexService.EXPECT().RefreshSecretIndexByApps(app1.Namespace, map[string][]string{
+		app1.Name: {"test-secret-02"},
+		app2.Name: {"test-secret-02"},
+	}).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).Times(2)
+	apps, err := as.CreateBatch(ctx, app1.Namespace, []*specV1.Application{app1, app2})
+	assert.NoError(t, err)
+	assert.Equal(t, []*specV1.Application{app1, app2}, apps)
+}
+
+func TestDefaultApplicationService_UnusedVolumes(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Configuration{}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Secret{}, nil).AnyTimes()
+
+	// the volume test-2 is not mounted
+	app, _ := genAppTestCase()
+	assert.Equal(t, []string{"test-2"}, unusedVolumes(app))
+
+	// warned only
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil).Times(2)
+	_, err := as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{})
+	assert.NoError(t, err)
+
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrUnusedVolume, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "test-2")
+
+	app.Services[0].VolumeMounts = append(app.Services[0].VolumeMounts, specV1.VolumeMount{Name: "test-2", MountPath: "other", ReadOnly: true})
+	assert.Empty(t, unusedVolumes(app))
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil)
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{Strict: true})
+	assert.NoError(t, err)
+}
+
+func TestDefaultApplicationService_SecretMountWritable(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Configuration{}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Secret{}, nil).AnyTimes()
+
+	// the secret volume test-2 is mounted writable, the config volume test may be
+	app, _ := genAppTestCase()
+	app.Services[0].VolumeMounts = append(app.Services[0].VolumeMounts, specV1.VolumeMount{Name: "test-2", MountPath: "secret"})
+	assert.Equal(t, []string{"Services[0].VolumeMounts[1]"}, writableSecretMounts(app))
+
+	// warned only
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil)
+	res, err := as.CreateWithResult(ctx, app.Namespace, app, &models.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, res.Warnings, 2)
+	assert.Equal(t, models.WarnSecretMountWritable, res.Warnings[0].Code)
+	assert.Equal(t, "Services[0].VolumeMounts[1]", res.Warnings[0].Where)
+
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrSecretMountWritable, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "Services[0].VolumeMounts[1]")
+
+	app.Services[0].VolumeMounts[1].ReadOnly = true
+	assert.Empty(t, writableSecretMounts(app))
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil)
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{Strict: true})
+	assert.NoError(t, err)
+}
+
+func TestDefaultApplicationService_SkipValidation(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Configuration{}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Secret{}, nil).AnyTimes()
+
+	// the duplicated service names are invalid
+	app, _ := genAppTestCase()
+	app.Services = append(app.Services, app.Services[0])
+	_, err := as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{})
+	assert.Error(t, err)
+
+	// skipping validation is denied unless allowed by config
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{SkipValidation: true})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestAccessDenied, err.(errors.Coder).Code())
+
+	as.conf.AllowSkipValidation = true
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{SkipValidation: true, Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	// the history and index are still written
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(app.Namespace, app.Name, gomock.Any()).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(app.Namespace, app.Name, gomock.Any()).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(app, "migrator").Return(nil, nil)
+	res, err := as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{SkipValidation: true, Operator: "migrator"})
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+}
+
+func TestDefaultApplicationService_Metadata(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	// the stored application is returned as it was written
+	var stored *specV1.Application
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+		res := *app
+		stored = &res
+		return &res, nil
+	})
+	mockObject.modelStorage.EXPECT().GetApplication("default", "meta", "").DoAndReturn(func(_, _, _ string) (*specV1.Application, error) {
+		return stored, nil
+	})
+
+	labels := map[string]string{"owner": "alice", "team": "edge", "ticket": "OPS-123"}
+	app := &specV1.Application{
+		Name:        "meta",
+		Namespace:   "default",
+		Labels:      labels,
+		Description: "see https://example.com/ticket/OPS-123",
+	}
+	_, err := as.Create(ctx, "default", app)
+	assert.NoError(t, err)
+	res, err := as.Get(ctx, "default", "meta", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "alice", "team": "edge", "ticket": "OPS-123"}, res.Labels)
+	assert.Equal(t, "see https://example.com/ticket/OPS-123", res.Description)
+}
+
+func TestDefaultApplicationService_Quota(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+		conf: config.AppConfig{
+			Quota: config.AppQuotaConfig{Limit: 2, Namespaces: map[string]int{"other": 1}},
+		},
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Configuration{}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(&specV1.Secret{}, nil).AnyTimes()
+
+	app, _ := genAppTestCase()
+	// the quota is checked by the count of storage
+	one := &models.ApplicationList{Total: 1, ListOptions: &models.ListOptions{}, Items: []models.AppItem{{Name: "a"}}}
+	two := &models.ApplicationList{Total: 2, ListOptions: &models.ListOptions{Continue: "YQ"}, Items: []models.AppItem{{Name: "a"}}}
+
+	// under limit
+	mockObject.modelStorage.EXPECT().ListApplication(app.Namespace, &models.ListOptions{Limit: 1}).Return(one, nil)
+	mockObject.modelStorage.EXPECT().CreateApplication(app.Namespace, app).Return(app, nil)
+	_, err := as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+
+	// at limit
+	mockObject.modelStorage.EXPECT().ListApplication(app.Namespace, gomock.Any()).Return(two, nil)
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrQuotaExceeded, err.(errors.Coder).Code())
+
+	// the namespace override
+	other, _ := genAppTestCase()
+	other.Namespace = ""
+	mockObject.modelStorage.EXPECT().ListApplication("other", gomock.Any()).Return(one, nil)
+	_, err = as.Create(ctx, "other", other)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrQuotaExceeded, err.(errors.Coder).Code())
+
+	// the batch crosses the limit, nothing is created
+	app2, _ := genAppTestCase()
+	app2.Name = "def"
+	mockObject.modelStorage.EXPECT().ListApplication(app.Namespace, gomock.Any()).Return(one, nil)
+	_, err = as.CreateBatch(ctx, app.Namespace, []*specV1.Application{app, app2})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrQuotaExceeded, err.(errors.Coder).Code())
+
+	// update is never blocked
+	current := *app
+	current.Description = "current"
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(&current, nil)
+	mockObject.modelStorage.EXPECT().UpdateApplication(app.Namespace, app).Return(app, nil)
+	_, err = as.Update(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+}
+
+func TestDefaultApplicationService_Export(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+	app, _ := genAppTestCase()
+	// the config is referenced twice but exported once
+	app.Volumes = append(app.Volumes, specV1.Volume{
+		Name:         "test-3",
+		VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "agent-conf"}},
+	})
+	cfg := &specV1.Configuration{Namespace: app.Namespace, Name: "agent-conf", Data: map[string]string{"conf.yml": "a: b"}}
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, app.Version).Return(app, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(cfg, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").DoAndReturn(func(namespace, name, _ string) (*specV1.Secret, error) {
+		return &specV1.Secret{Namespace: namespace, Name: name, Data: map[string][]byte{"password": []byte("123456")}}, nil
+	}).Times(2)
+
+	bundle, err := as.Export(ctx, app.Namespace, app.Name, app.Version)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ApplicationBundleFormat, bundle.Format)
+	assert.Equal(t, app, bundle.App)
+	assert.Equal(t, []specV1.Configuration{*cfg}, bundle.Configs)
+	assert.Len(t, bundle.Secrets, 1)
+	assert.Equal(t, map[string][]byte{"password": []byte("123456")}, bundle.Secrets[0].Data)
+
+	bundle, err = as.ExportWithOptions(ctx, app.Namespace, app.Name, app.Version, &models.ExportOptions{Redact: true})
+	assert.NoError(t, err)
+	assert.Len(t, bundle.Configs, 1)
+	assert.Len(t, bundle.Secrets, 1)
+	assert.Equal(t, "test-secret-02", bundle.Secrets[0].Name)
+	assert.Equal(t, map[string][]byte{"password": {}}, bundle.Secrets[0].Data)
+
+	// the referenced secret is missing
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, app.Version).Return(app, nil)
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(cfg, nil)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(nil, common.Error(common.ErrResourceNotFound))
+	_, err = as.Export(ctx, app.Namespace, app.Name, app.Version)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_Describe(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+	app, _ := genAppTestCase()
+	app.Volumes = append(app.Volumes, specV1.Volume{
+		Name:         "test-3",
+		VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "missing"}},
+	})
+	cfg := &specV1.Configuration{Namespace: app.Namespace, Name: "agent-conf", Data: map[string]string{"conf.yml": "a: b"}}
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, app.Version).Return(app, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(cfg, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "missing", "").Return(nil, plugin.NotFound(fmt.Errorf("configs not found"))).Times(2)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").DoAndReturn(func(namespace, name, _ string) (*specV1.Secret, error) {
+		return &specV1.Secret{Namespace: namespace, Name: name, Data: map[string][]byte{"password": []byte("123456")}}, nil
+	}).Times(2)
+
+	// the secrets are redacted by default
+	desc, err := as.Describe(ctx, app.Namespace, app.Name, app.Version)
+	assert.NoError(t, err)
+	assert.Equal(t, app, desc.App)
+	assert.Equal(t, []specV1.Configuration{*cfg}, desc.Configs)
+	assert.Len(t, desc.Secrets, 1)
+	assert.Equal(t, "test-secret-02", desc.Secrets[0].Name)
+	assert.Equal(t, map[string][]byte{"password": {}}, desc.Secrets[0].Data)
+	assert.Equal(t, []string{"missing"}, desc.MissingConfigs)
+	assert.Empty(t, desc.MissingSecrets)
+
+	desc, err = as.DescribeWithOptions(ctx, app.Namespace, app.Name, app.Version, &models.DescribeOptions{ShowSecrets: true})
+	assert.NoError(t, err)
+	assert.Len(t, desc.Configs, 1)
+	assert.Equal(t, map[string][]byte{"password": []byte("123456")}, desc.Secrets[0].Data)
+
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, "other", "").Return(nil, fmt.Errorf("error"))
+	_, err = as.Describe(ctx, app.Namespace, "other", "")
+	assert.EqualError(t, err, "error")
+}
+
+func TestDefaultApplicationService_Import(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	// the configs and secrets stored in the destination namespace
+	configs := map[string]*specV1.Configuration{}
+	secrets := map[string]*specV1.Secret{}
+	mockObject.modelStorage.EXPECT().GetConfig("dst", gomock.Any(), "").DoAndReturn(func(_, name, _ string) (*specV1.Configuration, error) {
+		if cfg, ok := configs[name]; ok {
+			return cfg, nil
+		}
+		return nil, common.Error(common.ErrResourceNotFound)
+	}).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateConfig("dst", gomock.Any()).DoAndReturn(func(_ string, cfg *specV1.Configuration) (*specV1.Configuration, error) {
+		if _, ok := configs[cfg.Name]; ok {
+			return nil, fmt.Errorf("configs \"%s\" already exists", cfg.Name)
+		}
+		res := *cfg
+		res.Version = "c1"
+		configs[cfg.Name] = &res
+		return &res, nil
+	}).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret("dst", gomock.Any(), "").DoAndReturn(func(_, name, _ string) (*specV1.Secret, error) {
+		if scr, ok := secrets[name]; ok {
+			return scr, nil
+		}
+		return nil, common.Error(common.ErrResourceNotFound)
+	}).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateSecret("dst", gomock.Any()).DoAndReturn(func(_ string, scr *specV1.Secret) (*specV1.Secret, error) {
+		if _, ok := secrets[scr.Name]; ok {
+			return nil, fmt.Errorf("secrets \"%s\" already exists", scr.Name)
+		}
+		res := *scr
+		res.Version = "s1"
+		secrets[scr.Name] = &res
+		return &res, nil
+	}).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication("dst", "abc", "").Return(nil, common.Error(common.ErrResourceNotFound)).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateApplication("dst", gomock.Any()).DoAndReturn(func(namespace string, app *specV1.Application) (*specV1.Application, error) {
+		return app, nil
+	}).AnyTimes()
+
+	genBundle := func() *models.ApplicationBundle {
+		app, _ := genAppTestCase()
+		return &models.ApplicationBundle{
+			Format:  models.ApplicationBundleFormat,
+			App:     app,
+			Configs: []specV1.Configuration{{Namespace: "default", Name: "agent-conf", Version: "1", Data: map[string]string{"a": "b"}}},
+			Secrets: []specV1.Secret{{Namespace: "default", Name: "test-secret-02", Version: "1", Data: map[string][]byte{"c": []byte("d")}}},
+		}
+	}
+
+	// a bad bundle imports nothing
+	bundle := genBundle()
+	bundle.Secrets = nil
+	_, err := as.Import(ctx, "dst", bundle, nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+	bundle = genBundle()
+	bundle.Format = "v0"
+	_, err = as.Import(ctx, "dst", bundle, nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	// the checks of create are run before import as well
+	bad := []struct {
+		code   string
+		modify func(app *specV1.Application)
+	}{
+		{code: common.ErrInvalidName, modify: func(app *specV1.Application) { app.Services[0].Name = "Agent_1" }},
+		{code: common.ErrInvalidImageRef, modify: func(app *specV1.Application) { app.Services[0].Image = "UPPER/Image" }},
+		{code: common.ErrSchemaViolation, modify: func(app *specV1.Application) { app.Type = "vm" }},
+		{code: common.ErrSpecTooLarge, modify: func(app *specV1.Application) { as.conf.Limit.Volumes = 1 }},
+		{code: common.ErrVolumeType, modify: func(app *specV1.Application) {
+			app.Volumes[0].Secret = &specV1.ObjectReference{Name: "test-secret-02"}
+		}},
+	}
+	for _, tt := range bad {
+		bundle = genBundle()
+		tt.modify(bundle.App)
+		_, err = as.Import(ctx, "dst", bundle, nil)
+		assert.Error(t, err, tt.code)
+		assert.Equal(t, tt.code, err.(errors.Coder).Code())
+		as.conf.Limit.Volumes = 0
+	}
+	assert.Empty(t, configs)
+	assert.Empty(t, secrets)
+
+	// clean import
+	bundle = genBundle()
+	app, err := as.Import(ctx, "dst", bundle, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "dst", app.Namespace)
+	assert.Equal(t, specV1.ObjectReference{Name: "agent-conf", Version: "c1"}, *app.Volumes[0].Config)
+	assert.Equal(t, specV1.ObjectReference{Name: "test-secret-02", Version: "s1"}, *app.Volumes[1].Secret)
+	assert.Equal(t, "dst", configs["agent-conf"].Namespace)
+	assert.Equal(t, map[string][]byte{"c": []byte("d")}, secrets["test-secret-02"].Data)
+	// the bundle is not changed
+	assert.Equal(t, genBundle(), bundle)
+
+	// the config names are used, suffixed copies are created
+	app, err = as.Import(ctx, "dst", genBundle(), nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(app.Volumes[0].Config.Name, "agent-conf-"))
+	assert.True(t, strings.HasPrefix(app.Volumes[1].Secret.Name, "test-secret-02-"))
+	assert.Contains(t, configs, app.Volumes[0].Config.Name)
+	assert.Contains(t, secrets, app.Volumes[1].Secret.Name)
+	assert.Len(t, configs, 2)
+
+	// the existing ones are updated with overwrite
+	mockObject.modelStorage.EXPECT().UpdateConfig("dst", gomock.Any()).DoAndReturn(func(_ string, cfg *specV1.Configuration) (*specV1.Configuration, error) {
+		assert.Equal(t, "c1", cfg.Version)
+		res := *cfg
+		res.Version = "c2"
+		configs[cfg.Name] = &res
+		return &res, nil
+	})
+	mockObject.modelStorage.EXPECT().UpdateSecret("dst", gomock.Any()).DoAndReturn(func(_ string, scr *specV1.Secret) (*specV1.Secret, error) {
+		res := *scr
+		res.Version = "s2"
+		secrets[scr.Name] = &res
+		return &res, nil
+	})
+	app, err = as.Import(ctx, "dst", genBundle(), &models.ImportOptions{Overwrite: true})
+	assert.NoError(t, err)
+	assert.Equal(t, specV1.ObjectReference{Name: "agent-conf", Version: "c2"}, *app.Volumes[0].Config)
+	assert.Equal(t, specV1.ObjectReference{Name: "test-secret-02", Version: "s2"}, *app.Volumes[1].Secret)
+	assert.Len(t, configs, 2)
+}
+
+func TestDefaultApplicationService_ListStream(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage: mockObject.modelStorage,
+	}
+	// two pages in storage
+	pages := map[string]*models.ApplicationList{
+		"": {
+			Items:       []models.AppItem{{Name: "a"}, {Name: "b"}},
+			ListOptions: &models.ListOptions{Continue: "next"},
+		},
+		"next": {
+			Items:       []models.AppItem{{Name: "c"}},
+			ListOptions: &models.ListOptions{},
+		},
+	}
+	mockObject.modelStorage.EXPECT().ListApplication("default", gomock.Any()).DoAndReturn(func(_ string, opts *models.ListOptions) (*models.ApplicationList, error) {
+		assert.Equal(t, int64(listStreamPageSize), opts.Limit)
+		list := *pages[opts.Continue]
+		return &list, nil
+	}).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication("default", gomock.Any(), "").DoAndReturn(func(namespace, name, _ string) (*specV1.Application, error) {
+		return &specV1.Application{Namespace: namespace, Name: name}, nil
+	}).AnyTimes()
+
+	var names []string
+	err := as.ListStream(ctx, "default", nil, func(app *specV1.Application) error {
+		names = append(names, app.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	// the error of fn aborts the iteration
+	names = nil
+	err = as.ListStream(ctx, "default", &models.ListOptions{}, func(app *specV1.Application) error {
+		names = append(names, app.Name)
+		if app.Name == "b" {
+			return fmt.Errorf("stop")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	// the specs of a page are got in a single query if the storage supports batch, the missing ones are left out
+	batch := mockPlugin.NewMockApplicationBatchGetter(mockObject.ctl)
+	as.storage = &batchModelStorage{MockModelStorage: mockObject.modelStorage, MockApplicationBatchGetter: batch}
+	batch.EXPECT().GetApplications("default", []string{"a", "b"}).Return([]specV1.Application{{Namespace: "default", Name: "a"}}, nil)
+	batch.EXPECT().GetApplications("default", []string{"c"}).Return([]specV1.Application{{Namespace: "default", Name: "c"}}, nil)
+	names = nil
+	err = as.ListStream(ctx, "default", nil, func(app *specV1.Application) error {
+		names = append(names, app.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, names)
+}
+
+func TestDefaultApplicationService_RenderWithBase(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+	// nothing is written to storage
+	mockObject.modelStorage.EXPECT().CreateConfig(gomock.Any(), gomock.Any()).Times(0)
+	mockObject.modelStorage.EXPECT().CreateSecret(gomock.Any(), gomock.Any()).Times(0)
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Times(0)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), gomock.Any()).Times(0)
+
+	newApp, baseApp := genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	baseApp.Volumes = append(baseApp.Volumes, specV1.Volume{
+		Name:         "test-03",
+		VolumeSource: specV1.VolumeSource{Secret: &specV1.ObjectReference{Name: "base-secret"}},
+	})
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "agent-conf", "").Return(&specV1.Configuration{Name: "agent-conf"}, nil)
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Name: "agent-conf"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret("baetyl-cloud", "base-secret", "").Return(&specV1.Secret{Name: "base-secret"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret("default", "base-secret", "").Return(nil, plugin.NotFound(fmt.Errorf("secrets \"base-secret\" not found")))
+	res, err := as.RenderWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ConfigCopy{
+		{Volume: "test-02", Type: "config", Name: "agent-conf", Namespace: "baetyl-cloud", Conflict: true},
+		{Volume: "test-03", Type: "secret", Name: "base-secret", Namespace: "baetyl-cloud"},
+	}, res.Copies)
+	assert.Len(t, res.App.Services, 2)
+	assert.Equal(t, "agent-02", res.App.Services[0].Name)
+	assert.Equal(t, "agent", res.App.Services[1].Name)
+	assert.Len(t, res.App.Volumes, 4)
+	assert.Equal(t, "agent-conf", res.App.Volumes[0].Config.Name)
+	assert.Equal(t, "version01", res.App.Volumes[0].Config.Version)
+	// the inputs are untouched
+	assert.Len(t, newApp.Services, 1)
+	assert.Len(t, newApp.Volumes, 2)
+
+	// base in the same namespace is not copied
+	newApp, baseApp = genAppTestCase()
+	res, err = as.RenderWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Copies)
+	assert.Len(t, res.App.Services, 2)
+
+	res, err = as.RenderWithBase(ctx, newApp.Namespace, newApp, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, newApp, res.App)
+
+	// missing config of base
+	newApp, baseApp = genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "agent-conf", "").Return(nil, fmt.Errorf("error"))
+	_, err = as.RenderWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	// name conflict
+	newApp, baseApp = genAppTestCase()
+	newApp.Services = append(newApp.Services, specV1.Service{Name: "agent-02"})
+	_, err = as.RenderWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_CreateWithBase(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	mockObject.modelStorage.EXPECT().ListConfig(gomock.Any(), gomock.Any()).Return(&models.ConfigurationList{}, nil).AnyTimes()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	mockIndexService.EXPECT().RefreshIndex(gomock.Any(), common.Application, common.Base, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	config := &specV1.Configuration{Name: "agent-conf", Version: "123"}
+	secret2 := &specV1.Secret{Name: "test-secret-02", Version: "123"}
+
+	newApp, baseApp := genAppTestCase()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Return(newApp, nil).Times(1)
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(config, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(secret2, nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, fmt.Errorf("error"))
+	_, err := as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.NoError(t, err)
+
+	mockObject.modelStorage.EXPECT().CreateConfig(gomock.Any(), gomock.Any()).Return(config, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(config, fmt.Errorf("error")).Times(1)
+	baseApp.Namespace = "test01"
+	_, err = as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.NotNil(t, err)
+
+	newApp, baseApp = genAppTestCase()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Return(newApp, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(config, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil)
+	baseApp.Namespace = "test02"
+	_, err = as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.NoError(t, err)
+
+	newApp, baseApp = genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil)
+	baseApp.Namespace = "test02"
+	_, err = as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.NoError(t, err)
+
+	newApp, baseApp = genAppTestCase()
+	newApp.Volumes = append(newApp.Volumes, specV1.Volume{Name: "test"})
+	baseApp.Namespace = "test02"
+	_, err = as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.Error(t, err)
+
+	newApp, baseApp = genAppTestCase()
+	newApp.Services = append(newApp.Services, specV1.Service{Name: "agent"})
+	baseApp.Namespace = "test02"
+	_, err = as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.Error(t, err)
+
+	newApp, baseApp = genAppTestCase()
+	newApp.Services[0].VolumeMounts = append(newApp.Services[0].VolumeMounts,
+		specV1.VolumeMount{
+			Name:      "test_01",
+			MountPath: "mountPath",
+		})
+	baseApp.Namespace = "test02"
+	_, err = as.CreateWithBase(ctx, newApp.Namespace, newApp, baseApp)
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_CreateWithBases(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	mockObject.modelStorage.EXPECT().ListConfig(gomock.Any(), gomock.Any()).Return(&models.ConfigurationList{}, nil).AnyTimes()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	genBase := func(namespace, name, service, volume string) *specV1.Application {
+		return &specV1.Application{
+			Namespace: namespace,
+			Name:      name,
+			Services: []specV1.Service{{
+				Name:         service,
+				Image:        "image",
+				VolumeMounts: []specV1.VolumeMount{{Name: volume, MountPath: "/etc/" + volume}},
+			}},
+			Volumes: []specV1.Volume{{
+				Name:         volume,
+				VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: volume}},
+			}},
+		}
+	}
+
+	// merge two bases
+	app, _ := genAppTestCase()
+	sidecar := genBase("baetyl-cloud", "sidecar", "sidecar", "sidecar-conf")
+	logging := genBase("default", "logging", "logger", "logger-conf")
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "sidecar-conf", "").Return(&specV1.Configuration{Name: "sidecar-conf"}, nil)
+	mockObject.modelStorage.EXPECT().CreateConfig("default", gomock.Any()).Return(&specV1.Configuration{Name: "sidecar-conf", Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().GetConfig("default", gomock.Any(), "").Return(&specV1.Configuration{Version: "1"}, nil).Times(3)
+	mockObject.modelStorage.EXPECT().GetSecret("default", "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		})
+	mockIndexService.EXPECT().RefreshConfigIndexByApp("default", app.Name, []string{"sidecar-conf", "logger-conf", "agent-conf"}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp("default", app.Name, []string{"test-secret-02"}).Return(nil)
+	// both bases are protected from deletion
+	mockIndexService.EXPECT().RefreshIndex("baetyl-cloud", common.Application, common.Base, "default/"+app.Name, []string{"sidecar"}).Return(nil)
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, app.Name, []string{"logging"}).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil)
+	res, err := as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{sidecar, logging}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "baetyl-cloud/sidecar,logging", res.Labels[common.LabelBaseApp])
+	var services, volumes []string
+	for _, s := range res.Services {
+		services = append(services, s.Name)
+	}
+	for _, v := range res.Volumes {
+		volumes = append(volumes, v.Name)
+	}
+	assert.Equal(t, []string{"sidecar", "logger", "agent"}, services)
+	assert.Equal(t, []string{"sidecar-conf", "logger-conf", "test", "test-2"}, volumes)
+
+	// conflict between bases
+	app, _ = genAppTestCase()
+	sidecar = genBase("baetyl-cloud", "sidecar", "sidecar", "sidecar-conf")
+	logging = genBase("default", "logging", "sidecar", "sidecar-conf")
+	_, err = as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{sidecar, logging}, nil)
+	assert.Error(t, err)
+	errs := err.(*common.MultiError).Errors()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, common.ErrAppNameConflict, errs[0].(errors.Coder).Code())
+	assert.Contains(t, errs[0].Error(), "bases[1](logging).Services[0]")
+	assert.Contains(t, errs[1].Error(), "bases[1](logging).Volumes[0]")
+}
+
+func TestDefaultApplicationService_SelfReference(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+	// the copy of base creates sidecar-conf in default, which the volume of app references
+	app, _ := genAppTestCase()
+	app.Volumes = append(app.Volumes, specV1.Volume{
+		Name:         "mine",
+		VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "sidecar-conf"}},
+	})
+	app.Services[0].VolumeMounts = append(app.Services[0].VolumeMounts, specV1.VolumeMount{Name: "mine", MountPath: "/etc/mine"})
+	base := &specV1.Application{
+		Namespace: "baetyl-cloud",
+		Name:      "sidecar",
+		Services:  []specV1.Service{{Name: "sidecar", Image: "image", VolumeMounts: []specV1.VolumeMount{{Name: "sidecar-conf", MountPath: "/etc/sidecar"}}}},
+		Volumes:   []specV1.Volume{{Name: "sidecar-conf", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "sidecar-conf"}}}},
+	}
+	mockObject.modelStorage.EXPECT().GetConfig("default", "sidecar-conf", "").Return(nil, plugin.NotFound(fmt.Errorf("configs \"sidecar-conf\" not found")))
+	_, err := as.CreateWithBases(context.Background(), app.Namespace, app, []*specV1.Application{base}, &models.CreateOptions{NoDefaultBase: true})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrSelfReference, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(sidecar-conf) of volume (mine)")
+
+	// the existing one is referenced, the copy is suffixed
+	copies := []models.ConfigCopy{{Volume: "sidecar-conf", Type: "config", Name: "sidecar-conf", Namespace: "baetyl-cloud", Conflict: true}}
+	assert.NoError(t, validSelfReference(app, copies))
+	copies[0].Conflict = false
+	assert.Error(t, validSelfReference(app, copies))
+	copies[0].Type = "secret"
+	assert.NoError(t, validSelfReference(app, copies))
+}
+
+func TestDefaultApplicationService_DefaultBase(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	as.conf.DefaultBase.Name = "logging"
+	as.conf.DefaultBase.Namespaces = map[string]config.AppBaseRef{"other": {}}
+	logging := &specV1.Application{
+		Namespace: "default",
+		Name:      "logging",
+		Services: []specV1.Service{{
+			Name:         "logger",
+			Image:        "image",
+			VolumeMounts: []specV1.VolumeMount{{Name: "logger-conf", MountPath: "/etc/logger"}},
+		}},
+		Volumes: []specV1.Volume{{
+			Name:         "logger-conf",
+			VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "logger-conf"}},
+		}},
+	}
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		}).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshIndex(gomock.Any(), common.Application, common.Base, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	names := func(app *specV1.Application) []string {
+		var res []string
+		for _, s := range app.Services {
+			res = append(res, s.Name)
+		}
+		return res
+	}
+
+	// applied by create
+	mockObject.modelStorage.EXPECT().GetApplication("default", "logging", "").Return(logging, nil).Times(3)
+	app, _ := genAppTestCase()
+	res, err := as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "agent"}, names(res))
+	assert.Equal(t, "logger-conf", res.Volumes[0].Name)
+	assert.Equal(t, "logging", res.Labels[common.LabelBaseApp])
+	// the stored base is not changed
+	assert.Len(t, logging.Services, 1)
+
+	// not merged again into the app restored or cloned, which has it already
+	merged := res
+	mockObject.dbStorage.EXPECT().GetSoftDeletedApplication(merged.Name, "default", gomock.Any()).Return(merged, nil)
+	mockObject.dbStorage.EXPECT().RestoreApplication(merged.Name, "default", gomock.Any()).Return(nil, nil)
+	res, err = as.Restore(ctx, "default", merged.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "agent"}, names(res))
+	mockObject.modelStorage.EXPECT().GetApplication("default", merged.Name, "").Return(merged, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("default", "clone", "").Return(nil, plugin.NotFound(fmt.Errorf("applications.baetyl.io \"clone\" not found")))
+	res, err = as.Clone(ctx, "default", merged.Name, "", "default", "clone")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "agent"}, names(res))
+	assert.Equal(t, "logging", res.Labels[common.LabelBaseApp])
+
+	// applied with options
+	app, _ = genAppTestCase()
+	res, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{Operator: "user"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "agent"}, names(res))
+
+	// applied before the bases, only once if it's passed as well
+	app, _ = genAppTestCase()
+	sidecar := &specV1.Application{Namespace: "default", Name: "sidecar", Services: []specV1.Service{{Name: "sidecar", Image: "image"}}}
+	res, err = as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{sidecar, logging}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sidecar", "logger", "agent"}, names(res))
+
+	// opt out
+	app, _ = genAppTestCase()
+	res, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{NoDefaultBase: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"agent"}, names(res))
+	app, _ = genAppTestCase()
+	res, err = as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{sidecar}, &models.CreateOptions{NoDefaultBase: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sidecar", "agent"}, names(res))
+
+	// the base itself and the namespace without default base
+	res, err = as.Create(ctx, "default", &specV1.Application{Namespace: "default", Name: "logging", Services: logging.Services, Volumes: logging.Volumes})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger"}, names(res))
+	app, _ = genAppTestCase()
+	app.Namespace = "other"
+	res, err = as.Create(ctx, app.Namespace, app)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"agent"}, names(res))
+
+	// applied by create batch
+	mockObject.modelStorage.EXPECT().GetApplication("default", "logging", "").Return(logging, nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApps("default", gomock.Any()).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApps("default", gomock.Any()).Return(nil)
+	app, _ = genAppTestCase()
+	apps, err := as.CreateBatch(ctx, app.Namespace, []*specV1.Application{app})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "agent"}, names(apps[0]))
+
+	// the base is missing
+	mockObject.modelStorage.EXPECT().GetApplication("default", "logging", "").Return(nil, plugin.NotFound(fmt.Errorf("applications.baetyl.io \"logging\" not found")))
+	app, _ = genAppTestCase()
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_MergeStrategy(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		}).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp("default", gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp("default", gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "app", []string{"base"}).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	gen := func() (*specV1.Application, *specV1.Application) {
+		base := &specV1.Application{
+			Namespace: "default",
+			Name:      "base",
+			Services:  []specV1.Service{{Name: "web", Image: "base"}, {Name: "sidecar", Image: "sidecar"}},
+		}
+		app := &specV1.Application{
+			Namespace: "default",
+			Name:      "app",
+			Services:  []specV1.Service{{Name: "web", Image: "app"}},
+		}
+		return app, base
+	}
+	images := func(app *specV1.Application) []string {
+		var res []string
+		for _, s := range app.Services {
+			res = append(res, s.Name+":"+s.Image)
+		}
+		return res
+	}
+
+	// error by default
+	app, base := gen()
+	_, err := as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{base}, nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+	app, base = gen()
+	_, err = as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{base}, &models.CreateOptions{MergeStrategy: models.MergeError})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+
+	// the app's service wins
+	app, base = gen()
+	res, err := as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{base}, &models.CreateOptions{MergeStrategy: models.MergeOverride})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sidecar:sidecar", "web:app"}, images(res))
+
+	// the base's service is kept
+	app, base = gen()
+	res, err = as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{base}, &models.CreateOptions{MergeStrategy: models.MergeSkip})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web:base", "sidecar:sidecar"}, images(res))
+
+	app, base = gen()
+	_, err = as.CreateWithBases(ctx, app.Namespace, app, []*specV1.Application{base}, &models.CreateOptions{MergeStrategy: "unknown"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+}
+
+func TestDeepMergeService(t *testing.T) {
+	base := specV1.Service{
+		Name:     "web",
+		Hostname: "web",
+		Image:    "base",
+		Replica:  1,
+		Env:      []specV1.Environment{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}},
+		VolumeMounts: []specV1.VolumeMount{
+			{Name: "conf", MountPath: "/etc/conf"},
+			{Name: "data", MountPath: "/data"},
+		},
+		Labels: map[string]string{"a": "base", "b": "base"},
+	}
+	app := specV1.Service{
+		Name:  "web",
+		Image: "app",
+		Args:  []string{"-c", "/etc/conf"},
+		Env:   []specV1.Environment{{Name: "B", Value: "3"}, {Name: "C", Value: "4"}},
+		VolumeMounts: []specV1.VolumeMount{
+			{Name: "logs", MountPath: "/var/log"},
+			{Name: "conf2", MountPath: "/etc/conf", ReadOnly: true},
+		},
+		Labels: map[string]string{"b": "app"},
+	}
+	expect := specV1.Service{
+		Name:     "web",
+		Hostname: "web",
+		Image:    "app",
+		Replica:  1,
+		Args:     []string{"-c", "/etc/conf"},
+		Env: []specV1.Environment{
+			{Name: "A", Value: "1"},
+			{Name: "B", Value: "3"},
+			{Name: "C", Value: "4"},
+		},
+		VolumeMounts: []specV1.VolumeMount{
+			{Name: "conf2", MountPath: "/etc/conf", ReadOnly: true},
+			{Name: "data", MountPath: "/data"},
+			{Name: "logs", MountPath: "/var/log"},
+		},
+		Labels: map[string]string{"a": "base", "b": "app"},
+	}
+	assert.Equal(t, expect, deepMergeService(base, app))
+	// the base isn't changed
+	assert.Equal(t, "2", base.Env[1].Value)
+	assert.Equal(t, "conf", base.VolumeMounts[0].Name)
+	assert.Equal(t, "base", base.Labels["b"])
+}
+
+func TestDefaultApplicationService_MergeDeep(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		})
+	// the merged app mounts host paths only, so there is no index to write
+	mockIndexService.EXPECT().RefreshConfigIndexByApp("default", "app", gomock.Any()).Times(0)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp("default", "app", gomock.Any()).Times(0)
+	mockIndexService.EXPECT().RefreshIndex("default", common.Application, common.Base, "app", []string{"base"}).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil)
+
+	hostPath := func(name string) specV1.Volume {
+		return specV1.Volume{Name: name, VolumeSource: specV1.VolumeSource{HostPath: &specV1.HostPathVolumeSource{Path: "/" + name}}}
+	}
+	base := &specV1.Application{
+		Namespace: "default",
+		Name:      "base",
+		Services: []specV1.Service{
+			{Name: "sidecar", Image: "sidecar"},
+			{
+				Name:         "web",
+				Image:        "base",
+				Env:          []specV1.Environment{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}},
+				VolumeMounts: []specV1.VolumeMount{{Name: "conf", MountPath: "/etc/conf"}},
+			},
+		},
+		Volumes: []specV1.Volume{hostPath("conf"), hostPath("data")},
+	}
+	app := &specV1.Application{
+		Namespace: "default",
+		Name:      "app",
+		Services: []specV1.Service{{
+			Name:         "web",
+			Image:        "app",
+			Env:          []specV1.Environment{{Name: "B", Value: "3"}},
+			VolumeMounts: []specV1.VolumeMount{{Name: "data", MountPath: "/data"}},
+		}},
+		Volumes: []specV1.Volume{{Name: "data", VolumeSource: specV1.VolumeSource{HostPath: &specV1.HostPathVolumeSource{Path: "/app/data"}}}},
+	}
+	res, err := as.CreateWithBases(context.Background(), app.Namespace, app, []*specV1.Application{base}, &models.CreateOptions{MergeStrategy: models.MergeDeep})
+	assert.NoError(t, err)
+	assert.Len(t, res.Services, 2)
+	assert.Equal(t, "sidecar", res.Services[0].Name)
+	assert.Equal(t, "web", res.Services[1].Name)
+	assert.Equal(t, "app", res.Services[1].Image)
+	assert.Equal(t, []specV1.Environment{{Name: "A", Value: "1"}, {Name: "B", Value: "3"}}, res.Services[1].Env)
+	assert.Equal(t, []specV1.VolumeMount{{Name: "conf", MountPath: "/etc/conf"}, {Name: "data", MountPath: "/data"}}, res.Services[1].VolumeMounts)
+	assert.Len(t, res.Volumes, 2)
+	assert.Equal(t, "conf", res.Volumes[0].Name)
+	assert.Equal(t, "/app/data", res.Volumes[1].HostPath.Path)
+}
+
+func TestDefaultApplicationService_ResolveBases(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+	a := &specV1.Application{Namespace: "default", Name: "a", Labels: map[string]string{common.LabelBaseApp: "b"}}
+	b := &specV1.Application{Namespace: "default", Name: "b", Labels: map[string]string{common.LabelBaseApp: "baetyl-cloud/c"}}
+	c := &specV1.Application{Namespace: "baetyl-cloud", Name: "c"}
+	mockObject.modelStorage.EXPECT().GetApplication("default", "a", "").Return(a, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("default", "b", "").Return(b, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("baetyl-cloud", "c", "").Return(c, nil)
+	bases, err := as.ResolveBases(ctx, "default", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []*specV1.Application{c, b, a}, bases)
+
+	// the bases merged together, the one shared is returned once
+	m := &specV1.Application{Namespace: "default", Name: "m", Labels: map[string]string{common.LabelBaseApp: "baetyl-cloud/c, b"}}
+	mockObject.modelStorage.EXPECT().GetApplication("default", "m", "").Return(m, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("baetyl-cloud", "c", "").Return(c, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("default", "b", "").Return(b, nil)
+	bases, err = as.ResolveBases(ctx, "default", "m")
+	assert.NoError(t, err)
+	assert.Equal(t, []*specV1.Application{c, b, m}, bases)
+
+	// a two-app cycle
+	x := &specV1.Application{Namespace: "default", Name: "x", Labels: map[string]string{common.LabelBaseApp: "y"}}
+	y := &specV1.Application{Namespace: "default", Name: "y", Labels: map[string]string{common.LabelBaseApp: "default/x"}}
+	mockObject.modelStorage.EXPECT().GetApplication("default", "x", "").Return(x, nil)
+	mockObject.modelStorage.EXPECT().GetApplication("default", "y", "").Return(y, nil)
+	_, err = as.ResolveBases(ctx, "default", "x")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrCircularReference, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "cycle=default/x -> default/y -> default/x")
+
+	mockObject.modelStorage.EXPECT().GetApplication("default", "a", "").Return(nil, fmt.Errorf("error"))
+	_, err = as.ResolveBases(ctx, "default", "a")
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_validFunctions(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockFunctionService := ms.NewMockFunctionService(mockObject.ctl)
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:         mockObject.modelStorage,
+		indexService:    mockIndexService,
+		dbStorage:       mockObject.dbStorage,
+		functionService: mockFunctionService,
+		sources:         []string{"cfc", "lambda"},
+	}
+	app, _ := genAppTestCase()
+	genConfig := func(metadata map[string]string) *specV1.Configuration {
+		data, _ := json.Marshal(&specV1.ConfigurationObject{Metadata: metadata})
+		return &specV1.Configuration{
+			Namespace: app.Namespace,
+			Name:      "agent-conf",
+			Data: map[string]string{
+				"conf.yml":                          "a: b",
+				common.ConfigObjectPrefix + "index": string(data),
+			},
+		}
+	}
+	function := genConfig(map[string]string{"type": "function", "function": "process", "version": "2", "userID": "alice"})
+
+	// present in one of the sources
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(function, nil)
+	mockFunctionService.EXPECT().GetFunction(gomock.Any(), "alice", "process", "2", "cfc").
+		Return(nil, common.Error(common.ErrResourceNotFound, common.Field("type", "function")))
+	mockFunctionService.EXPECT().GetFunction(gomock.Any(), "alice", "process", "2", "lambda").Return(&models.Function{Name: "process"}, nil)
+	assert.NoError(t, as.validFunctions(ctx, app.Namespace, app))
+
+	// the user defaults to namespace, the objects which are not functions are not checked
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(genConfig(map[string]string{"type": "function", "function": "process"}), nil)
+	mockFunctionService.EXPECT().GetFunction(gomock.Any(), app.Namespace, "process", "", "cfc").Return(&models.Function{Name: "process"}, nil)
+	assert.NoError(t, as.validFunctions(ctx, app.Namespace, app))
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(genConfig(map[string]string{"type": "object", "bucket": "b"}), nil)
+	assert.NoError(t, as.validFunctions(ctx, app.Namespace, app))
+
+	// the missing config is left to the checks of references
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(nil, plugin.NotFound(fmt.Errorf("configs \"agent-conf\" not found")))
+	assert.NoError(t, as.validFunctions(ctx, app.Namespace, app))
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(nil, fmt.Errorf("error"))
+	assert.Error(t, as.validFunctions(ctx, app.Namespace, app))
+
+	// missing
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(function, nil).Times(3)
+	mockFunctionService.EXPECT().GetFunction(gomock.Any(), "alice", "process", "2", gomock.Any()).
+		Return(nil, common.Error(common.ErrResourceNotFound, common.Field("type", "function"))).Times(6)
+	err := as.validFunctions(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "process:2")
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	_, err = as.Update(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	// other errors are returned at once
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(function, nil)
+	mockFunctionService.EXPECT().GetFunction(gomock.Any(), "alice", "process", "2", "cfc").Return(nil, fmt.Errorf("error"))
+	assert.Error(t, as.validFunctions(ctx, app.Namespace, app))
+
+	// nothing is checked without function source
+	as.sources = nil
+	assert.NoError(t, as.validFunctions(ctx, app.Namespace, app))
+
+	// skipped
+	as.sources = []string{"cfc"}
+	as.conf.SkipFunctionCheck = true
+	assert.NoError(t, as.validFunctions(ctx, app.Namespace, app))
+}
+
+func TestDefaultApplicationService_Update(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+
+	newApp, oldApp := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(&specV1.Application{Version: newApp.Version}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), "").Return(nil, fmt.Errorf("error")).Times(1)
+	_, err := as.Update(ctx, newApp.Namespace, newApp)
+	assert.NotNil(t, err)
+
+	secret1 := &specV1.Secret{Name: "test-secret-01", Version: "123"}
+	secret2 := &specV1.Secret{Name: "test-secret-02", Version: "123"}
+
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockObject.modelStorage.EXPECT().UpdateApplication(newApp.Namespace, newApp).Return(oldApp, nil)
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret1.Name, gomock.Any()).Return(secret1, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, fmt.Errorf("error"))
+	_, err = as.Update(ctx, newApp.Namespace, newApp)
+	assert.NoError(t, err)
+
+	newApp, _ = genAppTestCase()
+	mockObject.modelStorage.EXPECT().UpdateApplication(newApp.Namespace, newApp).Return(nil, fmt.Errorf("error"))
+	_, err = as.Update(ctx, newApp.Namespace, newApp)
+	assert.NotNil(t, err)
+
+	_, oldApp = genAppTestCase()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).Times(1)
+	mockObject.modelStorage.EXPECT().UpdateApplication(gomock.Any(), gomock.Any()).Return(oldApp, nil)
+	_, err = as.Update(ctx, newApp.Namespace, newApp)
+	assert.NotNil(t, err)
+
+}
+
+func TestDefaultApplicationService_Canary(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	current := &specV1.Application{Namespace: "default", Name: "abc", Version: "5", Labels: map[string]string{"a": "b"},
+		Services: []specV1.Service{{Name: "s", Image: "i:2"}}}
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	// no canary, the current version takes all
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(current, nil)
+	canary, err := as.GetCanary(ctx, "default", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ApplicationCanary{Stable: "5"}, canary)
+
+	// set
+	var stored *specV1.Application
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(current, nil).Times(4)
+	mockObject.dbStorage.EXPECT().GetApplication("abc", "default", "3").Return(&specV1.Application{Name: "abc", Version: "3"}, nil)
+	mockObject.modelStorage.EXPECT().UpdateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+			stored, _ = copyApplication(app)
+			stored.Version = "6"
+			return stored, nil
+		})
+	// clearing no canary writes nothing
+	assert.NoError(t, as.SetCanary(ctx, "default", "abc", "v3", "", 0))
+	assert.Nil(t, stored)
+	assert.NoError(t, as.SetCanary(ctx, "default", "abc", "v3", "5", 20))
+	assert.Equal(t, map[string]string{
+		"a":                       "b",
+		common.LabelCanaryStable:  "3",
+		common.LabelCanaryVersion: "5",
+		common.LabelCanaryWeight:  "20",
+	}, stored.Labels)
+
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(stored, nil)
+	canary, err = as.GetCanary(ctx, "default", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ApplicationCanary{Stable: "3", Canary: "5", Weight: 20}, canary)
+
+	// clear
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(stored, nil).Times(2)
+	mockObject.modelStorage.EXPECT().UpdateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+			assert.Equal(t, map[string]string{"a": "b"}, app.Labels)
+			return app, nil
+		})
+	assert.NoError(t, as.SetCanary(ctx, "default", "abc", "", "", 0))
+
+	// invalid
+	for _, weight := range []int{-1, 101} {
+		err = as.SetCanary(ctx, "default", "abc", "3", "5", weight)
+		assert.Error(t, err)
+		assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+	}
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(current, nil).Times(2)
+	err = as.SetCanary(ctx, "default", "abc", "", "v5", 10)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+	mockObject.dbStorage.EXPECT().GetApplication("abc", "default", gomock.Any()).Return(nil, nil).Times(2)
+	err = as.SetCanary(ctx, "default", "abc", "", "9", 10)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_GetDeploymentStatus(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	mockShadow := mockPlugin.NewMockShadow(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		shadow:       mockShadow,
+	}
+	app := &specV1.Application{Namespace: "default", Name: "abc", Version: "12"}
+	report := func(stats ...specV1.AppStats) specV1.Report {
+		r := specV1.Report{}
+		r.SetAppStats(false, stats)
+		return r
+	}
+	stats := func(version string, status specV1.Status) specV1.AppStats {
+		return specV1.AppStats{AppInfo: specV1.AppInfo{Name: "abc", Version: version}, Status: status}
+	}
+	nodes := []string{"n1", "n2", "n3", "n4", "n5", "n6", "n7"}
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	mockIndexService.EXPECT().ListNodesByApp("default", "abc").Return(nodes, nil)
+	mockShadow.EXPECT().List("default", gomock.Any()).DoAndReturn(func(_ string, list *models.NodeList) (*models.ShadowList, error) {
+		assert.Len(t, list.Items, len(nodes))
+		return &models.ShadowList{Items: []models.Shadow{
+			{Name: "n1", Report: report(stats("12", specV1.Running))},
+			{Name: "n2", Report: report(stats("v12", specV1.Running))},
+			{Name: "n3", Report: report(stats("11", specV1.Running))},
+			{Name: "n4", Report: report(stats("12", specV1.Failed))},
+			{Name: "n5", Report: report(stats("12", specV1.Pending))},
+			// another app is reported only
+			{Name: "n6", Report: report(specV1.AppStats{AppInfo: specV1.AppInfo{Name: "other", Version: "12"}, Status: specV1.Running})},
+			// n7 has not reported
+		}}, nil
+	})
+	status, err := as.GetDeploymentStatus(ctx, "default", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.DeploymentStatus{Name: "abc", Version: "12", Total: 7, UpToDate: 2, Outdated: 1, Failed: 1, Pending: 3}, status)
+
+	// no target node
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	mockIndexService.EXPECT().ListNodesByApp("default", "abc").Return(nil, nil)
+	status, err = as.GetDeploymentStatus(ctx, "default", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.DeploymentStatus{Name: "abc", Version: "12"}, status)
+
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	mockIndexService.EXPECT().ListNodesByApp("default", "abc").Return(nodes, nil)
+	mockShadow.EXPECT().List("default", gomock.Any()).Return(nil, fmt.Errorf("shadow error"))
+	_, err = as.GetDeploymentStatus(ctx, "default", "abc")
+	assert.EqualError(t, err, "shadow error")
+}
+
+func TestDefaultApplicationService_Rollback(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+
+	currentApp, historyApp := genAppTestCase()
+	mockObject.dbStorage.EXPECT().GetApplication(currentApp.Name, currentApp.Namespace, "0").Return(nil, nil)
+	_, err := as.Rollback(ctx, currentApp.Namespace, currentApp.Name, "0")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	mockObject.dbStorage.EXPECT().GetApplication(currentApp.Name, currentApp.Namespace, "1").Return(nil, fmt.Errorf("error"))
+	_, err = as.Rollback(ctx, currentApp.Namespace, currentApp.Name, "1")
+	assert.Error(t, err)
+
+	restoredApp := &specV1.Application{
+		Namespace: currentApp.Namespace,
+		Name:      currentApp.Name,
+		Version:   "3",
+		Services:  historyApp.Services,
+		Volumes:   historyApp.Volumes,
+	}
+	mockObject.dbStorage.EXPECT().GetApplication(currentApp.Name, currentApp.Namespace, "1").Return(historyApp, nil)
+	mockObject.modelStorage.EXPECT().GetApplication(currentApp.Namespace, currentApp.Name, "").Return(currentApp, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetConfig(currentApp.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "5"}, nil)
+	mockObject.modelStorage.EXPECT().UpdateApplication(currentApp.Namespace, gomock.Any()).
+		DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+			assert.Equal(t, currentApp.Version, app.Version)
+			assert.Equal(t, historyApp.Services, app.Services)
+			return restoredApp, nil
+		})
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(currentApp.Namespace, currentApp.Name, []string{"agent-conf"}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(currentApp.Namespace, currentApp.Name, nil).Return(nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(restoredApp, "").Return(nil, nil)
+	app, err := as.Rollback(ctx, currentApp.Namespace, currentApp.Name, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", app.Version)
+}
+
+// memoryEventSink keeps the published events in memory
+type memoryEventSink struct {
+	events []models.ApplicationEvent
+	err    error
+}
+
+func (m *memoryEventSink) Publish(event *models.ApplicationEvent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memoryEventSink) Close() error {
+	return nil
+}
+
+func TestDefaultApplicationService_Events(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	sink := &memoryEventSink{}
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+		events:       sink,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().ListIndex(gomock.Any(), common.Application, common.Base, gomock.Any()).Return(nil, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	newApp, oldApp := genAppTestCase()
+	newApp.Volumes, oldApp.Volumes = nil, nil
+	newApp.Services[0].VolumeMounts, oldApp.Services[0].VolumeMounts = nil, nil
+
+	mockObject.modelStorage.EXPECT().CreateApplication(newApp.Namespace, newApp).Return(newApp, nil)
+	_, err := as.Create(ctx, newApp.Namespace, newApp)
+	assert.NoError(t, err)
+	assert.Len(t, sink.events, 1)
+	assert.Equal(t, models.ApplicationCreated, sink.events[0].Type)
+	assert.Equal(t, newApp.Namespace, sink.events[0].Namespace)
+	assert.Equal(t, newApp.Name, sink.events[0].Name)
+	assert.Equal(t, newApp.Version, sink.events[0].Version)
+	assert.False(t, sink.events[0].Time.IsZero())
+
+	current := *oldApp
+	current.Description = "current"
+	mockObject.modelStorage.EXPECT().GetApplication(oldApp.Namespace, oldApp.Name, "").Return(&current, nil)
+	mockObject.modelStorage.EXPECT().UpdateApplication(oldApp.Namespace, oldApp).Return(newApp, nil)
+	_, err = as.Update(ctx, oldApp.Namespace, oldApp)
+	assert.NoError(t, err)
+	assert.Len(t, sink.events, 2)
+	assert.Equal(t, models.ApplicationUpdated, sink.events[1].Type)
+	assert.Equal(t, newApp.Version, sink.events[1].Version)
+
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(newApp.Namespace, newApp.Name).Return(nil)
+	assert.NoError(t, as.Delete(ctx, newApp.Namespace, newApp.Name, newApp.Version))
+	assert.Len(t, sink.events, 3)
+	assert.Equal(t, models.ApplicationDeleted, sink.events[2].Type)
+	assert.Equal(t, newApp.Name, sink.events[2].Name)
+	assert.Equal(t, newApp.Version, sink.events[2].Version)
+
+	// nothing is published if the operation fails
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(newApp.Namespace, newApp.Name).Return(fmt.Errorf("error"))
+	assert.Error(t, as.Delete(ctx, newApp.Namespace, newApp.Name, newApp.Version))
+	assert.Len(t, sink.events, 3)
+
+	// the failure of sink doesn't fail the operation
+	sink.err = fmt.Errorf("error")
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(newApp.Namespace, newApp.Name).Return(nil)
+	assert.NoError(t, as.Delete(ctx, newApp.Namespace, newApp.Name, newApp.Version))
+	assert.Len(t, sink.events, 3)
+
+	// the sink is optional
+	as.events = nil
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(newApp.Namespace, newApp.Name).Return(nil)
+	assert.NoError(t, as.Delete(ctx, newApp.Namespace, newApp.Name, newApp.Version))
+}
+
+func TestDefaultApplicationService_ListHistory(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+
+	newApp, oldApp := genAppTestCase()
+	mockObject.dbStorage.EXPECT().CountApplication(nil, newApp.Name, newApp.Namespace).Return(0, fmt.Errorf("error"))
+	_, err := as.ListHistory(ctx, newApp.Namespace, newApp.Name, nil)
+	assert.Error(t, err)
+
+	mockObject.dbStorage.EXPECT().CountApplication(nil, newApp.Name, newApp.Namespace).Return(2, nil)
+	mockObject.dbStorage.EXPECT().ListApplicationHistory(newApp.Name, newApp.Namespace, 1, 20).Return(nil, fmt.Errorf("error"))
+	_, err = as.ListHistory(ctx, newApp.Namespace, newApp.Name, nil)
+	assert.Error(t, err)
+
+	mockObject.dbStorage.EXPECT().CountApplication(nil, newApp.Name, newApp.Namespace).Return(3, nil)
+	mockObject.dbStorage.EXPECT().ListApplicationHistory(newApp.Name, newApp.Namespace, 1, 2).
+		Return([]models.ApplicationHistory{{App: *newApp, Operator: "alice"}, {App: *oldApp, Operator: common.UnknownOperator}}, nil)
+	opts := &models.ListOptions{PageSize: 2}
+	list, err := as.ListHistory(ctx, newApp.Namespace, newApp.Name, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, list.Total)
+	assert.Equal(t, 1, list.ListOptions.PageNo)
+	// the options of the caller are not changed
+	assert.Equal(t, 0, opts.PageNo)
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, newApp.Version, list.Items[0].Version)
+	assert.Equal(t, "alice", list.Items[0].Operator)
+	assert.Equal(t, oldApp.Version, list.Items[1].Version)
+	assert.Equal(t, common.UnknownOperator, list.Items[1].Operator)
+}
+
+func TestDefaultApplicationService_Operator(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().ListIndex(gomock.Any(), common.Application, common.Base, gomock.Any()).Return(nil, nil).AnyTimes()
+
+	newApp, oldApp := genAppTestCase()
+	newApp.Volumes, oldApp.Volumes = nil, nil
+	newApp.Services[0].VolumeMounts, oldApp.Services[0].VolumeMounts = nil, nil
+	mockObject.modelStorage.EXPECT().CreateApplication(newApp.Namespace, newApp).Return(newApp, nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(newApp, "alice").Return(nil, nil)
+	_, err := as.CreateWithOptions(ctx, newApp.Namespace, newApp, &models.CreateOptions{Operator: "alice"})
+	assert.NoError(t, err)
+
+	current := *oldApp
+	current.Description = "current"
+	mockObject.modelStorage.EXPECT().GetApplication(oldApp.Namespace, oldApp.Name, "").Return(&current, nil)
+	mockObject.modelStorage.EXPECT().UpdateApplication(oldApp.Namespace, oldApp).Return(newApp, nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(newApp, "bob").Return(nil, nil)
+	_, err = as.UpdateWithOptions(ctx, oldApp.Namespace, oldApp, &models.UpdateOptions{Operator: "bob"})
+	assert.NoError(t, err)
+
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(newApp.Namespace, newApp.Name).Return(nil)
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(newApp.Name, newApp.Namespace, newApp.Version, "carol").Return(nil, nil)
+	assert.NoError(t, as.DeleteWithOptions(ctx, newApp.Namespace, newApp.Name, newApp.Version, &models.DeleteOptions{Operator: "carol"}))
+
+	// the operator is left to storage to fill in if unknown
+	mockObject.modelStorage.EXPECT().GetApplication(newApp.Namespace, newApp.Name, "").Return(newApp, nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(newApp.Namespace, newApp.Name).Return(nil)
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(newApp.Name, newApp.Namespace, newApp.Version, "").Return(nil, nil)
+	assert.NoError(t, as.DeleteWithOptions(ctx, newApp.Namespace, newApp.Name, newApp.Version, nil))
+}
+
+func TestDefaultApplicationService_ListHistoryByTime(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+
+	newApp, oldApp := genAppTestCase()
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	_, err := as.ListHistoryByTime(ctx, newApp.Namespace, newApp.Name, end, start)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+	_, err = as.ListHistoryByTime(ctx, newApp.Namespace, newApp.Name, start, start)
+	assert.Error(t, err)
+
+	mockObject.dbStorage.EXPECT().ListApplicationByTime(newApp.Name, newApp.Namespace, start, end).Return(nil, fmt.Errorf("error"))
+	_, err = as.ListHistoryByTime(ctx, newApp.Namespace, newApp.Name, start, end)
+	assert.Error(t, err)
+
+	mockObject.dbStorage.EXPECT().ListApplicationByTime(newApp.Name, newApp.Namespace, start, end).
+		Return([]specV1.Application{*newApp, *oldApp}, nil)
+	list, err := as.ListHistoryByTime(ctx, newApp.Namespace, newApp.Name, start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, list.Total)
+	assert.Equal(t, newApp.Version, list.Items[0].Version)
+	assert.Equal(t, oldApp.Version, list.Items[1].Version)
+
+	mockObject.dbStorage.EXPECT().ListApplicationByTime(newApp.Name, newApp.Namespace, start, time.Time{}).Return(nil, nil)
+	list, err = as.ListHistoryByTime(ctx, newApp.Namespace, newApp.Name, start, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, list.Total)
+	assert.Len(t, list.Items, 0)
+}
+
+func TestDefaultApplicationService_PruneHistory(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+
+	app, _ := genAppTestCase()
+	_, err := as.PruneHistory(ctx, app.Namespace, app.Name, -1)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(nil, plugin.NotFound(fmt.Errorf("application not found")))
+	_, err = as.PruneHistory(ctx, app.Namespace, app.Name, 0)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	// keep only active
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(app, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().PruneApplication(app.Name, app.Namespace, app.Version, 0).Return(&mockSQLResult{affect: 3}, nil)
+	pruned, err := as.PruneHistory(ctx, app.Namespace, app.Name, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pruned)
+
+	// keep more than history
+	mockObject.dbStorage.EXPECT().PruneApplication(app.Name, app.Namespace, app.Version, 100).Return(&mockSQLResult{affect: 0}, nil)
+	pruned, err = as.PruneHistory(ctx, app.Namespace, app.Name, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pruned)
+
+	mockObject.dbStorage.EXPECT().PruneApplication(app.Name, app.Namespace, app.Version, 1).Return(nil, fmt.Errorf("error"))
+	_, err = as.PruneHistory(ctx, app.Namespace, app.Name, 1)
+	assert.Error(t, err)
+}
+
+func TestHistoryPruner_Prune(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	app, _ := genAppTestCase()
+	p := &HistoryPruner{
+		app: &applicationService{
+			storage:   mockObject.modelStorage,
+			dbStorage: mockObject.dbStorage,
+		},
+		db:   mockObject.dbStorage,
+		conf: config.AppHistoryConfig{Keep: 2},
+		done: make(chan struct{}),
+	}
+
+	mockObject.dbStorage.EXPECT().ListHistoryApplications().Return(nil, fmt.Errorf("error"))
+	_, err := p.Prune()
+	assert.Error(t, err)
+
+	mockObject.dbStorage.EXPECT().ListHistoryApplications().Return([]models.AppItem{
+		{Namespace: app.Namespace, Name: app.Name},
+		{Namespace: app.Namespace, Name: "deleted"},
+		{Namespace: app.Namespace, Name: "broken"},
+	}, nil)
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, app.Name, "").Return(app, nil)
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, "deleted", "").Return(nil, plugin.NotFound(fmt.Errorf("application not found")))
+	mockObject.modelStorage.EXPECT().GetApplication(app.Namespace, "broken", "").Return(nil, fmt.Errorf("error"))
+	mockObject.dbStorage.EXPECT().PruneApplication(app.Name, app.Namespace, app.Version, 2).Return(&mockSQLResult{affect: 4}, nil)
+	pruned, err := p.Prune()
+	assert.Error(t, err)
+	assert.Equal(t, 4, pruned)
+
+	// disabled
+	p.Run()
+	p.Close()
+}
+
+func TestHistoryPruner_Disabled(t *testing.T) {
+	conf := mockEmptyTestConfig()
+	conf.Application.History.Disabled = true
+	conf.Application.History.Interval = time.Minute
+	p, err := NewHistoryPruner(conf)
+	assert.NoError(t, err)
+	assert.Nil(t, p.db)
+	p.Run()
+	p.Close()
+}
+
+func TestCanonicalVersion(t *testing.T) {
+	tests := map[string]string{
+		"":      "",
+		"12":    "12",
+		"v12":   "12",
+		"V012":  "12",
+		" 12 ":  "12",
+		"v":     "v",
+		"abc":   "abc",
+		"v1.2":  "v1.2",
+		"-1":    "-1",
+		"00000": "0",
+	}
+	for version, expected := range tests {
+		assert.Equal(t, expected, canonicalVersion(version), version)
+	}
+	assert.Equal(t, []string{"12", "v12"}, versionForms("v12"))
+	assert.Equal(t, []string{"12", "v12"}, versionForms("12"))
+	assert.Equal(t, []string{"abc"}, versionForms("abc"))
+}
+
+func TestDefaultApplicationService_LegacyVersion(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+
+	current, _ := genAppTestCase()
+	current.Version = "12"
+
+	// the current version matches in either form
+	app, err := as.getVersion(ctx, current, "v12")
+	assert.NoError(t, err)
+	assert.Equal(t, current, app)
+
+	// the history recorded with the bare integer is found by the prefixed form
+	old := &specV1.Application{Namespace: current.Namespace, Name: current.Name, Version: "5"}
+	mockObject.dbStorage.EXPECT().GetApplication(current.Name, current.Namespace, "5").Return(old, nil)
+	app, err = as.getVersion(ctx, current, "v5")
+	assert.NoError(t, err)
+	assert.Equal(t, old, app)
+
+	// and the one recorded with the prefix is found by the bare integer
+	prefixed := &specV1.Application{Namespace: current.Namespace, Name: current.Name, Version: "v7"}
+	mockObject.dbStorage.EXPECT().GetApplication(current.Name, current.Namespace, "7").Return(nil, nil)
+	mockObject.dbStorage.EXPECT().GetApplication(current.Name, current.Namespace, "v7").Return(prefixed, nil)
+	app, err = as.getVersion(ctx, current, "7")
+	assert.NoError(t, err)
+	assert.Equal(t, prefixed, app)
+
+	// the requested version is canonicalized before reading storage
+	mockObject.modelStorage.EXPECT().GetApplication(current.Namespace, current.Name, "12").Return(current, nil)
+	app, err = as.Get(ctx, current.Namespace, current.Name, "v12")
+	assert.NoError(t, err)
+	assert.Equal(t, current, app)
+}
+
+func TestDefaultApplicationService_Diff(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+
+	current, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetApplication(current.Namespace, current.Name, "").Return(current, nil).AnyTimes()
+
+	mockObject.dbStorage.EXPECT().GetApplication(current.Name, current.Namespace, "0").Return(nil, nil)
+	mockObject.dbStorage.EXPECT().GetApplication(current.Name, current.Namespace, "v0").Return(nil, nil)
+	_, err := as.Diff(ctx, current.Namespace, current.Name, "0", current.Version)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	diff, err := as.Diff(ctx, current.Namespace, current.Name, current.Version, current.Version)
+	assert.NoError(t, err)
+	assert.NotNil(t, diff.Changes)
+	assert.Len(t, diff.Changes, 0)
+
+	old, _ := genAppTestCase()
+	old.Version = "1"
+	old.Services[0].Image = "hub.baidubce.com/baetyl/baetyl-agent:0.9.0"
+	old.Services[0].VolumeMounts[0].ReadOnly = true
+	old.Services = append(old.Services, specV1.Service{Name: "web"})
+	old.Volumes = old.Volumes[:1]
+	mockObject.dbStorage.EXPECT().GetApplication(current.Name, current.Namespace, "1").Return(old, nil)
+	diff, err = as.Diff(ctx, current.Namespace, current.Name, "1", current.Version)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.DiffChange{
+		{
+			Type:     models.DiffChanged,
+			Path:     "services[agent].image",
+			OldValue: "hub.baidubce.com/baetyl/baetyl-agent:0.9.0",
+			NewValue: "hub.baidubce.com/baetyl/baetyl-agent:1.0.0",
+		},
+		{
+			Type:     models.DiffChanged,
+			Path:     "services[agent].volumeMounts[test]",
+			OldValue: specV1.VolumeMount{Name: "test", MountPath: "mountPath", ReadOnly: true},
+			NewValue: specV1.VolumeMount{Name: "test", MountPath: "mountPath"},
+		},
+		{
+			Type:     models.DiffRemoved,
+			Path:     "services[web]",
+			OldValue: specV1.Service{Name: "web"},
+		},
+		{
+			Type:     models.DiffAdded,
+			Path:     "volumes[test-2]",
+			NewValue: current.Volumes[1],
+		},
+	}, diff.Changes)
+}
+
+func TestDefaultApplicationService_Validate(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(&specV1.Configuration{}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{}, nil)
+	assert.NoError(t, as.Validate(ctx, app.Namespace, app))
+
+	app.Services = append(app.Services, specV1.Service{Name: "agent"})
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "agent-conf", "").Return(nil, plugin.NotFound(fmt.Errorf("configs \"agent-conf\" not found")))
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(nil, plugin.NotFound(fmt.Errorf("secrets \"test-secret-02\" not found")))
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "agent-conf", "").Return(nil, plugin.NotFound(fmt.Errorf("secrets \"agent-conf\" not found")))
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "test-secret-02", "").Return(nil, plugin.NotFound(fmt.Errorf("configs \"test-secret-02\" not found")))
+	err := as.Validate(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+	errs := err.(*common.MultiError).Errors()
+	assert.Len(t, errs, 3)
+	assert.Equal(t, common.ErrResourceNotFound, errs[1].(errors.Coder).Code())
+	assert.Contains(t, errs[1].Error(), "agent-conf")
+	assert.Equal(t, common.ErrResourceNotFound, errs[2].(errors.Coder).Code())
+	assert.Contains(t, errs[2].Error(), "test-secret-02")
+
+	// both config and secret are set
+	app, _ = genAppTestCase()
+	app.Volumes[0].Secret = &specV1.ObjectReference{Name: "test-secret-02"}
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{}, nil)
+	err = as.Validate(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrVolumeType, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "both config and secret are set")
+	_, _, _, err = as.getConfigsAndSecrets(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrVolumeType, err.(errors.Coder).Code())
+
+	// the config referenced is a secret actually
+	app, _ = genAppTestCase()
+	app.Volumes[0].Config.Name = "test-secret-02"
+	mockObject.modelStorage.EXPECT().GetConfig(app.Namespace, "test-secret-02", "").Return(nil, plugin.NotFound(fmt.Errorf("configs \"test-secret-02\" not found"))).Times(2)
+	mockObject.modelStorage.EXPECT().GetSecret(app.Namespace, "test-secret-02", "").Return(&specV1.Secret{}, nil).Times(3)
+	err = as.Validate(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrVolumeType, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(test) type should be (secret)")
+	_, _, _, err = as.getConfigsAndSecrets(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrVolumeType, err.(errors.Coder).Code())
+
+	// the checks of create are all run, such as namespace, schema, dns names and functions
+	app, _ = genAppTestCase()
+	app.Namespace = "other"
+	app.Type = "vm"
+	app.Services[0].Name = "Agent_1"
+	mockFunctionService := ms.NewMockFunctionService(mockObject.ctl)
+	as.functionService, as.sources = mockFunctionService, []string{"cfc"}
+	data, _ := json.Marshal(&specV1.ConfigurationObject{Metadata: map[string]string{"type": "function", "function": "process"}})
+	function := &specV1.Configuration{Namespace: "default", Data: map[string]string{common.ConfigObjectPrefix + "index": string(data)}}
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(function, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetSecret("default", "test-secret-02", "").Return(&specV1.Secret{Namespace: "default"}, nil)
+	mockFunctionService.EXPECT().GetFunction(gomock.Any(), "default", "process", "", "cfc").
+		Return(nil, common.Error(common.ErrResourceNotFound, common.Field("type", "function")))
+	err = as.Validate(ctx, "default", app)
+	assert.Error(t, err)
+	var codes []string
+	for _, e := range err.(*common.MultiError).Errors() {
+		codes = append(codes, e.(errors.Coder).Code())
+	}
+	assert.Equal(t, []string{common.ErrRequestParamInvalid, common.ErrSchemaViolation,
+		common.ErrInvalidName, common.ErrResourceNotFound}, codes)
+	assert.Contains(t, err.Error(), "($.type)")
+	assert.Contains(t, err.Error(), "Agent_1")
+	assert.Contains(t, err.Error(), "process")
+}
+
+func TestValidDNSNames(t *testing.T) {
+	cases := []struct {
+		name  string
+		app   *specV1.Application
+		where string
+	}{
+		{"valid", &specV1.Application{
+			Name:     "app-01",
+			Services: []specV1.Service{{Name: "agent"}},
+			Volumes:  []specV1.Volume{{Name: "conf-1"}},
+		}, ""},
+		{"uppercase app", &specV1.Application{Name: "App"}, "app"},
+		{"underscore service", &specV1.Application{Name: "app", Services: []specV1.Service{{Name: "my_agent"}}}, "Services[0]"},
+		{"too long volume", &specV1.Application{Name: "app", Volumes: []specV1.Volume{{Name: strings.Repeat("a", 64)}}}, "Volumes[0]"},
+		{"leading hyphen", &specV1.Application{Name: "-app"}, "app"},
+		{"trailing hyphen", &specV1.Application{Name: "app", Services: []specV1.Service{{Name: "ok"}, {Name: "agent-"}}}, "Services[1]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validDNSNames(c.app)
+			if c.where == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, common.ErrInvalidName, err.(errors.Coder).Code())
+			assert.Contains(t, err.Error(), "of "+c.where+" is invalid")
+		})
+	}
+
+	// the app is rejected before it is created
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage}
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Times(0)
+	_, err := as.Create(context.Background(), "default", &specV1.Application{Name: "My_App"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidName, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(My_App)")
+}
+
+func TestValidNodeSelector(t *testing.T) {
+	for _, selector := range []string{"", "test", "env=prod", "env in (prod,test), !edge", "zone!=a"} {
+		assert.NoError(t, validNodeSelector(&specV1.Application{Name: "app", Selector: selector}), selector)
+	}
+	for _, selector := range []string{"env=prod=", "env in prod", "=prod", "env==="} {
+		err := validNodeSelector(&specV1.Application{Name: "app", Selector: selector})
+		assert.Error(t, err, selector)
+		assert.Equal(t, common.ErrInvalidNodeSelector, err.(errors.Coder).Code())
+	}
+
+	// the app is rejected before it is created
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage, conf: mockObject.conf.Application}
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Times(0)
+	_, err := as.Create(context.Background(), "default", &specV1.Application{Name: "app", Selector: "env in prod"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidNodeSelector, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(env in prod)")
+}
+
+func TestDefaultApplicationService_ListApplicationsByNodeSelector(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage}
+
+	mockObject.modelStorage.EXPECT().ListApplication("default", &models.ListOptions{}).Return(&models.ApplicationList{
+		Items: []models.AppItem{
+			{Name: "c", Selector: "zone=a,env=prod"},
+			{Name: "a", Selector: "env=prod, zone=a"},
+			{Name: "b", Selector: "env=prod"},
+			{Name: "d"},
+			{Name: "e", Selector: "env=prod="},
+		},
+	}, nil)
+	list, err := as.ListApplicationsByNodeSelector(ctx, "default", "env=prod,zone=a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, list.Total)
+	assert.Equal(t, "a", list.Items[0].Name)
+	assert.Equal(t, "c", list.Items[1].Name)
+
+	for _, selector := range []string{"", "env in prod"} {
+		_, err = as.ListApplicationsByNodeSelector(ctx, "default", selector)
+		assert.Error(t, err)
+		assert.Equal(t, common.ErrInvalidNodeSelector, err.(errors.Coder).Code())
+	}
+
+	mockObject.modelStorage.EXPECT().ListApplication("other", &models.ListOptions{}).Return(nil, fmt.Errorf("error"))
+	_, err = as.ListApplicationsByNodeSelector(ctx, "other", "env=prod")
+	assert.Error(t, err)
+}
+
+func TestDefaultApplicationService_CrossNamespaceReference(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage, conf: mockObject.conf.Application}
+	app := &specV1.Application{Namespace: "default", Name: "app", Volumes: []specV1.Volume{
+		{Name: "v1", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "c1"}}},
+		{Name: "v2", VolumeSource: specV1.VolumeSource{Secret: &specV1.ObjectReference{Name: "s1"}}},
+	}}
+
+	// the storage may leave the namespace empty
+	mockObject.modelStorage.EXPECT().GetConfig("default", "c1", "").Return(&specV1.Configuration{Namespace: "default", Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret("default", "s1", "").Return(&specV1.Secret{Version: "2"}, nil)
+	configs, secrets, _, err := as.getConfigsAndSecrets(ctx, "default", app)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c1"}, configs)
+	assert.Equal(t, []string{"s1"}, secrets)
+
+	mockObject.modelStorage.EXPECT().GetConfig("default", "c1", "").Return(&specV1.Configuration{Namespace: "other", Version: "1"}, nil)
+	_, _, _, err = as.getConfigsAndSecrets(ctx, "default", app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrCrossNamespaceReference, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "It is in (other)")
+
+	mockObject.modelStorage.EXPECT().GetConfig("default", "c1", "").Return(&specV1.Configuration{Namespace: "default", Version: "1"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret("default", "s1", "").Return(&specV1.Secret{Namespace: "other", Version: "2"}, nil)
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Times(0)
+	_, err = as.Create(ctx, "default", app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrCrossNamespaceReference, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(s1) of volume (v2)")
+}
+
+func TestNormalizeNames(t *testing.T) {
+	ctx := context.Background()
+	app := &specV1.Application{
+		Name: " app\t",
+		Services: []specV1.Service{{
+			Name:         "agent ",
+			VolumeMounts: []specV1.VolumeMount{{Name: "\tconf", MountPath: "/etc/conf"}},
+		}},
+		Volumes: []specV1.Volume{{Name: " conf "}},
+	}
+	assert.NoError(t, normalizeNames(app))
+	assert.Equal(t, "app", app.Name)
+	assert.Equal(t, "agent", app.Services[0].Name)
+	assert.Equal(t, "conf", app.Services[0].VolumeMounts[0].Name)
+	assert.Equal(t, "conf", app.Volumes[0].Name)
+
+	err := normalizeNames(&specV1.Application{Name: "app", Services: []specV1.Service{{Name: "ok"}, {Name: " \t "}}})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidName, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "of Services[1] is invalid")
+
+	// the names are trimmed before the uniqueness checks
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	as := applicationService{storage: mockObject.modelStorage, conf: mockObject.conf.Application}
+	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Times(0)
+	_, err = as.Create(ctx, "default", &specV1.Application{Name: "app", Services: []specV1.Service{{Name: " web"}, {Name: "web"}}})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "name=web")
+
+	_, err = as.Create(ctx, "default", &specV1.Application{Name: "  "})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidName, err.(errors.Coder).Code())
+
+	_, err = as.Update(ctx, "default", &specV1.Application{Name: "app", Volumes: []specV1.Volume{{Name: "\t"}}})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidName, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_validName(t *testing.T) {
+	as := applicationService{}
+
+	app, _ := genAppTestCase()
+	assert.NoError(t, as.validName(app))
+
+	app.Volumes = append(app.Volumes, specV1.Volume{Name: "test"})
+	app.Services = append(app.Services, specV1.Service{
+		Name: "agent",
+		VolumeMounts: []specV1.VolumeMount{
+			{Name: "test", MountPath: "mountPath"},
+			{Name: "missing", MountPath: "missingPath"},
+		},
+	})
+	err := as.validName(app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+
+	errs := err.(*common.MultiError).Errors()
+	assert.Len(t, errs, 3)
+	assert.Equal(t, common.ErrAppNameConflict, errs[0].(errors.Coder).Code())
+	assert.Contains(t, errs[0].Error(), "where=Volumes[2]. name=test.")
+	assert.Equal(t, common.ErrAppNameConflict, errs[1].(errors.Coder).Code())
+	assert.Contains(t, errs[1].Error(), "where=Services[1]. name=agent.")
+	assert.Equal(t, common.ErrVolumeNotFoundWhenMount, errs[2].(errors.Coder).Code())
+	assert.Contains(t, errs[2].Error(), "(missing)")
+	assert.Contains(t, errs[2].Error(), "where=Services[1].VolumeMounts[1].")
+	for _, e := range errs {
+		assert.Contains(t, err.Error(), e.Error())
+	}
+}
+
+func TestDefaultApplicationService_validMountPath(t *testing.T) {
+	as := applicationService{}
+
+	app, _ := genAppTestCase()
+	app.Services[0].VolumeMounts = append(app.Services[0].VolumeMounts, specV1.VolumeMount{
+		Name:      "test-2",
+		MountPath: "mountPath",
+	})
+	err := as.validName(app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrVolumeMountPathConflict, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(mountPath)")
+	assert.Contains(t, err.Error(), "(agent)")
+
+	// the same mount path in different services is allowed
+	app, _ = genAppTestCase()
+	app.Services = append(app.Services, specV1.Service{
+		Name: "agent-02",
+		VolumeMounts: []specV1.VolumeMount{
+			{
+				Name:      "test",
+				MountPath: "mountPath",
+			},
+		},
+	})
+	assert.NoError(t, as.validName(app))
+}
+
+func TestValidatePorts(t *testing.T) {
+	app, _ := genAppTestCase()
+	app.Services[0].Ports = []specV1.ContainerPort{
+		{HostPort: 8080, ContainerPort: 80},
+	}
+	app.Services = append(app.Services, specV1.Service{
+		Name: "web",
+		Ports: []specV1.ContainerPort{
+			{HostPort: 8081, ContainerPort: 80},
+			{ContainerPort: 80},
+			{HostPort: 8080, ContainerPort: 80, Protocol: "UDP"},
+		},
+	})
+	assert.NoError(t, validatePorts(app))
+
+	app.Services[1].Ports = append(app.Services[1].Ports, specV1.ContainerPort{HostPort: 8080, ContainerPort: 8080, Protocol: "tcp"})
+	err := validatePorts(app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrServicePortConflict, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "(8080)")
+	assert.Contains(t, err.Error(), "(web)")
+	assert.Contains(t, err.Error(), "(agent)")
+
+	as := applicationService{}
+	err = as.validName(app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrServicePortConflict, err.(errors.Coder).Code())
+}
+
+func TestValidateImages(t *testing.T) {
+	tests := []struct {
+		name   string
+		image  string
+		strict bool
+		msg    string
+	}{
+		{name: "tag", image: "hub.baidubce.com/baetyl/baetyl-agent:1.0.0", strict: true},
+		{name: "port and tag", image: "localhost:5000/app:v1", strict: true},
+		{name: "digest", image: "myregistry/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", strict: true},
+		{name: "tag and digest", image: "app:v1@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", strict: true},
+		{name: "tagless", image: "myregistry/app"},
+		{name: "tagless strict", image: "myregistry/app", strict: true, msg: "tag or digest is required"},
+		{name: "upper case", image: "UPPER/Image", msg: "invalid reference format"},
+		{name: "empty tag", image: "app:", msg: "invalid reference format"},
+		{name: "short digest", image: "app@sha256:abc", msg: "invalid reference format"},
+		{name: "too long", image: "app/" + strings.Repeat("a", 252) + ":v1", msg: "must not be more than 255 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := genAppTestCase()
+			app.Services[0].Image = tt.image
+			err := validateImages(app, tt.strict)
+			if tt.msg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, common.ErrInvalidImageRef, err.(errors.Coder).Code())
+			assert.Contains(t, err.Error(), "(agent)")
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+
+	// the format is checked on every write, the tag only in strict mode
+	app, _ := genAppTestCase()
+	app.Services[0].Image = "UPPER/Image"
+	as := applicationService{}
+	err := as.validName(app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidImageRef, err.(errors.Coder).Code())
+	app.Services[0].Image = "myregistry/app"
+	assert.NoError(t, as.validName(app))
+	app.Volumes = nil
+	for i := range app.Services {
+		app.Services[i].VolumeMounts = nil
+	}
+	_, err = as.CreateWithOptions(context.Background(), app.Namespace, app, &models.CreateOptions{Strict: true})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrInvalidImageRef, err.(errors.Coder).Code())
+}
+
+func TestValidateEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []specV1.Environment
+		code string
+		msg  string
+	}{
+		{name: "no env"},
+		{name: "valid", env: []specV1.Environment{{Name: "PATH"}, {Name: "_a1"}, {Name: "b_2", Value: "x"}}},
+		{name: "empty", env: []specV1.Environment{{Name: "", Value: "x"}}, code: common.ErrInvalidEnvName},
+		{name: "leading digit", env: []specV1.Environment{{Name: "1A"}}, code: common.ErrInvalidEnvName, msg: "(1A)"},
+		{name: "dash", env: []specV1.Environment{{Name: "A-B"}}, code: common.ErrInvalidEnvName, msg: "(A-B)"},
+		{name: "duplicated", env: []specV1.Environment{{Name: "A", Value: "1"}, {Name: "A", Value: "2"}}, code: common.ErrEnvNameConflict, msg: "(A)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := genAppTestCase()
+			app.Services[0].Env = tt.env
+			// the same name in another service is fine
+			app.Services = append(app.Services, specV1.Service{Name: "other", Env: []specV1.Environment{{Name: "A"}}})
+			err := validateEnv(app)
+			if tt.code == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.code, err.(errors.Coder).Code())
+			assert.Contains(t, err.Error(), "(agent)")
+			assert.Contains(t, err.Error(), tt.msg)
+
+			as := applicationService{}
+			err = as.validName(app)
+			assert.Error(t, err)
+			assert.Equal(t, tt.code, err.(errors.Coder).Code())
+		})
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *specV1.Resources
+		where     string
+	}{
+		{name: "no resources"},
+		{name: "empty", resources: &specV1.Resources{}},
+		{name: "valid", resources: &specV1.Resources{
+			Limits:   map[string]string{"cpu": "1", "memory": "1Gi"},
+			Requests: map[string]string{"cpu": "500m", "memory": "1024Mi"},
+		}},
+		{name: "only requests", resources: &specV1.Resources{Requests: map[string]string{"memory": "2Gi"}}},
+		{name: "inverted memory", resources: &specV1.Resources{
+			Limits:   map[string]string{"memory": "256Mi"},
+			Requests: map[string]string{"memory": "512Mi"},
+		}, where: "Services[0].Resources.Requests.memory"},
+		{name: "inverted cpu", resources: &specV1.Resources{
+			Limits:   map[string]string{"cpu": "0.5"},
+			Requests: map[string]string{"cpu": "600m"},
+		}, where: "Services[0].Resources.Requests.cpu"},
+		{name: "unparseable limit", resources: &specV1.Resources{
+			Limits: map[string]string{"memory": "1GB"},
+		}, where: "Services[0].Resources.Limits.memory"},
+		{name: "unparseable request", resources: &specV1.Resources{
+			Requests: map[string]string{"cpu": "abc"},
+		}, where: "Services[0].Resources.Requests.cpu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := genAppTestCase()
+			app.Services[0].Resources = tt.resources
+			err := validateResources(app)
+			if tt.where == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, common.ErrInvalidResourceSpec, err.(errors.Coder).Code())
+			assert.Contains(t, err.Error(), tt.where)
+			assert.Contains(t, err.Error(), "(agent)")
+
+			as := applicationService{}
+			err = as.validName(app)
+			assert.Error(t, err)
+			assert.Equal(t, common.ErrInvalidResourceSpec, err.(errors.Coder).Code())
+		})
+	}
+}
+
+func TestDefaultApplicationService_UpdateWithOptions(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
 	}
 
-	mockObject.modelStorage.EXPECT().ListApplication(namespace, selector).Return(nil, nil).AnyTimes()
-	cs, err := NewApplicationService(mockObject.conf)
+	current, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetApplication(current.Namespace, current.Name, "").Return(current, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(current.Namespace, "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(current.Namespace, "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(current.Namespace, current.Name, gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(current.Namespace, current.Name, gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	// conflict
+	app, _ := genAppTestCase()
+	app.Version = "1"
+	_, err := as.Update(ctx, app.Namespace, app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceConflict, err.(errors.Coder).Code())
+	_, err = as.UpdateWithOptions(ctx, app.Namespace, app, &models.UpdateOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceConflict, err.(errors.Coder).Code())
+
+	// force
+	updated := &specV1.Application{Namespace: current.Namespace, Name: current.Name, Version: "3"}
+	mockObject.modelStorage.EXPECT().UpdateApplication(current.Namespace, gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			assert.Equal(t, current.Version, a.Version)
+			return updated, nil
+		}).Times(2)
+	res, err := as.UpdateWithOptions(ctx, app.Namespace, app, &models.UpdateOptions{Force: true})
 	assert.NoError(t, err)
-	_, err = cs.List(namespace, selector)
+	assert.Equal(t, updated, res)
+
+	// happy path
+	app, _ = genAppTestCase()
+	res, err = as.Update(ctx, app.Namespace, app)
 	assert.NoError(t, err)
+	assert.Equal(t, updated, res)
 }
 
-func TestDefaultApplicationService_Delete(t *testing.T) {
+func TestDefaultApplicationService_UpdateUnchanged(t *testing.T) {
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
 
@@ -128,98 +3625,312 @@ func TestDefaultApplicationService_Delete(t *testing.T) {
 		indexService: mockIndexService,
 		dbStorage:    mockObject.dbStorage,
 	}
-	newApp, _ := genAppTestCase()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	mockObject.modelStorage.EXPECT().DeleteApplication(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).Times(1)
-	err := as.Delete(newApp.Namespace, newApp.Name, "")
-	assert.NotNil(t, err)
+	current, _ := genAppTestCase()
+	current.Volumes, current.Services[0].VolumeMounts = nil, nil
+	current.CreationTimestamp = time.Now()
+	mockObject.modelStorage.EXPECT().GetApplication(current.Namespace, current.Name, "").Return(current, nil).AnyTimes()
 
-	mockObject.modelStorage.EXPECT().DeleteApplication(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
-	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
-	mockObject.dbStorage.EXPECT().DeleteApplication(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
-	err = as.Delete(newApp.Namespace, newApp.Name, "")
+	// the identical resubmit is a no-op
+	app, _ := genAppTestCase()
+	app.Volumes, app.Services[0].VolumeMounts = nil, nil
+	mockObject.modelStorage.EXPECT().UpdateApplication(gomock.Any(), gomock.Any()).Times(0)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), gomock.Any()).Times(0)
+	res, err := as.Update(context.Background(), app.Namespace, app)
 	assert.NoError(t, err)
+	assert.Equal(t, current, res)
+	assert.Equal(t, "2", res.Version)
+}
 
-	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	mockObject.dbStorage.EXPECT().DeleteApplication(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+func TestDefaultApplicationService_UpdateUnchangedForce(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	err = as.Delete(newApp.Namespace, newApp.Name, "")
+	current, _ := genAppTestCase()
+	current.Volumes, current.Services[0].VolumeMounts = nil, nil
+	mockObject.modelStorage.EXPECT().GetApplication(current.Namespace, current.Name, "").Return(current, nil)
+
+	// force writes the identical spec
+	app, _ := genAppTestCase()
+	app.Volumes, app.Services[0].VolumeMounts = nil, nil
+	updated := *app
+	updated.Version = "3"
+	mockObject.modelStorage.EXPECT().UpdateApplication(app.Namespace, app).Return(&updated, nil)
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(&updated, "").Return(nil, nil)
+	res, err := as.UpdateWithOptions(context.Background(), app.Namespace, app, &models.UpdateOptions{Force: true})
 	assert.NoError(t, err)
+	assert.Equal(t, "3", res.Version)
 }
 
-func TestDefaultApplicationService_CreateWithBase(t *testing.T) {
+func TestDefaultApplicationService_Patch(t *testing.T) {
+	ctx := context.Background()
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
 
 	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+
+	current, _ := genAppTestCase()
+	current.Services[0].Env = []specV1.Environment{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}
+	mockObject.modelStorage.EXPECT().GetApplication(current.Namespace, current.Name, "").Return(current, nil).AnyTimes()
+	var updated *specV1.Application
+	mockObject.modelStorage.EXPECT().UpdateApplication(current.Namespace, gomock.Any()).DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+		res := *app
+		res.Version = "3"
+		updated = &res
+		return &res, nil
+	}).AnyTimes()
+
+	// image and env changed
+	res, err := as.Patch(ctx, current.Namespace, current.Name, &models.ApplicationPatch{
+		Services: []models.ServicePatch{{
+			Name:  "agent",
+			Image: "agent:2.0.0",
+			Env:   map[string]string{"B": "", "A": "0", "C": "3"},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", res.Version)
+	assert.Equal(t, "agent:2.0.0", updated.Services[0].Image)
+	assert.Equal(t, []specV1.Environment{{Name: "A", Value: "0"}, {Name: "C", Value: "3"}}, updated.Services[0].Env)
+	assert.Len(t, updated.Volumes, 2)
+	// the stored one is not changed
+	assert.Equal(t, "hub.baidubce.com/baetyl/baetyl-agent:1.0.0", current.Services[0].Image)
+
+	// add a volume and mount it
+	res, err = as.Patch(ctx, current.Namespace, current.Name, &models.ApplicationPatch{
+		Version: current.Version,
+		AddVolumes: []specV1.Volume{{
+			Name:         "test-3",
+			VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "agent-conf-2"}},
+		}},
+		Services: []models.ServicePatch{{
+			Name:            "agent",
+			AddVolumeMounts: []specV1.VolumeMount{{Name: "test-3", MountPath: "other"}},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, updated.Volumes, 3)
+	assert.Equal(t, "test-3", updated.Volumes[2].Name)
+	assert.Len(t, updated.Services[0].VolumeMounts, 2)
+
+	// the volume name is used
+	updated = nil
+	_, err = as.Patch(ctx, current.Namespace, current.Name, &models.ApplicationPatch{
+		AddVolumes: []specV1.Volume{{
+			Name:         "test",
+			VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "agent-conf-2"}},
+		}},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
+	assert.Nil(t, updated)
+
+	// the service is missing
+	_, err = as.Patch(ctx, current.Namespace, current.Name, &models.ApplicationPatch{
+		Services: []models.ServicePatch{{Name: "none", Image: "none"}},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+
+	// outdated
+	_, err = as.Patch(ctx, current.Namespace, current.Name, &models.ApplicationPatch{Version: "1"})
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceConflict, err.(errors.Coder).Code())
+	assert.Nil(t, updated)
+}
+
+func TestDefaultApplicationService_PinConfigVersions(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
 
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
 	as := applicationService{
 		storage:      mockObject.modelStorage,
 		indexService: mockIndexService,
 		dbStorage:    mockObject.dbStorage,
 	}
-	config := &specV1.Configuration{Name: "agent-conf", Version: "123"}
-	secret2 := &specV1.Secret{Name: "test-secret-02", Version: "123"}
+	mockObject.modelStorage.EXPECT().GetSecret("default", "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		}).AnyTimes()
+	mockObject.modelStorage.EXPECT().UpdateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		}).AnyTimes()
 
-	newApp, baseApp := genAppTestCase()
-	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
-	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
-	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Return(newApp, nil).Times(1)
-	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(config, nil).Times(2)
-	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(secret2, nil)
-	mockObject.dbStorage.EXPECT().CreateApplication(gomock.Any()).Return(nil, fmt.Errorf("error"))
-	_, err := as.CreateWithBase(newApp.Namespace, newApp, baseApp)
+	// pinned, the version at create time is kept
+	app, _ := genAppTestCase()
+	app.Labels = map[string]string{"a": "b"}
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil)
+	pinned, err := as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{PinConfigVersions: true})
 	assert.NoError(t, err)
+	assert.Equal(t, "true", pinned.Labels[common.LabelConfigPinned])
+	assert.Equal(t, "b", pinned.Labels["a"])
+	assert.Equal(t, "1", pinned.Volumes[0].Config.Version)
 
-	mockObject.modelStorage.EXPECT().CreateConfig(gomock.Any(), gomock.Any()).Return(config, nil).AnyTimes()
-	mockObject.dbStorage.EXPECT().CreateApplication(gomock.Any()).Return(nil, nil).AnyTimes()
-	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(config, fmt.Errorf("error")).Times(1)
-	baseApp.Namespace = "test01"
-	_, err = as.CreateWithBase(newApp.Namespace, newApp, baseApp)
-	assert.NotNil(t, err)
+	mockObject.modelStorage.EXPECT().GetApplication("default", app.Name, "").Return(pinned, nil)
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "2"}, nil)
+	update, _ := genAppTestCase()
+	update.Volumes[0].Config.Version = "1"
+	res, err := as.UpdateWithResult(ctx, update.Namespace, update, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", res.App.Volumes[0].Config.Version)
+	assert.Equal(t, "true", res.App.Labels[common.LabelConfigPinned])
+	assert.Equal(t, []models.ConfigDrift{{Volume: "test", Name: "agent-conf", Version: "1", Latest: "2"}}, res.Drifts)
 
-	newApp, baseApp = genAppTestCase()
+	// floating, the latest version is used
+	app, _ = genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil)
+	floating, err := as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, floating.Labels, common.LabelConfigPinned)
+	assert.Equal(t, "1", floating.Volumes[0].Config.Version)
+
+	mockObject.modelStorage.EXPECT().GetApplication("default", app.Name, "").Return(floating, nil)
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "2"}, nil)
+	update, _ = genAppTestCase()
+	update.Volumes[0].Config.Version = "1"
+	res, err = as.UpdateWithResult(ctx, update.Namespace, update, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", res.App.Volumes[0].Config.Version)
+	assert.Empty(t, res.Drifts)
+}
+
+func TestDefaultApplicationService_HistoryDisabled(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	// no database storage at all
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+	}
+	as.conf.History.Disabled = true
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret("default", "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
 	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockObject.modelStorage.EXPECT().CreateApplication(gomock.Any(), gomock.Any()).Return(newApp, nil).AnyTimes()
-	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(config, nil).AnyTimes()
-	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil)
-	baseApp.Namespace = "test02"
-	_, err = as.CreateWithBase(newApp.Namespace, newApp, baseApp)
+	mockIndexService.EXPECT().ListIndex(gomock.Any(), common.Application, common.Base, gomock.Any()).Return(nil, nil).AnyTimes()
+
+	// the operations succeed without history
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).Return(app, nil)
+	_, err := as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{Operator: "user"})
 	assert.NoError(t, err)
 
-	newApp, baseApp = genAppTestCase()
-	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil)
-	baseApp.Namespace = "test02"
-	_, err = as.CreateWithBase(newApp.Namespace, newApp, baseApp)
+	current, _ := genAppTestCase()
+	current.Description = "current"
+	updated, _ := genAppTestCase()
+	updated.Version = "3"
+	mockObject.modelStorage.EXPECT().GetApplication("default", app.Name, "").Return(current, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().UpdateApplication("default", gomock.Any()).Return(updated, nil)
+	_, err = as.Update(ctx, app.Namespace, app)
 	assert.NoError(t, err)
 
-	newApp, baseApp = genAppTestCase()
-	newApp.Volumes = append(newApp.Volumes, specV1.Volume{Name: "test"})
-	baseApp.Namespace = "test02"
-	_, err = as.CreateWithBase(newApp.Namespace, newApp, baseApp)
-	assert.Error(t, err)
+	mockObject.modelStorage.EXPECT().DeleteApplication("default", app.Name).Return(nil)
+	assert.NoError(t, as.Delete(ctx, app.Namespace, app.Name, ""))
 
-	newApp, baseApp = genAppTestCase()
-	newApp.Services = append(newApp.Services, specV1.Service{Name: "Agent"})
-	baseApp.Namespace = "test02"
-	_, err = as.CreateWithBase(newApp.Namespace, newApp, baseApp)
+	// the methods need history are not supported
+	notSupported := func(err error) {
+		assert.Error(t, err)
+		assert.Equal(t, common.ErrNotSupported, err.(errors.Coder).Code())
+		assert.Contains(t, err.Error(), "application history is disabled")
+	}
+	_, err = as.ListHistory(ctx, app.Namespace, app.Name, nil)
+	notSupported(err)
+	_, err = as.ListHistoryByTime(ctx, app.Namespace, app.Name, time.Now().Add(-time.Hour), time.Time{})
+	notSupported(err)
+	_, err = as.Rollback(ctx, app.Namespace, app.Name, "1")
+	notSupported(err)
+	_, err = as.PruneHistory(ctx, app.Namespace, app.Name, 1)
+	notSupported(err)
+	notSupported(as.SoftDelete(ctx, app.Namespace, app.Name, ""))
+	_, err = as.Restore(ctx, app.Namespace, app.Name)
+	notSupported(err)
+	_, err = as.Diff(ctx, app.Namespace, app.Name, "1", "")
+	notSupported(err)
+	_, err = as.CreateWithOptions(ctx, app.Namespace, app, &models.CreateOptions{RequestID: "r1"})
+	notSupported(err)
+	// the current version is still available
+	_, err = as.Diff(ctx, app.Namespace, app.Name, "", "")
+	assert.NoError(t, err)
+}
+
+func TestDefaultApplicationService_SpecLimit(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{
+		storage:   mockObject.modelStorage,
+		dbStorage: mockObject.dbStorage,
+	}
+	app, _ := genAppTestCase()
+	data, err := json.Marshal(app)
+	assert.NoError(t, err)
+
+	// just under and at the limits
+	as.conf.Limit.SpecBytes = len(data)
+	as.conf.Limit.Services = len(app.Services)
+	as.conf.Limit.Volumes = len(app.Volumes)
+	assert.NoError(t, as.validName(app))
+
+	// just over the size
+	as.conf.Limit.SpecBytes = len(data) - 1
+	err = as.validName(app)
 	assert.Error(t, err)
+	assert.Equal(t, common.ErrSpecTooLarge, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), fmt.Sprintf("the size is %d bytes which exceeds the limit %d", len(data), len(data)-1))
 
-	newApp, baseApp = genAppTestCase()
-	newApp.Services[0].VolumeMounts = append(newApp.Services[0].VolumeMounts,
-		specV1.VolumeMount{
-			Name:      "test_01",
-			MountPath: "mountPath",
-		})
-	baseApp.Namespace = "test02"
-	_, err = as.CreateWithBase(newApp.Namespace, newApp, baseApp)
+	// nothing is written
+	_, err = as.Create(ctx, app.Namespace, app)
+	assert.Equal(t, common.ErrSpecTooLarge, err.(errors.Coder).Code())
+	_, err = as.Update(ctx, app.Namespace, app)
+	assert.Equal(t, common.ErrSpecTooLarge, err.(errors.Coder).Code())
+
+	// just over the counts
+	as.conf.Limit.SpecBytes = 0
+	app.Services = append(app.Services, specV1.Service{Name: "other", Image: "other"})
+	as.conf.Limit.Volumes = len(app.Volumes) - 1
+	err = as.validName(app)
 	assert.Error(t, err)
+	errs := err.(*common.MultiError).Errors()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, common.ErrSpecTooLarge, errs[0].(errors.Coder).Code())
+	assert.Contains(t, errs[0].Error(), "the number of services is 2 which exceeds the limit 1")
+	assert.Contains(t, errs[1].Error(), "the number of volumes is 2 which exceeds the limit 1")
 }
 
-func TestDefaultApplicationService_Update(t *testing.T) {
+func TestDefaultApplicationService_Warnings(t *testing.T) {
+	ctx := context.Background()
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
 
@@ -229,48 +3940,131 @@ func TestDefaultApplicationService_Update(t *testing.T) {
 		indexService: mockIndexService,
 		dbStorage:    mockObject.dbStorage,
 	}
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret("default", "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		}).AnyTimes()
+	mockObject.modelStorage.EXPECT().UpdateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			return a, nil
+		}).AnyTimes()
+	codes := func(warnings []models.ApplicationWarning) []string {
+		var res []string
+		for _, w := range warnings {
+			res = append(res, w.Code+":"+w.Name)
+		}
+		return res
+	}
 
-	newApp, oldApp := genAppTestCase()
-	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), "").Return(nil, fmt.Errorf("error")).Times(1)
-	_, err := as.Update(newApp.Namespace, newApp)
-	assert.NotNil(t, err)
+	// the volume test-2 is not mounted, the config of test follows the latest
+	app, _ := genAppTestCase()
+	res, err := as.CreateWithResult(ctx, app.Namespace, app, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, app.Name, res.App.Name)
+	assert.Equal(t, []string{"UnusedVolume:test-2", "FloatingConfig:test"}, codes(res.Warnings))
+	assert.Equal(t, "Volumes[0].Config", res.Warnings[1].Where)
 
-	secret1 := &specV1.Secret{Name: "test-secret-01", Version: "123"}
-	secret2 := &specV1.Secret{Name: "test-secret-02", Version: "123"}
+	// pinned and the image is tagless
+	app, _ = genAppTestCase()
+	app.Services[0].Image = "myregistry/agent"
+	res, err = as.CreateWithResult(ctx, app.Namespace, app, &models.CreateOptions{PinConfigVersions: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ImageLatest:agent", "UnusedVolume:test-2"}, codes(res.Warnings))
+	assert.Equal(t, "Services[0].Image", res.Warnings[0].Where)
+	assert.Contains(t, res.Warnings[0].Message, "myregistry/agent")
 
-	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
-	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
-	mockObject.modelStorage.EXPECT().UpdateApplication(newApp.Namespace, newApp).Return(oldApp, nil)
-	mockObject.modelStorage.EXPECT().GetConfig(gomock.Any(), gomock.Any(), "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
-	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret1.Name, gomock.Any()).Return(secret1, nil).AnyTimes()
-	mockObject.modelStorage.EXPECT().GetSecret(gomock.Any(), secret2.Name, gomock.Any()).Return(secret2, nil).AnyTimes()
-	mockObject.dbStorage.EXPECT().CreateApplication(gomock.Any()).Return(nil, fmt.Errorf("error"))
-	_, err = as.Update(newApp.Namespace, newApp)
+	// the strict mode rejects them instead
+	app, _ = genAppTestCase()
+	app.Services[0].Image = "myregistry/agent"
+	_, err = as.CreateWithResult(ctx, app.Namespace, app, &models.CreateOptions{Strict: true})
+	assert.Error(t, err)
+
+	// no warning
+	app, _ = genAppTestCase()
+	app.Volumes = app.Volumes[1:]
+	app.Services[0].VolumeMounts = []specV1.VolumeMount{{Name: "test-2", MountPath: "secret", ReadOnly: true}}
+	res, err = as.CreateWithResult(ctx, app.Namespace, app, nil)
 	assert.NoError(t, err)
+	assert.Empty(t, res.Warnings)
 
-	newApp, _ = genAppTestCase()
-	mockObject.modelStorage.EXPECT().UpdateApplication(newApp.Namespace, newApp).Return(nil, fmt.Errorf("error"))
-	_, err = as.Update(newApp.Namespace, newApp)
-	assert.NotNil(t, err)
+	// update
+	current, _ := genAppTestCase()
+	current.Description = "current"
+	mockObject.modelStorage.EXPECT().GetApplication("default", current.Name, "").Return(current, nil)
+	update, _ := genAppTestCase()
+	update.Services[0].Image = "myregistry/agent"
+	ures, err := as.UpdateWithResult(ctx, update.Namespace, update, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ImageLatest:agent", "UnusedVolume:test-2", "FloatingConfig:test"}, codes(ures.Warnings))
+}
 
-	_, oldApp = genAppTestCase()
-	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).Times(1)
-	mockObject.modelStorage.EXPECT().UpdateApplication(gomock.Any(), gomock.Any()).Return(oldApp, nil)
-	_, err = as.Update(newApp.Namespace, newApp)
-	assert.NotNil(t, err)
+func TestDefaultApplicationService_Clone(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	mockObject.modelStorage.EXPECT().ListConfig(gomock.Any(), gomock.Any()).Return(&models.ConfigurationList{}, nil).AnyTimes()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}
+
+	src, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().GetApplication(src.Namespace, src.Name, "").Return(src, nil).AnyTimes()
+
+	// name conflict
+	mockObject.modelStorage.EXPECT().GetApplication("dst", "copy", "").Return(&specV1.Application{}, nil)
+	_, err := as.Clone(ctx, src.Namespace, src.Name, "", "dst", "copy")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrAppNameConflict, err.(errors.Coder).Code())
 
+	// configs and secrets are copied to another namespace
+	mockObject.modelStorage.EXPECT().GetApplication("dst", "copy", "").Return(nil, plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"copy\" not found")))
+	mockObject.modelStorage.EXPECT().GetConfig(src.Namespace, "agent-conf", "").Return(&specV1.Configuration{Name: "agent-conf"}, nil)
+	mockObject.modelStorage.EXPECT().CreateConfig("dst", gomock.Any()).Return(&specV1.Configuration{Name: "agent-conf", Version: "c1"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret(src.Namespace, "test-secret-02", "").Return(&specV1.Secret{Name: "test-secret-02"}, nil)
+	mockObject.modelStorage.EXPECT().CreateSecret("dst", gomock.Any()).Return(nil, fmt.Errorf("error"))
+	mockObject.modelStorage.EXPECT().CreateSecret("dst", gomock.Any()).Return(&specV1.Secret{Name: "test-secret-02-abc", Version: "s1"}, nil)
+	mockObject.modelStorage.EXPECT().GetConfig("dst", "agent-conf", "").Return(&specV1.Configuration{Version: "c1"}, nil)
+	mockObject.modelStorage.EXPECT().GetSecret("dst", "test-secret-02-abc", "").Return(&specV1.Secret{Version: "s1"}, nil)
+	mockIndexService.EXPECT().RefreshConfigIndexByApp("dst", "copy", []string{"agent-conf"}).Return(nil)
+	mockIndexService.EXPECT().RefreshSecretIndexByApp("dst", "copy", []string{"test-secret-02-abc"}).Return(nil)
+	mockObject.modelStorage.EXPECT().CreateApplication("dst", gomock.Any()).
+		DoAndReturn(func(_ string, app *specV1.Application) (*specV1.Application, error) {
+			assert.Equal(t, "copy", app.Name)
+			assert.Equal(t, "dst", app.Namespace)
+			assert.Empty(t, app.Version)
+			assert.Equal(t, src.Services, app.Services)
+			return app, nil
+		})
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil)
+	app, err := as.Clone(ctx, src.Namespace, src.Name, "", "dst", "copy")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-secret-02-abc", app.Volumes[1].Secret.Name)
+	// the source application is untouched
+	assert.Equal(t, "test-secret-02", src.Volumes[1].Secret.Name)
+	assert.Equal(t, "abc", src.Name)
 }
 
 func TestDefaultApplicationService_constuctConfig(t *testing.T) {
+	ctx := context.Background()
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
+	mockObject.modelStorage.EXPECT().ListConfig(gomock.Any(), gomock.Any()).Return(&models.ConfigurationList{}, nil).AnyTimes()
 	cs := applicationService{
 		storage: mockObject.modelStorage,
 	}
 
 	_, baseApp := genAppTestCase()
 	mockObject.modelStorage.EXPECT().GetConfig(baseApp.Namespace, baseApp.Volumes[0].Config.Name, "").Return(nil, fmt.Errorf("error")).Times(1)
-	err := cs.constuctConfig("default", baseApp)
+	err := cs.constuctConfig(ctx, "default", baseApp)
 	assert.NotNil(t, err)
 
 	config := &specV1.Configuration{Name: "agent-conf"}
@@ -280,8 +4074,93 @@ func TestDefaultApplicationService_constuctConfig(t *testing.T) {
 		Return(config, nil)
 	mockObject.modelStorage.EXPECT().CreateConfig(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
 	mockObject.modelStorage.EXPECT().CreateConfig(gomock.Any(), gomock.Any()).Return(config, nil)
-	err = cs.constuctConfig("default", baseApp)
+	err = cs.constuctConfig(ctx, "default", baseApp)
+	assert.NoError(t, err)
+
+	// both config and secret are materialized
+	_, baseApp = genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	baseApp.Volumes = append(baseApp.Volumes, specV1.Volume{
+		Name:         "test-03",
+		VolumeSource: specV1.VolumeSource{Secret: &specV1.ObjectReference{Name: "agent-secret", Version: "version01"}},
+	})
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "agent-conf", "").Return(&specV1.Configuration{Name: "agent-conf"}, nil)
+	mockObject.modelStorage.EXPECT().CreateConfig("default", gomock.Any()).
+		DoAndReturn(func(_ string, c *specV1.Configuration) (*specV1.Configuration, error) {
+			assert.Equal(t, "agent-conf", c.Name)
+			return &specV1.Configuration{Namespace: "default", Name: c.Name, Version: "c1"}, nil
+		})
+	mockObject.modelStorage.EXPECT().GetSecret("baetyl-cloud", "agent-secret", "").Return(&specV1.Secret{Name: "agent-secret"}, nil)
+	mockObject.modelStorage.EXPECT().CreateSecret("default", gomock.Any()).Return(nil, fmt.Errorf("secrets \"agent-secret\" already exists"))
+	mockObject.modelStorage.EXPECT().CreateSecret("default", gomock.Any()).
+		DoAndReturn(func(_ string, s *specV1.Secret) (*specV1.Secret, error) {
+			assert.True(t, strings.HasPrefix(s.Name, "agent-secret-"))
+			return &specV1.Secret{Namespace: "default", Name: s.Name, Version: "s1"}, nil
+		})
+	err = cs.constuctConfig(ctx, "default", baseApp)
 	assert.NoError(t, err)
+	assert.Equal(t, specV1.ObjectReference{Name: "agent-conf", Version: "c1"}, *baseApp.Volumes[0].Config)
+	assert.True(t, strings.HasPrefix(baseApp.Volumes[1].Secret.Name, "agent-secret-"))
+	assert.Equal(t, "s1", baseApp.Volumes[1].Secret.Version)
+
+	_, baseApp = genAppTestCase()
+	baseApp.Volumes[0].Config = nil
+	baseApp.Volumes[0].Secret = &specV1.ObjectReference{Name: "agent-secret"}
+	mockObject.modelStorage.EXPECT().GetSecret(baseApp.Namespace, "agent-secret", "").Return(nil, fmt.Errorf("error"))
+	err = cs.constuctConfig(ctx, "default", baseApp)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+}
+
+func TestDefaultApplicationService_constuctConfigShared(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	cs := applicationService{
+		storage: mockObject.modelStorage,
+	}
+
+	var created []specV1.Configuration
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "agent-conf", "").
+		Return(&specV1.Configuration{Name: "agent-conf", Data: map[string]string{"a": "b"}}, nil).Times(3)
+	mockObject.modelStorage.EXPECT().ListConfig("default", gomock.Any()).
+		DoAndReturn(func(_ string, opts *models.ListOptions) (*models.ConfigurationList, error) {
+			res := &models.ConfigurationList{}
+			for _, c := range created {
+				if opts.LabelSelector == common.LabelConfigDigest+"="+c.Labels[common.LabelConfigDigest] {
+					res.Items = append(res.Items, c)
+				}
+			}
+			return res, nil
+		}).Times(3)
+	mockObject.modelStorage.EXPECT().CreateConfig("default", gomock.Any()).
+		DoAndReturn(func(_ string, c *specV1.Configuration) (*specV1.Configuration, error) {
+			cfg := *c
+			cfg.Namespace, cfg.Version = "default", fmt.Sprintf("c%d", len(created)+1)
+			created = append(created, cfg)
+			return &cfg, nil
+		}).Times(2)
+
+	var refs []specV1.ObjectReference
+	for i := 0; i < 2; i++ {
+		_, baseApp := genAppTestCase()
+		baseApp.Namespace = "baetyl-cloud"
+		baseApp.Volumes = baseApp.Volumes[:1]
+		assert.NoError(t, cs.constuctConfig(ctx, "default", baseApp))
+		refs = append(refs, *baseApp.Volumes[0].Config)
+	}
+	assert.Len(t, created, 1)
+	assert.Equal(t, refs[0], refs[1])
+	assert.Equal(t, specV1.ObjectReference{Name: "agent-conf", Version: "c1"}, refs[0])
+
+	// the shared config was updated, a new copy is created
+	created[0].Data = map[string]string{"a": "c"}
+	_, baseApp := genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	baseApp.Volumes = baseApp.Volumes[:1]
+	assert.NoError(t, cs.constuctConfig(ctx, "default", baseApp))
+	assert.Len(t, created, 2)
+	assert.Equal(t, "c2", baseApp.Volumes[0].Config.Version)
 }
 
 type Test1 struct {
@@ -290,6 +4169,78 @@ type Test1 struct {
 	t1 string
 }
 
+func TestDefaultApplicationService_constuctConfigSuffix(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	mockObject.modelStorage.EXPECT().ListConfig(gomock.Any(), gomock.Any()).Return(&models.ConfigurationList{}, nil).AnyTimes()
+	cs := applicationService{
+		storage: mockObject.modelStorage,
+	}
+
+	// the config of the same name exists in the destination namespace
+	stored := map[string]*specV1.Configuration{"agent-conf": {Namespace: "default", Name: "agent-conf", Version: "1"}}
+	_, baseApp := genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "agent-conf", "").Return(&specV1.Configuration{Name: "agent-conf"}, nil)
+	mockObject.modelStorage.EXPECT().CreateConfig("default", gomock.Any()).
+		DoAndReturn(func(namespace string, c *specV1.Configuration) (*specV1.Configuration, error) {
+			if _, ok := stored[c.Name]; ok {
+				return nil, fmt.Errorf("configs \"%s\" already exists", c.Name)
+			}
+			res := &specV1.Configuration{Namespace: namespace, Name: c.Name, Version: "2"}
+			stored[c.Name] = res
+			return res, nil
+		}).Times(2)
+	err := cs.constuctConfig(context.Background(), "default", baseApp)
+	assert.NoError(t, err)
+	assert.Len(t, stored, 2)
+	ref := baseApp.Volumes[0].Config
+	assert.NotEqual(t, "agent-conf", ref.Name)
+	assert.Contains(t, stored, ref.Name)
+	assert.Equal(t, stored[ref.Name].Version, ref.Version)
+	assert.Equal(t, "1", stored["agent-conf"].Version)
+}
+
+func TestDefaultApplicationService_constuctConfigCounter(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+	mockObject.modelStorage.EXPECT().ListConfig(gomock.Any(), gomock.Any()).Return(&models.ConfigurationList{}, nil).AnyTimes()
+	cs := applicationService{
+		storage: mockObject.modelStorage,
+		conf:    config.AppConfig{CopyName: config.AppCopyNameConfig{Strategy: config.CopyNameCounter, MaxAttempts: 2}},
+	}
+
+	// agent-conf and agent-conf-1 exist in the destination namespace
+	stored := map[string]*specV1.Configuration{
+		"agent-conf":   {Namespace: "default", Name: "agent-conf", Version: "1"},
+		"agent-conf-1": {Namespace: "default", Name: "agent-conf-1", Version: "1"},
+	}
+	create := func(namespace string, c *specV1.Configuration) (*specV1.Configuration, error) {
+		if _, ok := stored[c.Name]; ok {
+			return nil, fmt.Errorf("configs \"%s\" already exists", c.Name)
+		}
+		res := &specV1.Configuration{Namespace: namespace, Name: c.Name, Version: "2"}
+		stored[c.Name] = res
+		return res, nil
+	}
+	_, baseApp := genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	mockObject.modelStorage.EXPECT().GetConfig("baetyl-cloud", "agent-conf", "").Return(&specV1.Configuration{Name: "agent-conf"}, nil).Times(2)
+	mockObject.modelStorage.EXPECT().CreateConfig("default", gomock.Any()).DoAndReturn(create).Times(3)
+	err := cs.constuctConfig(ctx, "default", baseApp)
+	assert.NoError(t, err)
+	assert.Equal(t, specV1.ObjectReference{Name: "agent-conf-2", Version: "2"}, *baseApp.Volumes[0].Config)
+
+	// no more attempt after agent-conf-2
+	_, baseApp = genAppTestCase()
+	baseApp.Namespace = "baetyl-cloud"
+	mockObject.modelStorage.EXPECT().CreateConfig("default", gomock.Any()).DoAndReturn(create).Times(3)
+	err = cs.constuctConfig(ctx, "default", baseApp)
+	assert.Error(t, err)
+	assert.Equal(t, "agent-conf", baseApp.Volumes[0].Config.Name)
+}
+
 func TestNewCallbackService(t *testing.T) {
 
 	tt := Test1{
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/models"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+const tracerName = "github.com/baetyl/baetyl-cloud/service"
+
+// tracedApplicationService starts a span for each operation and passes it down with ctx,
+// so the spans of the plugins taking ctx nest under the ones of service
+type tracedApplicationService struct {
+	ApplicationService
+	tracer trace.Tracer
+}
+
+func withTracing(as ApplicationService, tracer trace.Tracer) ApplicationService {
+	if tracer == nil {
+		tracer = global.Tracer(tracerName)
+	}
+	return &tracedApplicationService{ApplicationService: as, tracer: tracer}
+}
+
+func (t *tracedApplicationService) start(ctx context.Context, operation, namespace, name, version string) (context.Context, trace.Span) {
+	ctx, span := t.tracer.Start(ctx, "application."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			label.String("operation", operation),
+			label.String("namespace", namespace),
+		))
+	if name != "" {
+		span.SetAttributes(label.String("name", name))
+	}
+	if version != "" {
+		span.SetAttributes(label.String("version", version))
+	}
+	return ctx, span
+}
+
+// endSpan record the version of result and the error, then end the span
+func endSpan(ctx context.Context, span trace.Span, app *specV1.Application, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if app != nil {
+		span.SetAttributes(label.String("version", app.Version))
+	}
+	span.End()
+}
+
+func (t *tracedApplicationService) Get(ctx context.Context, namespace, name, version string) (app *specV1.Application, err error) {
+	ctx, span := t.start(ctx, "get", namespace, name, version)
+	defer func() { endSpan(ctx, span, app, err) }()
+	return t.ApplicationService.Get(ctx, namespace, name, version)
+}
+
+func (t *tracedApplicationService) Exists(ctx context.Context, namespace, name string) (ok bool, err error) {
+	ctx, span := t.start(ctx, "exists", namespace, name, "")
+	defer func() {
+		span.SetAttributes(label.Bool("exists", ok))
+		endSpan(ctx, span, nil, err)
+	}()
+	return t.ApplicationService.Exists(ctx, namespace, name)
+}
+
+func (t *tracedApplicationService) GetBatch(ctx context.Context, namespace string, refs []models.AppRef) (res map[string]*specV1.Application, err error) {
+	ctx, span := t.start(ctx, "get_batch", namespace, "", "")
+	span.SetAttributes(label.Int("refs", len(refs)))
+	defer func() {
+		span.SetAttributes(label.Int("found", len(res)))
+		endSpan(ctx, span, nil, err)
+	}()
+	return t.ApplicationService.GetBatch(ctx, namespace, refs)
+}
+
+func (t *tracedApplicationService) GetAtLeastVersion(ctx context.Context, namespace, name, minVersion string, timeout time.Duration) (app *specV1.Application, err error) {
+	ctx, span := t.start(ctx, "get_at_least_version", namespace, name, minVersion)
+	defer func() { endSpan(ctx, span, app, err) }()
+	return t.ApplicationService.GetAtLeastVersion(ctx, namespace, name, minVersion, timeout)
+}
+
+func (t *tracedApplicationService) CreateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (res *specV1.Application, err error) {
+	ctx, span := t.start(ctx, "create", namespace, app.Name, "")
+	defer func() { endSpan(ctx, span, res, err) }()
+	return t.ApplicationService.CreateWithOptions(ctx, namespace, app, opts)
+}
+
+func (t *tracedApplicationService) CreateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.CreateOptions) (res *models.ApplicationCreateResult, err error) {
+	ctx, span := t.start(ctx, "create", namespace, app.Name, "")
+	defer func() {
+		var app *specV1.Application
+		if res != nil {
+			app = res.App
+		}
+		endSpan(ctx, span, app, err)
+	}()
+	return t.ApplicationService.CreateWithResult(ctx, namespace, app, opts)
+}
+
+func (t *tracedApplicationService) Create(ctx context.Context, namespace string, app *specV1.Application) (res *specV1.Application, err error) {
+	ctx, span := t.start(ctx, "create", namespace, app.Name, "")
+	defer func() { endSpan(ctx, span, res, err) }()
+	return t.ApplicationService.Create(ctx, namespace, app)
+}
+
+func (t *tracedApplicationService) Update(ctx context.Context, namespace string, app *specV1.Application) (res *specV1.Application, err error) {
+	return t.UpdateWithOptions(ctx, namespace, app, nil)
+}
+
+func (t *tracedApplicationService) UpdateWithResult(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (res *models.ApplicationUpdateResult, err error) {
+	ctx, span := t.start(ctx, "update", namespace, app.Name, app.Version)
+	defer func() {
+		var app *specV1.Application
+		if res != nil {
+			app = res.App
+		}
+		endSpan(ctx, span, app, err)
+	}()
+	return t.ApplicationService.UpdateWithResult(ctx, namespace, app, opts)
+}
+
+func (t *tracedApplicationService) UpdateWithOptions(ctx context.Context, namespace string, app *specV1.Application, opts *models.UpdateOptions) (res *specV1.Application, err error) {
+	ctx, span := t.start(ctx, "update", namespace, app.Name, app.Version)
+	defer func() { endSpan(ctx, span, res, err) }()
+	return t.ApplicationService.UpdateWithOptions(ctx, namespace, app, opts)
+}
+
+func (t *tracedApplicationService) Patch(ctx context.Context, namespace, name string, patch *models.ApplicationPatch) (res *specV1.Application, err error) {
+	ctx, span := t.start(ctx, "update", namespace, name, "")
+	defer func() { endSpan(ctx, span, res, err) }()
+	return t.ApplicationService.Patch(ctx, namespace, name, patch)
+}
+
+func (t *tracedApplicationService) DeleteWithOptions(ctx context.Context, namespace, name, version string, opts *models.DeleteOptions) (err error) {
+	ctx, span := t.start(ctx, "delete", namespace, name, version)
+	defer func() { endSpan(ctx, span, nil, err) }()
+	return t.ApplicationService.DeleteWithOptions(ctx, namespace, name, version, opts)
+}
+
+func (t *tracedApplicationService) Delete(ctx context.Context, namespace, name, version string) (err error) {
+	ctx, span := t.start(ctx, "delete", namespace, name, version)
+	defer func() { endSpan(ctx, span, nil, err) }()
+	return t.ApplicationService.Delete(ctx, namespace, name, version)
+}
+
+func (t *tracedApplicationService) ListApplicationsByNodeSelector(ctx context.Context, namespace, selector string) (list *models.ApplicationList, err error) {
+	ctx, span := t.start(ctx, "list_by_node_selector", namespace, "", "")
+	span.SetAttributes(label.String("selector", selector))
+	defer func() { endSpan(ctx, span, nil, err) }()
+	return t.ApplicationService.ListApplicationsByNodeSelector(ctx, namespace, selector)
+}
+
+func (t *tracedApplicationService) List(ctx context.Context, namespace string, listOptions *models.ListOptions) (list *models.ApplicationList, err error) {
+	ctx, span := t.start(ctx, "list", namespace, "", "")
+	defer func() { endSpan(ctx, span, nil, err) }()
+	return t.ApplicationService.List(ctx, namespace, listOptions)
+}
+
+func (t *tracedApplicationService) ListStream(ctx context.Context, namespace string, listOptions *models.ListOptions, fn func(*specV1.Application) error) (err error) {
+	ctx, span := t.start(ctx, "list", namespace, "", "")
+	defer func() { endSpan(ctx, span, nil, err) }()
+	return t.ApplicationService.ListStream(ctx, namespace, listOptions, fn)
+}
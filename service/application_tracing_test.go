@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	mockPlugin "github.com/baetyl/baetyl-cloud/mock/plugin"
+	ms "github.com/baetyl/baetyl-cloud/mock/service"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-go/errors"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/api/trace/tracetest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+func TestTracedApplicationService(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockIndexService := ms.NewMockIndexService(mockObject.ctl)
+	recorder := &tracetest.StandardSpanRecorder{}
+	tracer := tracetest.NewTracerProvider(tracetest.WithSpanRecorder(recorder)).Tracer(tracerName)
+	as := withTracing(&applicationService{
+		storage:      mockObject.modelStorage,
+		indexService: mockIndexService,
+		dbStorage:    mockObject.dbStorage,
+	}, tracer)
+
+	mockObject.modelStorage.EXPECT().GetConfig("default", "agent-conf", "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret("default", "test-secret-02", "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshConfigIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockIndexService.EXPECT().RefreshSecretIndexByApp(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+
+	// create
+	app, _ := genAppTestCase()
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).
+		DoAndReturn(func(_ string, a *specV1.Application) (*specV1.Application, error) {
+			res := *a
+			res.Version = "10"
+			return &res, nil
+		})
+	_, err := as.Create(context.Background(), app.Namespace, app)
+	assert.NoError(t, err)
+	spans := recorder.Completed()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "application.create", spans[0].Name())
+	assert.Equal(t, map[label.Key]label.Value{
+		"operation": label.StringValue("create"),
+		"namespace": label.StringValue("default"),
+		"name":      label.StringValue("abc"),
+		"version":   label.StringValue("10"),
+	}, spans[0].Attributes())
+	assert.Equal(t, codes.Unset, spans[0].StatusCode())
+
+	// the error is recorded
+	app, _ = genAppTestCase()
+	mockObject.modelStorage.EXPECT().CreateApplication("default", gomock.Any()).Return(nil, fmt.Errorf("storage error"))
+	_, err = as.Create(context.Background(), app.Namespace, app)
+	assert.Error(t, err)
+	spans = recorder.Completed()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[1].StatusCode())
+	assert.Equal(t, "storage error", spans[1].StatusMessage())
+
+	// the span of context methods is a child of ctx, and passed down
+	ctx, parent := tracer.Start(context.Background(), "request")
+	mockObject.modelStorage.EXPECT().GetApplication("default", "abc", "2").Return(nil, plugin.NotFound(fmt.Errorf("applications \"abc\" not found")))
+	_, err = as.Get(ctx, "default", "abc", "2")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrResourceNotFound, err.(errors.Coder).Code())
+	parent.End()
+	spans = recorder.Completed()
+	assert.Len(t, spans, 4)
+	assert.Equal(t, "application.get", spans[2].Name())
+	assert.Equal(t, parent.SpanContext().SpanID, spans[2].ParentSpanID())
+	assert.Equal(t, label.StringValue("2"), spans[2].Attributes()["version"])
+	assert.Equal(t, trace.SpanKindInternal, spans[2].SpanKind())
+
+	// the span of storage taking ctx is a child of the one of service, also for the methods without ctx
+	mctx := mockPlugin.NewMockModelStorageContext(mockObject.ctl)
+	as = withTracing(&applicationService{
+		storage:   &contextStorage{mockObject.modelStorage, mctx},
+		dbStorage: mockObject.dbStorage,
+	}, tracer)
+	storageGet := func(ctx context.Context, _, _, _ string) (*specV1.Application, error) {
+		_, span := tracer.Start(ctx, "storage.get")
+		defer span.End()
+		return &specV1.Application{Name: "abc", Version: "3"}, nil
+	}
+	mctx.EXPECT().GetApplicationContext(gomock.Any(), "default", "abc", "").DoAndReturn(storageGet).Times(2)
+	_, err = as.Exists(context.Background(), "default", "abc")
+	assert.NoError(t, err)
+	ctx, parent = tracer.Start(context.Background(), "request")
+	_, err = as.Get(ctx, "default", "abc", "")
+	assert.NoError(t, err)
+	parent.End()
+	spans = recorder.Completed()
+	assert.Len(t, spans, 9)
+	assert.Equal(t, "storage.get", spans[4].Name())
+	assert.Equal(t, "application.exists", spans[5].Name())
+	assert.Equal(t, spans[5].SpanContext().SpanID, spans[4].ParentSpanID())
+	assert.Equal(t, "storage.get", spans[6].Name())
+	assert.Equal(t, "application.get", spans[7].Name())
+	assert.Equal(t, spans[7].SpanContext().SpanID, spans[6].ParentSpanID())
+	assert.Equal(t, parent.SpanContext().SpanID, spans[7].ParentSpanID())
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/config"
+)
+
+// VersionStrategy compute the next version of app from the old one on update,
+// the empty version leaves the storage to assign it as before
+type VersionStrategy interface {
+	Next(old string) (string, error)
+}
+
+// NewVersionStrategy the strategy of name, see config.AppConfig.VersionStrategy
+func NewVersionStrategy(name string) (VersionStrategy, error) {
+	switch name {
+	case "", config.VersionStorage:
+		return storageVersion{}, nil
+	case config.VersionCounter:
+		return counterVersion{}, nil
+	case config.VersionTimestamp:
+		return &timestampVersion{now: time.Now}, nil
+	}
+	return nil, common.Error(common.ErrNotSupported, common.Field("name", "version strategy "+name))
+}
+
+type storageVersion struct{}
+
+func (storageVersion) Next(string) (string, error) {
+	return "", nil
+}
+
+// counterVersion counts up from the old version, which must be decimal
+type counterVersion struct{}
+
+func (counterVersion) Next(old string) (string, error) {
+	if old == "" {
+		return "1", nil
+	}
+	n, err := strconv.ParseUint(canonicalVersion(old), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to count up version %s: %s", old, err.Error())
+	}
+	return strconv.FormatUint(n+1, 10), nil
+}
+
+// timestampVersion the update time in nanoseconds, it still grows if the clock goes back
+type timestampVersion struct {
+	now func() time.Time
+}
+
+func (t *timestampVersion) Next(old string) (string, error) {
+	n := uint64(t.now().UnixNano())
+	if last, err := strconv.ParseUint(canonicalVersion(old), 10, 64); err == nil && n <= last {
+		n = last + 1
+	}
+	return strconv.FormatUint(n, 10), nil
+}
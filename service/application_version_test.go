@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/config"
+	mockPlugin "github.com/baetyl/baetyl-cloud/mock/plugin"
+	"github.com/baetyl/baetyl-go/errors"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type versionModelStorage struct {
+	*mockPlugin.MockModelStorage
+	*mockPlugin.MockApplicationVersioner
+}
+
+func TestNewVersionStrategy(t *testing.T) {
+	for _, name := range []string{"", config.VersionStorage, config.VersionCounter, config.VersionTimestamp} {
+		s, err := NewVersionStrategy(name)
+		assert.NoError(t, err)
+		assert.NotNil(t, s)
+	}
+	_, err := NewVersionStrategy("semver")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrNotSupported, err.(errors.Coder).Code())
+
+	v, err := storageVersion{}.Next("12")
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+}
+
+func TestCounterVersion(t *testing.T) {
+	s := counterVersion{}
+	for old, expected := range map[string]string{"": "1", "1": "2", "v41": "42", "18446744073709551614": "18446744073709551615"} {
+		v, err := s.Next(old)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v, old)
+	}
+	_, err := s.Next("1.2.3")
+	assert.Error(t, err)
+}
+
+func TestTimestampVersion(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	s := &timestampVersion{now: func() time.Time { return now }}
+	v, err := s.Next("")
+	assert.NoError(t, err)
+	assert.Equal(t, "1600000000000000000", v)
+	v, err = s.Next("v12")
+	assert.NoError(t, err)
+	assert.Equal(t, "1600000000000000000", v)
+
+	// the version still grows if the clock goes back or two updates read the same time
+	v, err = s.Next("1600000000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "1600000000000000001", v)
+	v, err = s.Next("1700000000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, "1700000000000000001", v)
+}
+
+func TestDefaultApplicationService_VersionStrategy(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	// the model storage must take versions
+	conf := *mockObject.conf
+	conf.Application.VersionStrategy = config.VersionCounter
+	_, err := NewApplicationService(&conf)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrNotSupported, err.(errors.Coder).Code())
+
+	app := &specV1.Application{Namespace: "default", Name: "abc", Version: "12"}
+	updated := &specV1.Application{Namespace: "default", Name: "abc", Version: "13"}
+	versioner := mockPlugin.NewMockApplicationVersioner(mockObject.ctl)
+	as := applicationService{
+		storage:  &versionModelStorage{MockModelStorage: mockObject.modelStorage, MockApplicationVersioner: versioner},
+		versions: counterVersion{},
+	}
+	versioner.EXPECT().UpdateApplicationVersion("default", app, "13").Return(updated, nil)
+	res, err := as.updateStorage(ctx, "default", app)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, res)
+
+	// the storage assigns the version by default
+	as.versions = storageVersion{}
+	mockObject.modelStorage.EXPECT().UpdateApplication("default", app).Return(updated, nil)
+	res, err = as.updateStorage(ctx, "default", app)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, res)
+
+	// the storage which doesn't take versions
+	as.versions = counterVersion{}
+	as.storage = mockObject.modelStorage
+	_, err = as.updateStorage(ctx, "default", app)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrNotSupported, err.(errors.Coder).Code())
+}
@@ -1,7 +1,7 @@
 package service
 
 import (
-	"strings"
+	"errors"
 	"time"
 
 	"github.com/baetyl/baetyl-cloud/common"
@@ -41,7 +41,7 @@ func NewConfigService(config *config.CloudConfig) (ConfigService, error) {
 // Get get a config
 func (s *configService) Get(namespace, name, version string) (*specV1.Configuration, error) {
 	res, err := s.storage.GetConfig(namespace, name, version)
-	if err != nil && strings.Contains(err.Error(), "not found") {
+	if err != nil && errors.Is(err, plugin.ErrNotFound) {
 		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "config"),
 			common.Field("name", name))
 	}
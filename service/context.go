@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/jmoiron/sqlx"
+)
+
+// The plugins take ctx by the optional interfaces, such as plugin.ModelStorageContext. The wrappers below bind ctx to
+// the calls of a plugin, which go to the ctx methods if the plugin implements them. Otherwise ctx is checked before the call,
+// so a done ctx still stops the calls not started yet, but not the one in progress.
+
+// contextModelStorage binds ctx to the calls of plugin.ModelStorageContext, the other calls are the ones of ModelStorage
+type contextModelStorage struct {
+	plugin.ModelStorage
+	ctx context.Context
+}
+
+func withStorageContext(ctx context.Context, storage plugin.ModelStorage) plugin.ModelStorage {
+	return &contextModelStorage{ModelStorage: storage, ctx: ctx}
+}
+
+func (c *contextModelStorage) GetApplication(namespace, name, version string) (*specV1.Application, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.GetApplicationContext(c.ctx, namespace, name, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.GetApplication(namespace, name, version)
+}
+
+func (c *contextModelStorage) CreateApplication(namespace string, application *specV1.Application) (*specV1.Application, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.CreateApplicationContext(c.ctx, namespace, application)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.CreateApplication(namespace, application)
+}
+
+func (c *contextModelStorage) UpdateApplication(namespace string, application *specV1.Application) (*specV1.Application, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.UpdateApplicationContext(c.ctx, namespace, application)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.UpdateApplication(namespace, application)
+}
+
+func (c *contextModelStorage) DeleteApplication(namespace, name string) error {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.DeleteApplicationContext(c.ctx, namespace, name)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.ModelStorage.DeleteApplication(namespace, name)
+}
+
+func (c *contextModelStorage) ListApplication(namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.ListApplicationContext(c.ctx, namespace, listOptions)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.ListApplication(namespace, listOptions)
+}
+
+func (c *contextModelStorage) GetConfig(namespace, name, version string) (*specV1.Configuration, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.GetConfigContext(c.ctx, namespace, name, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.GetConfig(namespace, name, version)
+}
+
+func (c *contextModelStorage) CreateConfig(namespace string, config *specV1.Configuration) (*specV1.Configuration, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.CreateConfigContext(c.ctx, namespace, config)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.CreateConfig(namespace, config)
+}
+
+func (c *contextModelStorage) UpdateConfig(namespace string, config *specV1.Configuration) (*specV1.Configuration, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.UpdateConfigContext(c.ctx, namespace, config)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.UpdateConfig(namespace, config)
+}
+
+func (c *contextModelStorage) ListConfig(namespace string, listOptions *models.ListOptions) (*models.ConfigurationList, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.ListConfigContext(c.ctx, namespace, listOptions)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.ListConfig(namespace, listOptions)
+}
+
+func (c *contextModelStorage) GetSecret(namespace, name, version string) (*specV1.Secret, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.GetSecretContext(c.ctx, namespace, name, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.GetSecret(namespace, name, version)
+}
+
+func (c *contextModelStorage) CreateSecret(namespace string, secret *specV1.Secret) (*specV1.Secret, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.CreateSecretContext(c.ctx, namespace, secret)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.CreateSecret(namespace, secret)
+}
+
+func (c *contextModelStorage) UpdateSecret(namespace string, secret *specV1.Secret) (*specV1.Secret, error) {
+	if s, ok := c.ModelStorage.(plugin.ModelStorageContext); ok {
+		return s.UpdateSecretContext(c.ctx, namespace, secret)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ModelStorage.UpdateSecret(namespace, secret)
+}
+
+// contextDBStorage binds ctx to the calls of plugin.DBStorageContext, the other calls are the ones of DBStorage
+type contextDBStorage struct {
+	plugin.DBStorage
+	ctx context.Context
+}
+
+// withDBContext the nil storage of disabled history is kept nil
+func withDBContext(ctx context.Context, storage plugin.DBStorage) plugin.DBStorage {
+	if storage == nil {
+		return nil
+	}
+	return &contextDBStorage{DBStorage: storage, ctx: ctx}
+}
+
+func (c *contextDBStorage) GetApplication(name, namespace, version string) (*specV1.Application, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.GetApplicationContext(c.ctx, name, namespace, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.GetApplication(name, namespace, version)
+}
+
+func (c *contextDBStorage) ListApplicationHistory(name, namespace string, pageNo, pageSize int) ([]models.ApplicationHistory, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.ListApplicationHistoryContext(c.ctx, name, namespace, pageNo, pageSize)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.ListApplicationHistory(name, namespace, pageNo, pageSize)
+}
+
+func (c *contextDBStorage) CreateApplicationHistory(app *specV1.Application, operator string) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.CreateApplicationHistoryContext(c.ctx, app, operator)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.CreateApplicationHistory(app, operator)
+}
+
+func (c *contextDBStorage) DeleteApplicationHistory(name, namespace, version, operator string) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.DeleteApplicationHistoryContext(c.ctx, name, namespace, version, operator)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.DeleteApplicationHistory(name, namespace, version, operator)
+}
+
+func (c *contextDBStorage) CreateApplication(app *specV1.Application) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.CreateApplicationContext(c.ctx, app)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.CreateApplication(app)
+}
+
+func (c *contextDBStorage) SoftDeleteApplication(name, namespace, version string) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.SoftDeleteApplicationContext(c.ctx, name, namespace, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.SoftDeleteApplication(name, namespace, version)
+}
+
+func (c *contextDBStorage) RestoreApplication(name, namespace, version string) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.RestoreApplicationContext(c.ctx, name, namespace, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.RestoreApplication(name, namespace, version)
+}
+
+func (c *contextDBStorage) PruneApplication(name, namespace, activeVersion string, keep int) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.PruneApplicationContext(c.ctx, name, namespace, activeVersion, keep)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.PruneApplication(name, namespace, activeVersion, keep)
+}
+
+func (c *contextDBStorage) GetSoftDeletedApplication(name, namespace string, since time.Time) (*specV1.Application, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.GetSoftDeletedApplicationContext(c.ctx, name, namespace, since)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.GetSoftDeletedApplication(name, namespace, since)
+}
+
+func (c *contextDBStorage) ListApplicationByTime(name, namespace string, start, end time.Time) ([]specV1.Application, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.ListApplicationByTimeContext(c.ctx, name, namespace, start, end)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.ListApplicationByTime(name, namespace, start, end)
+}
+
+func (c *contextDBStorage) CountApplication(tx *sqlx.Tx, name, namespace string) (int, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.CountApplicationContext(c.ctx, tx, name, namespace)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.DBStorage.CountApplication(tx, name, namespace)
+}
+
+func (c *contextDBStorage) CreateApplicationRequest(req *models.ApplicationRequest) (sql.Result, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.CreateApplicationRequestContext(c.ctx, req)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.CreateApplicationRequest(req)
+}
+
+func (c *contextDBStorage) GetApplicationRequest(namespace, requestID string) (*models.ApplicationRequest, error) {
+	if s, ok := c.DBStorage.(plugin.DBStorageContext); ok {
+		return s.GetApplicationRequestContext(c.ctx, namespace, requestID)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.DBStorage.GetApplicationRequest(namespace, requestID)
+}
+
+// contextFunction binds ctx to the calls of plugin.FunctionContext, the other calls are the ones of Function
+type contextFunction struct {
+	plugin.Function
+	ctx context.Context
+}
+
+func withFunctionContext(ctx context.Context, function plugin.Function) plugin.Function {
+	return &contextFunction{Function: function, ctx: ctx}
+}
+
+func (c *contextFunction) List(userID string, listOptions *models.ListOptions) ([]models.Function, int, error) {
+	if f, ok := c.Function.(plugin.FunctionContext); ok {
+		return f.ListContext(c.ctx, userID, listOptions)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	return c.Function.List(userID, listOptions)
+}
+
+func (c *contextFunction) ListFunctionVersions(userID, name string) ([]models.Function, error) {
+	if f, ok := c.Function.(plugin.FunctionContext); ok {
+		return f.ListFunctionVersionsContext(c.ctx, userID, name)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Function.ListFunctionVersions(userID, name)
+}
+
+func (c *contextFunction) Get(userID, name, version string) (*models.Function, error) {
+	if f, ok := c.Function.(plugin.FunctionContext); ok {
+		return f.GetContext(c.ctx, userID, name, version)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Function.Get(userID, name, version)
+}
+
+func (c *contextFunction) Invoke(userID, name, version string, payload []byte) ([]byte, error) {
+	if f, ok := c.Function.(plugin.FunctionContext); ok {
+		return f.InvokeContext(c.ctx, userID, name, version, payload)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Function.Invoke(userID, name, version, payload)
+}
+
+// detachedContext keeps the values of ctx but is never done, for the writes which must finish once the change is made,
+// such as the history of an app created
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	mockPlugin "github.com/baetyl/baetyl-cloud/mock/plugin"
+	"github.com/baetyl/baetyl-cloud/models"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// contextStorage a storage which takes ctx
+type contextStorage struct {
+	*mockPlugin.MockModelStorage
+	*mockPlugin.MockModelStorageContext
+}
+
+func TestWithStorageContext(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app := &specV1.Application{Name: "abc"}
+
+	// the ctx methods are called if the storage implements them
+	mctx := mockPlugin.NewMockModelStorageContext(ctl)
+	storage := withStorageContext(ctx, &contextStorage{mockPlugin.NewMockModelStorage(ctl), mctx})
+	mctx.EXPECT().GetApplicationContext(ctx, "default", "abc", "").Return(app, nil)
+	mctx.EXPECT().ListConfigContext(ctx, "default", nil).Return(&models.ConfigurationList{}, nil)
+	res, err := storage.GetApplication("default", "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+	_, err = storage.ListConfig("default", nil)
+	assert.NoError(t, err)
+
+	// otherwise ctx is checked before the call
+	mStorage := mockPlugin.NewMockModelStorage(ctl)
+	storage = withStorageContext(ctx, mStorage)
+	mStorage.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	res, err = storage.GetApplication("default", "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+
+	cancel()
+	_, err = storage.GetApplication("default", "abc", "")
+	assert.Equal(t, context.Canceled, err)
+	_, err = storage.CreateSecret("default", &specV1.Secret{Name: "abc"})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWithDBContext(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	assert.Nil(t, withDBContext(context.Background(), nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mDB := mockPlugin.NewMockDBStorage(ctl)
+	_, err := withDBContext(ctx, mDB).ListApplicationHistory("abc", "default", 1, 10)
+	assert.Equal(t, context.Canceled, err)
+	_, err = withDBContext(ctx, mDB).CountApplication(nil, "abc", "default")
+	assert.Equal(t, context.Canceled, err)
+
+	// the history is written with the detached ctx even if ctx is done
+	mDB.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil)
+	_, err = withDBContext(detachedContext{ctx}, mDB).CreateApplicationHistory(&specV1.Application{Name: "abc"}, "")
+	assert.NoError(t, err)
+}
+
+func TestApplicationService_ContextDone(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	as := applicationService{storage: mockObject.modelStorage, dbStorage: mockObject.dbStorage}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// no call of storage is expected once ctx is done
+	_, err := as.Exists(ctx, "default", "abc")
+	assert.Equal(t, context.Canceled, err)
+	_, err = as.Diff(ctx, "default", "abc", "1", "2")
+	assert.Equal(t, context.Canceled, err)
+	_, err = as.GetBatch(ctx, "default", []models.AppRef{{Name: "abc"}})
+	assert.Equal(t, context.Canceled, err)
+	_, err = as.Describe(ctx, "default", "abc", "")
+	assert.Equal(t, context.Canceled, err)
+}
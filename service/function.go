@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/config"
 	"github.com/baetyl/baetyl-cloud/models"
@@ -11,14 +14,20 @@ import (
 //go:generate mockgen -destination=../mock/service/function.go -package=plugin github.com/baetyl/baetyl-cloud/service FunctionService
 
 type FunctionService interface {
-	List(userID, source string) ([]models.Function, error)
-	ListFunctionVersions(userID, name, source string) ([]models.Function, error)
+	List(ctx context.Context, userID, source string) ([]models.Function, error)
+	// ListWithOptions returns a page of functions and the total, List is kept for the callers which need all of them
+	ListWithOptions(ctx context.Context, userID, source string, listOptions *models.ListOptions) ([]models.Function, int, error)
+	ListFunctionVersions(ctx context.Context, userID, name, source string) ([]models.Function, error)
 	ListSources() []models.FunctionSource
-	GetFunction(userID, name, version, source string) (*models.Function, error)
+	ListRuntimes(ctx context.Context, userID, source string) ([]models.FunctionRuntime, error)
+	GetFunction(ctx context.Context, userID, name, version, source string) (*models.Function, error)
+	// Invoke a test invocation of function, it returns ErrRequestTimeout if the function doesn't respond in time
+	Invoke(ctx context.Context, userID, name, version, source string, payload []byte) ([]byte, error)
 }
 
 type functionService struct {
-	functions map[string]plugin.Function
+	functions     map[string]plugin.Function
+	invokeTimeout time.Duration
 }
 
 // NewFunctionService NewFunctionService
@@ -32,24 +41,50 @@ func NewFunctionService(config *config.CloudConfig) (FunctionService, error) {
 		functions[v] = cs.(plugin.Function)
 	}
 	return &functionService{
-		functions: functions,
+		functions:     functions,
+		invokeTimeout: config.Function.InvokeTimeout,
 	}, nil
 }
 
-// List list functions
-func (c *functionService) List(userID string, source string) ([]models.Function, error) {
+// function the plugin of source whose calls take ctx, see withFunctionContext
+func (c *functionService) function(ctx context.Context, source string) (plugin.Function, error) {
 	functionPlugin, ok := c.functions[source]
 	if !ok {
 		return nil, common.Error(common.ErrRequestParamInvalid, common.Field("error", fmt.Sprintf("the source (%s) is not supported", source)))
 	}
-	return functionPlugin.List(userID)
+	return withFunctionContext(ctx, functionPlugin), nil
 }
 
-//ListVersions List all versions of a function
-func (c *functionService) ListFunctionVersions(userID, name string, source string) ([]models.Function, error) {
-	functionPlugin, ok := c.functions[source]
-	if !ok {
-		return nil, common.Error(common.ErrRequestParamInvalid, common.Field("error", fmt.Sprintf("the source (%s) is not supported", source)))
+// List list functions, it returns the error of ctx once ctx is done
+func (c *functionService) List(ctx context.Context, userID string, source string) ([]models.Function, error) {
+	functionPlugin, err := c.function(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := functionPlugin.List(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListWithOptions list functions by page, it returns the error of ctx once ctx is done
+func (c *functionService) ListWithOptions(ctx context.Context, userID, source string, listOptions *models.ListOptions) ([]models.Function, int, error) {
+	functionPlugin, err := c.function(ctx, source)
+	if err != nil {
+		return nil, 0, err
+	}
+	if listOptions == nil {
+		listOptions = &models.ListOptions{}
+	}
+	return functionPlugin.List(userID, listOptions)
+}
+
+// ListFunctionVersions list all versions of a function, it returns the error of ctx once ctx is done
+func (c *functionService) ListFunctionVersions(ctx context.Context, userID, name string, source string) ([]models.Function, error) {
+	functionPlugin, err := c.function(ctx, source)
+	if err != nil {
+		return nil, err
 	}
 	return functionPlugin.ListFunctionVersions(userID, name)
 }
@@ -65,11 +100,47 @@ func (c *functionService) ListSources() []models.FunctionSource {
 	return sources
 }
 
-func (c *functionService) GetFunction(userID, name, version, source string) (*models.Function, error) {
-	functionPlugin, ok := c.functions[source]
-	if !ok {
-		return nil, common.Error(common.ErrRequestParamInvalid, common.Field("error", fmt.Sprintf("the source (%s) is not supported", source)))
+// GetFunction get function, it returns the error of ctx once ctx is done
+func (c *functionService) GetFunction(ctx context.Context, userID, name, version, source string) (*models.Function, error) {
+	functionPlugin, err := c.function(ctx, source)
+	if err != nil {
+		return nil, err
 	}
-
 	return functionPlugin.Get(userID, name, version)
 }
+
+// Invoke invoke function by the plugin of source, the invocation is aborted once ctx is done or the invoke timeout passes.
+// The plugin not implementing plugin.FunctionContext can't be aborted, its late response is still taken as timeout
+func (c *functionService) Invoke(ctx context.Context, userID, name, version, source string, payload []byte) ([]byte, error) {
+	if c.invokeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.invokeTimeout)
+		defer cancel()
+	}
+	functionPlugin, err := c.function(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	res, err := functionPlugin.Invoke(userID, name, version, payload)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, common.Error(common.ErrRequestTimeout,
+			common.Field("timeout", c.invokeTimeout),
+			common.Field("error", fmt.Sprintf("function %s:%s doesn't respond", name, version)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListRuntimes list the runtimes supported by the plugin of source, it returns the error of ctx once ctx is done
+func (c *functionService) ListRuntimes(ctx context.Context, userID, source string) ([]models.FunctionRuntime, error) {
+	functionPlugin, err := c.function(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	return functionPlugin.ListRuntimes(userID)
+}
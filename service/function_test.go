@@ -1,10 +1,15 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-go/errors"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,10 +40,10 @@ func TestDefaultFunctionService_List(t *testing.T) {
 
 	namespace := "default"
 
-	mockObject.functionPlugin.EXPECT().List(namespace).Return(functions, nil)
+	mockObject.functionPlugin.EXPECT().List(namespace, nil).Return(functions, len(functions), nil)
 	cs, err := NewFunctionService(mockObject.conf)
 	assert.NoError(t, err)
-	res, err := cs.List(namespace, mockObject.conf.Plugin.Functions[0])
+	res, err := cs.List(context.Background(), namespace, mockObject.conf.Plugin.Functions[0])
 	assert.NoError(t, err)
 	assert.Equal(t, len(res), 3)
 	assert.Equal(t, functions[0].Name, res[0].Name)
@@ -51,7 +56,52 @@ func TestDefaultFunctionService_List(t *testing.T) {
 	assert.Equal(t, functions[0].Runtime, res[0].Runtime)
 }
 
+func TestDefaultFunctionService_ListWithOptions(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	cs, err := NewFunctionService(mockObject.conf)
+	assert.NoError(t, err)
+	source := mockObject.conf.Plugin.Functions[0]
+
+	functions := []models.Function{{Name: "test1", Version: "1"}}
+	opts := &models.ListOptions{NamePrefix: "test", PageNo: 1, PageSize: 1}
+	mockObject.functionPlugin.EXPECT().List("default", opts).Return(functions, 3, nil)
+	res, total, err := cs.ListWithOptions(ctx, "default", source, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, functions, res)
+
+	mockObject.functionPlugin.EXPECT().List("default", &models.ListOptions{}).Return([]models.Function{}, 0, nil)
+	res, total, err = cs.ListWithOptions(ctx, "default", source, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, res)
+
+	_, _, err = cs.ListWithOptions(ctx, "default", "unknown", nil)
+	assert.Error(t, err)
+}
+
+func TestDefaultFunctionService_ListContext(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	cs, err := NewFunctionService(mockObject.conf)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cs.List(ctx, "default", mockObject.conf.Plugin.Functions[0])
+	assert.Equal(t, context.Canceled, err)
+	_, err = cs.ListFunctionVersions(ctx, "default", "test1", mockObject.conf.Plugin.Functions[0])
+	assert.Equal(t, context.Canceled, err)
+	_, err = cs.GetFunction(ctx, "default", "test1", "1", mockObject.conf.Plugin.Functions[0])
+	assert.Equal(t, context.Canceled, err)
+}
+
 func TestDefaultFunctionService_ListFunctionVersions(t *testing.T) {
+	ctx := context.Background()
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
 
@@ -71,7 +121,7 @@ func TestDefaultFunctionService_ListFunctionVersions(t *testing.T) {
 	mockObject.functionPlugin.EXPECT().ListFunctionVersions(namespace, name).Return(functions, nil).Times(1)
 	cs, err := NewFunctionService(mockObject.conf)
 	assert.NoError(t, err)
-	res, err := cs.ListFunctionVersions(namespace, name, mockObject.conf.Plugin.Functions[0])
+	res, err := cs.ListFunctionVersions(ctx, namespace, name, mockObject.conf.Plugin.Functions[0])
 	assert.NoError(t, err)
 	assert.Equal(t, len(functions), len(res))
 	assert.Equal(t, functions[0].Name, res[0].Name)
@@ -80,8 +130,8 @@ func TestDefaultFunctionService_ListFunctionVersions(t *testing.T) {
 	assert.Equal(t, functions[0].Version, res[0].Version)
 
 	name2 := "test2"
-	mockObject.functionPlugin.EXPECT().ListFunctionVersions(namespace, name2).Return(nil, errors.New("err")).Times(1)
-	_, err2 := cs.ListFunctionVersions(namespace, name2, mockObject.conf.Plugin.Functions[0])
+	mockObject.functionPlugin.EXPECT().ListFunctionVersions(namespace, name2).Return(nil, fmt.Errorf("err")).Times(1)
+	_, err2 := cs.ListFunctionVersions(ctx, namespace, name2, mockObject.conf.Plugin.Functions[0])
 	assert.Error(t, err2)
 	assert.Equal(t, err2.Error(), "err")
 }
@@ -109,6 +159,7 @@ func TestDefaultFunctionService_ListSourcesWithEmptySource(t *testing.T) {
 }
 
 func TestDefaultFunctionService_GetFunction(t *testing.T) {
+	ctx := context.Background()
 	mockObject := InitMockEnvironment(t)
 	defer mockObject.Close()
 
@@ -129,13 +180,70 @@ func TestDefaultFunctionService_GetFunction(t *testing.T) {
 
 	cs, err := NewFunctionService(mockObject.conf)
 	assert.NoError(t, err)
-	res, err := cs.GetFunction(namespace, name, version, mockObject.conf.Plugin.Functions[0])
+	res, err := cs.GetFunction(ctx, namespace, name, version, mockObject.conf.Plugin.Functions[0])
 	assert.NoError(t, err)
 	assert.Equal(t, *res, function)
 
 	name2, version2 := "test2", "v2"
-	mockObject.functionPlugin.EXPECT().Get(namespace, name2, version2).Return(nil, errors.New("err")).Times(1)
-	_, err2 := cs.GetFunction(namespace, name2, version2, mockObject.conf.Plugin.Functions[0])
+	mockObject.functionPlugin.EXPECT().Get(namespace, name2, version2).Return(nil, fmt.Errorf("err")).Times(1)
+	_, err2 := cs.GetFunction(ctx, namespace, name2, version2, mockObject.conf.Plugin.Functions[0])
 	assert.Error(t, err2)
 	assert.Equal(t, err2.Error(), "err")
 }
+
+func TestDefaultFunctionService_Invoke(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	mockObject.conf.Function.InvokeTimeout = time.Millisecond * 50
+	cs, err := NewFunctionService(mockObject.conf)
+	assert.NoError(t, err)
+	source := mockObject.conf.Plugin.Functions[0]
+
+	// success
+	mockObject.functionPlugin.EXPECT().Invoke("user", "test", "1", []byte(`{"a":1}`)).Return([]byte(`{"b":2}`), nil)
+	res, err := cs.Invoke(ctx, "user", "test", "1", source, []byte(`{"a":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"b":2}`), res)
+
+	// error response
+	mockObject.functionPlugin.EXPECT().Invoke("user", "test", "1", gomock.Any()).Return(nil, common.Error(common.ErrFunction, common.Field("error", "[500] crashed")))
+	_, err = cs.Invoke(ctx, "user", "test", "1", source, nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrFunction, err.(errors.Coder).Code())
+	assert.Contains(t, err.Error(), "crashed")
+
+	// timeout
+	mockObject.functionPlugin.EXPECT().Invoke("user", "test", "1", gomock.Any()).
+		DoAndReturn(func(_, _, _ string, _ []byte) ([]byte, error) {
+			time.Sleep(time.Millisecond * 200)
+			return nil, nil
+		})
+	_, err = cs.Invoke(ctx, "user", "test", "1", source, nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestTimeout, err.(errors.Coder).Code())
+	time.Sleep(time.Millisecond * 200)
+
+	_, err = cs.Invoke(ctx, "user", "test", "1", "unknown", nil)
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+}
+
+func TestDefaultFunctionService_ListRuntimes(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	cs, err := NewFunctionService(mockObject.conf)
+	assert.NoError(t, err)
+
+	runtimes := []models.FunctionRuntime{{Name: "nodejs10", Handler: "index.handler"}, {Name: "python3", Handler: "index.handler"}}
+	mockObject.functionPlugin.EXPECT().ListRuntimes("default").Return(runtimes, nil)
+	res, err := cs.ListRuntimes(ctx, "default", mockObject.conf.Plugin.Functions[0])
+	assert.NoError(t, err)
+	assert.Equal(t, runtimes, res)
+
+	_, err = cs.ListRuntimes(ctx, "default", "unknown")
+	assert.Error(t, err)
+}
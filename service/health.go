@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+
+	"github.com/baetyl/baetyl-cloud/config"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+)
+
+//go:generate mockgen -destination=../mock/service/health.go -package=plugin github.com/baetyl/baetyl-cloud/service HealthService
+
+// HealthService HealthService
+type HealthService interface {
+	// HealthCheck probe all backing plugins, only the model storage is critical
+	HealthCheck(ctx context.Context) *models.HealthStatus
+}
+
+type healthService struct {
+	cfg          *config.CloudConfig
+	modelStorage plugin.ModelStorage
+	dbStorage    plugin.DBStorage
+	functions    map[string]plugin.Function
+}
+
+// NewHealthService NewHealthService
+func NewHealthService(config *config.CloudConfig) (HealthService, error) {
+	ms, err := plugin.GetPlugin(config.Plugin.ModelStorage)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := plugin.GetPlugin(config.Plugin.DatabaseStorage)
+	if err != nil {
+		return nil, err
+	}
+	functions := make(map[string]plugin.Function)
+	for _, v := range config.Plugin.Functions {
+		fs, err := plugin.GetPlugin(v)
+		if err != nil {
+			return nil, err
+		}
+		functions[v] = fs.(plugin.Function)
+	}
+	return &healthService{
+		cfg:          config,
+		modelStorage: ms.(plugin.ModelStorage),
+		dbStorage:    ds.(plugin.DBStorage),
+		functions:    functions,
+	}, nil
+}
+
+func (h *healthService) HealthCheck(ctx context.Context) *models.HealthStatus {
+	status := &models.HealthStatus{Healthy: true}
+	check := func(kind, name string, critical bool, err error) {
+		if err == nil {
+			return
+		}
+		if critical {
+			status.Healthy = false
+		} else {
+			status.Degraded = true
+		}
+		status.Unhealthy = append(status.Unhealthy, models.PluginHealth{
+			Kind:     kind,
+			Name:     name,
+			Critical: critical,
+			Error:    err.Error(),
+		})
+	}
+	check("modelStorage", h.cfg.Plugin.ModelStorage, true, h.modelStorage.Ping(ctx))
+	// the database stores history and indexes, the applications are still served without it
+	check("databaseStorage", h.cfg.Plugin.DatabaseStorage, false, h.dbStorage.Ping(ctx))
+	for _, name := range h.cfg.Plugin.Functions {
+		check("function", name, false, h.functions[name].Ping(ctx))
+	}
+	return status
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_HealthCheck(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	hs, err := NewHealthService(mockObject.conf)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	fn := mockObject.conf.Plugin.Functions[0]
+
+	// all healthy
+	mockObject.modelStorage.EXPECT().Ping(ctx).Return(nil)
+	mockObject.dbStorage.EXPECT().Ping(ctx).Return(nil)
+	mockObject.functionPlugin.EXPECT().Ping(ctx).Return(nil)
+	status := hs.HealthCheck(ctx)
+	assert.Equal(t, &models.HealthStatus{Healthy: true}, status)
+
+	// degraded
+	mockObject.modelStorage.EXPECT().Ping(ctx).Return(nil)
+	mockObject.dbStorage.EXPECT().Ping(ctx).Return(fmt.Errorf("db down"))
+	mockObject.functionPlugin.EXPECT().Ping(ctx).Return(fmt.Errorf("function down"))
+	status = hs.HealthCheck(ctx)
+	assert.True(t, status.Healthy)
+	assert.True(t, status.Degraded)
+	assert.Equal(t, []models.PluginHealth{
+		{Kind: "databaseStorage", Name: mockObject.conf.Plugin.DatabaseStorage, Error: "db down"},
+		{Kind: "function", Name: fn, Error: "function down"},
+	}, status.Unhealthy)
+
+	// critical
+	mockObject.modelStorage.EXPECT().Ping(ctx).Return(fmt.Errorf("k8s down"))
+	mockObject.dbStorage.EXPECT().Ping(gomock.Any()).Return(nil)
+	mockObject.functionPlugin.EXPECT().Ping(gomock.Any()).Return(nil)
+	status = hs.HealthCheck(ctx)
+	assert.False(t, status.Healthy)
+	assert.False(t, status.Degraded)
+	assert.Equal(t, []models.PluginHealth{
+		{Kind: "modelStorage", Name: mockObject.conf.Plugin.ModelStorage, Critical: true, Error: "k8s down"},
+	}, status.Unhealthy)
+}
@@ -1,9 +1,17 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/config"
+	"github.com/baetyl/baetyl-cloud/models"
 	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-go/log"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 )
 
 //go:generate mockgen -destination=../mock/service/index.go -package=plugin github.com/baetyl/baetyl-cloud/service IndexService
@@ -15,21 +23,93 @@ type IndexService interface {
 	// app and config
 	RefreshAppIndexByConfig(namespace, config string, apps []string) error
 	RefreshConfigIndexByApp(namespace, app string, configs []string) error
+	// RefreshConfigIndexByApps refresh the config index of many apps in one storage call
+	RefreshConfigIndexByApps(namespace string, appConfigs map[string][]string) error
 	ListAppIndexByConfig(namespace, config string) ([]string, error)
 	ListConfigIndexByApp(namespace, app string) ([]string, error)
 
 	ListNodesByApp(namespace, app string) ([]string, error)
 	ListAppsByNode(namespace, node string) ([]string, error)
 	ListAppIndexBySecret(namespace, secret string) ([]string, error)
+	// ListAppsByConfig the inverse of RefreshConfigIndexByApp, app names are sorted
+	ListAppsByConfig(namespace, config string) ([]string, error)
+	ListAppsBySecret(namespace, secret string) ([]string, error)
+	// IsReferenced tell whether the config or secret is still referenced, and by which apps
+	IsReferenced(namespace, kind, name string) (bool, []string, error)
+	// CountReferences the number of distinct apps referencing the config or secret
+	CountReferences(namespace, kind, name string) (int, error)
 
 	// app and secret
 	RefreshSecretIndexByApp(namespace, app string, secrets []string) error
+	RefreshSecretIndexByApps(namespace string, appSecrets map[string][]string) error
 	RefreshNodesIndexByApp(namespace, appName string, nodes []string) error
 	RefreshAppsIndexByNode(namespace, node string, apps []string) error
+
+	// GarbageCollect prune the config and secret index entries of apps which no longer exist
+	GarbageCollect(namespace string) (removed int, err error)
+	// Rebuild rewrite the config and secret index of all apps, it is safe to run repeatedly
+	Rebuild(namespace string) error
 }
 
 type indexService struct {
-	storage plugin.DBStorage
+	storage      plugin.DBStorage
+	modelStorage plugin.ModelStorage
+	// serializes the index writes of an app, the refresh is not atomic in storage
+	locks appLocks
+}
+
+// appLocks the mutexes of apps keyed by namespace and name, a mutex is dropped once nobody holds or waits for it
+type appLocks struct {
+	mutex sync.Mutex
+	locks map[string]*appLock
+}
+
+type appLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock acquire the mutex of app and return the function to release it
+func (l *appLocks) lock(namespace, app string) func() {
+	key := namespace + "/" + app
+	l.mutex.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*appLock{}
+	}
+	m, ok := l.locks[key]
+	if !ok {
+		m = &appLock{}
+		l.locks[key] = m
+	}
+	m.refs++
+	l.mutex.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+		l.mutex.Lock()
+		m.refs--
+		if m.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// lockApps acquire the mutexes of apps in order and return the function to release all of them
+func (l *appLocks) lockApps(namespace string, apps []string) func() {
+	sorted := make([]string, len(apps))
+	copy(sorted, apps)
+	sort.Strings(sorted)
+	unlocks := make([]func(), 0, len(sorted))
+	for _, app := range sorted {
+		unlocks = append(unlocks, l.lock(namespace, app))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
 }
 
 // NewIndexService New Index Service
@@ -38,7 +118,11 @@ func NewIndexService(config *config.CloudConfig) (IndexService, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &indexService{storage: ds.(plugin.DBStorage)}, nil
+	ms, err := plugin.GetPlugin(config.Plugin.ModelStorage)
+	if err != nil {
+		return nil, err
+	}
+	return &indexService{storage: ds.(plugin.DBStorage), modelStorage: ms.(plugin.ModelStorage)}, nil
 }
 
 func (i *indexService) RefreshIndex(namespace string, keyA, keyB common.Resource, valueA string, valueBs []string) error {
@@ -56,13 +140,22 @@ func (i *indexService) RefreshAppIndexByConfig(namespace, config string, apps []
 }
 
 func (i *indexService) RefreshConfigIndexByApp(namespace, app string, configs []string) error {
+	defer i.locks.lock(namespace, app)()
 	return i.RefreshIndex(namespace, common.Application, common.Config, app, configs)
 }
 
+func (i *indexService) RefreshConfigIndexByApps(namespace string, appConfigs map[string][]string) error {
+	return i.refreshIndexByApps(namespace, common.Config, appConfigs)
+}
+
 func (i *indexService) ListAppIndexByConfig(namespace, config string) ([]string, error) {
 	return i.ListIndex(namespace, common.Application, common.Config, config)
 }
 
+func (i *indexService) ListAppsByConfig(namespace, config string) ([]string, error) {
+	return i.listSortedApps(namespace, common.Config, config)
+}
+
 func (i *indexService) ListConfigIndexByApp(namespace, app string) ([]string, error) {
 	return i.ListIndex(namespace, common.Config, common.Application, app)
 }
@@ -85,9 +178,213 @@ func (i *indexService) ListAppsByNode(namespace, node string) ([]string, error)
 
 // secret && apps
 func (i *indexService) RefreshSecretIndexByApp(namespace, app string, secrets []string) error {
+	defer i.locks.lock(namespace, app)()
 	return i.RefreshIndex(namespace, common.Application, common.Secret, app, secrets)
 }
 
+func (i *indexService) RefreshSecretIndexByApps(namespace string, appSecrets map[string][]string) error {
+	return i.refreshIndexByApps(namespace, common.Secret, appSecrets)
+}
+
+func (i *indexService) refreshIndexByApps(namespace string, res common.Resource, values map[string][]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	apps := make([]string, 0, len(values))
+	for app := range values {
+		apps = append(apps, app)
+	}
+	defer i.locks.lockApps(namespace, apps)()
+	return i.storage.RefreshIndexes(namespace, common.Application, res, values)
+}
+
 func (i *indexService) ListAppIndexBySecret(namespace, secret string) ([]string, error) {
 	return i.ListIndex(namespace, common.Application, common.Secret, secret)
 }
+
+func (i *indexService) ListAppsBySecret(namespace, secret string) ([]string, error) {
+	return i.listSortedApps(namespace, common.Secret, secret)
+}
+
+// IsReferenced kind is config or secret, the app names are sorted
+func (i *indexService) IsReferenced(namespace, kind, name string) (bool, []string, error) {
+	res, err := referenceKind(kind)
+	if err != nil {
+		return false, nil, err
+	}
+	apps, err := i.listSortedApps(namespace, res, name)
+	if err != nil {
+		return false, nil, err
+	}
+	return len(apps) > 0, apps, nil
+}
+
+// CountReferences kind is config or secret, an app mounting it more than once is counted once
+func (i *indexService) CountReferences(namespace, kind, name string) (int, error) {
+	res, err := referenceKind(kind)
+	if err != nil {
+		return 0, err
+	}
+	apps, err := i.listSortedApps(namespace, res, name)
+	if err != nil {
+		return 0, err
+	}
+	return len(apps), nil
+}
+
+func referenceKind(kind string) (common.Resource, error) {
+	res := common.Resource(kind)
+	if res != common.Config && res != common.Secret {
+		return "", common.Error(common.ErrRequestParamInvalid,
+			common.Field("error", fmt.Sprintf("kind %s is not supported, it should be %s or %s", kind, common.Config, common.Secret)))
+	}
+	return res, nil
+}
+
+// listSortedApps the index has a row for each reference, so the app referencing value more than once is listed once
+func (i *indexService) listSortedApps(namespace string, byKey common.Resource, value string) ([]string, error) {
+	apps, err := i.ListIndex(namespace, common.Application, byKey, value)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(apps))
+	seen := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		if !seen[app] {
+			seen[app] = true
+			res = append(res, app)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// GarbageCollect can be run periodically, it returns the number of removed entries
+func (i *indexService) GarbageCollect(namespace string) (int, error) {
+	removed := 0
+	for _, res := range []common.Resource{common.Config, common.Secret} {
+		apps, err := i.storage.ListIndexKeys(namespace, common.Application, res)
+		if err != nil {
+			return removed, err
+		}
+		for _, app := range apps {
+			_, err = i.modelStorage.GetApplication(namespace, app, "")
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, plugin.ErrNotFound) {
+				return removed, err
+			}
+			values, err := i.ListIndex(namespace, res, common.Application, app)
+			if err != nil {
+				return removed, err
+			}
+			if err = i.RefreshIndex(namespace, common.Application, res, app, []string{}); err != nil {
+				return removed, err
+			}
+			for _, v := range values {
+				log.L().Debug("remove stale index",
+					log.Any(common.KeyContextNamespace, namespace),
+					log.Any("app", app),
+					log.Any(string(res), v))
+			}
+			removed += len(values)
+		}
+	}
+	return removed, nil
+}
+
+// Rebuild recompute the config and secret index from the volumes of apps and remove the entries no longer apply
+func (i *indexService) Rebuild(namespace string) error {
+	list, err := i.modelStorage.ListApplication(namespace, &models.ListOptions{})
+	if err != nil {
+		return err
+	}
+	added, removed := 0, 0
+	exists := map[string]bool{}
+	for _, item := range list.Items {
+		app, err := i.modelStorage.GetApplication(namespace, item.Name, "")
+		if err != nil {
+			return err
+		}
+		exists[item.Name] = true
+		configs, secrets := volumeRefs(app)
+		for res, values := range map[common.Resource][]string{common.Config: configs, common.Secret: secrets} {
+			a, r, err := i.rewriteIndex(namespace, res, item.Name, values)
+			if err != nil {
+				return err
+			}
+			added, removed = added+a, removed+r
+		}
+	}
+	for _, res := range []common.Resource{common.Config, common.Secret} {
+		apps, err := i.storage.ListIndexKeys(namespace, common.Application, res)
+		if err != nil {
+			return err
+		}
+		for _, app := range apps {
+			if exists[app] {
+				continue
+			}
+			_, r, err := i.rewriteIndex(namespace, res, app, []string{})
+			if err != nil {
+				return err
+			}
+			removed += r
+		}
+	}
+	log.L().Info("rebuild index",
+		log.Any(common.KeyContextNamespace, namespace),
+		log.Any("apps", len(list.Items)),
+		log.Any("added", added),
+		log.Any("removed", removed))
+	return nil
+}
+
+// rewriteIndex refresh the index of app and return the number of added and removed entries
+func (i *indexService) rewriteIndex(namespace string, res common.Resource, app string, values []string) (int, int, error) {
+	defer i.locks.lock(namespace, app)()
+	olds, err := i.ListIndex(namespace, res, common.Application, app)
+	if err != nil {
+		return 0, 0, err
+	}
+	oldSet, newSet := map[string]bool{}, map[string]bool{}
+	for _, v := range olds {
+		oldSet[v] = true
+	}
+	for _, v := range values {
+		newSet[v] = true
+	}
+	added, removed := 0, 0
+	for v := range newSet {
+		if !oldSet[v] {
+			added++
+		}
+	}
+	for v := range oldSet {
+		if !newSet[v] {
+			removed++
+		}
+	}
+	if added == 0 && removed == 0 && len(olds) == len(values) {
+		return 0, 0, nil
+	}
+	if err = i.RefreshIndex(namespace, common.Application, res, app, values); err != nil {
+		return 0, 0, err
+	}
+	return added, removed, nil
+}
+
+// volumeRefs the names of configs and secrets referenced by volumes of app
+func volumeRefs(app *specV1.Application) ([]string, []string) {
+	configs, secrets := []string{}, []string{}
+	for _, vol := range app.Volumes {
+		if vol.Config != nil {
+			configs = append(configs, vol.Config.Name)
+		}
+		if vol.Secret != nil {
+			secrets = append(secrets, vol.Secret.Name)
+		}
+	}
+	return configs, secrets
+}
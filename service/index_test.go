@@ -1,10 +1,19 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	"github.com/baetyl/baetyl-go/errors"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -98,3 +107,339 @@ func TestResourceRefresh(t *testing.T) {
 	err = is.RefreshAppsIndexByNode(namespace, data, arr)
 	assert.NoError(t, err)
 }
+
+func TestDefaultIndexService_GarbageCollect(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	namespace := "default"
+	is, err := NewIndexService(mockObject.conf)
+	assert.NoError(t, err)
+
+	mockObject.dbStorage.EXPECT().ListIndexKeys(namespace, common.Application, common.Config).Return([]string{"app", "dangling"}, nil)
+	mockObject.dbStorage.EXPECT().ListIndexKeys(namespace, common.Application, common.Secret).Return([]string{"app"}, nil)
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "app", "").Return(nil, nil).Times(2)
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "dangling", "").Return(nil, plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"dangling\" not found")))
+	mockObject.dbStorage.EXPECT().ListIndex(namespace, common.Config, common.Application, "dangling").Return([]string{"c1", "c2"}, nil)
+	mockObject.dbStorage.EXPECT().RefreshIndex(namespace, common.Application, common.Config, "dangling", []string{}).Return(nil)
+
+	removed, err := is.GarbageCollect(namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	mockObject.dbStorage.EXPECT().ListIndexKeys(namespace, common.Application, common.Config).Return([]string{"app"}, nil)
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "app", "").Return(nil, fmt.Errorf("error"))
+	_, err = is.GarbageCollect(namespace)
+	assert.Error(t, err)
+
+	mockObject.dbStorage.EXPECT().ListIndexKeys(namespace, common.Application, common.Config).Return(nil, fmt.Errorf("error"))
+	_, err = is.GarbageCollect(namespace)
+	assert.Error(t, err)
+}
+
+func TestDefaultIndexService_IsReferenced(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	namespace := "default"
+	is, err := NewIndexService(mockObject.conf)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		kind common.Resource
+		apps []string
+		want []string
+	}{
+		{name: "zero", kind: common.Config, apps: nil, want: []string{}},
+		{name: "one", kind: common.Secret, apps: []string{"a1"}, want: []string{"a1"}},
+		{name: "many", kind: common.Config, apps: []string{"a3", "a1", "a2"}, want: []string{"a1", "a2", "a3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockObject.dbStorage.EXPECT().ListIndex(namespace, common.Application, tt.kind, tt.name).Return(tt.apps, nil)
+			referenced, apps, err := is.IsReferenced(namespace, string(tt.kind), tt.name)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.want) > 0, referenced)
+			assert.Equal(t, tt.want, apps)
+		})
+	}
+
+	mockObject.dbStorage.EXPECT().ListIndex(namespace, common.Application, common.Config, "c").Return(nil, fmt.Errorf("error"))
+	_, _, err = is.IsReferenced(namespace, string(common.Config), "c")
+	assert.Error(t, err)
+
+	_, _, err = is.IsReferenced(namespace, string(common.Node), "c")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+}
+
+func TestDefaultIndexService_ListAppsByConfig(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	namespace := "default"
+	is, err := NewIndexService(mockObject.conf)
+	assert.NoError(t, err)
+
+	// index of app -> configs/secrets kept in memory
+	index := map[common.Resource]map[string][]string{common.Config: {}, common.Secret: {}}
+	mockObject.dbStorage.EXPECT().RefreshIndex(namespace, common.Application, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, _, keyB common.Resource, app string, values []string) error {
+			index[keyB][app] = values
+			return nil
+		}).AnyTimes()
+	mockObject.dbStorage.EXPECT().ListIndex(namespace, common.Application, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, _, byKey common.Resource, value string) ([]string, error) {
+			var apps []string
+			for app, values := range index[byKey] {
+				for _, v := range values {
+					if v == value {
+						apps = append(apps, app)
+					}
+				}
+			}
+			return apps, nil
+		}).AnyTimes()
+	mockObject.dbStorage.EXPECT().CreateApplicationHistory(gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.dbStorage.EXPECT().DeleteApplicationHistory(gomock.Any(), namespace, gomock.Any(), "").Return(nil, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetConfig(namespace, gomock.Any(), "").Return(&specV1.Configuration{Version: "1"}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetSecret(namespace, gomock.Any(), "").Return(&specV1.Secret{Version: "1"}, nil).AnyTimes()
+
+	as := applicationService{
+		storage:      mockObject.modelStorage,
+		dbStorage:    mockObject.dbStorage,
+		indexService: is,
+	}
+	genApp := func(name string, configs, secrets []string) *specV1.Application {
+		app := &specV1.Application{Name: name, Namespace: namespace, Version: "1"}
+		for _, c := range configs {
+			app.Volumes = append(app.Volumes, specV1.Volume{Name: c, VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: c}}})
+		}
+		for _, c := range secrets {
+			app.Volumes = append(app.Volumes, specV1.Volume{Name: c, VolumeSource: specV1.VolumeSource{Secret: &specV1.ObjectReference{Name: c}}})
+		}
+		return app
+	}
+
+	apps, err := is.ListAppsByConfig(namespace, "c1")
+	assert.NoError(t, err)
+	assert.NotNil(t, apps)
+	assert.Len(t, apps, 0)
+
+	// create
+	for _, app := range []*specV1.Application{
+		genApp("b", []string{"c1"}, []string{"s1"}),
+		genApp("a", []string{"c1", "c2"}, nil),
+	} {
+		mockObject.modelStorage.EXPECT().CreateApplication(namespace, app).Return(app, nil)
+		_, err = as.Create(ctx, namespace, app)
+		assert.NoError(t, err)
+	}
+	apps, err = is.ListAppsByConfig(namespace, "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, apps)
+	apps, err = is.ListAppsBySecret(namespace, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, apps)
+
+	// update
+	app := genApp("a", []string{"c2"}, []string{"s1"})
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "a", "").Return(genApp("a", nil, nil), nil)
+	mockObject.modelStorage.EXPECT().UpdateApplication(namespace, app).Return(app, nil)
+	_, err = as.Update(ctx, namespace, app)
+	assert.NoError(t, err)
+	apps, err = is.ListAppsByConfig(namespace, "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, apps)
+	apps, err = is.ListAppsBySecret(namespace, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, apps)
+
+	// delete
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "b", "").Return(genApp("b", []string{"c1"}, []string{"s1"}), nil)
+	mockObject.modelStorage.EXPECT().DeleteApplication(namespace, "b").Return(nil)
+	err = as.Delete(ctx, namespace, "b", "")
+	assert.NoError(t, err)
+	apps, err = is.ListAppsByConfig(namespace, "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, apps)
+	apps, err = is.ListAppsBySecret(namespace, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, apps)
+
+	// the app mounting the config twice is counted once
+	app = &specV1.Application{Name: "c", Namespace: namespace, Version: "1", Volumes: []specV1.Volume{
+		{Name: "v1", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "c2"}}},
+		{Name: "v2", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "c2"}}},
+	}}
+	mockObject.modelStorage.EXPECT().CreateApplication(namespace, app).Return(app, nil)
+	_, err = as.Create(ctx, namespace, app)
+	assert.NoError(t, err)
+	count, err := is.CountReferences(namespace, string(common.Config), "c2")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	apps, err = is.ListAppsByConfig(namespace, "c2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, apps)
+	count, err = is.CountReferences(namespace, string(common.Config), "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	_, err = is.CountReferences(namespace, string(common.Node), "c2")
+	assert.Error(t, err)
+	assert.Equal(t, common.ErrRequestParamInvalid, err.(errors.Coder).Code())
+
+	mockObject.dbStorage.EXPECT().ListIndex("other", common.Application, common.Secret, "s1").Return(nil, fmt.Errorf("error"))
+	_, err = is.ListAppsBySecret("other", "s1")
+	assert.Error(t, err)
+}
+
+func TestDefaultIndexService_Rebuild(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	namespace := "default"
+	is, err := NewIndexService(mockObject.conf)
+	assert.NoError(t, err)
+
+	index := map[common.Resource]map[string][]string{
+		common.Config: {"a": {"c0"}, "gone": {"c1"}},
+		common.Secret: {},
+	}
+	refreshed := 0
+	mockObject.dbStorage.EXPECT().RefreshIndex(namespace, common.Application, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, _, keyB common.Resource, app string, values []string) error {
+			refreshed++
+			if len(values) == 0 {
+				delete(index[keyB], app)
+			} else {
+				index[keyB][app] = values
+			}
+			return nil
+		}).AnyTimes()
+	mockObject.dbStorage.EXPECT().ListIndex(namespace, gomock.Any(), common.Application, gomock.Any()).
+		DoAndReturn(func(_ string, keyA, _ common.Resource, app string) ([]string, error) {
+			return index[keyA][app], nil
+		}).AnyTimes()
+	mockObject.dbStorage.EXPECT().ListIndexKeys(namespace, common.Application, gomock.Any()).
+		DoAndReturn(func(_ string, _, keyB common.Resource) ([]string, error) {
+			var apps []string
+			for app := range index[keyB] {
+				apps = append(apps, app)
+			}
+			return apps, nil
+		}).AnyTimes()
+
+	appA := &specV1.Application{Name: "a", Volumes: []specV1.Volume{
+		{Name: "c1", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "c1"}}},
+		{Name: "s1", VolumeSource: specV1.VolumeSource{Secret: &specV1.ObjectReference{Name: "s1"}}},
+	}}
+	appB := &specV1.Application{Name: "b", Volumes: []specV1.Volume{
+		{Name: "c2", VolumeSource: specV1.VolumeSource{Config: &specV1.ObjectReference{Name: "c2"}}},
+	}}
+	mockObject.modelStorage.EXPECT().ListApplication(namespace, &models.ListOptions{}).
+		Return(&models.ApplicationList{Items: []models.AppItem{{Name: "a"}, {Name: "b"}}}, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "a", "").Return(appA, nil).AnyTimes()
+	mockObject.modelStorage.EXPECT().GetApplication(namespace, "b", "").Return(appB, nil).AnyTimes()
+
+	err = is.Rebuild(namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"a": {"c1"}, "b": {"c2"}}, index[common.Config])
+	assert.Equal(t, map[string][]string{"a": {"s1"}}, index[common.Secret])
+
+	// run repeatedly
+	refreshed = 0
+	err = is.Rebuild(namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, refreshed)
+	assert.Equal(t, map[string][]string{"a": {"c1"}, "b": {"c2"}}, index[common.Config])
+
+	mockObject.modelStorage.EXPECT().ListApplication("other", gomock.Any()).Return(nil, fmt.Errorf("error"))
+	err = is.Rebuild("other")
+	assert.Error(t, err)
+}
+
+func TestDefaultIndexService_RefreshConcurrently(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	// the storage writes entries one by one, overlapped refreshes tear the index
+	var mutex sync.Mutex
+	var inflight, overlapped int32
+	index := map[common.Resource][]string{}
+	mockObject.dbStorage.EXPECT().RefreshIndex("default", common.Application, gomock.Any(), "app", gomock.Any()).
+		DoAndReturn(func(_ string, _, res common.Resource, _ string, values []string) error {
+			if atomic.AddInt32(&inflight, 1) > 1 {
+				atomic.AddInt32(&overlapped, 1)
+			}
+			defer atomic.AddInt32(&inflight, -1)
+			mutex.Lock()
+			index[res] = nil
+			mutex.Unlock()
+			for _, v := range values {
+				runtime.Gosched()
+				if v == "bad" {
+					return fmt.Errorf("insert error")
+				}
+				mutex.Lock()
+				index[res] = append(index[res], v)
+				mutex.Unlock()
+			}
+			return nil
+		}).AnyTimes()
+	is, err := NewIndexService(mockObject.conf)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 50; n++ {
+		wg.Add(2)
+		values := []string{fmt.Sprintf("a-%d", n), fmt.Sprintf("b-%d", n), fmt.Sprintf("c-%d", n)}
+		if n%10 == 0 {
+			// the lock is released on errors
+			values = append(values, "bad")
+		}
+		go func() {
+			defer wg.Done()
+			is.RefreshConfigIndexByApp("default", "app", values)
+		}()
+		go func() {
+			defer wg.Done()
+			is.RefreshSecretIndexByApp("default", "app", values)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), overlapped)
+	for _, res := range []common.Resource{common.Config, common.Secret} {
+		values := index[res]
+		assert.Len(t, values, 3)
+		sort.Strings(values)
+		n := values[0][2:]
+		assert.Equal(t, []string{"a-" + n, "b-" + n, "c-" + n}, values)
+	}
+	assert.Len(t, is.(*indexService).locks.locks, 0)
+
+	// other apps are not blocked
+	mockObject.dbStorage.EXPECT().RefreshIndex("default", common.Application, common.Config, "other", []string{"x"}).Return(nil)
+	unlock := is.(*indexService).locks.lock("default", "app")
+	assert.NoError(t, is.RefreshConfigIndexByApp("default", "other", []string{"x"}))
+	unlock()
+}
+
+func TestDefaultIndexService_RefreshIndexByApps(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	is, err := NewIndexService(mockObject.conf)
+	assert.NoError(t, err)
+	configs := map[string][]string{"app0": {"config0"}, "app1": {}}
+	secrets := map[string][]string{"app0": {"secret0"}}
+	mockObject.dbStorage.EXPECT().RefreshIndexes("default", common.Application, common.Config, configs).Return(nil)
+	mockObject.dbStorage.EXPECT().RefreshIndexes("default", common.Application, common.Secret, secrets).Return(fmt.Errorf("error"))
+	assert.NoError(t, is.RefreshConfigIndexByApps("default", configs))
+	assert.Error(t, is.RefreshSecretIndexByApps("default", secrets))
+	// nothing to refresh
+	assert.NoError(t, is.RefreshConfigIndexByApps("default", nil))
+	assert.Len(t, is.(*indexService).locks.locks, 0)
+}
@@ -1,7 +1,7 @@
 package service
 
 import (
-	"strings"
+	"errors"
 
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/config"
@@ -36,7 +36,7 @@ func NewNamespaceService(config *config.CloudConfig) (NamespaceService, error) {
 // Get get a namespace
 func (s *namespaceService) Get(namespace string) (*models.Namespace, error) {
 	res, err := s.storage.GetNamespace(namespace)
-	if err != nil && strings.Contains(err.Error(), "not found") {
+	if err != nil && errors.Is(err, plugin.ErrNotFound) {
 		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "namespace"),
 			common.Field("namespace", namespace))
 	}
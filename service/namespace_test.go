@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,7 +38,7 @@ func TestNamespaceService_Get(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, res)
 
-	mockObject.modelStorage.EXPECT().GetNamespace(ns.Name).Return(nil, fmt.Errorf("namespaces \"user-id-test\" not found"))
+	mockObject.modelStorage.EXPECT().GetNamespace(ns.Name).Return(nil, plugin.NotFound(fmt.Errorf("namespaces \"user-id-test\" not found")))
 	res, err = cs.Get(ns.Name)
 	assert.Error(t, err)
 	assert.Equal(t, true, res == nil)
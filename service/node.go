@@ -1,7 +1,7 @@
 package service
 
 import (
-	"strings"
+	"errors"
 
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/config"
@@ -63,7 +63,7 @@ func NewNodeService(config *config.CloudConfig) (NodeService, error) {
 // Get get the node
 func (n *nodeService) Get(namespace, name string) (*specV1.Node, error) {
 	node, err := n.storage.GetNode(namespace, name)
-	if err != nil && strings.Contains(err.Error(), "not found") {
+	if err != nil && errors.Is(err, plugin.ErrNotFound) {
 		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "node"),
 			common.Field("name", name))
 	} else if err != nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/baetyl/baetyl-cloud/common"
 	ms "github.com/baetyl/baetyl-cloud/mock/service"
 	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
 	"github.com/baetyl/baetyl-go/spec/v1"
 	specV1 "github.com/baetyl/baetyl-go/spec/v1"
 	"github.com/golang/mock/gomock"
@@ -57,7 +58,7 @@ func TestDefaultNodeService_Get(t *testing.T) {
 	_, err = cs.Get(node.Namespace, node.Name)
 	assert.NoError(t, err)
 
-	mockObject.modelStorage.EXPECT().GetNode(node.Namespace, node.Name).Return(nil, fmt.Errorf("node not found"))
+	mockObject.modelStorage.EXPECT().GetNode(node.Namespace, node.Name).Return(nil, plugin.NotFound(fmt.Errorf("node not found")))
 	n, err := cs.Get(node.Namespace, node.Name)
 	assert.Error(t, err)
 	assert.Nil(t, n)
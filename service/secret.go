@@ -1,7 +1,7 @@
 package service
 
 import (
-	"strings"
+	"errors"
 
 	"github.com/baetyl/baetyl-cloud/common"
 	"github.com/baetyl/baetyl-cloud/config"
@@ -39,7 +39,7 @@ func NewSecretService(config *config.CloudConfig) (SecretService, error) {
 // Get get a Secret
 func (s *secretService) Get(namespace, name, version string) (*specV1.Secret, error) {
 	res, err := s.storage.GetSecret(namespace, name, version)
-	if err != nil && strings.Contains(err.Error(), "not found") {
+	if err != nil && errors.Is(err, plugin.ErrNotFound) {
 		return nil, common.Error(common.ErrResourceNotFound, common.Field("type", "secret"),
 			common.Field("name", name))
 	}
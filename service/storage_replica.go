@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+)
+
+// replicaModelStorage serves the gets and lists of models from replica and writes them to primary.
+// The replica lags behind, so the object written within lag which is not found in replica is read from primary again,
+// and so is the object deleted within lag.
+// The namespaces and shadows are always served by primary.
+type replicaModelStorage struct {
+	plugin.ModelStorage
+	replica plugin.ModelStorage
+	lag     time.Duration
+	now     func() time.Time
+	mutex   sync.Mutex
+	writes  map[replicaKey]replicaWrite
+	// sweepAt the size of writes at which the expired records are swept, the ones looked up are pruned on lookup
+	sweepAt int
+}
+
+type replicaKey struct {
+	kind, namespace, name string
+}
+
+type replicaWrite struct {
+	at      time.Time
+	deleted bool
+}
+
+// replicaSweepSize the least size of writes to sweep the expired records
+const replicaSweepSize = 1024
+
+func withReplica(primary, replica plugin.ModelStorage, lag time.Duration) plugin.ModelStorage {
+	if replica == nil {
+		return primary
+	}
+	return &replicaModelStorage{
+		ModelStorage: primary,
+		replica:      replica,
+		lag:          lag,
+		now:          time.Now,
+		writes:       map[replicaKey]replicaWrite{},
+		sweepAt:      replicaSweepSize,
+	}
+}
+
+// written record the successful write of object. The expired records are swept once writes doubles since the last sweep,
+// so a write costs O(1) amortized
+func (r *replicaModelStorage) written(kind, namespace, name string, deleted bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := r.now()
+	r.writes[replicaKey{kind: kind, namespace: namespace, name: name}] = replicaWrite{at: now, deleted: deleted}
+	if len(r.writes) < r.sweepAt {
+		return
+	}
+	for k, w := range r.writes {
+		if now.Sub(w.at) > r.lag {
+			delete(r.writes, k)
+		}
+	}
+	r.sweepAt = 2 * len(r.writes)
+	if r.sweepAt < replicaSweepSize {
+		r.sweepAt = replicaSweepSize
+	}
+}
+
+// fresh tell whether the result of replica may be stale by lag, which is the object deleted within lag,
+// or the one written within lag but not found. The expired record is pruned
+func (r *replicaModelStorage) fresh(err error, kind, namespace, name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	k := replicaKey{kind: kind, namespace: namespace, name: name}
+	w, ok := r.writes[k]
+	if !ok {
+		return false
+	}
+	if r.now().Sub(w.at) > r.lag {
+		delete(r.writes, k)
+		return false
+	}
+	return w.deleted || err != nil && errors.Is(err, plugin.ErrNotFound)
+}
+
+func (r *replicaModelStorage) GetNode(namespace, name string) (*specV1.Node, error) {
+	res, err := r.replica.GetNode(namespace, name)
+	if r.fresh(err, "node", namespace, name) {
+		return r.ModelStorage.GetNode(namespace, name)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) CreateNode(namespace string, node *specV1.Node) (*specV1.Node, error) {
+	res, err := r.ModelStorage.CreateNode(namespace, node)
+	if err == nil {
+		r.written("node", namespace, node.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) UpdateNode(namespace string, node *specV1.Node) (*specV1.Node, error) {
+	res, err := r.ModelStorage.UpdateNode(namespace, node)
+	if err == nil {
+		r.written("node", namespace, node.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) DeleteNode(namespace, name string) error {
+	err := r.ModelStorage.DeleteNode(namespace, name)
+	if err == nil {
+		r.written("node", namespace, name, true)
+	}
+	return err
+}
+
+func (r *replicaModelStorage) ListNode(namespace string, listOptions *models.ListOptions) (*models.NodeList, error) {
+	return r.replica.ListNode(namespace, listOptions)
+}
+
+func (r *replicaModelStorage) GetConfig(namespace, name, version string) (*specV1.Configuration, error) {
+	return r.GetConfigContext(context.Background(), namespace, name, version)
+}
+
+func (r *replicaModelStorage) GetConfigContext(ctx context.Context, namespace, name, version string) (*specV1.Configuration, error) {
+	res, err := withStorageContext(ctx, r.replica).GetConfig(namespace, name, version)
+	if r.fresh(err, "config", namespace, name) {
+		return withStorageContext(ctx, r.ModelStorage).GetConfig(namespace, name, version)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) CreateConfig(namespace string, config *specV1.Configuration) (*specV1.Configuration, error) {
+	return r.CreateConfigContext(context.Background(), namespace, config)
+}
+
+func (r *replicaModelStorage) CreateConfigContext(ctx context.Context, namespace string, config *specV1.Configuration) (*specV1.Configuration, error) {
+	res, err := withStorageContext(ctx, r.ModelStorage).CreateConfig(namespace, config)
+	if err == nil {
+		r.written("config", namespace, config.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) UpdateConfig(namespace string, config *specV1.Configuration) (*specV1.Configuration, error) {
+	return r.UpdateConfigContext(context.Background(), namespace, config)
+}
+
+func (r *replicaModelStorage) UpdateConfigContext(ctx context.Context, namespace string, config *specV1.Configuration) (*specV1.Configuration, error) {
+	res, err := withStorageContext(ctx, r.ModelStorage).UpdateConfig(namespace, config)
+	if err == nil {
+		r.written("config", namespace, config.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) DeleteConfig(namespace, name string) error {
+	err := r.ModelStorage.DeleteConfig(namespace, name)
+	if err == nil {
+		r.written("config", namespace, name, true)
+	}
+	return err
+}
+
+func (r *replicaModelStorage) ListConfig(namespace string, listOptions *models.ListOptions) (*models.ConfigurationList, error) {
+	return r.ListConfigContext(context.Background(), namespace, listOptions)
+}
+
+func (r *replicaModelStorage) ListConfigContext(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ConfigurationList, error) {
+	return withStorageContext(ctx, r.replica).ListConfig(namespace, listOptions)
+}
+
+func (r *replicaModelStorage) GetApplication(namespace, name, version string) (*specV1.Application, error) {
+	return r.GetApplicationContext(context.Background(), namespace, name, version)
+}
+
+func (r *replicaModelStorage) GetApplicationContext(ctx context.Context, namespace, name, version string) (*specV1.Application, error) {
+	res, err := withStorageContext(ctx, r.replica).GetApplication(namespace, name, version)
+	if r.fresh(err, "app", namespace, name) {
+		return withStorageContext(ctx, r.ModelStorage).GetApplication(namespace, name, version)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) CreateApplication(namespace string, application *specV1.Application) (*specV1.Application, error) {
+	return r.CreateApplicationContext(context.Background(), namespace, application)
+}
+
+func (r *replicaModelStorage) CreateApplicationContext(ctx context.Context, namespace string, application *specV1.Application) (*specV1.Application, error) {
+	res, err := withStorageContext(ctx, r.ModelStorage).CreateApplication(namespace, application)
+	if err == nil {
+		r.written("app", namespace, application.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) UpdateApplication(namespace string, application *specV1.Application) (*specV1.Application, error) {
+	return r.UpdateApplicationContext(context.Background(), namespace, application)
+}
+
+func (r *replicaModelStorage) UpdateApplicationContext(ctx context.Context, namespace string, application *specV1.Application) (*specV1.Application, error) {
+	res, err := withStorageContext(ctx, r.ModelStorage).UpdateApplication(namespace, application)
+	if err == nil {
+		r.written("app", namespace, application.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) DeleteApplication(namespace, name string) error {
+	return r.DeleteApplicationContext(context.Background(), namespace, name)
+}
+
+func (r *replicaModelStorage) DeleteApplicationContext(ctx context.Context, namespace, name string) error {
+	err := withStorageContext(ctx, r.ModelStorage).DeleteApplication(namespace, name)
+	if err == nil {
+		r.written("app", namespace, name, true)
+	}
+	return err
+}
+
+// GetApplications get the apps from replica in one query if it supports, the missing ones written within lag
+// and the ones deleted within lag are read from primary
+func (r *replicaModelStorage) GetApplications(namespace string, names []string) ([]specV1.Application, error) {
+	bg, ok := r.replica.(plugin.ApplicationBatchGetter)
+	if !ok {
+		return getEachApplication(r, namespace, names)
+	}
+	res, err := bg.GetApplications(namespace, names)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(res))
+	apps := res[:0]
+	for _, app := range res {
+		if r.fresh(nil, "app", namespace, app.Name) {
+			continue
+		}
+		found[app.Name] = true
+		apps = append(apps, app)
+	}
+	res = apps
+	for _, name := range names {
+		if found[name] || !r.fresh(plugin.ErrNotFound, "app", namespace, name) {
+			continue
+		}
+		app, err := r.ModelStorage.GetApplication(namespace, name, "")
+		if err != nil {
+			if errors.Is(err, plugin.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		res = append(res, *app)
+	}
+	return res, nil
+}
+
+func (r *replicaModelStorage) UpdateApplicationVersion(namespace string, application *specV1.Application, version string) (*specV1.Application, error) {
+	versioner, ok := r.ModelStorage.(plugin.ApplicationVersioner)
+	if !ok {
+		return nil, common.Error(common.ErrNotSupported, common.Field("name", "update with version"))
+	}
+	res, err := versioner.UpdateApplicationVersion(namespace, application, version)
+	if err == nil {
+		r.written("app", namespace, application.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) ListApplication(namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	return r.ListApplicationContext(context.Background(), namespace, listOptions)
+}
+
+func (r *replicaModelStorage) ListApplicationContext(ctx context.Context, namespace string, listOptions *models.ListOptions) (*models.ApplicationList, error) {
+	return withStorageContext(ctx, r.replica).ListApplication(namespace, listOptions)
+}
+
+func (r *replicaModelStorage) GetSecret(namespace, name, version string) (*specV1.Secret, error) {
+	return r.GetSecretContext(context.Background(), namespace, name, version)
+}
+
+func (r *replicaModelStorage) GetSecretContext(ctx context.Context, namespace, name, version string) (*specV1.Secret, error) {
+	res, err := withStorageContext(ctx, r.replica).GetSecret(namespace, name, version)
+	if r.fresh(err, "secret", namespace, name) {
+		return withStorageContext(ctx, r.ModelStorage).GetSecret(namespace, name, version)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) CreateSecret(namespace string, secret *specV1.Secret) (*specV1.Secret, error) {
+	return r.CreateSecretContext(context.Background(), namespace, secret)
+}
+
+func (r *replicaModelStorage) CreateSecretContext(ctx context.Context, namespace string, secret *specV1.Secret) (*specV1.Secret, error) {
+	res, err := withStorageContext(ctx, r.ModelStorage).CreateSecret(namespace, secret)
+	if err == nil {
+		r.written("secret", namespace, secret.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) UpdateSecret(namespace string, secret *specV1.Secret) (*specV1.Secret, error) {
+	return r.UpdateSecretContext(context.Background(), namespace, secret)
+}
+
+func (r *replicaModelStorage) UpdateSecretContext(ctx context.Context, namespace string, secret *specV1.Secret) (*specV1.Secret, error) {
+	res, err := withStorageContext(ctx, r.ModelStorage).UpdateSecret(namespace, secret)
+	if err == nil {
+		r.written("secret", namespace, secret.Name, false)
+	}
+	return res, err
+}
+
+func (r *replicaModelStorage) DeleteSecret(namespace, name string) error {
+	err := r.ModelStorage.DeleteSecret(namespace, name)
+	if err == nil {
+		r.written("secret", namespace, name, true)
+	}
+	return err
+}
+
+func (r *replicaModelStorage) ListSecret(namespace string, listOptions *models.ListOptions) (*models.SecretList, error) {
+	return r.replica.ListSecret(namespace, listOptions)
+}
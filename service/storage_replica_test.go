@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mockPlugin "github.com/baetyl/baetyl-cloud/mock/plugin"
+	"github.com/baetyl/baetyl-cloud/models"
+	"github.com/baetyl/baetyl-cloud/plugin"
+	specV1 "github.com/baetyl/baetyl-go/spec/v1"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaModelStorage(t *testing.T) {
+	ctx := context.Background()
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	primary := mockObject.modelStorage
+	replica := mockPlugin.NewMockModelStorage(mockObject.ctl)
+	assert.Equal(t, primary, withReplica(primary, nil, time.Second))
+
+	now := time.Unix(1000, 0)
+	rs := withReplica(primary, replica, 5*time.Second).(*replicaModelStorage)
+	rs.now = func() time.Time { return now }
+	notFound := plugin.NotFound(fmt.Errorf("applications.cloud.baetyl.io \"abc\" not found"))
+
+	// reads are served by replica
+	app := &specV1.Application{Namespace: "default", Name: "abc", Version: "1"}
+	replica.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	res, err := rs.GetApplication("default", "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, app, res)
+	list := &models.ApplicationList{Total: 1}
+	replica.EXPECT().ListApplication("default", gomock.Any()).Return(list, nil)
+	l, err := rs.ListApplication("default", &models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, list, l)
+
+	// the missing one is not read again if it's not written recently
+	replica.EXPECT().GetApplication("default", "def", "").Return(nil, notFound)
+	_, err = rs.GetApplication("default", "def", "")
+	assert.Equal(t, notFound, err)
+
+	// the replica lags behind the write
+	created := &specV1.Application{Namespace: "default", Name: "def", Version: "2"}
+	primary.EXPECT().CreateApplication("default", gomock.Any()).Return(created, nil)
+	_, err = rs.CreateApplication("default", &specV1.Application{Name: "def"})
+	assert.NoError(t, err)
+	now = now.Add(5 * time.Second)
+	replica.EXPECT().GetApplication("default", "def", "2").Return(nil, notFound)
+	primary.EXPECT().GetApplication("default", "def", "2").Return(created, nil)
+	res, err = rs.GetApplication("default", "def", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, created, res)
+
+	// other errors are returned
+	replica.EXPECT().GetApplication("default", "def", "").Return(nil, fmt.Errorf("timeout"))
+	_, err = rs.GetApplication("default", "def", "")
+	assert.EqualError(t, err, "timeout")
+
+	// the replica catches up after lag, the expired record is pruned on lookup
+	now = now.Add(time.Second)
+	replica.EXPECT().GetApplication("default", "def", "").Return(nil, notFound)
+	_, err = rs.GetApplication("default", "def", "")
+	assert.Equal(t, notFound, err)
+	assert.Len(t, rs.writes, 0)
+	primary.EXPECT().UpdateConfig("default", gomock.Any()).Return(&specV1.Configuration{}, nil)
+	_, err = rs.UpdateConfig("default", &specV1.Configuration{Name: "c"})
+	assert.NoError(t, err)
+	assert.Len(t, rs.writes, 1)
+
+	// the kinds are not mixed up
+	replica.EXPECT().GetSecret("default", "c", "").Return(nil, notFound)
+	_, err = rs.GetSecret("default", "c", "")
+	assert.Equal(t, notFound, err)
+	replica.EXPECT().GetConfig("default", "c", "").Return(nil, notFound)
+	primary.EXPECT().GetConfig("default", "c", "").Return(&specV1.Configuration{Name: "c"}, nil)
+	cfg, err := rs.GetConfig("default", "c", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", cfg.Name)
+
+	// batch get falls back to the one by one gets if replica doesn't support it
+	replica.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	replica.EXPECT().GetApplication("default", "def", "").Return(nil, notFound)
+	apps, err := rs.GetApplications("default", []string{"abc", "def"})
+	assert.NoError(t, err)
+	assert.Equal(t, []specV1.Application{*app}, apps)
+
+	// the missing ones written recently are read from primary
+	batch := mockPlugin.NewMockApplicationBatchGetter(mockObject.ctl)
+	rs.replica = &batchModelStorage{MockModelStorage: replica, MockApplicationBatchGetter: batch}
+	written := &specV1.Application{Namespace: "default", Name: "jkl", Version: "4"}
+	primary.EXPECT().UpdateApplication("default", gomock.Any()).Return(written, nil)
+	_, err = rs.UpdateApplication("default", &specV1.Application{Name: "jkl"})
+	assert.NoError(t, err)
+	batch.EXPECT().GetApplications("default", []string{"abc", "jkl", "def"}).Return([]specV1.Application{*app}, nil)
+	primary.EXPECT().GetApplication("default", "jkl", "").Return(written, nil)
+	apps, err = rs.GetApplications("default", []string{"abc", "jkl", "def"})
+	assert.NoError(t, err)
+	assert.Equal(t, []specV1.Application{*app, *written}, apps)
+
+	// the ones deleted recently are read from primary even if replica still has them
+	primary.EXPECT().DeleteApplication("default", "abc").Return(nil)
+	assert.NoError(t, rs.DeleteApplication("default", "abc"))
+	batch.EXPECT().GetApplications("default", []string{"abc"}).Return([]specV1.Application{*app}, nil)
+	primary.EXPECT().GetApplication("default", "abc", "").Return(nil, notFound)
+	apps, err = rs.GetApplications("default", []string{"abc"})
+	assert.NoError(t, err)
+	assert.Len(t, apps, 0)
+	rs.replica = replica
+	replica.EXPECT().GetApplication("default", "abc", "").Return(app, nil)
+	primary.EXPECT().GetApplication("default", "abc", "").Return(nil, notFound)
+	_, err = rs.GetApplication("default", "abc", "")
+	assert.Equal(t, notFound, err)
+
+	// the failed writes are not recorded
+	primary.EXPECT().CreateApplication("default", gomock.Any()).Return(nil, fmt.Errorf("error"))
+	_, err = rs.CreateApplication("default", &specV1.Application{Name: "mno"})
+	assert.Error(t, err)
+	primary.EXPECT().DeleteSecret("default", "mno").Return(notFound)
+	assert.Equal(t, notFound, rs.DeleteSecret("default", "mno"))
+	replica.EXPECT().GetApplication("default", "mno", "").Return(nil, notFound)
+	_, err = rs.GetApplication("default", "mno", "")
+	assert.Equal(t, notFound, err)
+	replica.EXPECT().GetSecret("default", "mno", "").Return(&specV1.Secret{Name: "mno"}, nil)
+	_, err = rs.GetSecret("default", "mno", "")
+	assert.NoError(t, err)
+
+	// transparent to application service
+	as := applicationService{storage: rs}
+	replica.EXPECT().GetApplication("default", "ghi", "").Return(nil, notFound)
+	_, err = as.Get(ctx, "default", "ghi", "")
+	assert.Error(t, err)
+	primary.EXPECT().UpdateApplication("default", gomock.Any()).Return(&specV1.Application{}, nil)
+	_, err = rs.UpdateApplication("default", &specV1.Application{Name: "ghi"})
+	assert.NoError(t, err)
+	replica.EXPECT().GetApplication("default", "ghi", "").Return(nil, notFound)
+	primary.EXPECT().GetApplication("default", "ghi", "").Return(&specV1.Application{Name: "ghi", Version: "3"}, nil)
+	res, err = as.Get(ctx, "default", "ghi", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", res.Version)
+}
+
+func TestReplicaModelStorage_Sweep(t *testing.T) {
+	mockObject := InitMockEnvironment(t)
+	defer mockObject.Close()
+
+	now := time.Unix(1000, 0)
+	rs := withReplica(mockObject.modelStorage, mockPlugin.NewMockModelStorage(mockObject.ctl), time.Second).(*replicaModelStorage)
+	rs.now = func() time.Time { return now }
+
+	// the records never looked up are swept once writes reaches the size
+	for i := 0; i < replicaSweepSize-1; i++ {
+		rs.written("app", "default", fmt.Sprintf("app-%d", i), false)
+	}
+	now = now.Add(2 * time.Second)
+	assert.Len(t, rs.writes, replicaSweepSize-1)
+	rs.written("app", "default", "last", false)
+	assert.Len(t, rs.writes, 1)
+	assert.Equal(t, replicaSweepSize, rs.sweepAt)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -109,7 +110,7 @@ func (t *syncService) Desire(namespace string, crdInfos []specV1.ResourceInfo) (
 		log.L().Info("sync get crd", log.Any("kind", info.Kind), log.Any("name", info.Name))
 		switch info.Kind {
 		case specV1.KindApplication, specV1.KindApp:
-			app, err := t.as.Get(namespace, info.Name, info.Version)
+			app, err := t.as.Get(context.Background(), namespace, info.Name, info.Version)
 			if err != nil {
 				log.L().Error("failed to get application", log.Any(common.KeyContextNamespace, namespace), log.Any("name", info.Name))
 				return nil, err
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/baetyl/baetyl-cloud/common"
+	"github.com/baetyl/baetyl-go/log"
+)
+
+// txn a compensating transaction shared by storage and index writes.
+// Steps run in order, once a step fails or ctx is done the rest are skipped and the undos of done steps run in reverse order.
+type txn struct {
+	ctx   context.Context
+	err   error
+	undos []func() error
+}
+
+// do run the step if no step failed before, undo is registered only if the step succeeds
+func (t *txn) do(step, undo func() error) {
+	if t.err != nil {
+		return
+	}
+	if t.ctx != nil {
+		if t.err = t.ctx.Err(); t.err != nil {
+			return
+		}
+	}
+	if t.err = step(); t.err == nil && undo != nil {
+		t.undos = append(t.undos, undo)
+	}
+}
+
+// end roll back if any step failed and return its error, failures of undo are logged as dirty data
+func (t *txn) end(fields ...log.Field) error {
+	if t.err == nil {
+		return nil
+	}
+	for i := len(t.undos) - 1; i >= 0; i-- {
+		if err := t.undos[i](); err != nil {
+			common.LogDirtyData(err, fields...)
+		}
+	}
+	t.undos = nil
+	return t.err
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxn(t *testing.T) {
+	var steps []string
+	step := func(name string, err error) func() error {
+		return func() error {
+			steps = append(steps, name)
+			return err
+		}
+	}
+
+	tx := &txn{}
+	tx.do(step("a", nil), step("undo a", nil))
+	tx.do(step("b", nil), nil)
+	assert.NoError(t, tx.end())
+	assert.Equal(t, []string{"a", "b"}, steps)
+
+	steps = nil
+	tx = &txn{}
+	tx.do(step("a", nil), step("undo a", fmt.Errorf("error")))
+	tx.do(step("b", nil), step("undo b", nil))
+	tx.do(step("c", fmt.Errorf("c failed")), step("undo c", nil))
+	tx.do(step("d", nil), step("undo d", nil))
+	err := tx.end()
+	assert.EqualError(t, err, "c failed")
+	assert.Equal(t, []string{"a", "b", "c", "undo b", "undo a"}, steps)
+
+	steps = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	tx = &txn{ctx: ctx}
+	tx.do(step("a", nil), step("undo a", nil))
+	cancel()
+	tx.do(step("b", nil), step("undo b", nil))
+	assert.Equal(t, context.Canceled, tx.end())
+	assert.Equal(t, []string{"a", "undo a"}, steps)
+}